@@ -0,0 +1,109 @@
+package azfile
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ModifiedAccessConditions identifies standard HTTP access conditions which you optionally set.
+// The File service doesn't evaluate conditional headers, so the conditions are evaluated by the client, against the
+// properties the resource has just before the operation is sent. This isn't atomic: a concurrent writer can still
+// change the resource in between. Hold a lease on the resource to exclude other writers.
+// An operation whose conditions aren't met fails with a StorageError whose ServiceCode is ServiceCodeConditionNotMet.
+type ModifiedAccessConditions struct {
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+	IfMatch           ETag
+	IfNoneMatch       ETag
+}
+
+// FileAccessConditions identifies file-specific access conditions which you optionally set.
+type FileAccessConditions struct {
+	ModifiedAccessConditions
+	LeaseAccessConditions
+}
+
+// ShareAccessConditions identifies share-specific access conditions which you optionally set.
+type ShareAccessConditions struct {
+	ModifiedAccessConditions
+	LeaseAccessConditions
+}
+
+// isSet reports whether any of the conditions is set.
+func (ac ModifiedAccessConditions) isSet() bool {
+	return !ac.IfModifiedSince.IsZero() || !ac.IfUnmodifiedSince.IsZero() || ac.IfMatch != ETagNone || ac.IfNoneMatch != ETagNone
+}
+
+// met reports whether the conditions are met by a resource with the specified ETag and last modified time.
+func (ac ModifiedAccessConditions) met(etag ETag, lastModified time.Time) bool {
+	switch {
+	case ac.IfMatch != ETagNone && ac.IfMatch != ETagAny && ac.IfMatch != etag:
+		return false
+	case ac.IfNoneMatch == ETagAny || (ac.IfNoneMatch != ETagNone && ac.IfNoneMatch == etag):
+		return false
+	case !ac.IfModifiedSince.IsZero() && !lastModified.After(ac.IfModifiedSince):
+		return false
+	case !ac.IfUnmodifiedSince.IsZero() && lastModified.After(ac.IfUnmodifiedSince):
+		return false
+	}
+	return true
+}
+
+// propertiesResponse is implemented by the responses of the GetProperties operations.
+type propertiesResponse interface {
+	Response() *http.Response
+	ETag() ETag
+	LastModified() time.Time
+}
+
+// check is for internal infrastructure. If a condition is set, it evaluates the conditions against the properties
+// getProperties returns. If mayNotExist, a resource that doesn't exist meets all the conditions but IfMatch.
+func (ac ModifiedAccessConditions) check(ctx context.Context, mayNotExist bool,
+	getProperties func(ctx context.Context) (propertiesResponse, error)) error {
+	if !ac.isSet() {
+		return nil
+	}
+	props, err := getProperties(ctx)
+	if err != nil {
+		storageErr, ok := err.(StorageError)
+		if !ok || !mayNotExist || storageErr.Response() == nil || storageErr.Response().StatusCode != http.StatusNotFound {
+			return err
+		}
+		if ac.IfMatch == ETagNone {
+			return nil
+		}
+		return newConditionNotMetError(storageErr.Response())
+	}
+	if !ac.met(props.ETag(), props.LastModified()) {
+		return newConditionNotMetError(props.Response())
+	}
+	return nil
+}
+
+// newConditionNotMetError returns the StorageError of an operation whose access conditions aren't met by the
+// resource's properties, which resp returned.
+func newConditionNotMetError(resp *http.Response) error {
+	r := *resp
+	r.StatusCode = http.StatusPreconditionFailed
+	r.Status = "412 The condition specified using HTTP conditional header(s) is not met."
+	r.Header = http.Header{}
+	r.Header.Set("x-ms-error-code", string(ServiceCodeConditionNotMet))
+	r.Header.Set("x-ms-request-id", resp.Header.Get("x-ms-request-id"))
+	r.Body = http.NoBody
+	return newStorageError(nil, &r, "The condition specified using HTTP conditional header(s) is not met.")
+}
+
+// LeaseAccessConditions identifies lease access conditions for a file or share which you optionally set.
+// When LeaseID is set, the operation only succeeds if the resource's lease is active and matches this ID.
+type LeaseAccessConditions struct {
+	LeaseID string
+}
+
+// pointers is for internal infrastructure. It returns the fields as pointers.
+func (ac LeaseAccessConditions) pointers() (leaseID *string) {
+	if ac.LeaseID != "" {
+		leaseID = &ac.LeaseID
+	}
+	return
+}
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"bytes"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
@@ -23,7 +28,8 @@ const (
 
 // UploadToAzureFileOptions identifies options used by the UploadBufferToAzureFile and UploadFileToAzureFile functions.
 type UploadToAzureFileOptions struct {
-	// RangeSize specifies the range size to use in each parallel upload; the default (and maximum size) is FileMaxUploadRangeBytes.
+	// RangeSize specifies the range size to use in each parallel upload; the default is FileMaxUploadRangeBytes, and
+	// larger sizes are clamped to it.
 	RangeSize int64
 
 	// Progress is a function that is invoked periodically as bytes are send in a UploadRange call to the FileURL.
@@ -32,28 +38,60 @@ type UploadToAzureFileOptions struct {
 	// Parallelism indicates the maximum number of ranges to upload in parallel. If 0(default) is provided, 5 parallelism will be used by default.
 	Parallelism uint16
 
-	// FileHTTPHeaders contains read/writeable file properties.
+	// FileHTTPHeaders contains read/writeable file properties. Its EncryptionScope is used for the ranges too.
 	FileHTTPHeaders FileHTTPHeaders
 
 	// Metadata contains metadata key/value pairs.
 	Metadata Metadata
 }
 
-// UploadBufferToAzureFile uploads a buffer to an Azure file.
-// Note: o.RangeSize must be >= 0 and <= FileMaxUploadRangeBytes, and if not specified, method will use FileMaxUploadRangeBytes by default.
+// UploadBufferToAzureFile uploads a buffer to an Azure file, like UploadReaderAtToAzureFile.
+// Note: o.RangeSize must be >= 0, and if not specified or larger than FileMaxUploadRangeBytes, method will use FileMaxUploadRangeBytes.
 // The total size to be uploaded should be <= FileMaxSizeInBytes.
 func UploadBufferToAzureFile(ctx context.Context, b []byte,
 	fileURL FileURL, o UploadToAzureFileOptions) error {
+	return UploadReaderAtToAzureFile(ctx, bytes.NewReader(b), int64(len(b)), fileURL, o)
+}
+
+// RangeUploadError is returned by UploadReaderAtToAzureFile, and the functions built on it, when a range fails to
+// upload.
+type RangeUploadError struct {
+	// Offset and Count are the offset in the file of the range that failed, and its size, in bytes.
+	Offset, Count int64
+
+	// Err is the error of the range's upload.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RangeUploadError) Error() string {
+	return fmt.Sprintf("failed to upload the %d bytes at offset %d: %v", e.Count, e.Offset, e.Err)
+}
+
+// Unwrap returns the error of the range's upload, for errors.Is and errors.As.
+func (e *RangeUploadError) Unwrap() error {
+	return e.Err
+}
+
+// UploadReaderAtToAzureFile uploads the first size bytes of reader to an Azure file. The Azure file is created with
+// size bytes, and then [0, size) is uploaded in o.RangeSize ranges by o.Parallelism goroutines, which read them with
+// concurrent calls to reader's ReadAt. If a range fails, the outstanding uploads are cancelled and the failure is
+// returned as a *RangeUploadError; if ctx is done, its error is returned.
+// Note: o.RangeSize must be >= 0, and if not specified or larger than FileMaxUploadRangeBytes, method will use FileMaxUploadRangeBytes.
+// size should be <= FileMaxSizeInBytes.
+func UploadReaderAtToAzureFile(ctx context.Context, reader io.ReaderAt, size int64,
+	fileURL FileURL, o UploadToAzureFileOptions) error {
 
 	// 1. Validate parameters, and set defaults.
-	if o.RangeSize < 0 || o.RangeSize > FileMaxUploadRangeBytes {
-		return fmt.Errorf("invalid argument, o.RangeSize must be >= 0 and <= %d, in bytes", FileMaxUploadRangeBytes)
+	if o.RangeSize < 0 {
+		return errors.New("invalid argument, o.RangeSize must be >= 0")
 	}
-	if o.RangeSize == 0 {
-		o.RangeSize = FileMaxUploadRangeBytes
+	if o.RangeSize == 0 || o.RangeSize > FileMaxUploadRangeBytes {
+		o.RangeSize = FileMaxUploadRangeBytes // UploadRange doesn't accept larger ranges
+	}
+	if size < 0 {
+		return errors.New("invalid argument, size must be >= 0")
 	}
-
-	size := int64(len(b))
 
 	parallelism := o.Parallelism
 	if parallelism == 0 {
@@ -61,7 +99,7 @@ func UploadBufferToAzureFile(ctx context.Context, b []byte,
 	}
 
 	// 2. Try to create the Azure file.
-	_, err := fileURL.Create(ctx, size, o.FileHTTPHeaders, o.Metadata)
+	_, err := fileURL.Create(ctx, size, o.FileHTTPHeaders, o.Metadata, FileAccessConditions{})
 	if err != nil {
 		return err
 	}
@@ -78,30 +116,36 @@ func UploadBufferToAzureFile(ctx context.Context, b []byte,
 		transferSize: size,
 		chunkSize:    o.RangeSize,
 		parallelism:  parallelism,
-		operation: func(offset int64, curRangeSize int64) error {
-			// Prepare to read the proper section of the buffer.
-			var body io.ReadSeeker = bytes.NewReader(b[offset : offset+curRangeSize])
+		operation: func(offset int64, curRangeSize int64, ctx context.Context) error {
+			// Prepare to read the proper section of the reader.
+			body := io.NewSectionReader(reader, offset, curRangeSize)
+			uo := UploadRangeOptions{EncryptionScope: o.FileHTTPHeaders.EncryptionScope}
 			if o.Progress != nil {
 				rangeProgress := int64(0)
-				body = pipeline.NewRequestBodyProgress(body,
-					func(bytesTransferred int64) {
-						diff := bytesTransferred - rangeProgress
-						rangeProgress = bytesTransferred
-						progressLock.Lock()
-						defer progressLock.Unlock()
-						fileProgress += diff
-						o.Progress(fileProgress)
-					})
+				uo.Progress = func(bytesTransferred int64) {
+					diff := bytesTransferred - rangeProgress
+					rangeProgress = bytesTransferred
+					progressLock.Lock()
+					defer progressLock.Unlock()
+					fileProgress += diff
+					o.Progress(fileProgress)
+				}
 			}
 
-			_, err := fileURL.UploadRange(ctx, int64(offset), body, nil)
+			_, err := fileURL.UploadRange(ctx, int64(offset), body, nil, uo)
+			if err != nil && ctx.Err() == nil {
+				return &RangeUploadError{Offset: offset, Count: curRangeSize, Err: err}
+			}
 			return err
 		},
-		operationName: "UploadBufferToAzureFile",
+		operationName: "UploadReaderAtToAzureFile",
 	})
 }
 
-// UploadFileToAzureFile uploads a local file to an Azure file.
+// UploadFileToAzureFile uploads a local file to an Azure file, like UploadReaderAtToAzureFile.
+// The Azure file is created with the local file's size, and then the content is uploaded in o.RangeSize ranges by
+// o.Parallelism goroutines. If ctx is cancelled or any range fails, the outstanding uploads are cancelled and the
+// first error is returned.
 func UploadFileToAzureFile(ctx context.Context, file *os.File,
 	fileURL FileURL, o UploadToAzureFileOptions) error {
 
@@ -109,15 +153,166 @@ func UploadFileToAzureFile(ctx context.Context, file *os.File,
 	if err != nil {
 		return err
 	}
-	m := mmf{} // Default to an empty slice; used for 0-size file
-	if stat.Size() != 0 {
-		m, err = newMMF(file, false, 0, int(stat.Size()))
+	return UploadReaderAtToAzureFile(ctx, file, stat.Size(), fileURL, o)
+}
+
+// UploadStreamOptions identifies options used by the UploadStreamToAzureFile function.
+type UploadStreamOptions struct {
+	// BufferSize specifies the size of each buffer, which is also the size of each uploaded range; the default is
+	// FileMaxUploadRangeBytes, and larger sizes are clamped to it.
+	BufferSize int
+
+	// MaxBuffers indicates the maximum number of buffers, and therefore of ranges uploaded in parallel. If 0(default) is provided, 5 buffers will be used by default.
+	MaxBuffers int
+
+	// BufferManager, if not nil, supplies the buffers in place of BufferSize and MaxBuffers, so that uploads sharing it
+	// are bounded together; its BufferSize must not exceed FileMaxUploadRangeBytes. Buffers are pooled either way.
+	BufferManager *BufferManager
+
+	// FileHTTPHeaders contains read/writeable file properties. Its EncryptionScope is used for the ranges too.
+	FileHTTPHeaders FileHTTPHeaders
+
+	// Metadata contains metadata key/value pairs.
+	Metadata Metadata
+
+	// MaxFileSize, if not 0, caps the number of bytes read from the stream: a longer stream fails the upload with a
+	// *FileTooLargeError as soon as more is read, and the partial file is deleted. Streams are capped at
+	// FileMaxSizeInBytes either way.
+	MaxFileSize int64
+}
+
+// FileTooLargeError is returned when a file is larger than a MaxFileSize option allows, or than FileMaxSizeInBytes.
+type FileTooLargeError struct {
+	// Size is the file's size, in bytes; for a stream being uploaded, the number of bytes read when it went over.
+	Size int64
+
+	// MaxSize is the limit Size exceeds.
+	MaxSize int64
+}
+
+// Error implements the error interface.
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("the file's %d bytes exceed the limit of %d bytes", e.Size, e.MaxSize)
+}
+
+// UploadStreamToAzureFile uploads the content read from reader, whose length doesn't need to be known up front, to an Azure file.
+// The content is read into o.BufferSize chunks that are uploaded concurrently, with at most o.MaxBuffers buffers in use.
+// The Azure file is grown as data arrives and, once reader returns io.EOF, resized to the exact number of bytes read.
+// If reading or uploading fails, or the stream is longer than o.MaxFileSize, the remaining uploads are cancelled, the
+// partially written Azure file is deleted (on a best effort basis) and the first error is returned.
+func UploadStreamToAzureFile(ctx context.Context, reader io.Reader, fileURL FileURL, o UploadStreamOptions) error {
+	// 1. Validate parameters, and set defaults.
+	if o.BufferSize < 0 {
+		return errors.New("invalid argument, o.BufferSize must be >= 0")
+	}
+	if o.BufferSize == 0 || o.BufferSize > FileMaxUploadRangeBytes {
+		o.BufferSize = FileMaxUploadRangeBytes // UploadRange doesn't accept larger ranges
+	}
+	if o.MaxBuffers < 0 {
+		return errors.New("invalid argument, o.MaxBuffers must be >= 0")
+	}
+	if o.BufferManager == nil {
+		o.BufferManager = NewBufferManager(o.BufferSize, o.MaxBuffers)
+	}
+	if o.BufferManager.BufferSize() > FileMaxUploadRangeBytes {
+		return fmt.Errorf("invalid argument, o.BufferManager's buffers must be <= %d bytes", FileMaxUploadRangeBytes)
+	}
+	if o.MaxFileSize < 0 {
+		return errors.New("invalid argument, o.MaxFileSize must be >= 0")
+	}
+	if o.MaxFileSize == 0 || o.MaxFileSize > FileMaxSizeInBytes {
+		o.MaxFileSize = FileMaxSizeInBytes
+	}
+
+	// 2. Try to create the Azure file, it's grown as data is read.
+	if _, err := fileURL.Create(ctx, 0, o.FileHTTPHeaders, o.Metadata, FileAccessConditions{}); err != nil {
+		return err
+	}
+
+	// 3. Read the stream and upload each chunk from its own goroutine, bounded by the buffer manager.
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		errOnce   sync.Once
+		uploadErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			uploadErr = err
+			cancel()
+		})
+	}
+
+	offset, fileSize := int64(0), int64(0)
+	for {
+		b, err := o.BufferManager.Acquire(uploadCtx)
+		if err != nil {
+			fail(err)
+			break
+		}
+
+		n, err := io.ReadFull(reader, b)
+		if n == 0 {
+			o.BufferManager.Release(b)
+		} else {
+			end := offset + int64(n)
+			if end > o.MaxFileSize {
+				o.BufferManager.Release(b)
+				fail(&FileTooLargeError{Size: end, MaxSize: o.MaxFileSize})
+				break
+			}
+			if end > fileSize {
+				// Grow geometrically to avoid a resize per range; the final size is set once the stream ends.
+				newSize := 2 * fileSize
+				if newSize < end {
+					newSize = end
+				}
+				if newSize > o.MaxFileSize {
+					newSize = o.MaxFileSize
+				}
+				if _, resizeErr := fileURL.Resize(uploadCtx, newSize, ResizeOptions{}); resizeErr != nil {
+					o.BufferManager.Release(b)
+					fail(resizeErr)
+					break
+				}
+				fileSize = newSize
+			}
+
+			wg.Add(1)
+			go func(b []byte, offset int64, n int) {
+				defer wg.Done()
+				defer o.BufferManager.Release(b)
+				if _, err := fileURL.UploadRange(uploadCtx, offset, bytes.NewReader(b[:n]), nil,
+					UploadRangeOptions{EncryptionScope: o.FileHTTPHeaders.EncryptionScope}); err != nil {
+					fail(err)
+				}
+			}(b, offset, n)
+			offset = end
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break // The stream has ended
+		}
 		if err != nil {
+			fail(err)
+			break
+		}
+	}
+	wg.Wait()
+
+	// 4. Clean up on failure, or set the file to its exact size.
+	if uploadErr != nil {
+		fileURL.Delete(context.Background(), FileAccessConditions{}) // The caller's context may already be done
+		return uploadErr
+	}
+	if fileSize != offset {
+		if _, err := fileURL.Resize(ctx, offset, ResizeOptions{}); err != nil {
 			return err
 		}
-		defer m.unmap()
 	}
-	return UploadBufferToAzureFile(ctx, m, fileURL, o)
+	return nil
 }
 
 // DownloadFromAzureFileOptions identifies options used by the DownloadAzureFileToBuffer and DownloadAzureFileToFile functions.
@@ -125,7 +320,8 @@ type DownloadFromAzureFileOptions struct {
 	// RangeSize specifies the range size to use in each parallel download; the default is FileMaxUploadRangeBytes.
 	RangeSize int64
 
-	// Progress is a function that is invoked periodically as bytes are recieved.
+	// Progress is a function that is invoked periodically as bytes are recieved. The totals it's passed never go
+	// down: when a range is started over, they grow again only once its bytes received exceed what was reported.
 	Progress pipeline.ProgressReceiver
 
 	// Parallelism indicates the maximum number of ranges to download in parallel. If 0(default) is provided, 5 parallelism will be used by default.
@@ -133,12 +329,58 @@ type DownloadFromAzureFileOptions struct {
 
 	// Max retry requests used during reading data for each range.
 	MaxRetryRequestsPerRange int
+
+	// MaxChunkRetries is the number of times the download of a range is started over after it fails, once the
+	// pipeline's retries of its request and MaxRetryRequestsPerRange are used up, without failing the other ranges.
+	// The default, 0, fails the download with the range's first failure.
+	MaxChunkRetries int
+
+	// MaxFileSize, if not 0, is the size of the largest file to download, in bytes: the download of a larger file,
+	// even of a part of it, fails with a *FileTooLargeError once its size is known, before any data is fetched or
+	// the local file is resized.
+	MaxFileSize int64
+
+	// Properties, if not nil, are the file's properties from an earlier GetProperties or download, used in place of
+	// a GetProperties request to learn the file's length. They must be current, so only pass them for a file that
+	// isn't being changed; they are also what the download returns.
+	Properties *FileGetPropertiesResponse
+}
+
+// checkFileSize returns a *FileTooLargeError if a file of size bytes exceeds o.MaxFileSize.
+func (o DownloadFromAzureFileOptions) checkFileSize(size int64) error {
+	if o.MaxFileSize < 0 {
+		return errors.New("invalid argument, o.MaxFileSize must be >= 0")
+	}
+	if o.MaxFileSize > 0 && size > o.MaxFileSize {
+		return &FileTooLargeError{Size: size, MaxSize: o.MaxFileSize}
+	}
+	return nil
+}
+
+// RangeDownloadError is returned by DownloadAzureFileToBuffer and DownloadAzureFileToFile when a range fails to
+// download, after DownloadFromAzureFileOptions.MaxChunkRetries attempts to start it over.
+type RangeDownloadError struct {
+	// Offset and Count are the offset in the file of the range that failed, and its size, in bytes.
+	Offset, Count int64
+
+	// Err is the error of the range's last attempt.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RangeDownloadError) Error() string {
+	return fmt.Sprintf("failed to download the %d bytes at offset %d: %v", e.Count, e.Offset, e.Err)
 }
 
-// downloadAzureFileToBuffer downloads an Azure file to a buffer with parallel.
+// Unwrap returns the error of the range's last attempt, for errors.Is and errors.As.
+func (e *RangeDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// downloadAzureFileToBuffer downloads count bytes of an Azure file, starting at offset, to a buffer with parallel.
 // Note: o.RangeSize must be >= 0.
 func downloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, azfileProperties *FileGetPropertiesResponse,
-	b []byte, o DownloadFromAzureFileOptions) (*FileGetPropertiesResponse, error) {
+	offset int64, count int64, b []byte, o DownloadFromAzureFileOptions) (*FileGetPropertiesResponse, error) {
 
 	// 1. Validate parameters, and set defaults.
 	if o.RangeSize < 0 {
@@ -147,6 +389,12 @@ func downloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, azfilePrope
 	if o.RangeSize == 0 {
 		o.RangeSize = FileMaxUploadRangeBytes
 	}
+	if o.MaxChunkRetries < 0 {
+		return nil, errors.New("invalid argument, o.MaxChunkRetries must be >= 0")
+	}
+	if offset < 0 || count < 0 {
+		return nil, errors.New("invalid argument, offset and count must be >= 0")
+	}
 
 	if azfileProperties == nil {
 		p, err := fileURL.GetProperties(ctx)
@@ -156,14 +404,24 @@ func downloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, azfilePrope
 		azfileProperties = p
 	}
 	azfileSize := azfileProperties.ContentLength()
+	if err := o.checkFileSize(azfileSize); err != nil {
+		return nil, err
+	}
+
+	if offset > azfileSize {
+		return nil, fmt.Errorf("invalid argument, offset must be <= the Azure file's size: %d", azfileSize)
+	}
+	if count == CountToEnd || offset+count > azfileSize {
+		count = azfileSize - offset
+	}
 
-	// If azure file size equals to 0, directly return as nothing need be downloaded.
-	if azfileSize == 0 {
+	// If the range is empty, directly return as nothing need be downloaded.
+	if count == 0 {
 		return azfileProperties, nil
 	}
 
-	if int64(len(b)) < azfileSize {
-		sanityCheckFailed(fmt.Sprintf("The buffer's size should be equal to or larger than Azure file's size: %d.", azfileSize))
+	if int64(len(b)) < count {
+		sanityCheckFailed(fmt.Sprintf("The buffer's size should be equal to or larger than the size to download: %d.", count))
 	}
 
 	parallelism := o.Parallelism
@@ -174,33 +432,62 @@ func downloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, azfilePrope
 	// 2. Prepare and do parallel download.
 	fileProgress := int64(0)
 	progressLock := &sync.Mutex{}
+	var progress pipeline.ProgressReceiver
+	if o.Progress != nil {
+		progress = newMonotonicProgress(o.Progress) // A range started over doesn't make the progress go backwards
+	}
 
 	err := doBatchTransfer(ctx, batchTransferOptions{
-		transferSize: azfileSize,
+		transferSize: count,
 		chunkSize:    o.RangeSize,
 		parallelism:  parallelism,
-		operation: func(offset int64, curRangeSize int64) error {
-			dr, err := fileURL.Download(ctx, offset, curRangeSize, false)
-			body := dr.Body(RetryReaderOptions{MaxRetryRequests: o.MaxRetryRequestsPerRange})
-
-			if o.Progress != nil {
-				rangeProgress := int64(0)
-				body = pipeline.NewResponseBodyProgress(
-					body,
-					func(bytesTransferred int64) {
-						diff := bytesTransferred - rangeProgress
-						rangeProgress = bytesTransferred
+		operation: func(chunkStart int64, curRangeSize int64, ctx context.Context) error {
+			// The progress of a range is kept across its attempts, so that starting it over takes back what the
+			// failed attempt counted; the total reported only grows again once the range is past that point.
+			rangeProgress := int64(0)
+			downloadRange := func() error {
+				dr, err := fileURL.Download(ctx, offset+chunkStart, curRangeSize, false)
+				if err != nil {
+					return err
+				}
+				ro := RetryReaderOptions{MaxRetryRequests: o.MaxRetryRequestsPerRange}
+				if o.Progress != nil {
+					attemptStart := rangeProgress
+					ro.Progress = func(bytesTransferred int64) {
+						diff := attemptStart + bytesTransferred - rangeProgress
+						rangeProgress = attemptStart + bytesTransferred
 						progressLock.Lock()
 						defer progressLock.Unlock()
 						fileProgress += diff
-						o.Progress(fileProgress)
-					})
-			}
+						progress(fileProgress)
+					}
+				}
+				body := dr.Body(ro)
 
-			_, err = io.ReadFull(body, b[offset:offset+curRangeSize])
-			body.Close()
+				// Ranges may complete in any order, each one is written at its own position in the buffer.
+				_, err = io.ReadFull(body, b[chunkStart:chunkStart+curRangeSize])
+				body.Close()
+				return err
+			}
 
-			return err
+			for try := 0; ; try++ {
+				err := downloadRange()
+				if err == nil {
+					return nil
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if try == o.MaxChunkRetries {
+					return &RangeDownloadError{Offset: offset + chunkStart, Count: curRangeSize, Err: err}
+				}
+				if o.Progress != nil && rangeProgress != 0 {
+					progressLock.Lock()
+					fileProgress -= rangeProgress
+					progressLock.Unlock()
+				}
+				rangeProgress = 0
+			}
 		},
 		operationName: "downloadAzureFileToBuffer",
 	})
@@ -211,50 +498,171 @@ func downloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, azfilePrope
 	return azfileProperties, nil
 }
 
-// DownloadAzureFileToBuffer downloads an Azure file to a buffer with parallel.
-func DownloadAzureFileToBuffer(ctx context.Context, fileURL FileURL,
+// DownloadAzureFileToBuffer downloads count bytes of an Azure file, starting at offset, to a buffer with parallel.
+// If count is CountToEnd (0), then data is read from the specified offset to the end of the file.
+// The data is written to the start of b, which must be large enough to hold it.
+func DownloadAzureFileToBuffer(ctx context.Context, fileURL FileURL, offset int64, count int64,
 	b []byte, o DownloadFromAzureFileOptions) (*FileGetPropertiesResponse, error) {
-	return downloadAzureFileToBuffer(ctx, fileURL, nil, b, o)
+	return downloadAzureFileToBuffer(ctx, fileURL, o.Properties, offset, count, b, o)
 }
 
-// DownloadAzureFileToFile downloads an Azure file to a local file.
-// The file would be created if it doesn't exist, and would be truncated if the size doesn't match.
+// DownloadAzureFileToFile downloads count bytes of an Azure file, starting at offset, to a local file.
+// If count is CountToEnd (0), then data is read from the specified offset to the end of the file.
+// The file would be created if it doesn't exist, and would be truncated if the size doesn't match the size to download.
 // Note: file can't be nil.
-func DownloadAzureFileToFile(ctx context.Context, fileURL FileURL, file *os.File, o DownloadFromAzureFileOptions) (*FileGetPropertiesResponse, error) {
+func DownloadAzureFileToFile(ctx context.Context, fileURL FileURL, offset int64, count int64,
+	file *os.File, o DownloadFromAzureFileOptions) (*FileGetPropertiesResponse, error) {
 	// 1. Validate parameters.
 	if file == nil {
 		return nil, errors.New("invalid argument, file can't be nil")
 	}
+	if offset < 0 || count < 0 {
+		return nil, errors.New("invalid argument, offset and count must be >= 0")
+	}
 
-	// 2. Try to get Azure file's size.
-	azfileProperties, err := fileURL.GetProperties(ctx)
-	if err != nil {
-		return nil, err
+	// 2. Try to get Azure file's size, and compute the size to download.
+	azfileProperties := o.Properties
+	if azfileProperties == nil {
+		p, err := fileURL.GetProperties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		azfileProperties = p
 	}
 	azfileSize := azfileProperties.ContentLength()
+	if err := o.checkFileSize(azfileSize); err != nil {
+		return nil, err
+	}
+	if offset > azfileSize {
+		return nil, fmt.Errorf("invalid argument, offset must be <= the Azure file's size: %d", azfileSize)
+	}
+	if count == CountToEnd || offset+count > azfileSize {
+		count = azfileSize - offset
+	}
 
-	// 3. Compare and try to resize local file's size if it doesn't match Azure file's size.
+	// 3. Compare and try to resize local file's size if it doesn't match the size to download.
 	stat, err := file.Stat()
 	if err != nil {
 		return nil, err
 	}
-	if stat.Size() != azfileSize {
-		if err = file.Truncate(azfileSize); err != nil {
+	if stat.Size() != count {
+		if err = file.Truncate(count); err != nil {
 			return nil, err
 		}
 	}
 
-	// 4. Set mmap and call DownloadAzureFileToBuffer, in this case file size should be > 0.
+	// 4. Set mmap and call downloadAzureFileToBuffer, in this case the size to download should be > 0.
 	m := mmf{} // Default to an empty slice; used for 0-size file
-	if azfileSize > 0 {
-		m, err = newMMF(file, true, 0, int(azfileSize))
+	if count > 0 {
+		m, err = newMMF(file, true, 0, int(count))
 		if err != nil {
 			return nil, err
 		}
 		defer m.unmap()
 	}
 
-	return downloadAzureFileToBuffer(ctx, fileURL, azfileProperties, m, o)
+	return downloadAzureFileToBuffer(ctx, fileURL, azfileProperties, offset, count, m, o)
+}
+
+// DownloadChangedRanges downloads the ranges of an Azure file that were written since prevSnapshot, a snapshot of its
+// share, writing each at its own offset in w, and returns them in ascending order. The rest of w is left untouched,
+// so applying the ranges to a copy of the file as of prevSnapshot, such as an earlier backup, brings it up to date.
+// Ranges cleared since prevSnapshot aren't listed, so they are left untouched too, and so is w's size; resize the
+// copy to the ContentLength of the file's properties, and zero the ClearRanges of FileURL.GetRangeListDiff. Each range is downloaded in parts of at most o.RangeSize
+// bytes, o.Parallelism at a time, and the download fails with an error ending in FileModifiedDuringReadMessage if
+// the file is modified meanwhile.
+func DownloadChangedRanges(ctx context.Context, fileURL FileURL, prevSnapshot string, w io.WriterAt,
+	o DownloadFromAzureFileOptions) ([]Range, error) {
+	if w == nil {
+		return nil, errors.New("invalid argument, w can't be nil")
+	}
+	if prevSnapshot == "" {
+		return nil, errors.New("invalid argument, prevSnapshot can't be empty")
+	}
+	if o.RangeSize < 0 {
+		return nil, errors.New("invalid argument, o.RangeSize must be >= 0")
+	}
+	if o.RangeSize == 0 {
+		o.RangeSize = FileMaxUploadRangeBytes
+	}
+	parallelism := o.Parallelism
+	if parallelism == 0 {
+		parallelism = defaultParallelCount // default parallelism
+	}
+
+	// The properties are read first, so that the ranges downloaded can be checked to be of the file they list.
+	azfileProperties := o.Properties
+	if azfileProperties == nil {
+		p, err := fileURL.GetProperties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		azfileProperties = p
+	}
+	ranges, err := fileURL.GetRangeList(ctx, 0, CountToEnd, GetRangeListOptions{PrevShareSnapshot: prevSnapshot})
+	if err != nil {
+		return nil, err
+	}
+	var parts []Range
+	for _, r := range ranges.Items {
+		for start := r.Start; start <= r.End; start += o.RangeSize {
+			end := start + o.RangeSize - 1
+			if end > r.End {
+				end = r.End
+			}
+			parts = append(parts, Range{Start: start, End: end})
+		}
+	}
+	if len(parts) == 0 {
+		return ranges.Items, nil
+	}
+
+	fileProgress := int64(0)
+	progressLock := &sync.Mutex{}
+	etag := azfileProperties.ETag()
+	// Each "chunk" of the batch is one of the parts, which aren't contiguous.
+	err = doBatchTransfer(ctx, batchTransferOptions{
+		transferSize: int64(len(parts)),
+		chunkSize:    1,
+		parallelism:  parallelism,
+		operation: func(index int64, _ int64, ctx context.Context) error {
+			part := parts[index]
+			count := part.End - part.Start + 1
+			dr, err := fileURL.Download(ctx, part.Start, count, false)
+			if err != nil {
+				return err
+			}
+			if dr.ETag() != etag {
+				dr.Response().Body.Close()
+				return fmt.Errorf("expected ETag %s but got %s: %s", etag, dr.ETag(), FileModifiedDuringReadMessage)
+			}
+			ro := RetryReaderOptions{MaxRetryRequests: o.MaxRetryRequestsPerRange}
+			if o.Progress != nil {
+				rangeProgress := int64(0)
+				ro.Progress = func(bytesTransferred int64) {
+					diff := bytesTransferred - rangeProgress
+					rangeProgress = bytesTransferred
+					progressLock.Lock()
+					defer progressLock.Unlock()
+					fileProgress += diff
+					o.Progress(fileProgress)
+				}
+			}
+			body := dr.Body(ro)
+			defer body.Close()
+			b := make([]byte, count)
+			if _, err = io.ReadFull(body, b); err != nil {
+				return err
+			}
+			_, err = w.WriteAt(b, part.Start)
+			return err
+		},
+		operationName: "DownloadChangedRanges",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ranges.Items, nil
 }
 
 // BatchTransferOptions identifies options used by doBatchTransfer.
@@ -262,54 +670,1122 @@ type batchTransferOptions struct {
 	transferSize  int64
 	chunkSize     int64
 	parallelism   uint16
-	operation     func(offset int64, chunkSize int64) error
+	operation     func(offset int64, chunkSize int64, ctx context.Context) error
 	operationName string
 }
 
 // doBatchTransfer helps to execute operations in a batch manner.
+// Each operation receives a context that is cancelled as soon as any operation fails or ctx is done,
+// and the first error encountered is returned. It returns only once every operation started has returned.
 func doBatchTransfer(ctx context.Context, o batchTransferOptions) error {
 	// Prepare and do parallel operations.
 	numChunks := ((o.transferSize - 1) / o.chunkSize) + 1
 	operationChannel := make(chan func() error, o.parallelism) // Create the channel that release 'parallelism' goroutines concurrently
-	operationResponseChannel := make(chan error, numChunks)    // Holds each response
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Create the goroutines that process each operation (in parallel).
+	// Create the goroutines that process each operation (in parallel). The first error is kept, and cancels the
+	// remaining operations.
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
 	for g := uint16(0); g < o.parallelism; g++ {
-		//grIndex := g
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			for f := range operationChannel {
-				//fmt.Printf("[%s] gr-%d start action\n", o.operationName, grIndex)
-				err := f()
-				operationResponseChannel <- err
-				//fmt.Printf("[%s] gr-%d end action\n", o.operationName, grIndex)
+				if err := f(); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					cancel() // As soon as any operation fails, cancel all remaining operation calls
+				}
 			}
 		}()
 	}
 
-	curChunkSize := o.chunkSize
-	// Add each chunk's operation to the channel.
-	for chunkIndex := int64(0); chunkIndex < numChunks; chunkIndex++ {
-		if chunkIndex == numChunks-1 { // Last chunk
-			curChunkSize = o.transferSize - (int64(chunkIndex) * o.chunkSize) // Remove size of all transferred chunks from total
+	// Add each chunk's operation to the channel. This is done from a separate goroutine so that a failure can be
+	// observed, and the remaining operations cancelled, while chunks are still being queued.
+	go func() {
+		defer close(operationChannel)
+		curChunkSize := o.chunkSize
+		for chunkIndex := int64(0); chunkIndex < numChunks; chunkIndex++ {
+			if chunkIndex == numChunks-1 { // Last chunk
+				curChunkSize = o.transferSize - (int64(chunkIndex) * o.chunkSize) // Remove size of all transferred chunks from total
+			}
+			offset := int64(chunkIndex) * o.chunkSize
+
+			closureChunkSize := curChunkSize
+			select {
+			case operationChannel <- func() error {
+				if err := ctx.Err(); err != nil {
+					return err // Don't start operations once the transfer has been cancelled
+				}
+				return o.operation(offset, closureChunkSize, ctx)
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Wait for every operation to return, even after a failure, so none still uses the source or the destination
+	// once the transfer returns.
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err() // Set if the caller's context was done before every operation was queued
+}
+
+// SkipDir can be returned by the function passed to WalkFiles to skip the remaining files of the directory containing
+// the file, and all of the directory's subdirectories.
+var SkipDir = errors.New("skip this directory")
+
+// WalkFiles walks the tree of directories rooted at root, calling fn for each file with the file's path relative to
+// the share, e.g. "dir/subdir/file". Each directory is listed completely before fn is called for its files, and
+// before its subdirectories are walked. Up to 5 directories are listed in parallel, but fn is never called
+// concurrently, so it needn't be safe for concurrent use. The order in which directories are visited is unspecified.
+// If fn returns an error other than SkipDir, or a listing fails, or ctx is done, WalkFiles stops walking and returns
+// the first error.
+func WalkFiles(ctx context.Context, root DirectoryURL, fn func(path string, item FileItem) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &fileWalker{fn: fn, cancel: cancel, listings: make(chan struct{}, defaultParallelCount)}
+	w.wg.Add(1)
+	go w.walk(ctx, root, strings.Trim(NewFileURLParts(root.URL()).DirectoryOrFilePath, "/"))
+	w.wg.Wait()
+	return w.err
+}
+
+// fileWalker holds the state shared by the goroutines of a WalkFiles call: one goroutine per directory, of which at
+// most cap(listings) list their directory at a time.
+type fileWalker struct {
+	fn       func(path string, item FileItem) error
+	cancel   context.CancelFunc
+	listings chan struct{}
+	wg       sync.WaitGroup
+
+	mu  sync.Mutex // Serializes calls to fn, and guards err
+	err error
+}
+
+// fail records err, if it's the first error, and stops the walk.
+func (w *fileWalker) fail(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mu.Unlock()
+	w.cancel()
+}
+
+func (w *fileWalker) walk(ctx context.Context, dir DirectoryURL, dirPath string) {
+	defer w.wg.Done()
+
+	select {
+	case w.listings <- struct{}{}:
+	case <-ctx.Done():
+		w.fail(ctx.Err())
+		return
+	}
+	var files []FileItem
+	var dirs []DirectoryItem
+	it := dir.ListAll(ctx, ListFilesAndDirectoriesOptions{})
+	for it.Next() {
+		if f := it.File(); f != nil {
+			files = append(files, *f)
+		} else {
+			dirs = append(dirs, *it.Directory())
+		}
+	}
+	<-w.listings
+	if err := it.Err(); err != nil {
+		w.fail(err)
+		return
+	}
+
+	w.mu.Lock()
+	for _, f := range files {
+		if w.err == nil {
+			w.err = ctx.Err()
+		}
+		if w.err != nil {
+			break
+		}
+		if err := w.fn(path.Join(dirPath, f.Name), f); err == SkipDir {
+			w.mu.Unlock()
+			return
+		} else if err != nil {
+			w.err = err
+			w.cancel()
+		}
+	}
+	stopped := w.err != nil
+	w.mu.Unlock()
+	if stopped {
+		return
+	}
+
+	for _, d := range dirs {
+		w.wg.Add(1)
+		go w.walk(ctx, dir.NewDirectoryURL(d.Name), path.Join(dirPath, d.Name))
+	}
+}
+
+// copyPollMinInterval is the delay before WaitForCopy first polls a copy; it doubles after each poll.
+const copyPollMinInterval = 250 * time.Millisecond
+
+// CopyError is returned by WaitForCopy when a copy ends without succeeding.
+type CopyError struct {
+	// CopyID identifies the copy.
+	CopyID string
+
+	// Status is CopyStatusAborted or CopyStatusFailed.
+	Status CopyStatusType
+
+	// StatusDescription is the service's explanation of why the copy ended, if any.
+	StatusDescription string
+}
+
+// Error implements the error interface.
+func (e *CopyError) Error() string {
+	if e.StatusDescription == "" {
+		return fmt.Sprintf("copy %s %s", e.CopyID, e.Status)
+	}
+	return fmt.Sprintf("copy %s %s: %s", e.CopyID, e.Status, e.StatusDescription)
+}
+
+// WaitForCopy polls the properties of the destination file of a copy started with FileURL.StartCopy until the copy
+// identified by copyID ends, and returns the properties read once it succeeds. If the copy is aborted or fails, the
+// error is a *CopyError. Polls are spaced by an exponential backoff, starting at 250 milliseconds and capped at
+// pollInterval; WaitForCopy returns ctx's error if ctx is done first.
+func WaitForCopy(ctx context.Context, fileURL FileURL, copyID string, pollInterval time.Duration) (*FileGetPropertiesResponse, error) {
+	if copyID == "" {
+		return nil, errors.New("invalid argument, copyID must not be empty")
+	}
+	if pollInterval <= 0 {
+		return nil, errors.New("invalid argument, pollInterval must be > 0")
+	}
+
+	delay := copyPollMinInterval
+	for {
+		props, err := fileURL.GetProperties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if props.CopyID() != copyID {
+			return nil, fmt.Errorf("copy %s is no longer the last copy to the file, copy %s is", copyID, props.CopyID())
+		}
+		switch props.CopyStatus() {
+		case CopyStatusSuccess:
+			return props, nil
+		case CopyStatusAborted, CopyStatusFailed:
+			return nil, &CopyError{CopyID: copyID, Status: props.CopyStatus(), StatusDescription: props.CopyStatusDescription()}
+		}
+
+		if delay > pollInterval {
+			delay = pollInterval
 		}
-		offset := int64(chunkIndex) * o.chunkSize
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// BatchDeleteOptions identifies options used by DeleteFiles.
+type BatchDeleteOptions struct {
+	// Parallelism indicates the maximum number of files to delete in parallel. If 0(default) is provided, 5 parallelism will be used by default.
+	Parallelism uint16
+
+	// ContinueOnError makes DeleteFiles delete the remaining files after a delete fails. By default, the first failure
+	// cancels the outstanding deletes and no more are started.
+	ContinueOnError bool
+}
+
+// FileDeleteResult is the outcome of deleting one of the files passed to DeleteFiles.
+type FileDeleteResult struct {
+	// Name is the file's name, relative to the directory.
+	Name string
+
+	// Error is nil if the file was deleted. It's the context's error if the delete was cancelled or never started.
+	Error error
+}
+
+// BatchDeleteError is returned by DeleteFiles when any file couldn't be deleted.
+type BatchDeleteError struct {
+	// Failures holds the result of each file that couldn't be deleted, in the order the files were passed.
+	Failures []FileDeleteResult
+
+	// Count is the number of files DeleteFiles was passed.
+	Count int
+}
+
+// Error implements the error interface.
+func (e *BatchDeleteError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("failed to delete %d of %d files, the first of them %s: %v", len(e.Failures), e.Count, first.Name, first.Error)
+}
 
-		closureChunkSize := curChunkSize
-		operationChannel <- func() error {
-			return o.operation(offset, closureChunkSize)
+// Unwrap returns the error of each failure, for errors.Is and errors.As.
+func (e *BatchDeleteError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Error
+	}
+	return errs
+}
+
+// DeleteFiles deletes the files named names in dir, o.Parallelism at a time. The File service has no batch delete, so
+// each file takes a request of its own. It returns the result of each file, in the order of names, and, if any file
+// couldn't be deleted, a *BatchDeleteError. If ctx is done, the outstanding deletes are cancelled.
+func DeleteFiles(ctx context.Context, dir DirectoryURL, names []string, o BatchDeleteOptions) ([]FileDeleteResult, error) {
+	if o.Parallelism == 0 {
+		o.Parallelism = defaultParallelCount // default parallelism
+	}
+	results := make([]FileDeleteResult, len(names))
+	for i, name := range names {
+		results[i].Name = name
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for g := uint16(0); g < o.Parallelism; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Error = err // Don't start deletes once the batch has been cancelled
+					continue
+				}
+				_, err := dir.NewFileURL(names[i]).Delete(ctx, FileAccessConditions{})
+				results[i].Error = err
+				if err != nil && !o.ContinueOnError {
+					cancel() // Cancel the outstanding deletes and start no more
+				}
+			}
+		}()
+	}
+
+	started := 0
+queue:
+	for ; started < len(names); started++ {
+		select {
+		case indexes <- started:
+		case <-ctx.Done():
+			break queue
 		}
 	}
-	close(operationChannel)
+	close(indexes)
+	wg.Wait()
+	for i := started; i < len(names); i++ {
+		results[i].Error = ctx.Err()
+	}
 
-	// Wait for the operations to complete.
-	for chunkIndex := int64(0); chunkIndex < numChunks; chunkIndex++ {
-		responseError := <-operationResponseChannel
-		if responseError != nil {
-			cancel()             // As soon as any operation fails, cancel all remaining operation calls
-			return responseError // No need to process anymore responses
+	var failures []FileDeleteResult
+	for _, result := range results {
+		if result.Error != nil {
+			failures = append(failures, result)
+		}
+	}
+	if failures != nil {
+		return results, &BatchDeleteError{Failures: failures, Count: len(names)}
+	}
+	return results, nil
+}
+
+// ReadModifyWriteOptions identifies options used by ReadModifyWrite.
+type ReadModifyWriteOptions struct {
+	// MaxAttempts is the maximum number of times the file is read, modified and written, as each attempt that loses
+	// the race to another writer starts over. If 0(default) is provided, 3 attempts will be made by default.
+	MaxAttempts int
+
+	// MaxRetryRequests is the maximum number of retries of a failed read of the file's data.
+	MaxRetryRequests int
+}
+
+// ReadModifyWrite replaces the data of an Azure file with what fn returns for the data it has, unless another writer
+// changes the file in the meantime. The file is downloaded, noting its ETag, and passed to fn; then a lease is
+// acquired on the file, and the result written under it once the file's ETag is found to be unchanged. If another
+// writer won the race, or holds a lease on the file, the whole transaction starts over, up to o.MaxAttempts times,
+// after which the last attempt's error, for which IsPreconditionFailed or IsConflict returns true, is returned.
+// An error from fn is returned as is, and nothing is written. The file's HTTP headers, metadata and SMB properties
+// are preserved.
+// The file is resized to the new data's length before the data is uploaded, so a write that fails, or a process that
+// ends, partway through leaves the file truncated or only partly written. The lease is a FileInfiniteLeaseDuration
+// one: if the process ends before releasing it, the file stays leased, and every other writer fails, until
+// BreakLease is called; check the file's data then.
+func ReadModifyWrite(ctx context.Context, fileURL FileURL, fn func(old []byte) ([]byte, error), o ReadModifyWriteOptions) error {
+	if fn == nil {
+		return errors.New("invalid argument, fn must not be nil")
+	}
+	if o.MaxAttempts < 0 {
+		return errors.New("invalid argument, o.MaxAttempts must be >= 0")
+	}
+	if o.MaxAttempts == 0 {
+		o.MaxAttempts = 3
+	}
+
+	var err error
+	for attempt := 0; attempt < o.MaxAttempts; attempt++ {
+		dr, downloadErr := fileURL.Download(ctx, 0, CountToEnd, false)
+		if downloadErr != nil {
+			return downloadErr
+		}
+		body := dr.Body(RetryReaderOptions{MaxRetryRequests: o.MaxRetryRequests})
+		old, readErr := ioutil.ReadAll(body)
+		body.Close()
+		if readErr != nil {
+			return readErr
+		}
+
+		data, fnErr := fn(old)
+		if fnErr != nil {
+			return fnErr
+		}
+		err = writeIfUnchanged(ctx, fileURL, dr.ETag(), data)
+		if !IsPreconditionFailed(err) && !isStorageError(err, 0, ServiceCodeLeaseAlreadyPresent) {
+			return err // Written, or failed for a reason other than another writer
+		}
+	}
+	return err
+}
+
+// writeIfUnchanged replaces the data of the file with data, under a lease, if the file's ETag is still etag.
+func writeIfUnchanged(ctx context.Context, fileURL FileURL, etag ETag, data []byte) (err error) {
+	leaseID := newUUID().String()
+	if _, err := fileURL.AcquireLease(ctx, leaseID, FileInfiniteLeaseDuration); err != nil {
+		return err
+	}
+	defer func() {
+		// The lease never expires, so it's released even if ctx is done.
+		if _, releaseErr := fileURL.ReleaseLease(context.Background(), leaseID); err == nil {
+			err = releaseErr
+		}
+	}()
+
+	lease := LeaseAccessConditions{LeaseID: leaseID}
+	ac := FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfMatch: etag}, LeaseAccessConditions: lease}
+	if _, err := fileURL.Resize(ctx, int64(len(data)), ResizeOptions{FileAccessConditions: ac}); err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); offset += FileMaxUploadRangeBytes {
+		end := offset + FileMaxUploadRangeBytes
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := fileURL.UploadRange(ctx, int64(offset), bytes.NewReader(data[offset:end]), nil,
+			UploadRangeOptions{FileAccessConditions: FileAccessConditions{LeaseAccessConditions: lease}})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// UploadDirOptions identifies options used by UploadDirectoryToShare.
+type UploadDirOptions struct {
+	// RangeSize specifies the range size to use in each upload of a file's content; the default is
+	// FileMaxUploadRangeBytes, and larger sizes are clamped to it.
+	RangeSize int64
+
+	// Parallelism indicates the maximum number of files to upload in parallel. If 0(default) is provided, 5 parallelism will be used by default.
+	// The ranges of each file are uploaded one at a time, so at most Parallelism ranges are uploaded at once.
+	Parallelism uint16
+
+	// PreserveSMBProperties sets the SMB properties of the local files and directories on their copies in the share,
+	// as far as the local file system has them: on Windows, the attributes and the creation and last write times;
+	// elsewhere, the last write time, and the ReadOnly attribute for files without write permission.
+	PreserveSMBProperties bool
+
+	// SkipSymlinks skips symbolic links. By default, a link to a file is uploaded as a copy of the file it links to.
+	// Links to directories are always skipped, so that a link can't make the upload loop.
+	SkipSymlinks bool
+
+	// Filter, if not nil, is called for each file and directory under the local root, with its path relative to the
+	// root in slash-separated form, e.g. "dir/file". Only those for which it returns true are uploaded; returning
+	// false for a directory skips everything in it.
+	Filter func(path string, info os.FileInfo) bool
+
+	// Progress, if not nil, is invoked periodically as a file's content is uploaded, with the file's path, as passed
+	// to Filter, and the number of bytes of it uploaded so far. It's invoked concurrently for files uploaded in parallel.
+	Progress func(path string, bytesTransferred int64)
+
+	// ContinueOnError makes UploadDirectoryToShare upload the remaining files after one fails. By default, the first
+	// failure cancels the outstanding uploads and no more are started.
+	ContinueOnError bool
+}
+
+// FileTransferResult is the outcome of transferring one of the files or directories of a tree.
+type FileTransferResult struct {
+	// Path is the file's or directory's path relative to the root of the tree, e.g. "dir/file".
+	Path string
+
+	// Error is nil if the file or directory was transferred. It's the context's error if the transfer was cancelled.
+	Error error
+}
+
+// DirectoryTransferError is returned by UploadDirectoryToShare and DownloadShareToDirectory when any file or directory
+// couldn't be transferred.
+type DirectoryTransferError struct {
+	// Failures holds the result of each file or directory that couldn't be transferred, in the order they were found.
+	Failures []FileTransferResult
+
+	// Count is the number of files and directories whose transfer was attempted.
+	Count int
+}
+
+// Error implements the error interface.
+func (e *DirectoryTransferError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("failed to transfer %d of %d files and directories, the first of them %s: %v", len(e.Failures), e.Count, first.Path, first.Error)
+}
+
+// Unwrap returns the error of each failure, for errors.Is and errors.As.
+func (e *DirectoryTransferError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Error
+	}
+	return errs
+}
+
+// UploadDirectoryToShare uploads the tree of local directories rooted at localRoot to dir, which is created if it
+// doesn't exist: each directory is created in the share, unless it already exists, before the files in it are
+// uploaded, o.Parallelism at a time, replacing any files of the same names. Files that aren't regular files, such as
+// devices and sockets, are skipped. If any file or directory can't be uploaded, the error is a
+// *DirectoryTransferError; a directory that can't be created fails the files in it too, which aren't attempted.
+// If ctx is done, the outstanding uploads are cancelled.
+func UploadDirectoryToShare(ctx context.Context, localRoot string, dir DirectoryURL, o UploadDirOptions) error {
+	if o.RangeSize < 0 {
+		return errors.New("invalid argument, o.RangeSize must be >= 0")
+	}
+	if o.Parallelism == 0 {
+		o.Parallelism = defaultParallelCount // default parallelism
+	}
+	if info, err := os.Stat(localRoot); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("invalid argument, %s isn't a directory", localRoot)
+	}
+	if NewFileURLParts(dir.URL()).DirectoryOrFilePath != "" {
+		if _, err := dir.CreateIfNotExists(ctx, nil, SMBProperties{}); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	u := &directoryUploader{treeTransfer: treeTransfer{continueOnError: o.ContinueOnError, cancel: cancel}, dir: dir, o: o}
+	u.start(ctx, o.Parallelism)
+
+	dirIndexes := map[string]int{}
+	walkErr := filepath.Walk(localRoot, func(localPath string, info os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(localRoot, localPath)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if o.SkipSymlinks {
+				return nil
+			}
+			if info, err = os.Stat(localPath); err == nil && info.IsDir() {
+				return nil
+			}
+		}
+		if err != nil {
+			// Walk reports a directory it couldn't read after the directory itself.
+			index, ok := dirIndexes[rel]
+			if !ok {
+				index = u.add(rel)
+			}
+			u.done(index, err)
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		if o.Filter != nil && !o.Filter(rel, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		job := localUpload{index: u.add(rel), path: rel, localPath: localPath, info: info}
+		if info.IsDir() {
+			_, err := dir.NewDirectoryURL(rel).CreateIfNotExists(ctx, nil, SMBProperties{})
+			u.done(job.index, err)
+			if err != nil {
+				return filepath.SkipDir
+			}
+			dirIndexes[rel] = job.index
+			if o.PreserveSMBProperties {
+				u.setDirectoryProperties(job.index, func(ctx context.Context) error {
+					_, err := dir.NewDirectoryURL(job.path).SetProperties(ctx, localSMBProperties(job.info))
+					return err
+				})
+			}
+			return nil
+		}
+		return u.queue(ctx, job.index, func(ctx context.Context) error { return u.uploadFile(ctx, job) })
+	})
+	u.finish(ctx)
+	return u.err(walkErr)
+}
+
+// localUpload is a local file or directory found by UploadDirectoryToShare.
+type localUpload struct {
+	index     int
+	path      string // Relative to the local root, in slash-separated form
+	localPath string
+	info      os.FileInfo
+}
+
+// treeTransfer runs the transfers of a tree's files on a pool of goroutines, and records their results and those of
+// the tree's directories, for UploadDirectoryToShare, DownloadShareToDirectory and CopyTree.
+type treeTransfer struct {
+	continueOnError bool
+	cancel          context.CancelFunc
+	fileDone        func(result FileTransferResult) // If not nil, invoked as each file's transfer ends
+
+	jobs chan treeJob
+	wg   sync.WaitGroup
+	dirs []treeJob // The setting of the directories' properties, in the order the directories were found
+
+	mu      sync.Mutex // Guards results
+	results []FileTransferResult
+}
+
+// treeJob is the transfer of a file, or the setting of a directory's properties, whose result is at index.
+type treeJob struct {
+	index int
+	run   func(ctx context.Context) error
+}
+
+// start starts the parallelism goroutines that run the file transfers passed to queue, until finish is called.
+func (t *treeTransfer) start(ctx context.Context, parallelism uint16) {
+	t.jobs = make(chan treeJob)
+	for g := uint16(0); g < parallelism; g++ {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			for job := range t.jobs {
+				err := ctx.Err() // Don't start transfers once the transfer has been cancelled
+				if err == nil {
+					err = job.run(ctx)
+				}
+				t.done(job.index, err)
+				if t.fileDone != nil {
+					t.mu.Lock()
+					path := t.results[job.index].Path
+					t.mu.Unlock()
+					t.fileDone(FileTransferResult{Path: path, Error: err})
+				}
+			}
+		}()
+	}
+}
+
+// queue queues transfer, the transfer of the file whose result is at index, for the goroutines started by start. It
+// returns ctx's error, and records it as the file's, if ctx is done before transfer could be queued.
+func (t *treeTransfer) queue(ctx context.Context, index int, transfer func(ctx context.Context) error) error {
+	select {
+	case t.jobs <- treeJob{index: index, run: transfer}:
+		return nil
+	case <-ctx.Done():
+		t.done(index, ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// setDirectoryProperties records set, which sets the properties of the directory whose result is at index, for
+// finish to call. Directories must be recorded parents first.
+func (t *treeTransfer) setDirectoryProperties(index int, set func(ctx context.Context) error) {
+	t.dirs = append(t.dirs, treeJob{index: index, run: set})
+}
+
+// finish waits for the queued file transfers to end, and then sets the properties of the directories. Adding files to
+// a directory changes its last write time, so directories get their properties last, and deepest first, so that
+// setting a directory's properties doesn't change its parent's.
+func (t *treeTransfer) finish(ctx context.Context) {
+	close(t.jobs)
+	t.wg.Wait()
+	for i := len(t.dirs) - 1; i >= 0 && ctx.Err() == nil; i-- {
+		t.done(t.dirs[i].index, t.dirs[i].run(ctx))
+	}
+}
+
+// add records that the file or directory at path is being transferred, and returns the index of its result.
+func (t *treeTransfer) add(path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = append(t.results, FileTransferResult{Path: path})
+	return len(t.results) - 1
+}
+
+// done records the outcome of the transfer whose result is at index, and stops the transfer if it failed, unless
+// continueOnError is set. A later success doesn't clear an earlier failure.
+func (t *treeTransfer) done(index int, err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.results[index].Error == nil {
+		t.results[index].Error = err
+	}
+	t.mu.Unlock()
+	if !t.continueOnError {
+		t.cancel() // Cancel the outstanding transfers and start no more
+	}
+}
+
+// err returns a *DirectoryTransferError if any transfer failed, and otherwise walkErr, the error that stopped the
+// traversal of the tree, if any.
+func (t *treeTransfer) err(walkErr error) error {
+	var failures []FileTransferResult
+	for _, result := range t.results {
+		if result.Error != nil {
+			failures = append(failures, result)
+		}
+	}
+	if failures != nil {
+		return &DirectoryTransferError{Failures: failures, Count: len(t.results)}
+	}
+	return walkErr
+}
+
+// directoryUploader holds the state shared by the goroutines of an UploadDirectoryToShare call.
+type directoryUploader struct {
+	treeTransfer
+	dir DirectoryURL
+	o   UploadDirOptions
+}
+
+// uploadFile uploads the local file of job, and then sets its SMB properties, since uploading its content would
+// change its last write time.
+func (u *directoryUploader) uploadFile(ctx context.Context, job localUpload) error {
+	file, err := os.Open(job.localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileURL := u.dir.NewFileURL(job.path)
+	uo := UploadToAzureFileOptions{RangeSize: u.o.RangeSize, Parallelism: 1}
+	if u.o.Progress != nil {
+		uo.Progress = func(bytesTransferred int64) { u.o.Progress(job.path, bytesTransferred) }
+	}
+	if err := UploadFileToAzureFile(ctx, file, fileURL, uo); err != nil {
+		return err
+	}
+	if u.o.PreserveSMBProperties {
+		_, err = fileURL.SetProperties(ctx, FileHTTPHeaders{SMBProperties: localSMBProperties(job.info)}, FileAccessConditions{})
+	}
+	return err
+}
+
+// DownloadTempFileSuffix is appended to the name of each local file that DownloadShareToDirectory is downloading. A
+// file is renamed once it's complete, so files with this suffix are partial downloads, left behind if the process
+// exits before the download finishes.
+const DownloadTempFileSuffix = ".azdownload"
+
+// DownloadDirOptions identifies options used by DownloadShareToDirectory.
+type DownloadDirOptions struct {
+	// RangeSize specifies the range size to use in each download; the default is FileMaxUploadRangeBytes.
+	RangeSize int64
+
+	// Parallelism indicates the maximum number of files to download in parallel. If 0(default) is provided, 5 parallelism will be used by default.
+	// The ranges of each file are downloaded one at a time, so at most Parallelism ranges are downloaded at once.
+	Parallelism uint16
+
+	// MaxRetryRequestsPerRange is the number of times the download of a range is retried after its response body fails.
+	MaxRetryRequestsPerRange int
+
+	// PreserveSMBProperties sets the SMB properties of the files and directories in the share on their local copies,
+	// as far as the local file system has them: on Windows, the attributes and the creation and last write times;
+	// elsewhere, the last write time, and for a file with the ReadOnly attribute, the removal of its write permission.
+	PreserveSMBProperties bool
+
+	// Incremental skips the files whose local copy has the size and the last write time of the file in the share,
+	// rather than downloading them again, and sets the last write time of each file it downloads so that the next
+	// download skips it. Local files don't keep the ETag of the file they were downloaded from, so a file changed in
+	// the share without a change of its size or last write time isn't downloaded.
+	Incremental bool
+
+	// Progress, if not nil, is invoked periodically as a file's content is downloaded, with the file's path relative
+	// to the directory in the share, e.g. "dir/file", and the number of bytes of it downloaded so far. It's invoked
+	// concurrently for files downloaded in parallel.
+	Progress func(path string, bytesTransferred int64)
+
+	// ContinueOnError makes DownloadShareToDirectory download the remaining files after one fails. By default, the
+	// first failure cancels the outstanding downloads and no more are started.
+	ContinueOnError bool
+}
+
+// DownloadShareToDirectory downloads the tree of directories rooted at dir to localRoot, which is created if it
+// doesn't exist: each directory, including the empty ones, is created locally as it's listed, and the files in it
+// are downloaded, o.Parallelism at a time, replacing any local files of the same names. Each file is downloaded to
+// its name with DownloadTempFileSuffix appended, and renamed once it's complete; if its download fails or is
+// cancelled, the partial file is removed. If any file or directory can't be downloaded, the error is a
+// *DirectoryTransferError; a directory that can't be listed fails, but the rest of the tree is still downloaded
+// if o.ContinueOnError is set. If ctx is done, the outstanding downloads are cancelled.
+func DownloadShareToDirectory(ctx context.Context, dir DirectoryURL, localRoot string, o DownloadDirOptions) error {
+	if o.RangeSize < 0 {
+		return errors.New("invalid argument, o.RangeSize must be >= 0")
+	}
+	if o.Parallelism == 0 {
+		o.Parallelism = defaultParallelCount // default parallelism
+	}
+	if err := os.MkdirAll(localRoot, 0755); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d := &directoryDownloader{treeTransfer: treeTransfer{continueOnError: o.ContinueOnError, cancel: cancel}, dir: dir, o: o}
+	d.start(ctx, o.Parallelism)
+
+	include := ListFilesAndDirectoriesDetail{Timestamps: o.Incremental || o.PreserveSMBProperties, Attributes: o.PreserveSMBProperties}
+	dirs := []remoteDownload{{index: -1, localPath: localRoot}} // The root, which is listed first but isn't a result
+	walkErr := func() error {
+		for i := 0; i < len(dirs); i++ {
+			parent := dirs[i]
+			var it *FilesAndDirectoriesIterator
+			if parent.path == "" {
+				it = dir.ListAll(ctx, ListFilesAndDirectoriesOptions{Include: include})
+			} else {
+				it = dir.NewDirectoryURL(parent.path).ListAll(ctx, ListFilesAndDirectoriesOptions{Include: include})
+			}
+			for it.Next() {
+				if file := it.File(); file != nil {
+					job := newRemoteDownload(parent, file.Name, file.Properties, file.Attributes)
+					if o.Incremental && job.isLocalCopy() {
+						continue
+					}
+					job.index = d.add(job.path)
+					if err := d.queue(ctx, job.index, func(ctx context.Context) error { return d.downloadFile(ctx, job) }); err != nil {
+						return err
+					}
+					continue
+				}
+				directory := it.Directory()
+				job := newRemoteDownload(parent, directory.Name, directory.Properties, directory.Attributes)
+				job.index = d.add(job.path)
+				if err := os.MkdirAll(job.localPath, 0755); err != nil {
+					d.done(job.index, err)
+					continue
+				}
+				dirs = append(dirs, job)
+				if o.PreserveSMBProperties {
+					d.setDirectoryProperties(job.index, func(ctx context.Context) error {
+						return setLocalSMBProperties(job.localPath, job.properties)
+					})
+				}
+			}
+			if err := it.Err(); err != nil {
+				if parent.index < 0 {
+					return err
+				}
+				d.done(parent.index, err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	d.finish(ctx)
+	return d.err(walkErr)
+}
+
+// remoteDownload is a file or directory in the share found by DownloadShareToDirectory.
+type remoteDownload struct {
+	index      int
+	path       string // Relative to the directory in the share
+	localPath  string
+	size       int64
+	properties SMBProperties // As the listing returned them
+}
+
+// newRemoteDownload returns the download of the listed file or directory called name in parent.
+func newRemoteDownload(parent remoteDownload, name string, properties *FileProperty, attributes *string) remoteDownload {
+	r := remoteDownload{path: joinTreePath(parent.path, name), localPath: filepath.Join(parent.localPath, filepath.FromSlash(name)),
+		properties: listedSMBProperties(properties, attributes)}
+	if properties != nil {
+		r.size = properties.ContentLength
+	}
+	return r
+}
+
+// joinTreePath returns the path of name in the directory at parent, both relative to the root of a tree.
+func joinTreePath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+// listedSMBProperties returns the attributes and the creation and last write times of a listed file or directory,
+// as far as the listing included them.
+func listedSMBProperties(properties *FileProperty, attributes *string) SMBProperties {
+	var p SMBProperties
+	if properties != nil {
+		p.FileCreationTime, p.FileLastWriteTime = properties.CreationTime, properties.LastWriteTime
+	}
+	if attributes != nil {
+		if f, err := ParseFileAttributeFlags(*attributes); err == nil {
+			p.FileAttributes = &f
+		}
+	}
+	return p
+}
+
+// isLocalCopy reports whether the local file has the size and the last write time of the file in the share.
+func (r remoteDownload) isLocalCopy() bool {
+	info, err := os.Stat(r.localPath)
+	return err == nil && info.Mode().IsRegular() && info.Size() == r.size &&
+		r.properties.FileLastWriteTime != nil && info.ModTime().Equal(*r.properties.FileLastWriteTime)
+}
+
+// directoryDownloader holds the state shared by the goroutines of a DownloadShareToDirectory call.
+type directoryDownloader struct {
+	treeTransfer
+	dir DirectoryURL
+	o   DownloadDirOptions
+}
+
+// downloadFile downloads the file of job to a temporary local file, which replaces the local file once it's
+// complete, and then sets its SMB properties.
+func (d *directoryDownloader) downloadFile(ctx context.Context, job remoteDownload) error {
+	tempPath := job.localPath + DownloadTempFileSuffix
+	file, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	do := DownloadFromAzureFileOptions{RangeSize: d.o.RangeSize, Parallelism: 1, MaxRetryRequestsPerRange: d.o.MaxRetryRequestsPerRange}
+	if d.o.Progress != nil {
+		do.Progress = func(bytesTransferred int64) { d.o.Progress(job.path, bytesTransferred) }
+	}
+	_, err = DownloadAzureFileToFile(ctx, d.dir.NewFileURL(job.path), 0, CountToEnd, file, do)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, job.localPath)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	switch {
+	case d.o.PreserveSMBProperties:
+		return setLocalSMBProperties(job.localPath, job.properties)
+	case d.o.Incremental && job.properties.FileLastWriteTime != nil:
+		return setLocalSMBProperties(job.localPath, SMBProperties{FileLastWriteTime: job.properties.FileLastWriteTime})
+	}
+	return nil
+}
+
+// defaultCopyPollInterval is the longest delay between the polls of a copy that CopyTree waits for, by default.
+const defaultCopyPollInterval = 5 * time.Second
+
+// CopyTreeOptions identifies options used by CopyTree.
+type CopyTreeOptions struct {
+	// Parallelism indicates the maximum number of file copies in progress at once, each started and then polled
+	// until it ends. If 0(default) is provided, 5 parallelism will be used by default.
+	Parallelism uint16
+
+	// PollInterval caps the delay between the polls of each copy, as WaitForCopy's pollInterval does; the default is
+	// 5 seconds.
+	PollInterval time.Duration
+
+	// PreserveSMBProperties copies the security descriptor, the attributes, and the creation and last write times of
+	// the source files and directories to their copies. Files copy them on the service; the directories' are read
+	// from the listings of the source, and the security descriptors from its share, unless the copy is in the same
+	// share, where their permission keys are reused.
+	PreserveSMBProperties bool
+
+	// CopySourceAuthorization, if not "", is an Azure AD (OAuth) access token with which the service reads the source
+	// files; see StartCopyOptions.CopySourceAuthorization.
+	CopySourceAuthorization string
+
+	// FileCopied, if not nil, is invoked as the copy of each file ends, with the file's path relative to the source
+	// directory and its error, if any. It's invoked concurrently for files copied in parallel.
+	FileCopied func(result FileTransferResult)
+
+	// ContinueOnError makes CopyTree copy the remaining files after one fails. By default, the first failure cancels
+	// the outstanding copies, which are left to end on the service, and no more are started.
+	ContinueOnError bool
+}
+
+// CopyTree copies the tree of directories rooted at source to dest, which is created if it doesn't exist, without the
+// data passing through the client: each directory is created in dest as it's listed, unless it exists, and each file
+// is copied on the service with FileURL.StartCopy, o.Parallelism at a time, replacing any files of the same names.
+// The files keep their metadata, the directories don't. The service must be able to read the source files: within an
+// account, source and dest's pipeline can share a SharedKeyCredential, but from another account source needs a SAS,
+// or o.CopySourceAuthorization a token. If any file or directory can't be copied, the error is a
+// *DirectoryTransferError; a directory that can't be created or listed fails, with what's in it, but the rest of the
+// tree is still copied if o.ContinueOnError is set. If ctx is done, CopyTree stops, and returns; the copies already
+// started go on on the service.
+func CopyTree(ctx context.Context, source DirectoryURL, dest DirectoryURL, o CopyTreeOptions) error {
+	if o.PollInterval < 0 {
+		return errors.New("invalid argument, o.PollInterval must be >= 0")
+	}
+	if o.PollInterval == 0 {
+		o.PollInterval = defaultCopyPollInterval
+	}
+	if o.Parallelism == 0 {
+		o.Parallelism = defaultParallelCount // default parallelism
+	}
+	if NewFileURLParts(dest.URL()).DirectoryOrFilePath != "" {
+		if _, err := dest.CreateIfNotExists(ctx, nil, SMBProperties{}); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	t := &treeCopier{treeTransfer: treeTransfer{continueOnError: o.ContinueOnError, cancel: cancel, fileDone: o.FileCopied},
+		source: source, dest: dest, o: o, permissions: map[string]*string{}}
+	t.start(ctx, o.Parallelism)
+
+	include := ListFilesAndDirectoriesDetail{Timestamps: o.PreserveSMBProperties, Attributes: o.PreserveSMBProperties,
+		PermissionKey: o.PreserveSMBProperties}
+	dirs := []treeCopy{{index: -1}} // The root, which is listed first but isn't a result
+	walkErr := func() error {
+		for i := 0; i < len(dirs); i++ {
+			parent := dirs[i]
+			var it *FilesAndDirectoriesIterator
+			if parent.path == "" {
+				it = source.ListAll(ctx, ListFilesAndDirectoriesOptions{Include: include})
+			} else {
+				it = source.NewDirectoryURL(parent.path).ListAll(ctx, ListFilesAndDirectoriesOptions{Include: include})
+			}
+			for it.Next() {
+				if file := it.File(); file != nil {
+					job := treeCopy{path: joinTreePath(parent.path, file.Name)}
+					job.index = t.add(job.path)
+					if err := t.queue(ctx, job.index, func(ctx context.Context) error { return t.copyFile(ctx, job.path) }); err != nil {
+						return err
+					}
+					continue
+				}
+				directory := it.Directory()
+				job := treeCopy{path: joinTreePath(parent.path, directory.Name),
+					properties: listedSMBProperties(directory.Properties, directory.Attributes)}
+				job.index = t.add(job.path)
+				if o.PreserveSMBProperties && directory.PermissionKey != nil {
+					permission, err := t.permission(ctx, *directory.PermissionKey)
+					if err != nil {
+						t.done(job.index, err)
+						continue
+					}
+					job.properties.FilePermission, job.properties.FilePermissionKey = permission.sddl, permission.key
+				}
+				if _, err := dest.NewDirectoryURL(job.path).CreateIfNotExists(ctx, nil, SMBProperties{}); err != nil {
+					t.done(job.index, err)
+					continue
+				}
+				dirs = append(dirs, job)
+				if o.PreserveSMBProperties {
+					t.setDirectoryProperties(job.index, func(ctx context.Context) error {
+						_, err := dest.NewDirectoryURL(job.path).SetProperties(ctx, job.properties)
+						return err
+					})
+				}
+			}
+			if err := it.Err(); err != nil {
+				if parent.index < 0 {
+					return err
+				}
+				t.done(parent.index, err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	t.finish(ctx)
+	return t.err(walkErr)
+}
+
+// treeCopy is a file or directory in the source of a CopyTree call.
+type treeCopy struct {
+	index      int
+	path       string        // Relative to the source directory
+	properties SMBProperties // For a directory, those to set on its copy
+}
+
+// treeCopier holds the state shared by the goroutines of a CopyTree call.
+type treeCopier struct {
+	treeTransfer
+	source, dest DirectoryURL
+	o            CopyTreeOptions
+
+	// permissions maps the permission keys of the source's share to the security descriptors they stand for, in
+	// dest's share; only the goroutine listing the source uses it.
+	permissions map[string]*string
+}
+
+// copyPermission is the security descriptor to set on the copy of a directory: the key of the source's, if the
+// copy is in the same share, or else the descriptor itself.
+type copyPermission struct {
+	sddl, key *string
+}
+
+// permission returns the security descriptor to set on the copies of the directories whose permission key is key.
+func (t *treeCopier) permission(ctx context.Context, key string) (copyPermission, error) {
+	sourceShare, destShare := NewFileURLParts(t.source.URL()), NewFileURLParts(t.dest.URL())
+	if strings.EqualFold(sourceShare.Host, destShare.Host) && sourceShare.IPEndpointStyleInfo == destShare.IPEndpointStyleInfo &&
+		sourceShare.ShareName == destShare.ShareName && destShare.ShareSnapshot == "" {
+		return copyPermission{key: &key}, nil
+	}
+	if sddl, ok := t.permissions[key]; ok {
+		return copyPermission{sddl: sddl}, nil
+	}
+	sourceShare.DirectoryOrFilePath = ""
+	resp, err := NewShareURL(sourceShare.URL(), t.source.directoryClient.Pipeline()).GetPermission(ctx, key)
+	if err != nil {
+		return copyPermission{}, err
+	}
+	t.permissions[key] = &resp.Permission
+	return copyPermission{sddl: &resp.Permission}, nil
+}
+
+// copyFile starts the copy of the file at path, and waits for it to end.
+func (t *treeCopier) copyFile(ctx context.Context, path string) error {
+	so := StartCopyOptions{CopySourceAuthorization: t.o.CopySourceAuthorization}
+	if t.o.PreserveSMBProperties {
+		so.PermissionCopyMode = PermissionCopyModeSource
+		so.CopyFileAttributes, so.CopyCreationTime, so.CopyLastWriteTime = true, true, true
+	}
+	destFile := t.dest.NewFileURL(path)
+	resp, err := destFile.StartCopy(ctx, t.source.NewFileURL(path).URL(), nil, so)
+	if err != nil {
+		return err
+	}
+	if resp.CopyStatus() == CopyStatusSuccess {
+		return nil
+	}
+	_, err = WaitForCopy(ctx, destFile, resp.CopyID(), t.o.PollInterval)
+	return err
+}
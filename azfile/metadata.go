@@ -0,0 +1,45 @@
+package azfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// metadataKeyPattern matches the metadata keys the service accepts: C# identifiers, in ASCII.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate returns an error if the service would reject md: if a key isn't a valid C# identifier (letters, digits
+// and underscores, not starting with a digit), or if two keys differ only by case, since the service treats keys
+// case-insensitively. The methods that send metadata call Validate, and return its error without sending a request.
+func (md Metadata) Validate() error {
+	lowered := make(map[string]string, len(md))
+	for k := range md {
+		if !metadataKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid argument, metadata key %q isn't a valid C# identifier", k)
+		}
+		l := strings.ToLower(k)
+		if other, ok := lowered[l]; ok {
+			if other > k {
+				other, k = k, other
+			}
+			return fmt.Errorf("invalid argument, metadata keys %q and %q differ only by case", other, k)
+		}
+		lowered[l] = k
+	}
+	return nil
+}
+
+// Get returns the value of the key that equals key, ignoring case, and whether there is one. The NewMetadata
+// methods of responses return lowercase keys, whatever the case they were set with; look them up with Get.
+func (md Metadata) Get(key string) (string, bool) {
+	if v, ok := md[key]; ok {
+		return v, true
+	}
+	for k, v := range md {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
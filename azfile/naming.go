@@ -0,0 +1,103 @@
+package azfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ShareNameMinLength and ShareNameMaxLength bound the length of a share's name.
+	ShareNameMinLength, ShareNameMaxLength = 3, 63
+
+	// DirectoryNameMaxLength is the maximum length, in characters, of the name of a directory or file.
+	DirectoryNameMaxLength = 255
+
+	// FilePathMaxLength is the maximum length, in characters, of the path of a directory or file from the root of its
+	// share, and FilePathMaxDepth the maximum number of directories in it.
+	FilePathMaxLength, FilePathMaxDepth = 2048, 250
+)
+
+// reservedNames are the directory and file names the service rejects, whatever their case.
+var reservedNames = map[string]bool{
+	".": true, "..": true, "CON": true, "PRN": true, "AUX": true, "NUL": true, "CLOCK$": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// ValidateShareName returns an error if the service would reject name as a share's name: it must be 3 to 63
+// characters long, of lowercase letters, digits and hyphens, start and end with a letter or a digit, and not have
+// two hyphens in a row.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/naming-and-referencing-shares--directories--files--and-metadata.
+func ValidateShareName(name string) error {
+	if len(name) < ShareNameMinLength || len(name) > ShareNameMaxLength {
+		return fmt.Errorf("invalid argument, share name %q must be %d to %d characters long", name, ShareNameMinLength, ShareNameMaxLength)
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return fmt.Errorf("invalid argument, share name %q may only contain lowercase letters, digits and hyphens", name)
+		}
+	}
+	if name[0] == '-' || name[len(name)-1] == '-' || strings.Contains(name, "--") {
+		return fmt.Errorf("invalid argument, share name %q must start and end with a letter or a digit, and not have consecutive hyphens", name)
+	}
+	return nil
+}
+
+// ValidateDirectoryName returns an error if the service would reject name as the name of a directory or file, a
+// single element of a path: it must be 1 to 255 characters long, have none of the characters " \ / : | < > * ? and
+// no control characters, and not be a reserved name, such as "..", "CON" or "LPT1". Names are case-insensitive.
+// Other characters, like '%', '#' or spaces, are allowed; pass names unescaped, the URLs percent-encode them.
+func ValidateDirectoryName(name string) error {
+	if name == "" {
+		return errors.New("invalid argument, directory and file names must not be empty")
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("invalid argument, name %q isn't valid UTF-8", name)
+	}
+	if n := utf8.RuneCountInString(name); n > DirectoryNameMaxLength {
+		return fmt.Errorf("invalid argument, name %q is %d characters long, more than %d", name, n, DirectoryNameMaxLength)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("invalid argument, name %q contains the control character %U", name, r)
+		}
+		if strings.ContainsRune(`"\/:|<>*?`, r) {
+			return fmt.Errorf("invalid argument, name %q contains %q, which directory and file names can't", name, r)
+		}
+	}
+	if reservedNames[strings.ToUpper(name)] {
+		return fmt.Errorf("invalid argument, %q is a reserved name", name)
+	}
+	return nil
+}
+
+// ValidateFilePath returns an error if the service would reject path as the path of a directory or file from the
+// root of its share, such as "dir/sub/file": each of its elements, separated by '/', must be valid for
+// ValidateDirectoryName, and the path must be at most 2048 characters long, with at most 250 directories.
+func ValidateFilePath(path string) error {
+	if n := utf8.RuneCountInString(path); n > FilePathMaxLength {
+		return fmt.Errorf("invalid argument, path %q is %d characters long, more than %d", path, n, FilePathMaxLength)
+	}
+	elements := strings.Split(path, "/")
+	if len(elements)-1 > FilePathMaxDepth {
+		return fmt.Errorf("invalid argument, path %q has %d directories, more than %d", path, len(elements)-1, FilePathMaxDepth)
+	}
+	for _, element := range elements {
+		if err := ValidateDirectoryName(element); err != nil {
+			return fmt.Errorf("%v, in path %q", err, path)
+		}
+	}
+	return nil
+}
+
+// validatePath returns ValidateFilePath's error for the path, from the root of the share, of name relative to base.
+func validatePath(base FileURLParts, name string) error {
+	path := strings.TrimSuffix(base.DirectoryOrFilePath, "/")
+	if path != "" {
+		path += "/"
+	}
+	return ValidateFilePath(path + name)
+}
@@ -12,9 +12,13 @@ const (
 
 // A FileURLParts object represents the components that make up an Azure Storage Share/Directory/File URL. You parse an
 // existing URL into its parts by calling NewFileURLParts(). You construct a URL from parts by calling URL().
+// For example, to strip a URL's SAS, or to point it at a share snapshot, change SAS or ShareSnapshot and call URL().
+// DirectoryOrFilePath is unescaped, e.g. "my dir/100%.txt"; URL() percent-encodes it, as the URL's String method
+// escapes its Path. A trailing "/" in DirectoryOrFilePath, as in a directory URL "share/dir/", is kept; the share's
+// root directory has a DirectoryOrFilePath of "", and no trailing "/".
 // NOTE: Changing any SAS-related field requires computing a new SAS signature.
 type FileURLParts struct {
-	Scheme              string // Ex: "https://"
+	Scheme              string // Ex: "https"
 	Host                string // Ex: "account.share.core.windows.net", "10.132.141.33", "10.132.141.33:80"
 	ShareName           string // Share name, Ex: "myshare"
 	DirectoryOrFilePath string // Path of directory or file, Ex: "mydirectory/myfile"
@@ -101,7 +105,7 @@ func NewFileURLParts(u url.URL) FileURLParts {
 		// If we recognized the query parameter, remove it from the map
 		delete(paramsMap, shareSnapshot)
 	}
-	up.SAS = newSASQueryParameters(paramsMap, true)
+	up.SAS = NewSASQueryParameters(paramsMap, true)
 	up.UnparsedParams = paramsMap.Encode()
 	return up
 }
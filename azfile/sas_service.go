@@ -16,7 +16,7 @@ type FileSASSignatureValues struct {
 	ExpiryTime         time.Time   `param:"se"`  // Not specified if IsZero
 	Permissions        string      `param:"sp"`  // Create by initializing a ShareSASPermissions or FileSASPermissions and then call String()
 	IPRange            IPRange     `param:"sip"`
-	Identifier         string      `param:"si"`
+	Identifier         string      `param:"si"` // The ID of a stored access policy; see ShareURL.SetPermissions
 	ShareName          string
 	FilePath           string // Ex: "directory/FileName" or "FileName". Use "" to create a Share SAS.
 	CacheControl       string // rscc
@@ -32,6 +32,9 @@ func (v FileSASSignatureValues) NewSASQueryParameters(sharedKeyCredential *Share
 	if sharedKeyCredential == nil {
 		return SASQueryParameters{}, errors.New("sharedKeyCredential can't be nil")
 	}
+	if err := v.Validate(); err != nil {
+		return SASQueryParameters{}, err
+	}
 
 	resource := "s"
 	if v.FilePath == "" {
@@ -97,6 +100,33 @@ func (v FileSASSignatureValues) NewSASQueryParameters(sharedKeyCredential *Share
 	return p, nil
 }
 
+// Validate returns an error if the service would reject a SAS with v's values: if ShareName is empty, if Permissions
+// has a permission the SAS's resource doesn't have (only a share SAS, whose FilePath is "", can grant List), if
+// Protocol isn't one of the SASProtocol constants, if IPRange isn't a range of IPv4 addresses, or if StartTime isn't
+// before ExpiryTime. ExpiryTime and Permissions are required unless Identifier names a stored access policy, which
+// can provide them. NewSASQueryParameters calls Validate, and refuses to sign invalid values.
+func (v FileSASSignatureValues) Validate() error {
+	if v.ShareName == "" {
+		return errors.New("invalid argument, a file or share SAS needs a ShareName")
+	}
+	if v.FilePath == "" {
+		if err := (&ShareSASPermissions{}).Parse(v.Permissions); err != nil {
+			return err
+		}
+	} else {
+		if strings.ContainsRune(v.Permissions, 'l') {
+			return errors.New("invalid argument, a file SAS can't grant List; only a share SAS, whose FilePath is \"\", can")
+		}
+		if err := (&FileSASPermissions{}).Parse(v.Permissions); err != nil {
+			return err
+		}
+	}
+	if v.Identifier == "" && (v.ExpiryTime.IsZero() || v.Permissions == "") {
+		return errors.New("invalid argument, a SAS without an Identifier needs an ExpiryTime and Permissions")
+	}
+	return validateSASValues(v.Protocol, v.IPRange, v.StartTime, v.ExpiryTime)
+}
+
 // getCanonicalName computes the canonical name for a share or file resource for SAS signing.
 func getCanonicalName(account string, shareName string, filePath string) string {
 	// Share: "/file/account/sharename"
@@ -157,7 +187,7 @@ func (p *ShareSASPermissions) Parse(s string) error {
 		case 'l':
 			p.List = true
 		default:
-			return fmt.Errorf("Invalid permission: '%v'", r)
+			return fmt.Errorf("Invalid permission: '%c'", r)
 		}
 	}
 	return nil
@@ -200,7 +230,7 @@ func (p *FileSASPermissions) Parse(s string) error {
 		case 'd':
 			p.Delete = true
 		default:
-			return fmt.Errorf("Invalid permission: '%v'", r)
+			return fmt.Errorf("Invalid permission: '%c'", r)
 		}
 	}
 	return nil
@@ -19,6 +19,18 @@ const (
 	// A portion of the specified file is locked by an SMB client (409).
 	ServiceCodeFileLockConflict ServiceCodeType = "FileLockConflict"
 
+	// There is already a lease present (409).
+	ServiceCodeLeaseAlreadyPresent ServiceCodeType = "LeaseAlreadyPresent"
+
+	// The lease ID specified did not match the lease ID for the file or share (409).
+	ServiceCodeLeaseIDMismatchWithLeaseOperation ServiceCodeType = "LeaseIdMismatchWithLeaseOperation"
+
+	// There is currently a lease on the file or share and no lease ID was specified in the request (412).
+	ServiceCodeLeaseIDMissing ServiceCodeType = "LeaseIdMissing"
+
+	// There is currently no lease on the file or share (409).
+	ServiceCodeLeaseNotPresentWithLeaseOperation ServiceCodeType = "LeaseNotPresentWithLeaseOperation"
+
 	// File or directory path is too long (400).
 	// Or File or directory path has too many subdirectories (400).
 	ServiceCodeInvalidFileOrDirectoryPathName ServiceCodeType = "InvalidFileOrDirectoryPathName"
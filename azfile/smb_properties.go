@@ -0,0 +1,254 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+)
+
+// FileAttributeFlags is a set of SMB (NTFS) file attributes. The values match the Windows FILE_ATTRIBUTE_* constants,
+// so attributes read from a Windows file system can be converted directly.
+type FileAttributeFlags uint32
+
+const (
+	// FileAttributeNone indicates that no attributes are set.
+	FileAttributeNone FileAttributeFlags = 0
+
+	// FileAttributeReadOnly indicates that the file is read-only.
+	FileAttributeReadOnly FileAttributeFlags = 0x1
+
+	// FileAttributeHidden indicates that the file is hidden.
+	FileAttributeHidden FileAttributeFlags = 0x2
+
+	// FileAttributeSystem indicates that the file is used by the operating system.
+	FileAttributeSystem FileAttributeFlags = 0x4
+
+	// FileAttributeDirectory indicates that the item is a directory.
+	FileAttributeDirectory FileAttributeFlags = 0x10
+
+	// FileAttributeArchive indicates that the file is marked for backup or removal.
+	FileAttributeArchive FileAttributeFlags = 0x20
+
+	// FileAttributeTemporary indicates that the file is used for temporary storage.
+	FileAttributeTemporary FileAttributeFlags = 0x100
+
+	// FileAttributeOffline indicates that the file's data isn't available immediately.
+	FileAttributeOffline FileAttributeFlags = 0x1000
+
+	// FileAttributeNotContentIndexed indicates that the file isn't indexed by the content indexing service.
+	FileAttributeNotContentIndexed FileAttributeFlags = 0x2000
+
+	// FileAttributeNoScrubData indicates that the file isn't read by the background data integrity scanner.
+	FileAttributeNoScrubData FileAttributeFlags = 0x20000
+)
+
+// fileAttributeNames lists the attributes in the order the service returns them, with the names it uses.
+var fileAttributeNames = []struct {
+	flag FileAttributeFlags
+	name string
+}{
+	{FileAttributeReadOnly, "ReadOnly"},
+	{FileAttributeHidden, "Hidden"},
+	{FileAttributeSystem, "System"},
+	{FileAttributeDirectory, "Directory"},
+	{FileAttributeArchive, "Archive"},
+	{FileAttributeTemporary, "Temporary"},
+	{FileAttributeOffline, "Offline"},
+	{FileAttributeNotContentIndexed, "NotContentIndexed"},
+	{FileAttributeNoScrubData, "NoScrubData"},
+}
+
+// String returns the attributes in the form used by the x-ms-file-attributes header, e.g. "ReadOnly|Archive".
+// An empty set is "None". Bits that don't correspond to a FileAttribute* constant are not rendered.
+func (f FileAttributeFlags) String() string {
+	names := []string{}
+	for _, a := range fileAttributeNames {
+		if f&a.flag != 0 {
+			names = append(names, a.name)
+		}
+	}
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseFileAttributeFlags parses attributes in the form returned in the x-ms-file-attributes header,
+// e.g. "ReadOnly | Archive". Names are matched case-insensitively; "" and "None" are the empty set.
+func ParseFileAttributeFlags(s string) (FileAttributeFlags, error) {
+	f := FileAttributeNone
+	if strings.TrimSpace(s) == "" {
+		return f, nil
+	}
+	for _, name := range strings.Split(s, "|") {
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, "None") {
+			continue
+		}
+		found := false
+		for _, a := range fileAttributeNames {
+			if strings.EqualFold(name, a.name) {
+				f |= a.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return FileAttributeNone, fmt.Errorf("invalid file attribute: %q", name)
+		}
+	}
+	return f, nil
+}
+
+// FilePermissionMaxInlineSize is the largest security descriptor, in bytes, that the service accepts in the
+// x-ms-file-permission header. Larger descriptors must be stored with ShareURL.CreatePermission and referenced by key,
+// which SMBProperties.FilePermission does automatically.
+const FilePermissionMaxInlineSize = 8 * 1024
+
+// smbTimeFormat is the ISO 8601 format the service uses for the x-ms-file-*-time headers.
+const smbTimeFormat = "2006-01-02T15:04:05.0000000Z"
+
+// SMBProperties are the SMB (NTFS) properties of a file or directory.
+// A nil field takes the service default when creating, and keeps the current value when setting properties.
+type SMBProperties struct {
+	// FileAttributes are the file's attributes. When nil, a new file has no attributes.
+	FileAttributes *FileAttributeFlags
+
+	// FileCreationTime is the file's creation time. When nil, a new file's creation time is the time of the request.
+	FileCreationTime *time.Time
+
+	// FileLastWriteTime is the file's last write time. When nil, a new file's last write time is the time of the request.
+	FileLastWriteTime *time.Time
+
+	// FileChangeTime is the file's change time. When nil, and FileChangeTimeNow is false, it's left to the service: a
+	// new file's is the time of the request, and setting properties keeps the current one. It's sent by Create,
+	// SetProperties and Rename, with service version 2021-06-08, which earlier versions don't support; StartCopy
+	// ignores it.
+	FileChangeTime *time.Time
+
+	// FileChangeTimeNow, if true, sets the change time to the time of the request, in place of FileChangeTime. It's
+	// sent like FileChangeTime.
+	FileChangeTimeNow bool
+
+	// FilePermission is the file's security descriptor, in SDDL form. A descriptor longer than
+	// FilePermissionMaxInlineSize is stored on the file's share with ShareURL.CreatePermission, and sent by key.
+	// At most one of FilePermission and FilePermissionKey may be set; when both are nil, a new file inherits the
+	// security descriptor of its parent directory.
+	FilePermission *string
+
+	// FilePermissionKey is the key of a security descriptor stored on the file's share; see ShareURL.CreatePermission.
+	FilePermissionKey *string
+}
+
+// pointers returns the header values for the SMB properties, using the specified service defaults for nil fields.
+func (p SMBProperties) pointers(defaultAttributes, defaultTime, defaultPermission string) (attributes, creationTime, lastWriteTime string, permission, permissionKey *string) {
+	attributes, creationTime, lastWriteTime = defaultAttributes, defaultTime, defaultTime
+	if p.FileAttributes != nil {
+		attributes = p.FileAttributes.String()
+	}
+	if p.FileCreationTime != nil {
+		creationTime = p.FileCreationTime.UTC().Format(smbTimeFormat)
+	}
+	if p.FileLastWriteTime != nil {
+		lastWriteTime = p.FileLastWriteTime.UTC().Format(smbTimeFormat)
+	}
+	// The service requires exactly one of x-ms-file-permission and x-ms-file-permission-key.
+	if p.FilePermissionKey != nil {
+		permissionKey = p.FilePermissionKey
+	} else if p.FilePermission != nil {
+		permission = p.FilePermission
+	} else {
+		permission = &defaultPermission
+	}
+	return
+}
+
+// timePointers returns the header values for the attributes and times, which are nil for nil fields.
+func (p SMBProperties) timePointers() (attributes, creationTime, lastWriteTime *string) {
+	if p.FileAttributes != nil {
+		a := p.FileAttributes.String()
+		attributes = &a
+	}
+	if p.FileCreationTime != nil {
+		t := p.FileCreationTime.UTC().Format(smbTimeFormat)
+		creationTime = &t
+	}
+	if p.FileLastWriteTime != nil {
+		t := p.FileLastWriteTime.UTC().Format(smbTimeFormat)
+		lastWriteTime = &t
+	}
+	return
+}
+
+// changeTimePointer returns the header value for the change time, which is nil when the change time is nil, and
+// "now" for FileChangeTimeNow.
+func (p SMBProperties) changeTimePointer() *string {
+	if p.FileChangeTimeNow {
+		now := defaultCurrentTimeValue
+		return &now
+	}
+	if p.FileChangeTime == nil {
+		return nil
+	}
+	t := p.FileChangeTime.UTC().Format(smbTimeFormat)
+	return &t
+}
+
+// withPermissionKey returns p with a FilePermission too large for the x-ms-file-permission header replaced by
+// the key of the same descriptor, stored on the share of the file or directory at u.
+func (p SMBProperties) withPermissionKey(ctx context.Context, u url.URL, pl pipeline.Pipeline) (SMBProperties, error) {
+	if p.FilePermission != nil && p.FilePermissionKey != nil {
+		return p, errors.New("invalid argument, only one of FilePermission and FilePermissionKey may be specified")
+	}
+	if p.FilePermission == nil || len(*p.FilePermission) <= FilePermissionMaxInlineSize {
+		return p, nil
+	}
+	// Permissions are stored on the base share, whatever the file or directory's path and snapshot.
+	parts := NewFileURLParts(u)
+	parts.DirectoryOrFilePath, parts.ShareSnapshot = "", ""
+	resp, err := NewShareURL(parts.URL(), pl).CreatePermission(ctx, *p.FilePermission)
+	if err != nil {
+		return p, err
+	}
+	key := resp.FilePermissionKey()
+	p.FilePermission, p.FilePermissionKey = nil, &key
+	return p, nil
+}
+
+// smbPropertySource is implemented by the responses that return a file's or directory's SMB properties.
+type smbPropertySource interface {
+	FileAttributes() string
+	FileCreationTime() string
+	FileLastWriteTime() string
+	FileChangeTime() string
+	FilePermissionKey() string
+}
+
+// newSMBProperties returns the SMB properties of a response. Fields the response doesn't contain, or contains in an
+// unexpected form, are nil.
+func newSMBProperties(r smbPropertySource) SMBProperties {
+	p := SMBProperties{}
+	if s := r.FileAttributes(); s != "" {
+		if f, err := ParseFileAttributeFlags(s); err == nil {
+			p.FileAttributes = &f
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, r.FileCreationTime()); err == nil {
+		p.FileCreationTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, r.FileLastWriteTime()); err == nil {
+		p.FileLastWriteTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, r.FileChangeTime()); err == nil {
+		p.FileChangeTime = &t
+	}
+	if s := r.FilePermissionKey(); s != "" {
+		p.FilePermissionKey = &s
+	}
+	return p
+}
@@ -2,11 +2,17 @@ package azfile
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
+	"path"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
+// defaultDirectoryAttributes is what the service would apply when a directory's attributes are left unspecified.
+const defaultDirectoryAttributes = "Directory"
+
 // A DirectoryURL represents a URL to the Azure Storage directory allowing you to manipulate its directories and files.
 type DirectoryURL struct {
 	directoryClient directoryClient
@@ -35,6 +41,14 @@ func (d DirectoryURL) WithPipeline(p pipeline.Pipeline) DirectoryURL {
 	return NewDirectoryURL(d.URL(), p)
 }
 
+// WithSnapshot creates a new DirectoryURL object identical to the source but with the specified share snapshot timestamp.
+// Pass "" to remove the snapshot returning a URL to the base directory.
+func (d DirectoryURL) WithSnapshot(shareSnapshot string) DirectoryURL {
+	p := NewFileURLParts(d.URL())
+	p.ShareSnapshot = shareSnapshot
+	return NewDirectoryURL(p.URL(), d.directoryClient.Pipeline())
+}
+
 // NewFileURL creates a new FileURL object by concatenating fileName to the end of
 // DirectoryURL's URL. The new FileURL uses the same request policy pipeline as the DirectoryURL.
 // To change the pipeline, create the FileURL and then call its WithPipeline method passing in the
@@ -55,10 +69,62 @@ func (d DirectoryURL) NewDirectoryURL(directoryName string) DirectoryURL {
 	return NewDirectoryURL(directoryURL, d.directoryClient.Pipeline())
 }
 
+// NewValidatedFileURL is like NewFileURL, but it first checks the path the FileURL would have, from the root of the
+// share, with ValidateFilePath, and returns its error rather than a FileURL the service would reject.
+func (d DirectoryURL) NewValidatedFileURL(fileName string) (FileURL, error) {
+	if err := validatePath(NewFileURLParts(d.URL()), fileName); err != nil {
+		return FileURL{}, err
+	}
+	return d.NewFileURL(fileName), nil
+}
+
+// NewValidatedDirectoryURL is like NewDirectoryURL, but it first checks the path the DirectoryURL would have, from
+// the root of the share, with ValidateFilePath, and returns its error rather than a DirectoryURL the service would
+// reject.
+func (d DirectoryURL) NewValidatedDirectoryURL(directoryName string) (DirectoryURL, error) {
+	if err := validatePath(NewFileURLParts(d.URL()), directoryName); err != nil {
+		return DirectoryURL{}, err
+	}
+	return d.NewDirectoryURL(directoryName), nil
+}
+
+// withDirectoryAttribute returns p with FileAttributeDirectory added to its attributes, if they're specified;
+// the service always reports a directory's attributes with it.
+func withDirectoryAttribute(p SMBProperties) SMBProperties {
+	if p.FileAttributes != nil {
+		attributes := *p.FileAttributes | FileAttributeDirectory
+		p.FileAttributes = &attributes
+	}
+	return p
+}
+
 // Create creates a new directory within a storage account.
+// properties' nil fields take the service defaults (the Directory attribute only, creation and last write times
+// of now, and the parent directory's security descriptor). The Directory attribute is always set.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/create-directory.
-func (d DirectoryURL) Create(ctx context.Context, metadata Metadata) (*DirectoryCreateResponse, error) {
-	return d.directoryClient.Create(ctx, nil, metadata)
+func (d DirectoryURL) Create(ctx context.Context, metadata Metadata, properties SMBProperties) (*DirectoryCreateResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	properties, err := properties.withPermissionKey(ctx, d.URL(), d.directoryClient.Pipeline())
+	if err != nil {
+		return nil, err
+	}
+	attributes, creationTime, lastWriteTime, permission, permissionKey := withDirectoryAttribute(properties).pointers(
+		defaultDirectoryAttributes, defaultCurrentTimeValue, defaultFilePermission)
+	return d.directoryClient.Create(ctx, attributes, creationTime, lastWriteTime, properties.changeTimePointer(), nil,
+		metadata, permission, permissionKey)
+}
+
+// CreateIfNotExists creates the directory like Create, unless a directory with the same name already exists.
+// It returns true if it created the directory, and false with a nil error if the directory existed. A file with the
+// same name is still an error.
+func (d DirectoryURL) CreateIfNotExists(ctx context.Context, metadata Metadata, properties SMBProperties) (bool, error) {
+	_, err := d.Create(ctx, metadata, properties)
+	if storageErr, ok := err.(StorageError); ok && storageErr.ServiceCode() == ServiceCodeResourceAlreadyExists {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // Delete removes the specified empty directory. Note that the directory must be empty before it can be deleted..
@@ -67,34 +133,236 @@ func (d DirectoryURL) Delete(ctx context.Context) (*DirectoryDeleteResponse, err
 	return d.directoryClient.Delete(ctx, nil)
 }
 
+// Undelete restores the soft-deleted directory, which listings with ListFilesAndDirectoriesDetail.Deleted return with
+// Deleted set. Like FileURL.Undelete, it fails with the service's error on accounts without soft delete for files and
+// directories; see FileURL.Undelete to detect the feature.
+func (d DirectoryURL) Undelete(ctx context.Context) (*DirectoryUndeleteResponse, error) {
+	return d.directoryClient.Undelete(ctx, nil)
+}
+
+// DeleteIfExists deletes the directory like Delete, unless the directory, or its parent, doesn't exist.
+// It returns true if it deleted the directory, and false with a nil error if the directory didn't exist.
+func (d DirectoryURL) DeleteIfExists(ctx context.Context) (bool, error) {
+	_, err := d.Delete(ctx)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteRecursiveOptions defines options available when calling DeleteRecursive.
+type DeleteRecursiveOptions struct {
+	// Parallelism indicates the maximum number of files of a directory to delete in parallel. If 0(default) is provided, 5 parallelism will be used by default.
+	Parallelism uint16
+
+	// ForceCloseHandles makes DeleteRecursive close the SMB handles open on the directory and everything under it
+	// before deleting anything, as an open handle fails the delete of its file or directory.
+	ForceCloseHandles bool
+}
+
+// DeleteRecursiveError is returned by DeleteRecursive when a file or directory couldn't be deleted, or listed.
+type DeleteRecursiveError struct {
+	// Path is the path, in the share, of the file or directory that blocked the delete.
+	Path string
+
+	// Err is the error of the request that failed.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DeleteRecursiveError) Error() string {
+	return fmt.Sprintf("failed to delete %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error of the request that failed, for errors.Is and errors.As.
+func (e *DeleteRecursiveError) Unwrap() error {
+	return e.Err
+}
+
+// DeleteRecursive deletes the directory with all of its files and subdirectories. Each directory is emptied, its
+// files o.Parallelism at a time and then its subdirectories one after the other, before it's deleted itself. The
+// first failure stops the walk and is returned as a *DeleteRecursiveError, leaving what wasn't deleted yet in place.
+func (d DirectoryURL) DeleteRecursive(ctx context.Context, o DeleteRecursiveOptions) error {
+	dirPath := NewFileURLParts(d.URL()).DirectoryOrFilePath
+	if o.ForceCloseHandles {
+		for marker := (Marker{}); marker.NotDone(); {
+			resp, err := d.ForceCloseAllHandles(ctx, marker, ForceCloseHandlesOptions{Recursive: true})
+			if err != nil {
+				return &DeleteRecursiveError{Path: dirPath, Err: err}
+			}
+			marker = resp.NextMarker()
+		}
+	}
+	return d.deleteRecursive(ctx, dirPath, o)
+}
+
+func (d DirectoryURL) deleteRecursive(ctx context.Context, dirPath string, o DeleteRecursiveOptions) error {
+	var fileNames, dirNames []string
+	it := d.ListAll(ctx, ListFilesAndDirectoriesOptions{})
+	for it.Next() {
+		if file := it.File(); file != nil {
+			fileNames = append(fileNames, file.Name)
+		} else {
+			dirNames = append(dirNames, it.Directory().Name)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return &DeleteRecursiveError{Path: dirPath, Err: err}
+	}
+
+	if _, err := DeleteFiles(ctx, d, fileNames, BatchDeleteOptions{Parallelism: o.Parallelism}); err != nil {
+		// The first failure cancels the other deletes; report it rather than a cancelled one.
+		failures := err.(*BatchDeleteError).Failures
+		failure := failures[0]
+		for _, f := range failures {
+			if f.Error != context.Canceled {
+				failure = f
+				break
+			}
+		}
+		return &DeleteRecursiveError{Path: path.Join(dirPath, failure.Name), Err: failure.Error}
+	}
+	for _, name := range dirNames {
+		if err := d.NewDirectoryURL(name).deleteRecursive(ctx, path.Join(dirPath, name), o); err != nil {
+			return err
+		}
+	}
+
+	if _, err := d.Delete(ctx); err != nil {
+		return &DeleteRecursiveError{Path: dirPath, Err: err}
+	}
+	return nil
+}
+
+// Rename renames the directory, with its contents, on the service, to destinationPath: a path from the root of the
+// directory's share, such as "dir/subdir", or the URL of a directory in the same share. It returns a DirectoryURL for
+// the destination, with the directory's pipeline. Renaming to another share returns an error without sending a
+// request. The Directory attribute is always set.
+// Rename uses service version 2021-04-10, which the SDK otherwise doesn't require.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/rename-directory.
+func (d DirectoryURL) Rename(ctx context.Context, destinationPath string, o RenameOptions) (DirectoryURL, *DirectoryRenameResponse, error) {
+	replaceIfExists, ignoreReadOnly, err := o.renamePointers()
+	if err != nil {
+		return DirectoryURL{}, nil, err
+	}
+	dest, err := renameDestination(d.URL(), destinationPath)
+	if err != nil {
+		return DirectoryURL{}, nil, err
+	}
+	destURL := NewDirectoryURL(dest, d.directoryClient.Pipeline())
+	p, err := o.SMBProperties.withPermissionKey(ctx, dest, d.directoryClient.Pipeline())
+	if err != nil {
+		return DirectoryURL{}, nil, err
+	}
+	attributes, creationTime, lastWriteTime := withDirectoryAttribute(p).timePointers()
+	resp, err := destURL.directoryClient.Rename(ctx, d.String(), nil, replaceIfExists, ignoreReadOnly,
+		o.SourceLeaseAccessConditions.pointers(), o.DestinationLeaseAccessConditions.pointers(),
+		attributes, creationTime, lastWriteTime, p.changeTimePointer(), p.FilePermission, p.FilePermissionKey, o.Metadata)
+	if err != nil {
+		return DirectoryURL{}, resp, err
+	}
+	return destURL, resp, nil
+}
+
 // GetProperties returns the directory's metadata and system properties.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/get-directory-properties.
 func (d DirectoryURL) GetProperties(ctx context.Context) (*DirectoryGetPropertiesResponse, error) {
 	return d.directoryClient.GetProperties(ctx, nil, nil)
 }
 
-// SetMetadata sets the directory's metadata.
+// Exists reports whether the directory exists, with GetProperties. It returns false with a nil error if the service
+// says the directory, or its parent or share, doesn't exist; any other failure, such as a 403 (Forbidden) for a
+// request that isn't authorized, is returned as an error.
+func (d DirectoryURL) Exists(ctx context.Context) (bool, error) {
+	_, err := d.GetProperties(ctx)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetProperties sets the directory's SMB properties. properties' nil fields keep their current values.
+// The Directory attribute is always set.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/set-directory-properties.
+func (d DirectoryURL) SetProperties(ctx context.Context, properties SMBProperties) (*DirectorySetPropertiesResponse, error) {
+	properties, err := properties.withPermissionKey(ctx, d.URL(), d.directoryClient.Pipeline())
+	if err != nil {
+		return nil, err
+	}
+	attributes, creationTime, lastWriteTime, permission, permissionKey := withDirectoryAttribute(properties).pointers(
+		defaultPreserveValue, defaultPreserveValue, defaultPreserveValue)
+	return d.directoryClient.SetProperties(ctx, attributes, creationTime, lastWriteTime, properties.changeTimePointer(), nil,
+		permission, permissionKey)
+}
+
+// SetMetadata sets the directory's metadata, replacing all of its metadata; an empty or nil metadata clears it.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/set-directory-metadata.
 func (d DirectoryURL) SetMetadata(ctx context.Context, metadata Metadata) (*DirectorySetMetadataResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
 	return d.directoryClient.SetMetadata(ctx, nil, metadata)
 }
 
+// GetMetadata returns the directory's metadata, from GetProperties. Its keys are lowercase; look them up with
+// Metadata.Get, which ignores case.
+func (d DirectoryURL) GetMetadata(ctx context.Context) (Metadata, error) {
+	props, err := d.GetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return props.NewMetadata(), nil
+}
+
 // ListFilesAndDirectoriesOptions defines options available when calling ListFilesAndDirectoriesSegment.
 type ListFilesAndDirectoriesOptions struct {
-	Prefix     string // No Prefix header is produced if ""
-	MaxResults int32  // 0 means unspecified
+	Prefix     string                        // No Prefix header is produced if ""
+	MaxResults int32                         // 0 means unspecified
+	Include    ListFilesAndDirectoriesDetail // No include query parameter is produced if empty
 }
 
-func (o *ListFilesAndDirectoriesOptions) pointers() (prefix *string, maxResults *int32) {
+func (o *ListFilesAndDirectoriesOptions) pointers() (prefix *string, maxResults *int32, include []ListFilesIncludeType) {
 	if o.Prefix != "" {
 		prefix = &o.Prefix
 	}
 	if o.MaxResults != 0 {
 		maxResults = &o.MaxResults
 	}
+	include = o.Include.toArray()
 	return
 }
 
+// ListFilesAndDirectoriesDetail indicates what additional information the service should return with each file and
+// directory, saving a GetProperties call per entry. Timestamps fills in the CreationTime, LastAccessTime,
+// LastWriteTime, ChangeTime and LastModified of each entry's Properties, ETag fills in Properties.Etag, and Attributes
+// and PermissionKey fill in the entry's fields of the same names. Requesting any of them also returns each entry's FileID.
+// Deleted also lists the soft-deleted files and directories, with Deleted set and their Properties' DeletedTime and
+// RemainingRetentionDays, on accounts that have soft delete for them; others reject it, see FileURL.Undelete.
+type ListFilesAndDirectoriesDetail struct {
+	Timestamps, ETag, Attributes, PermissionKey, Deleted bool
+}
+
+// toArray produces the include query parameter's value.
+func (d *ListFilesAndDirectoriesDetail) toArray() []ListFilesIncludeType {
+	items := make([]ListFilesIncludeType, 0, 5)
+	if d.Timestamps {
+		items = append(items, ListFilesIncludeTimestamps)
+	}
+	if d.ETag {
+		items = append(items, ListFilesIncludeEtag)
+	}
+	if d.Attributes {
+		items = append(items, ListFilesIncludeAttributes)
+	}
+	if d.PermissionKey {
+		items = append(items, ListFilesIncludePermissionKey)
+	}
+	if d.Deleted {
+		items = append(items, ListFilesIncludeDeleted)
+	}
+	return items
+}
+
 // toConvenienceModel convert raw response to convenience model.
 // func (r *listFilesAndDirectoriesSegmentResponse) toConvenienceModel() *ListFilesAndDirectoriesSegmentResponse {
 // 	cr := ListFilesAndDirectoriesSegmentResponse{
@@ -137,8 +405,116 @@ func (o *ListFilesAndDirectoriesOptions) pointers() (prefix *string, maxResults
 // Use an empty Marker to start enumeration from the beginning. File and directory names are returned in lexicographic order.
 // After getting a segment, process it, and then call ListFilesAndDirectoriesSegment again (passing the the previously-returned
 // Marker) to get the next segment. This method lists the contents only for a single level of the directory hierarchy.
+// Set o.Include to return more of each entry's properties.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/list-directories-and-files.
 func (d DirectoryURL) ListFilesAndDirectoriesSegment(ctx context.Context, marker Marker, o ListFilesAndDirectoriesOptions) (*ListFilesAndDirectoriesSegmentResponse, error) {
-	prefix, maxResults := o.pointers()
-	return d.directoryClient.ListFilesAndDirectoriesSegment(ctx, prefix, nil, marker.val, maxResults, nil)
+	prefix, maxResults, include := o.pointers()
+	return d.directoryClient.ListFilesAndDirectoriesSegment(ctx, prefix, nil, marker.val, maxResults, nil, include, nil)
+}
+
+// ListAll returns an iterator over all of the directory's files and subdirectories, which fetches segments with
+// ListFilesAndDirectoriesSegment as they're needed, following each segment's NextMarker. o.MaxResults, if set,
+// limits the size of each segment rather than the number of entries. Like ListFilesAndDirectoriesSegment, ListAll
+// lists a single level of the directory hierarchy.
+func (d DirectoryURL) ListAll(ctx context.Context, o ListFilesAndDirectoriesOptions) *FilesAndDirectoriesIterator {
+	return &FilesAndDirectoriesIterator{ctx: ctx, d: d, o: o}
+}
+
+// FilesAndDirectoriesIterator iterates over the entries of a directory listing in lexicographic order; see
+// DirectoryURL.ListAll. Call Next to advance to each entry, and check Err once Next returns false:
+//
+//	it := directoryURL.ListAll(ctx, azfile.ListFilesAndDirectoriesOptions{})
+//	for it.Next() {
+//		if file := it.File(); file != nil {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type FilesAndDirectoriesIterator struct {
+	ctx    context.Context
+	d      DirectoryURL
+	o      ListFilesAndDirectoriesOptions
+	marker Marker
+	files  []FileItem
+	dirs   []DirectoryItem
+	file   *FileItem
+	dir    *DirectoryItem
+	err    error
+}
+
+// Next advances to the next entry, fetching the next segment of the listing if necessary. It returns false when the
+// listing is complete, when a request fails, or when the iterator's context is done; Err tells these apart.
+func (it *FilesAndDirectoriesIterator) Next() bool {
+	it.file, it.dir = nil, nil
+	for len(it.files) == 0 && len(it.dirs) == 0 {
+		if it.err != nil || !it.marker.NotDone() {
+			return false
+		}
+		if it.err = it.ctx.Err(); it.err != nil {
+			return false
+		}
+		resp, err := it.d.ListFilesAndDirectoriesSegment(it.ctx, it.marker, it.o)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.marker = resp.NextMarker
+		it.files, it.dirs = resp.FileItems, resp.DirectoryItems
+	}
+
+	// The service returns each segment's entries in lexicographic order, split into files and directories; merge them.
+	if len(it.dirs) == 0 || (len(it.files) > 0 && it.files[0].Name < it.dirs[0].Name) {
+		it.file, it.files = &it.files[0], it.files[1:]
+	} else {
+		it.dir, it.dirs = &it.dirs[0], it.dirs[1:]
+	}
+	return true
+}
+
+// File returns the current entry if it is a file, or nil if it is a directory.
+func (it *FilesAndDirectoriesIterator) File() *FileItem {
+	return it.file
+}
+
+// Directory returns the current entry if it is a directory, or nil if it is a file.
+func (it *FilesAndDirectoriesIterator) Directory() *DirectoryItem {
+	return it.dir
+}
+
+// Err returns the error that ended the iteration, or nil if the listing completed.
+func (it *FilesAndDirectoriesIterator) Err() error {
+	return it.err
+}
+
+// ListHandles returns a single segment of the SMB handles open on the directory, or with o.Recursive on the
+// directory and everything under it, starting from the specified Marker. Use an empty Marker to start enumeration
+// from the beginning. After getting a segment, process it, and then call ListHandles again (passing the the
+// previously-returned Marker) to get the next segment.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/list-handles.
+func (d DirectoryURL) ListHandles(ctx context.Context, marker Marker, o ListHandlesOptions) (*ListHandlesResponse, error) {
+	var maxResults *int32
+	if o.MaxResults != 0 {
+		maxResults = &o.MaxResults
+	}
+	return d.directoryClient.ListHandles(ctx, marker.val, maxResults, nil, &o.Recursive)
+}
+
+// ForceCloseHandles closes the SMB handle with the specified ID, as returned by ListHandles. The service may close
+// only some of the handles in one call; while the response's NextMarker is not done, call ForceCloseHandles again,
+// passing it, to close the rest. Use an empty Marker for the first call.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/force-close-handles.
+func (d DirectoryURL) ForceCloseHandles(ctx context.Context, handleID string, marker Marker, o ForceCloseHandlesOptions) (*DirectoryForceCloseHandlesResponse, error) {
+	if handleID == "" {
+		return nil, errors.New("invalid argument, handleID must not be empty")
+	}
+	return d.directoryClient.ForceCloseHandles(ctx, handleID, nil, marker.val, &o.Recursive)
+}
+
+// ForceCloseAllHandles closes all of the SMB handles open on the directory, or with o.Recursive on the directory and
+// everything under it. See ForceCloseHandles for how to continue a partial close.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/force-close-handles.
+func (d DirectoryURL) ForceCloseAllHandles(ctx context.Context, marker Marker, o ForceCloseHandlesOptions) (*DirectoryForceCloseHandlesResponse, error) {
+	return d.directoryClient.ForceCloseHandles(ctx, allHandles, nil, marker.val, &o.Recursive)
 }
@@ -1,11 +1,17 @@
 package azfile
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
@@ -13,11 +19,29 @@ import (
 const (
 	fileType = "file"
 
-	// FileMaxUploadRangeBytes indicates the maximum number of bytes that can be sent in a call to UploadRange.
+	// FileMaxUploadRangeBytes indicates the maximum number of bytes that can be sent in a call to UploadRange, unless
+	// UploadRangeOptions.MaxRangeSize raises it. The high-level upload functions use ranges of at most this size.
 	FileMaxUploadRangeBytes = 4 * 1024 * 1024 // 4MB
 
+	// FileMaxRangeGetContentMD5Bytes indicates the largest range for which Download can request the range's MD5.
+	FileMaxRangeGetContentMD5Bytes = 4 * 1024 * 1024 // 4MB
+
 	// FileMaxSizeInBytes indicates the maxiumum file size, in bytes.
 	FileMaxSizeInBytes int64 = 1 * 1024 * 1024 * 1024 * 1024 // 1TB
+
+	// FileInfiniteLeaseDuration is the only lease duration the File service supports; file leases never expire
+	// and must be released or broken explicitly.
+	FileInfiniteLeaseDuration int32 = -1
+
+	// The values below are what the service would apply when the file SMB properties are left unspecified.
+	// "preserve" keeps the value that is currently set on the file.
+	defaultFileAttributes   = "None"
+	defaultFilePermission   = "inherit"
+	defaultCurrentTimeValue = "now"
+	defaultPreserveValue    = "preserve"
+
+	// allHandles is the handle ID that ForceCloseHandles takes to close all handles.
+	allHandles = "*"
 )
 
 // A FileURL represents a URL to an Azure Storage file.
@@ -49,7 +73,7 @@ func (f FileURL) WithPipeline(p pipeline.Pipeline) FileURL {
 }
 
 // WithSnapshot creates a new FileURL object identical to the source but with the specified share snapshot timestamp.
-// Pass time.Time{} to remove the share snapshot returning a URL to the base file.
+// Pass "" to remove the share snapshot returning a URL to the base file.
 func (f FileURL) WithSnapshot(shareSnapshot string) FileURL {
 	p := NewFileURLParts(f.URL())
 	p.ShareSnapshot = shareSnapshot
@@ -57,28 +81,146 @@ func (f FileURL) WithSnapshot(shareSnapshot string) FileURL {
 }
 
 // Create creates a new file or replaces a file. Note that this method only initializes the file.
+// size is the file's size in bytes, between 0 and FileMaxSizeInBytes. The file has no data yet: it downloads as size
+// zero bytes, and GetRangeList lists no ranges, until ranges are written with UploadRange. A range can't be written
+// beyond the file's size, so a file created with size 0 must be grown with Resize, or UploadRangeOptions.GrowFile,
+// before any range is.
+// The file's SMB properties are taken from h.SMBProperties; nil fields take the service defaults (no attributes,
+// creation and last write times of now, and the parent directory's security descriptor).
+// A file that doesn't exist meets all of ac's ModifiedAccessConditions but IfMatch; pass an IfNoneMatch of ETagAny
+// to create the file only if it doesn't exist.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/create-file.
-func (f FileURL) Create(ctx context.Context, size int64, h FileHTTPHeaders, metadata Metadata) (*FileCreateResponse, error) {
-	return f.fileClient.Create(ctx, size, nil,
+func (f FileURL) Create(ctx context.Context, size int64, h FileHTTPHeaders, metadata Metadata, ac FileAccessConditions) (*FileCreateResponse, error) {
+	if size < 0 || size > FileMaxSizeInBytes {
+		return nil, fmt.Errorf("invalid argument, size must be >= 0 and <= %d", FileMaxSizeInBytes)
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	if err := f.checkAccessConditions(ctx, ac.ModifiedAccessConditions, true); err != nil {
+		return nil, err
+	}
+	properties, err := h.SMBProperties.withPermissionKey(ctx, f.URL(), f.fileClient.Pipeline())
+	if err != nil {
+		return nil, err
+	}
+	attributes, creationTime, lastWriteTime, permission, permissionKey := properties.pointers(
+		defaultFileAttributes, defaultCurrentTimeValue, defaultFilePermission)
+	return f.fileClient.Create(ctx, size, attributes, creationTime, lastWriteTime, properties.changeTimePointer(), nil,
 		&h.ContentType, &h.ContentEncoding, &h.ContentLanguage, &h.CacheControl,
-		h.ContentMD5, &h.ContentDisposition, metadata)
+		h.ContentMD5, &h.ContentDisposition, metadata, permission, permissionKey, ac.LeaseAccessConditions.pointers(),
+		optionalString(h.EncryptionScope))
+}
+
+// checkAccessConditions evaluates ac against the file's properties, if any of the conditions is set.
+func (f FileURL) checkAccessConditions(ctx context.Context, ac ModifiedAccessConditions, mayNotExist bool) error {
+	return ac.check(ctx, mayNotExist, func(ctx context.Context) (propertiesResponse, error) {
+		return f.GetProperties(ctx)
+	})
+}
+
+// copyFromSource is the value of the x-ms-file-* headers of a copy that take the property from the source file.
+const copyFromSource = "source"
+
+// StartCopyOptions defines options available when calling StartCopy.
+// By default the destination file gets the SMB properties a new file would: no attributes, creation and last write
+// times of now, and the security descriptor of its parent directory.
+type StartCopyOptions struct {
+	// SMBProperties are the SMB properties to set on the destination file. FilePermission and FilePermissionKey are
+	// only used with PermissionCopyModeOverride.
+	SMBProperties SMBProperties
+
+	// PermissionCopyMode, if PermissionCopyModeSource, copies the source file's security descriptor to the destination.
+	// PermissionCopyModeOverride sets the one in SMBProperties instead.
+	PermissionCopyMode PermissionCopyModeType
+
+	// CopyFileAttributes, CopyCreationTime and CopyLastWriteTime, if true, copy the source file's attributes, creation
+	// time and last write time to the destination, in place of those in SMBProperties.
+	CopyFileAttributes, CopyCreationTime, CopyLastWriteTime bool
+
+	// IgnoreReadOnly, if true, overwrites an existing destination file even if it has the ReadOnly attribute.
+	IgnoreReadOnly bool
+
+	// CopySourceAuthorization, if not "", is an Azure AD (OAuth) access token with which the service reads the source,
+	// independently of the destination's credential. The source must then be an https URL. Sending it requires
+	// service version 2022-11-02 or later, which the request then always sends.
+	CopySourceAuthorization string
+
+	// LeaseAccessConditions must identify the destination file's lease while it is leased.
+	LeaseAccessConditions
 }
 
-// StartCopy copies the data at the source URL to a file.
+// StartCopy starts copying the data at the source URL, a file or a blob, to a file. The copy happens asynchronously
+// on the service: the response's CopyID identifies it, and its CopyStatus is CopyStatusPending until it finishes.
+// Poll GetProperties, whose CopyStatus, CopyProgress and CopyStatusDescription report on the copy, to wait for it,
+// or pass the CopyID to AbortCopy to stop it.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/copy-file.
-func (f FileURL) StartCopy(ctx context.Context, source url.URL, metadata Metadata) (*FileStartCopyResponse, error) {
-	return f.fileClient.StartCopy(ctx, source.String(), nil, metadata)
+func (f FileURL) StartCopy(ctx context.Context, source url.URL, metadata Metadata, o StartCopyOptions) (*FileStartCopyResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	copySourceAuthorization, err := toCopySourceAuthorization(source, o.CopySourceAuthorization)
+	if err != nil {
+		return nil, err
+	}
+	p := o.SMBProperties
+	hasPermission := p.FilePermission != nil || p.FilePermissionKey != nil
+	if o.PermissionCopyMode == PermissionCopyModeOverride && !hasPermission {
+		return nil, errors.New("invalid argument, PermissionCopyModeOverride requires FilePermission or FilePermissionKey")
+	}
+	if o.PermissionCopyMode != PermissionCopyModeOverride && hasPermission {
+		return nil, errors.New("invalid argument, FilePermission and FilePermissionKey require PermissionCopyModeOverride")
+	}
+	if p, err = p.withPermissionKey(ctx, f.URL(), f.fileClient.Pipeline()); err != nil {
+		return nil, err
+	}
+
+	attributes, creationTime, lastWriteTime := p.timePointers()
+	fromSource := copyFromSource
+	if o.CopyFileAttributes {
+		attributes = &fromSource
+	}
+	if o.CopyCreationTime {
+		creationTime = &fromSource
+	}
+	if o.CopyLastWriteTime {
+		lastWriteTime = &fromSource
+	}
+	var ignoreReadOnly *bool
+	if o.IgnoreReadOnly {
+		ignoreReadOnly = &o.IgnoreReadOnly
+	}
+	return f.fileClient.StartCopy(ctx, source.String(), nil, metadata, p.FilePermission, p.FilePermissionKey,
+		o.PermissionCopyMode, ignoreReadOnly, attributes, creationTime, lastWriteTime,
+		o.LeaseAccessConditions.pointers(), copySourceAuthorization)
+}
+
+// toCopySourceAuthorization returns the x-ms-copy-source-authorization header value for an OAuth token, or nil if
+// token is "". The token is a bearer credential, so it may only be sent for a source that is read over https.
+func toCopySourceAuthorization(source url.URL, token string) (*string, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if !strings.EqualFold(source.Scheme, "https") {
+		return nil, errors.New("invalid argument, CopySourceAuthorization requires an https source URL")
+	}
+	bearer := "Bearer " + token
+	return &bearer, nil
 }
 
 // AbortCopy stops a pending copy that was previously started and leaves a destination file with 0 length and metadata.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/abort-copy-file.
-func (f FileURL) AbortCopy(ctx context.Context, copyID string) (*FileAbortCopyResponse, error) {
-	return f.fileClient.AbortCopy(ctx, copyID, nil)
+func (f FileURL) AbortCopy(ctx context.Context, copyID string, ac LeaseAccessConditions) (*FileAbortCopyResponse, error) {
+	if copyID == "" {
+		return nil, errors.New("invalid argument, copyID must not be empty")
+	}
+	return f.fileClient.AbortCopy(ctx, copyID, nil, ac.pointers())
 }
 
 // Download downloads count bytes of data from the start offset.
 // The response includes all of the file’s properties. However, passing true for rangeGetContentMD5 returns the range’s MD5 in the ContentMD5
-// response header/property if the range is <= 4MB; the HTTP request fails with 400 (Bad Request) if the requested range is greater than 4MB.
+// response header/property; the range must then be at most FileMaxRangeGetContentMD5Bytes (4MB), or the service
+// fails the request with 400 (Bad Request). Read the body with RetryReaderOptions.ValidateContentMD5 to check it.
 // Note: offset must be >=0, count must be >= 0.
 // If count is CountToEnd (0), then data is read from specified offset to the end.
 // rangeGetContentMD5 only works with partial data downloading.
@@ -89,9 +231,12 @@ func (f FileURL) Download(ctx context.Context, offset int64, count int64, rangeG
 		if offset == 0 && count == CountToEnd {
 			return nil, errors.New("invalid argument, rangeGetContentMD5 only works with partial data downloading")
 		}
+		if count > FileMaxRangeGetContentMD5Bytes {
+			return nil, fmt.Errorf("invalid argument, rangeGetContentMD5 requires count <= %d", FileMaxRangeGetContentMD5Bytes)
+		}
 		xRangeGetContentMD5 = &rangeGetContentMD5
 	}
-	dr, err := f.fileClient.Download(ctx, nil, httpRange{offset: offset, count: count}.pointers(), xRangeGetContentMD5)
+	dr, err := f.fileClient.Download(ctx, nil, httpRange{offset: offset, count: count}.pointers(), xRangeGetContentMD5, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -100,13 +245,33 @@ func (f FileURL) Download(ctx context.Context, offset int64, count int64, rangeG
 		f:    f,
 		dr:   dr,
 		ctx:  ctx,
-		info: HTTPGetterInfo{Offset: offset, Count: count, ETag: dr.ETag()}, // TODO: Note conditional header is not currently supported in Azure File.
+		info: HTTPGetterInfo{Offset: offset, Count: count, ETag: dr.ETag()},
 	}, err
 }
 
 // Body constructs a stream to read data from with a resilient reader option.
 // A zero-value option means to get a raw stream.
+// When a read fails, the stream re-issues a ranged download starting at the last successfully read offset, up to
+// o.MaxRetryRequests times. The File service does not evaluate If-Match on Get File, so each re-issued download's
+// ETag is compared with the original response's ETag instead; if the file was modified in the meantime, reading
+// fails with an error ending in FileModifiedDuringReadMessage rather than returning data from two versions.
+// With o.ValidateContentMD5, reading the end of the stream fails with an *IntegrityError if the data read doesn't
+// match the response's ContentMD5.
 func (dr *DownloadResponse) Body(o RetryReaderOptions) io.ReadCloser {
+	body := dr.body(o)
+	if o.ValidateContentMD5 {
+		if expected := dr.ContentMD5(); expected != nil {
+			body = &md5ValidatingReader{body: body, hash: md5.New(), expected: expected,
+				offset: dr.info.Offset, count: dr.ContentLength()}
+		}
+	}
+	if o.Progress != nil {
+		body = pipeline.NewResponseBodyProgress(body, o.Progress)
+	}
+	return body
+}
+
+func (dr *DownloadResponse) body(o RetryReaderOptions) io.ReadCloser {
 	if o.MaxRetryRequests == 0 {
 		return dr.Response().Body
 	}
@@ -121,57 +286,913 @@ func (dr *DownloadResponse) Body(o RetryReaderOptions) io.ReadCloser {
 			if err != nil {
 				return nil, err
 			}
+			if info.ETag != ETagNone && resp.ETag() != info.ETag {
+				resp.Response().Body.Close()
+				return nil, fmt.Errorf("expected ETag %s but got %s: %s", info.ETag, resp.ETag(), FileModifiedDuringReadMessage)
+			}
 			return resp.Response(), err
 		})
 }
 
+// IntegrityError is returned when downloaded data doesn't match the MD5 the service returned for it, and by
+// DownloadAndVerify when the service returns no MD5 for a range.
+type IntegrityError struct {
+	// Offset and Count identify the range of the file whose data is corrupt.
+	Offset, Count int64
+
+	// ExpectedMD5 is the MD5 the service returned, or nil if it returned none, and ActualMD5 the MD5 of the data read.
+	ExpectedMD5, ActualMD5 []byte
+}
+
+// Error implements the error interface.
+func (e *IntegrityError) Error() string {
+	if e.ExpectedMD5 == nil {
+		return fmt.Sprintf("no MD5 was returned for bytes %d-%d", e.Offset, e.Offset+e.Count-1)
+	}
+	return fmt.Sprintf("MD5 mismatch for bytes %d-%d: expected %s but got %s", e.Offset, e.Offset+e.Count-1,
+		base64.StdEncoding.EncodeToString(e.ExpectedMD5), base64.StdEncoding.EncodeToString(e.ActualMD5))
+}
+
+// md5ValidatingReader hashes the data read from body, and compares the hash with expected once body is read to the end.
+type md5ValidatingReader struct {
+	body          io.ReadCloser
+	hash          hash.Hash
+	expected      []byte
+	offset, count int64
+}
+
+func (r *md5ValidatingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := r.hash.Sum(nil); !bytes.Equal(actual, r.expected) {
+			return n, &IntegrityError{Offset: r.offset, Count: r.count, ExpectedMD5: r.expected, ActualMD5: actual}
+		}
+	}
+	return n, err
+}
+
+func (r *md5ValidatingReader) Close() error {
+	return r.body.Close()
+}
+
+// FileContentRange is the range of a file's content that a download returned.
+type FileContentRange struct {
+	// Start and End are the offsets of the first and last bytes of the range; End is Start-1 for an empty range.
+	Start, End int64
+
+	// Size is the size of the whole file.
+	Size int64
+}
+
+// Count returns the number of bytes in the range.
+func (r FileContentRange) Count() int64 {
+	return r.End - r.Start + 1
+}
+
+// NewContentRange returns the range of the file that the response holds, parsed from its Content-Range header,
+// e.g. "bytes 1024-2047/4096". A requested range that extends past the end of the file is clamped to it, so the
+// range can be shorter than the one requested. A download of the whole file returns no Content-Range; its range
+// is then the whole body, whose size is the file's.
+func (dr DownloadResponse) NewContentRange() (FileContentRange, error) {
+	h := dr.ContentRange()
+	if h == "" {
+		size := dr.ContentLength()
+		return FileContentRange{Start: 0, End: size - 1, Size: size}, nil
+	}
+	var r FileContentRange
+	var rest string
+	if n, _ := fmt.Sscanf(h, "bytes %d-%d/%d%s", &r.Start, &r.End, &r.Size, &rest); n != 3 ||
+		r.Start < 0 || r.End < r.Start || r.End >= r.Size {
+		return FileContentRange{}, fmt.Errorf("malformed Content-Range %q", h)
+	}
+	return r, nil
+}
+
+// NewRangeReader returns a reader of the response's body that keeps track of the file offset of the data it returns,
+// starting at the start of the range the service returned. o is as for Body.
+func (dr *DownloadResponse) NewRangeReader(o RetryReaderOptions) (*RangeReader, error) {
+	r, err := dr.NewContentRange()
+	if err != nil {
+		return nil, err
+	}
+	return &RangeReader{body: dr.Body(o), r: r, offset: r.Start}, nil
+}
+
+// RangeReader reads the body of a download of a range of a file; see DownloadResponse.NewRangeReader.
+type RangeReader struct {
+	body   io.ReadCloser
+	r      FileContentRange
+	offset int64
+}
+
+// Range returns the range of the file that the download returned.
+func (r *RangeReader) Range() FileContentRange {
+	return r.r
+}
+
+// Offset returns the offset in the file of the next byte Read returns.
+func (r *RangeReader) Offset() int64 {
+	return r.offset
+}
+
+func (r *RangeReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *RangeReader) Close() error {
+	return r.body.Close()
+}
+
+// ReaderAtOptions identifies options used by FileURL's NewReaderAt method.
+type ReaderAtOptions struct {
+	// MaxRetryRequestsPerRead specifies the maximum number of times the body of each ranged download is re-read after
+	// a failure. Failed requests themselves are retried by the pipeline's retry policy.
+	MaxRetryRequestsPerRead int
+
+	// Properties, if not nil, are the file's properties from an earlier GetProperties or download, whose length the
+	// reader uses in place of sending a GetProperties request when it's created.
+	Properties *FileGetPropertiesResponse
+}
+
+// NewReaderAt returns an io.ReaderAt that reads the file lazily, issuing one ranged Download per ReadAt call.
+// The file's length is read once with GetProperties when the reader is created, unless o.Properties is set, so the
+// reader doesn't observe later changes to the file's size. ReadAt is safe for concurrent use by multiple goroutines.
+// A read that reaches the end of the file returns the number of bytes read along with io.EOF.
+func (f FileURL) NewReaderAt(ctx context.Context, o ReaderAtOptions) (io.ReaderAt, error) {
+	p := o.Properties
+	if p == nil {
+		var err error
+		if p, err = f.GetProperties(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return &fileReaderAt{ctx: ctx, f: f, size: p.ContentLength(), o: o}, nil
+}
+
+// fileReaderAt implements io.ReaderAt on top of FileURL's Download; it holds no mutable state.
+type fileReaderAt struct {
+	ctx  context.Context
+	f    FileURL
+	size int64
+	o    ReaderAtOptions
+}
+
+func (r *fileReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("invalid argument, off must be >= 0")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	count := int64(len(p))
+	if remaining := r.size - off; count > remaining {
+		count = remaining
+	}
+	dr, err := r.f.Download(r.ctx, off, count, false)
+	if err != nil {
+		return 0, err
+	}
+	body := dr.Body(RetryReaderOptions{MaxRetryRequests: r.o.MaxRetryRequestsPerRead})
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:count])
+	if err == nil && count < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// DownloadToWriterOptions defines options available when calling DownloadToWriter.
+type DownloadToWriterOptions struct {
+	// MaxRetryRequests is the maximum number of times the download is re-issued, from the last byte written, after
+	// the connection fails mid-stream. Failed requests themselves are retried by the pipeline's retry policy.
+	MaxRetryRequests int
+
+	// Progress, if not nil, is invoked as the data is downloaded, with the number of bytes downloaded so far.
+	Progress pipeline.ProgressReceiver
+}
+
+// DownloadToWriter streams count bytes of the file, starting at offset, to w, without buffering them. If count is
+// CountToEnd (0), the file is written from offset to its end. It returns the number of bytes written to w.
+// A failed write stops the download at once, and returns the writer's error; the download's connection is closed
+// whether or not the download completes.
+func (f FileURL) DownloadToWriter(ctx context.Context, w io.Writer, offset int64, count int64, o DownloadToWriterOptions) (int64, error) {
+	if w == nil {
+		return 0, errors.New("invalid argument, w can't be nil")
+	}
+	if offset < 0 || count < 0 {
+		return 0, errors.New("invalid argument, offset and count must be >= 0")
+	}
+	dr, err := f.Download(ctx, offset, count, false)
+	if err != nil {
+		return 0, err
+	}
+	body := dr.Body(RetryReaderOptions{MaxRetryRequests: o.MaxRetryRequests, Progress: o.Progress})
+	defer body.Close()
+	return io.Copy(w, body)
+}
+
+// DownloadAndVerifyOptions defines options available when calling DownloadAndVerify.
+type DownloadAndVerifyOptions struct {
+	// MaxRetryRequestsPerRange specifies the maximum number of times the body of each range's download is re-read
+	// after a failure.
+	MaxRetryRequestsPerRange int
+}
+
+// DownloadAndVerify returns a reader of count bytes of the file, starting at offset, whose data is checked against
+// MD5s computed by the service. If count is CountToEnd (0), the file is read from offset to its end, as reported by
+// GetProperties; a count that runs past the end of the file also stops there. As the service only returns the MD5 of ranges of at most FileMaxRangeGetContentMD5Bytes (4MB), the
+// reader downloads the data one such range at a time, as it's read, with rangeGetContentMD5, and checks each range
+// once it's read to the end. A range whose data doesn't match its MD5, or for which no MD5 was returned, fails the
+// read with an *IntegrityError that identifies it. If the file changes between ranges, the read fails with an error
+// ending in FileModifiedDuringReadMessage. The first range is requested before DownloadAndVerify returns.
+// The caller must close the reader.
+func (f FileURL) DownloadAndVerify(ctx context.Context, offset int64, count int64, o DownloadAndVerifyOptions) (io.ReadCloser, error) {
+	if offset < 0 || count < 0 {
+		return nil, errors.New("invalid argument, offset and count must be >= 0")
+	}
+	r := &verifyingReader{ctx: ctx, f: f, o: o, offset: offset, end: offset + count, etag: ETagNone}
+	if count == CountToEnd {
+		p, err := f.GetProperties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if offset > p.ContentLength() {
+			return nil, fmt.Errorf("invalid argument, offset must be <= the Azure file's size: %d", p.ContentLength())
+		}
+		r.end, r.etag = p.ContentLength(), p.ETag()
+	}
+	if err := r.nextRange(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// verifyingReader reads the ranges of a DownloadAndVerify in order, through a body that validates each one's MD5.
+type verifyingReader struct {
+	ctx         context.Context
+	f           FileURL
+	o           DownloadAndVerifyOptions
+	offset, end int64 // The remaining data that hasn't been requested
+	etag        ETag
+	body        io.ReadCloser
+}
+
+// nextRange starts the download of the next range, if there's data that hasn't been requested.
+func (r *verifyingReader) nextRange() error {
+	if r.offset >= r.end {
+		return nil
+	}
+	count := r.end - r.offset
+	if count > FileMaxRangeGetContentMD5Bytes {
+		count = FileMaxRangeGetContentMD5Bytes
+	}
+	dr, err := r.f.Download(r.ctx, r.offset, count, true)
+	if err != nil {
+		return err
+	}
+	if r.etag == ETagNone {
+		r.etag = dr.ETag()
+	} else if dr.ETag() != r.etag {
+		dr.Response().Body.Close()
+		return fmt.Errorf("expected ETag %s but got %s: %s", r.etag, dr.ETag(), FileModifiedDuringReadMessage)
+	}
+	// A count that runs past the end of the file is clamped to it, so the reader stops there.
+	cr, err := dr.NewContentRange()
+	if err != nil {
+		dr.Response().Body.Close()
+		return err
+	}
+	if cr.Size < r.end {
+		r.end = cr.Size
+	}
+	if dr.ContentMD5() == nil {
+		dr.Response().Body.Close()
+		return &IntegrityError{Offset: r.offset, Count: cr.Count()}
+	}
+	r.body = dr.Body(RetryReaderOptions{MaxRetryRequests: r.o.MaxRetryRequestsPerRange, ValidateContentMD5: true})
+	r.offset += cr.Count()
+	return nil
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	for r.body != nil {
+		n, err := r.body.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		r.body.Close()
+		r.body = nil
+		if err := r.nextRange(); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, io.EOF
+}
+
+func (r *verifyingReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// RenameOptions defines options available when calling FileURL.Rename and DirectoryURL.Rename.
+// By default the destination keeps the source's SMB properties and metadata.
+type RenameOptions struct {
+	// ReplaceIfExists, if true, replaces an existing file at the destination. An existing directory is never replaced.
+	ReplaceIfExists bool
+
+	// IgnoreReadOnly, if true, replaces an existing destination file even if it has the ReadOnly attribute.
+	// It requires ReplaceIfExists.
+	IgnoreReadOnly bool
+
+	// SMBProperties are the SMB properties to set on the destination. Nil fields keep the source's values.
+	SMBProperties SMBProperties
+
+	// Metadata, if not nil, replaces the source's metadata on the destination.
+	Metadata Metadata
+
+	// SourceLeaseAccessConditions must identify the source file's lease while it is leased.
+	SourceLeaseAccessConditions LeaseAccessConditions
+
+	// DestinationLeaseAccessConditions must identify the lease of a leased file that ReplaceIfExists replaces.
+	DestinationLeaseAccessConditions LeaseAccessConditions
+}
+
+// renameDestination returns the URL of destinationPath, a path from the root of source's share or a URL in the same
+// share. A destination without a SAS takes source's.
+func renameDestination(source url.URL, destinationPath string) (url.URL, error) {
+	parts := NewFileURLParts(source)
+	if parts.ShareSnapshot != "" {
+		return url.URL{}, errors.New("invalid argument, a share snapshot's files and directories can't be renamed")
+	}
+	if strings.Contains(destinationPath, "://") {
+		u, err := url.Parse(destinationPath)
+		if err != nil {
+			return url.URL{}, fmt.Errorf("invalid argument, destinationPath is not a valid URL: %v", err)
+		}
+		destParts := NewFileURLParts(*u)
+		if !strings.EqualFold(destParts.Host, parts.Host) || destParts.IPEndpointStyleInfo != parts.IPEndpointStyleInfo ||
+			destParts.ShareName != parts.ShareName || destParts.ShareSnapshot != "" {
+			return url.URL{}, errors.New("invalid argument, destinationPath must be in the source's share; " +
+				"renaming across shares isn't supported")
+		}
+		if destParts.SAS.Signature() != "" {
+			parts.SAS = destParts.SAS
+		}
+		destinationPath = destParts.DirectoryOrFilePath
+	}
+	parts.DirectoryOrFilePath = strings.Trim(destinationPath, "/")
+	if parts.DirectoryOrFilePath == "" {
+		return url.URL{}, errors.New("invalid argument, destinationPath must name a file or directory")
+	}
+	return parts.URL(), nil
+}
+
+// Rename renames the file, on the service, to destinationPath: a path from the root of the file's share, such as
+// "dir/file", or the URL of a file in the same share. It returns a FileURL for the destination, with the file's
+// pipeline. Renaming to another share returns an error without sending a request.
+// Rename uses service version 2021-04-10, which the SDK otherwise doesn't require.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/rename-file.
+func (f FileURL) Rename(ctx context.Context, destinationPath string, o RenameOptions) (FileURL, *FileRenameResponse, error) {
+	replaceIfExists, ignoreReadOnly, err := o.renamePointers()
+	if err != nil {
+		return FileURL{}, nil, err
+	}
+	dest, err := renameDestination(f.URL(), destinationPath)
+	if err != nil {
+		return FileURL{}, nil, err
+	}
+	destURL := NewFileURL(dest, f.fileClient.Pipeline())
+	p, err := o.SMBProperties.withPermissionKey(ctx, dest, f.fileClient.Pipeline())
+	if err != nil {
+		return FileURL{}, nil, err
+	}
+	attributes, creationTime, lastWriteTime := p.timePointers()
+	resp, err := destURL.fileClient.Rename(ctx, f.String(), nil, replaceIfExists, ignoreReadOnly,
+		o.SourceLeaseAccessConditions.pointers(), o.DestinationLeaseAccessConditions.pointers(),
+		attributes, creationTime, lastWriteTime, p.changeTimePointer(), p.FilePermission, p.FilePermissionKey, o.Metadata)
+	if err != nil {
+		return FileURL{}, resp, err
+	}
+	return destURL, resp, nil
+}
+
+// renamePointers validates o, and returns the x-ms-file-rename-* header values, which are nil for false fields.
+func (o RenameOptions) renamePointers() (replaceIfExists, ignoreReadOnly *bool, err error) {
+	if o.IgnoreReadOnly && !o.ReplaceIfExists {
+		return nil, nil, errors.New("invalid argument, IgnoreReadOnly requires ReplaceIfExists")
+	}
+	if err = o.Metadata.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if o.ReplaceIfExists {
+		replaceIfExists = &o.ReplaceIfExists
+	}
+	if o.IgnoreReadOnly {
+		ignoreReadOnly = &o.IgnoreReadOnly
+	}
+	return
+}
+
+// Undelete restores the soft-deleted file, which listings with ListFilesAndDirectoriesDetail.Deleted return with
+// Deleted set. The File service only offers soft delete for whole shares so far (see ShareURL.Restore); until accounts
+// have it for files, Undelete fails with the service's error, a StorageError whose ServiceCode is usually
+// ServiceCodeInvalidQueryParameterValue or ServiceCodeUnsupportedQueryParameter. The request is sent with comp=undelete
+// as well as the x-ms-undelete header, so that a service without the feature can't take it for a Create. To detect
+// the feature, call Undelete on a path that doesn't exist: a 404 (ServiceCodeResourceNotFound) means the service
+// understood the request, a 400 that it doesn't support it.
+func (f FileURL) Undelete(ctx context.Context) (*FileUndeleteResponse, error) {
+	return f.fileClient.Undelete(ctx, nil)
+}
+
 // Delete immediately removes the file from the storage account.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/delete-file2.
-func (f FileURL) Delete(ctx context.Context) (*FileDeleteResponse, error) {
-	return f.fileClient.Delete(ctx, nil)
+func (f FileURL) Delete(ctx context.Context, ac FileAccessConditions) (*FileDeleteResponse, error) {
+	if err := f.checkAccessConditions(ctx, ac.ModifiedAccessConditions, false); err != nil {
+		return nil, err
+	}
+	return f.fileClient.Delete(ctx, nil, ac.LeaseAccessConditions.pointers())
 }
 
 // GetProperties returns the file's metadata and properties.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/get-file-properties.
 func (f FileURL) GetProperties(ctx context.Context) (*FileGetPropertiesResponse, error) {
-	return f.fileClient.GetProperties(ctx, nil, nil)
+	return f.fileClient.GetProperties(ctx, nil, nil, nil)
+}
+
+// Exists reports whether the file exists, with GetProperties. It returns false with a nil error if the service says
+// the file, or its directory or share, doesn't exist; any other failure, such as a 403 (Forbidden) for a request
+// that isn't authorized, is returned as an error.
+func (f FileURL) Exists(ctx context.Context) (bool, error) {
+	_, err := f.GetProperties(ctx)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetHTTPHeaders sets file's system properties. The file's SMB properties are kept; h.SMBProperties is ignored.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/set-file-properties.
+func (f FileURL) SetHTTPHeaders(ctx context.Context, h FileHTTPHeaders, ac FileAccessConditions) (*FileSetHTTPHeadersResponse, error) {
+	h.SMBProperties = SMBProperties{}
+	return f.SetProperties(ctx, h, ac)
 }
 
-// SetHTTPHeaders sets file's system properties.
+// SetProperties sets file's system properties and SMB properties.
+// The SMB properties are taken from h.SMBProperties; nil fields keep their current values.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/set-file-properties.
-func (f FileURL) SetHTTPHeaders(ctx context.Context, h FileHTTPHeaders) (*FileSetHTTPHeadersResponse, error) {
-	return f.fileClient.SetHTTPHeaders(ctx, nil,
-		nil, &h.ContentType, &h.ContentEncoding, &h.ContentLanguage, &h.CacheControl, h.ContentMD5, &h.ContentDisposition)
+func (f FileURL) SetProperties(ctx context.Context, h FileHTTPHeaders, ac FileAccessConditions) (*FileSetHTTPHeadersResponse, error) {
+	if err := f.checkAccessConditions(ctx, ac.ModifiedAccessConditions, false); err != nil {
+		return nil, err
+	}
+	properties, err := h.SMBProperties.withPermissionKey(ctx, f.URL(), f.fileClient.Pipeline())
+	if err != nil {
+		return nil, err
+	}
+	attributes, creationTime, lastWriteTime, permission, permissionKey := properties.pointers(
+		defaultPreserveValue, defaultPreserveValue, defaultPreserveValue)
+	return f.fileClient.SetHTTPHeaders(ctx, attributes, creationTime, lastWriteTime, properties.changeTimePointer(), nil,
+		nil, &h.ContentType, &h.ContentEncoding, &h.ContentLanguage, &h.CacheControl, h.ContentMD5, &h.ContentDisposition,
+		permission, permissionKey, ac.LeaseAccessConditions.pointers(), optionalString(h.EncryptionScope))
 }
 
-// SetMetadata sets a file's metadata.
+// SetMetadata sets a file's metadata, replacing all of its metadata; an empty or nil metadata clears it.
 // https://docs.microsoft.com/rest/api/storageservices/set-file-metadata.
-func (f FileURL) SetMetadata(ctx context.Context, metadata Metadata) (*FileSetMetadataResponse, error) {
-	return f.fileClient.SetMetadata(ctx, nil, metadata)
+func (f FileURL) SetMetadata(ctx context.Context, metadata Metadata, ac FileAccessConditions) (*FileSetMetadataResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	if err := f.checkAccessConditions(ctx, ac.ModifiedAccessConditions, false); err != nil {
+		return nil, err
+	}
+	return f.fileClient.SetMetadata(ctx, nil, metadata, ac.LeaseAccessConditions.pointers())
 }
 
-// Resize resizes the file to the specified size.
+// GetMetadata returns the file's metadata, from GetProperties. Its keys are lowercase; look them up with
+// Metadata.Get, which ignores case.
+func (f FileURL) GetMetadata(ctx context.Context) (Metadata, error) {
+	props, err := f.GetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return props.NewMetadata(), nil
+}
+
+// ResizeOptions defines options available when calling Resize.
+type ResizeOptions struct {
+	// FileAccessConditions must identify the file's lease while it is leased, and can make the resize conditional.
+	FileAccessConditions
+}
+
+// Resize resizes the file to length bytes. It sends only the new length, so the file's HTTP headers, metadata and
+// SMB properties are preserved. Shrinking the file clears its ranges beyond length, and growing it adds zeros.
+// The response's ETag is the resized file's.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/set-file-properties.
-func (f FileURL) Resize(ctx context.Context, length int64) (*FileSetHTTPHeadersResponse, error) {
-	return f.fileClient.SetHTTPHeaders(ctx, nil,
-		&length, nil, nil, nil, nil, nil, nil)
+func (f FileURL) Resize(ctx context.Context, length int64, o ResizeOptions) (*FileSetHTTPHeadersResponse, error) {
+	if err := f.checkAccessConditions(ctx, o.ModifiedAccessConditions, false); err != nil {
+		return nil, err
+	}
+	permission := defaultPreserveValue
+	return f.fileClient.SetHTTPHeaders(ctx, defaultPreserveValue, defaultPreserveValue, defaultPreserveValue, nil, nil,
+		&length, nil, nil, nil, nil, nil, nil, &permission, nil, o.LeaseAccessConditions.pointers(), nil)
+}
+
+// UploadRangeOptions defines options available when calling UploadRange.
+type UploadRangeOptions struct {
+	// FileAccessConditions must identify the file's lease while it is leased, and can make the upload conditional.
+	FileAccessConditions
+
+	// Progress, if not nil, is invoked as body's bytes are sent, with the number of bytes sent so far. Bytes that
+	// are sent again when the request is retried aren't counted twice.
+	Progress pipeline.ProgressReceiver
+
+	// MaxRangeSize, if not 0, is the largest body UploadRange sends, in place of FileMaxUploadRangeBytes. Set it to
+	// upload larger ranges with a service version that accepts them.
+	MaxRangeSize int64
+
+	// CheckSize, if true, makes UploadRange get the file's properties first, and fail without sending body if the
+	// range ends beyond the file's size, which the service would reject with ServiceCodeInvalidRange.
+	CheckSize bool
+
+	// EncryptionScope, if not "", is the name of the account's encryption scope with which the service encrypts the
+	// range. A scope the account doesn't have fails the request.
+	EncryptionScope string
+
+	// GrowFile, if true, makes UploadRange get the file's properties first, like CheckSize, and resize the file to
+	// end where the range ends if it's shorter. The size is read again, and the file resized, under a lease, so
+	// that the resize never shrinks a file that another writer grew in the meantime: the lease of
+	// FileAccessConditions, if set, or else a FileInfiniteLeaseDuration lease UploadRange acquires and releases around
+	// the resize, which fails if another client holds a lease on the file. That lease stays on the file if the process
+	// ends before releasing it, until BreakLease is called.
+	GrowFile bool
 }
 
-// UploadRange writes bytes to a file.
-// offset indiciates the offset at which to begin writing, in bytes.
+// UploadRange writes the bytes of body from its current position to its end to a file; body's length is found by
+// seeking to its end, and it's rewound to where its bytes start for each retry.
+// offset indiciates the offset at which to begin writing, in bytes. body's length must not exceed
+// FileMaxUploadRangeBytes, or o.MaxRangeSize if it's set; a longer body fails without a request being sent.
+// transactionalMD5, if not nil, is the MD5 of body's data; the service fails the request with
+// ServiceCodeMd5Mismatch rather than write data that doesn't match it.
+// The file must already be at least offset plus body's length bytes long. Set o.CheckSize to check that before
+// uploading, or o.GrowFile to resize a file that's too short.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/put-range.
-func (f FileURL) UploadRange(ctx context.Context, offset int64, body io.ReadSeeker, transactionalMD5 []byte) (*FileUploadRangeResponse, error) {
+func (f FileURL) UploadRange(ctx context.Context, offset int64, body io.ReadSeeker, transactionalMD5 []byte, o UploadRangeOptions) (*FileUploadRangeResponse, error) {
 	if body == nil {
 		return nil, errors.New("invalid argument, body must not be nil")
 	}
 
-	count := validateSeekableStreamAt0AndGetCount(body)
+	body, count, err := seekableStreamSection(body)
+	if err != nil {
+		return nil, err
+	}
 	if count == 0 {
 		return nil, errors.New("invalid argument, body must contain readable data whose size is > 0")
 	}
+	maxRangeSize := int64(FileMaxUploadRangeBytes)
+	if o.MaxRangeSize != 0 {
+		maxRangeSize = o.MaxRangeSize
+	}
+	if count > maxRangeSize {
+		return nil, fmt.Errorf("invalid argument, body's %d bytes exceed the maximum range size of %d bytes; upload them in several ranges", count, maxRangeSize)
+	}
+	if err := f.checkAccessConditions(ctx, o.ModifiedAccessConditions, false); err != nil {
+		return nil, err
+	}
+	if o.CheckSize || o.GrowFile {
+		if err := f.fitRange(ctx, offset+count, o.GrowFile, o.LeaseAccessConditions); err != nil {
+			return nil, err
+		}
+	}
+	if o.Progress != nil {
+		body = pipeline.NewRequestBodyProgress(body, newMonotonicProgress(o.Progress))
+	}
+
+	return f.fileClient.UploadRange(ctx, *toRange(offset, count), FileRangeWriteUpdate, count, body, nil, transactionalMD5,
+		o.LeaseAccessConditions.pointers(), optionalString(o.EncryptionScope))
+}
+
+// fitRange returns an error if the file is shorter than end bytes, for UploadRange's CheckSize option, or, if grow is
+// true, resizes it to end bytes. Without lac's lease, the size is read again under a lease of fitRange's own before
+// the resize, since another writer may have grown the file since it was first read.
+func (f FileURL) fitRange(ctx context.Context, end int64, grow bool, lac LeaseAccessConditions) (err error) {
+	props, err := f.GetProperties(ctx)
+	if err != nil {
+		return err
+	}
+	if end <= props.ContentLength() {
+		return nil
+	}
+	if !grow {
+		return fmt.Errorf("invalid argument, the range ends at byte %d, beyond the end of the file's %d bytes; "+
+			"resize the file first, or set UploadRangeOptions.GrowFile", end, props.ContentLength())
+	}
+	if lac.LeaseID == "" {
+		lac.LeaseID = newUUID().String()
+		if _, err := f.AcquireLease(ctx, lac.LeaseID, FileInfiniteLeaseDuration); err != nil {
+			return err
+		}
+		defer func() {
+			// The lease never expires, so it's released even if ctx is done.
+			if _, releaseErr := f.ReleaseLease(context.Background(), lac.LeaseID); err == nil {
+				err = releaseErr
+			}
+		}()
+		if props, err = f.GetProperties(ctx); err != nil {
+			return err
+		}
+		if end <= props.ContentLength() {
+			return nil
+		}
+	}
+	_, err = f.Resize(ctx, end, ResizeOptions{FileAccessConditions{LeaseAccessConditions: lac}})
+	return err
+}
+
+// newMonotonicProgress returns a ProgressReceiver that passes on to pr only the totals that exceed those it already
+// passed on, so that a request body rewound for a retry doesn't make the progress go backwards.
+func newMonotonicProgress(pr pipeline.ProgressReceiver) pipeline.ProgressReceiver {
+	reported := int64(0)
+	return func(bytesTransferred int64) {
+		if bytesTransferred > reported {
+			reported = bytesTransferred
+			pr(bytesTransferred)
+		}
+	}
+}
+
+// AppendOptions defines options available when calling Append.
+type AppendOptions struct {
+	// IfMatch, if not ETagNone, is the ETag the file must have for the data to be appended, such as the ETag of the
+	// previous Append's result; otherwise nothing is written, and the error is one for which IsPreconditionFailed
+	// returns true. It detects the appends, and other writes, made since that ETag was read.
+	IfMatch ETag
 
-	// TransactionalContentMD5 isn't supported currently.
-	return f.fileClient.UploadRange(ctx, *toRange(offset, count), FileRangeWriteUpdate, count, body, nil, transactionalMD5)
+	// LeaseAccessConditions, if its LeaseID is set, is a lease the caller holds on the file, under which the data is
+	// appended. Otherwise Append acquires a lease of its own for the duration of the append.
+	LeaseAccessConditions
+
+	// EncryptionScope, if not "", is the name of the account's encryption scope with which the data is encrypted.
+	EncryptionScope string
+}
+
+// AppendResult is returned by Append.
+type AppendResult struct {
+	// Offset is where the data was appended, which is the size the file had before.
+	Offset int64
+
+	// ETag is the file's ETag once the data was written, to pass as the IfMatch of the next Append.
+	ETag ETag
+}
+
+// Append writes the bytes of data from its current position to its end after the end of the file. The File service
+// has no native append: Append composes it on the client, with optimistic concurrency. Under a lease, so that
+// concurrent appenders can't interleave, it reads the file's size with GetProperties, checks o.IfMatch against the
+// file's ETag, resizes the file to hold data, and uploads data as a single range, so data's length must not exceed
+// FileMaxUploadRangeBytes. A concurrent append fails Append without writing anything, with an error for which
+// IsConflict returns true if the other append, or another client, holds a lease on the file, or IsPreconditionFailed
+// returns true if o.IfMatch is set and another append already landed; retry it, with a fresh IfMatch if one is set.
+// If the range's upload fails, the file is resized back. If only the release of Append's own lease fails, the data
+// was appended: the result is returned with the error. Append's own lease is a FileInfiniteLeaseDuration lease: if
+// the process ends during the append, the lease stays on the file, and the file may be left grown with zeros where
+// the data should be, until BreakLease is called.
+func (f FileURL) Append(ctx context.Context, data io.ReadSeeker, o AppendOptions) (result *AppendResult, err error) {
+	if data == nil {
+		return nil, errors.New("invalid argument, data must not be nil")
+	}
+	data, count, err := seekableStreamSection(data)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("invalid argument, data must contain readable data whose size is > 0")
+	}
+	if count > FileMaxUploadRangeBytes {
+		return nil, fmt.Errorf("invalid argument, data's %d bytes exceed the maximum range size of %d bytes", count, FileMaxUploadRangeBytes)
+	}
+
+	lease := o.LeaseAccessConditions
+	if lease.LeaseID == "" {
+		lease.LeaseID = newUUID().String()
+		if _, err := f.AcquireLease(ctx, lease.LeaseID, FileInfiniteLeaseDuration); err != nil {
+			return nil, err
+		}
+		defer func() {
+			// The lease never expires, so it's released even if ctx is done.
+			if _, releaseErr := f.ReleaseLease(context.Background(), lease.LeaseID); err == nil {
+				err = releaseErr
+			}
+		}()
+	}
+
+	props, err := f.GetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !(ModifiedAccessConditions{IfMatch: o.IfMatch}).met(props.ETag(), props.LastModified()) {
+		return nil, newConditionNotMetError(props.Response())
+	}
+	size := props.ContentLength()
+	ac := FileAccessConditions{LeaseAccessConditions: lease}
+	if _, err := f.Resize(ctx, size+count, ResizeOptions{FileAccessConditions: ac}); err != nil {
+		return nil, err
+	}
+	resp, err := f.UploadRange(ctx, size, data, nil, UploadRangeOptions{FileAccessConditions: ac, EncryptionScope: o.EncryptionScope})
+	if err != nil {
+		f.Resize(context.Background(), size, ResizeOptions{FileAccessConditions: ac}) // The caller's context may already be done
+		return nil, err
+	}
+	return &AppendResult{Offset: size, ETag: resp.ETag()}, nil
+}
+
+// RangeWriterOptions defines options available when calling NewRangeWriter.
+type RangeWriterOptions struct {
+	// RangeSize is the size of the buffer that writes are accumulated in, and so of each range uploaded but the
+	// last; the default is FileMaxUploadRangeBytes, and larger sizes are clamped to it.
+	RangeSize int64
+
+	// EncryptionScope, if not "", is the name of the account's encryption scope with which the ranges are encrypted.
+	EncryptionScope string
+
+	// LeaseAccessConditions must identify the file's lease while it is leased.
+	LeaseAccessConditions
+}
+
+// NewRangeWriter returns an io.WriteCloser that writes to the file from startOffset on, for producers that can't
+// seek, such as the reading end of an io.Pipe. Writes are accumulated in a buffer of o.RangeSize bytes, which is
+// uploaded with UploadRange each time it fills; the file is grown first when the range would end beyond it. Close
+// uploads what remains in the buffer and, if the writer grew the file, sets its size to end where the writes end.
+// The file must exist; its size is read once with GetProperties when the writer is created.
+// If an upload, or the growing of the file for it, fails, Write or Close returns a *RangeUploadError and keeps the
+// range's bytes, which count as written, so that the next call to Write or Close retries the upload before anything
+// else. The writer isn't safe for concurrent use.
+func (f FileURL) NewRangeWriter(ctx context.Context, startOffset int64, o RangeWriterOptions) (io.WriteCloser, error) {
+	if startOffset < 0 {
+		return nil, errors.New("invalid argument, startOffset must be >= 0")
+	}
+	if o.RangeSize < 0 {
+		return nil, errors.New("invalid argument, o.RangeSize must be >= 0")
+	}
+	if o.RangeSize == 0 || o.RangeSize > FileMaxUploadRangeBytes {
+		o.RangeSize = FileMaxUploadRangeBytes
+	}
+	props, err := f.GetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeWriter{ctx: ctx, f: f, o: o, offset: startOffset, size: props.ContentLength(),
+		buf: make([]byte, 0, o.RangeSize)}, nil
+}
+
+// rangeWriter is the io.WriteCloser returned by NewRangeWriter. buf holds the bytes to upload at offset.
+type rangeWriter struct {
+	ctx    context.Context
+	f      FileURL
+	o      RangeWriterOptions
+	offset int64
+	size   int64 // The file's size, as last read or set
+	grown  bool  // Whether the writer resized the file
+	buf    []byte
+	closed bool
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("the range writer is closed")
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(w.buf) == cap(w.buf) {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	if len(w.buf) == cap(w.buf) {
+		return n, w.flush()
+	}
+	return n, nil
+}
+
+// flush uploads the buffered bytes at w.offset, growing the file first if it's too short.
+func (w *rangeWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	end := w.offset + int64(len(w.buf))
+	if end > FileMaxSizeInBytes {
+		return fmt.Errorf("the writes end beyond the maximum file size of %d bytes", FileMaxSizeInBytes)
+	}
+	if end > w.size {
+		// Grow geometrically to avoid a resize per range; Close sets the final size.
+		newSize := 2 * w.size
+		if newSize < end {
+			newSize = end
+		}
+		if newSize > FileMaxSizeInBytes {
+			newSize = FileMaxSizeInBytes
+		}
+		if _, err := w.f.Resize(w.ctx, newSize, ResizeOptions{FileAccessConditions{LeaseAccessConditions: w.o.LeaseAccessConditions}}); err != nil {
+			return &RangeUploadError{Offset: w.offset, Count: int64(len(w.buf)), Err: err}
+		}
+		w.size, w.grown = newSize, true
+	}
+	_, err := w.f.UploadRange(w.ctx, w.offset, bytes.NewReader(w.buf), nil, UploadRangeOptions{
+		FileAccessConditions: FileAccessConditions{LeaseAccessConditions: w.o.LeaseAccessConditions}, EncryptionScope: w.o.EncryptionScope})
+	if err != nil {
+		return &RangeUploadError{Offset: w.offset, Count: int64(len(w.buf)), Err: err}
+	}
+	w.offset, w.buf = end, w.buf[:0]
+	return nil
+}
+
+// Close uploads the buffered bytes and trims the file to where the writes end, if the writer grew it. A Close that
+// fails can be called again; once one succeeds, later ones do nothing.
+func (w *rangeWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.grown && w.size != w.offset {
+		if _, err := w.f.Resize(w.ctx, w.offset, ResizeOptions{FileAccessConditions{LeaseAccessConditions: w.o.LeaseAccessConditions}}); err != nil {
+			return err
+		}
+		w.size = w.offset
+	}
+	w.closed = true
+	return nil
+}
+
+// UploadRangeFromURLOptions defines options available when calling UploadRangeFromURL.
+// The File service doesn't accept an MD5 of the source range; use the CRC64 conditions to validate it instead.
+type UploadRangeFromURLOptions struct {
+	// SourceContentCRC64, if not nil, is the CRC64 of the source range. The service fails the request with
+	// ServiceCodeCrc64Mismatch rather than write data that doesn't match it. The service's CRC64 is that of
+	// hash/crc64 with the polynomial 0x9A6C9329AC4BC9B5, in little-endian byte order.
+	SourceContentCRC64 []byte
+
+	// SourceIfMatchCRC64, if not nil, makes the copy succeed only if the CRC64 of the source range matches it.
+	SourceIfMatchCRC64 []byte
+
+	// SourceIfNoneMatchCRC64, if not nil, makes the copy succeed only if the CRC64 of the source range doesn't match it.
+	SourceIfNoneMatchCRC64 []byte
+
+	// CopySourceAuthorization, if not "", is an Azure AD (OAuth) access token with which the service reads the source,
+	// independently of the destination's credential. The source must then be an https URL. Sending it requires
+	// service version 2022-11-02 or later, which the request then always sends.
+	CopySourceAuthorization string
+
+	// LeaseAccessConditions must identify the destination file's lease while it is leased.
+	LeaseAccessConditions
+}
+
+// UploadRangeFromURL writes count bytes to the file at destOffset, which the service reads from sourceURL starting at
+// sourceOffset, without the data passing through the client. count must be > 0 and <= FileMaxUploadRangeBytes.
+// sourceURL may be a file or a blob; unless it is public, or a file in the same account authorized by the
+// destination's Shared Key credential, it must carry a SAS or o.CopySourceAuthorization must be set.
+// The response's ETag and LastModified are those of the destination file after the write, and its XMsContentCrc64
+// is the CRC64 of the data written.
+// For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/put-range-from-url.
+func (f FileURL) UploadRangeFromURL(ctx context.Context, sourceURL url.URL, sourceOffset int64, destOffset int64, count int64,
+	o UploadRangeFromURLOptions) (*FileUploadRangeFromURLResponse, error) {
+	if sourceOffset < 0 || destOffset < 0 {
+		return nil, errors.New("invalid argument, sourceOffset and destOffset must be >= 0")
+	}
+	if count <= 0 || count > FileMaxUploadRangeBytes {
+		return nil, fmt.Errorf("invalid argument, count must be > 0 and <= %d", FileMaxUploadRangeBytes)
+	}
+	copySourceAuthorization, err := toCopySourceAuthorization(sourceURL, o.CopySourceAuthorization)
+	if err != nil {
+		return nil, err
+	}
+	return f.fileClient.UploadRangeFromURL(ctx, *toRange(destOffset, count), sourceURL.String(), 0, nil,
+		toRange(sourceOffset, count), o.SourceContentCRC64, o.SourceIfMatchCRC64, o.SourceIfNoneMatchCRC64,
+		o.LeaseAccessConditions.pointers(), copySourceAuthorization)
+}
+
+// ClearRangeOptions defines options available when calling ClearRange.
+type ClearRangeOptions struct {
+	// LeaseAccessConditions must identify the file's lease while it is leased.
+	LeaseAccessConditions
 }
 
 // ClearRange clears the specified range and releases the space used in storage for that range.
@@ -179,18 +1200,126 @@ func (f FileURL) UploadRange(ctx context.Context, offset int64, body io.ReadSeek
 // count means count of bytes to clean, it cannot be CountToEnd (0), and must be explictly specified.
 // If the range specified is not 512-byte aligned, the operation will write zeros to
 // the start or end of the range that is not 512-byte aligned and free the rest of the range inside that is 512-byte aligned.
+// The cleared range reads as zeros and is no longer returned by GetRangeList. The range must be within the file;
+// the service fails the request with ServiceCodeInvalidRange otherwise. The response's ETag and LastModified are
+// those of the file after the range is cleared.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/put-range.
-func (f FileURL) ClearRange(ctx context.Context, offset int64, count int64) (*FileUploadRangeResponse, error) {
+func (f FileURL) ClearRange(ctx context.Context, offset int64, count int64, o ClearRangeOptions) (*FileUploadRangeResponse, error) {
 	if count <= 0 {
 		return nil, errors.New("invalid argument, count cannot be CountToEnd, and must be > 0")
 	}
 
-	return f.fileClient.UploadRange(ctx, *toRange(offset, count), FileRangeWriteClear, 0, nil, nil, nil)
+	return f.fileClient.UploadRange(ctx, *toRange(offset, count), FileRangeWriteClear, 0, nil, nil, nil, o.LeaseAccessConditions.pointers(), nil)
+}
+
+// GetRangeListOptions defines options available when calling GetRangeList.
+type GetRangeListOptions struct {
+	// PrevShareSnapshot, if not "", is a snapshot of the file's share. Only the ranges that were written since that
+	// snapshot was taken are returned; GetRangeListDiff also returns those that were cleared.
+	PrevShareSnapshot string
 }
 
-// GetRangeList returns the list of valid ranges for a file.
-// Use a count with value CountToEnd (0) to indicate the left part of file start from offset.
+// GetRangeList returns the list of valid ranges for a file, that is, the ranges that contain data; the rest of the
+// file is unallocated and reads as zeros. Ranges are returned in ascending order, and Start and End are inclusive.
+// Pass an offset of 0 and a count of CountToEnd (0) to list the ranges of the whole file, or another count to list
+// those overlapping that part of the file. Like the file's other operations, the list is of the share snapshot the
+// FileURL targets, if any.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/list-ranges.
-func (f FileURL) GetRangeList(ctx context.Context, offset int64, count int64) (*Ranges, error) {
-	return f.fileClient.GetRangeList(ctx, nil, nil, httpRange{offset: offset, count: count}.pointers())
+func (f FileURL) GetRangeList(ctx context.Context, offset int64, count int64, o GetRangeListOptions) (*Ranges, error) {
+	var prevShareSnapshot *string
+	if o.PrevShareSnapshot != "" {
+		prevShareSnapshot = &o.PrevShareSnapshot
+	}
+	return f.fileClient.GetRangeList(ctx, nil, prevShareSnapshot, nil, httpRange{offset: offset, count: count}.pointers(), nil)
+}
+
+// GetRangeListDiff returns the ranges of the file that were written since prevShareSnapshot, a snapshot of its share,
+// was taken, in Ranges, and those that were cleared since then, such as with ClearRange, in ClearRanges. Both lists
+// are in ascending order, with inclusive Start and End, and the rest of the file is as it was in prevShareSnapshot. Applying the diff to a copy of the file as of prevShareSnapshot, by writing the written
+// ranges and zeroing the cleared ones, brings it up to date. offset and count scope the lists like GetRangeList's.
+// For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/list-ranges.
+func (f FileURL) GetRangeListDiff(ctx context.Context, prevShareSnapshot string, offset int64, count int64) (*RangeDiff, error) {
+	if prevShareSnapshot == "" {
+		return nil, errors.New("invalid argument, prevShareSnapshot must not be empty")
+	}
+	return f.fileClient.GetRangeListDiff(ctx, nil, prevShareSnapshot, nil, httpRange{offset: offset, count: count}.pointers(), nil)
+}
+
+// File leases are always infinite (see FileInfiniteLeaseDuration), so unlike blobs there is no RenewLease.
+// The File service doesn't evaluate conditional (If-*) headers on lease operations; to make a lease conditional on
+// the file's state, compare the ETag returned by GetProperties before acquiring.
+
+// AcquireLease acquires a lease on the file for write and delete operations. proposedID may be "" to let the service
+// pick the lease ID. duration must be FileInfiniteLeaseDuration (-1).
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-file.
+func (f FileURL) AcquireLease(ctx context.Context, proposedID string, duration int32) (*FileAcquireLeaseResponse, error) {
+	var proposedLeaseID *string
+	if proposedID != "" {
+		proposedLeaseID = &proposedID
+	}
+	return f.fileClient.AcquireLease(ctx, nil, &duration, proposedLeaseID, nil)
+}
+
+// ReleaseLease releases the file's previously-acquired lease so that another client may immediately acquire a lease
+// against the file.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-file.
+func (f FileURL) ReleaseLease(ctx context.Context, leaseID string) (*FileReleaseLeaseResponse, error) {
+	return f.fileClient.ReleaseLease(ctx, leaseID, nil, nil)
+}
+
+// ChangeLease changes the file's lease ID from leaseID to proposedID.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-file.
+func (f FileURL) ChangeLease(ctx context.Context, leaseID string, proposedID string) (*FileChangeLeaseResponse, error) {
+	return f.fileClient.ChangeLease(ctx, leaseID, nil, &proposedID, nil)
+}
+
+// BreakLease breaks the file's lease, if one is held. A file lease is broken immediately; once broken, it cannot be
+// renewed and another client may acquire a new lease.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-file.
+func (f FileURL) BreakLease(ctx context.Context) (*FileBreakLeaseResponse, error) {
+	return f.fileClient.BreakLease(ctx, nil, nil, nil)
+}
+
+// ListHandlesOptions defines options available when calling ListHandles.
+type ListHandlesOptions struct {
+	MaxResults int32 // 0 means unspecified
+
+	// Recursive lists the handles open on a directory's files and subdirectories too. Only DirectoryURL uses it.
+	Recursive bool
+}
+
+// ForceCloseHandlesOptions defines options available when calling ForceCloseHandles and ForceCloseAllHandles.
+type ForceCloseHandlesOptions struct {
+	// Recursive closes the handles open on a directory's files and subdirectories too. Only DirectoryURL uses it.
+	Recursive bool
+}
+
+// ListHandles returns a single segment of the SMB handles open on the file, starting from the specified Marker.
+// Use an empty Marker to start enumeration from the beginning. After getting a segment, process it, and then call
+// ListHandles again (passing the the previously-returned Marker) to get the next segment.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/list-handles.
+func (f FileURL) ListHandles(ctx context.Context, marker Marker, o ListHandlesOptions) (*ListHandlesResponse, error) {
+	var maxResults *int32
+	if o.MaxResults != 0 {
+		maxResults = &o.MaxResults
+	}
+	return f.fileClient.ListHandles(ctx, marker.val, maxResults, nil)
+}
+
+// ForceCloseHandles closes the SMB handle with the specified ID, as returned by ListHandles. The service may close
+// only some of the handles in one call; while the response's NextMarker is not done, call ForceCloseHandles again,
+// passing it, to close the rest. Use an empty Marker for the first call.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/force-close-handles.
+func (f FileURL) ForceCloseHandles(ctx context.Context, handleID string, marker Marker, o ForceCloseHandlesOptions) (*FileForceCloseHandlesResponse, error) {
+	if handleID == "" {
+		return nil, errors.New("invalid argument, handleID must not be empty")
+	}
+	return f.fileClient.ForceCloseHandles(ctx, handleID, nil, marker.val)
+}
+
+// ForceCloseAllHandles closes all of the SMB handles open on the file. See ForceCloseHandles for how to continue a
+// partial close.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/force-close-handles.
+func (f FileURL) ForceCloseAllHandles(ctx context.Context, marker Marker, o ForceCloseHandlesOptions) (*FileForceCloseHandlesResponse, error) {
+	return f.fileClient.ForceCloseHandles(ctx, allHandles, nil, marker.val)
 }
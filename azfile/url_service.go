@@ -2,6 +2,7 @@ package azfile
 
 import (
 	"context"
+	"encoding/xml"
 	"net/url"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
@@ -51,6 +52,15 @@ func (s ServiceURL) NewShareURL(shareName string) ShareURL {
 	return NewShareURL(shareURL, s.client.Pipeline())
 }
 
+// NewValidatedShareURL is like NewShareURL, but it first checks shareName with ValidateShareName, and returns its
+// error rather than a ShareURL the service would reject.
+func (s ServiceURL) NewValidatedShareURL(shareName string) (ShareURL, error) {
+	if err := ValidateShareName(shareName); err != nil {
+		return ShareURL{}, err
+	}
+	return s.NewShareURL(shareName), nil
+}
+
 // appendToURLPath appends a string to the end of a URL's path (prefixing the string with a '/' if required)
 func appendToURLPath(u url.URL, name string) url.URL {
 	// e.g. "https://ms.com/a/b/?k1=v1&k2=v2#f"
@@ -81,6 +91,118 @@ func (s ServiceURL) ListSharesSegment(ctx context.Context, marker Marker, o List
 	return s.client.ListSharesSegment(ctx, prefix, marker.val, maxResults, include, nil)
 }
 
+// ListSharesAll returns an iterator over all of the account's shares, which fetches segments with ListSharesSegment
+// as they're needed, following each segment's NextMarker. o.MaxResults, if set, limits the size of each segment
+// rather than the number of shares.
+func (s ServiceURL) ListSharesAll(ctx context.Context, o ListSharesOptions) *SharesIterator {
+	return &SharesIterator{ctx: ctx, s: s, o: o}
+}
+
+// SharesIterator iterates over the shares of an account listing in lexicographic order; see ServiceURL.ListSharesAll.
+// Call Next to advance to each share, and check Err once Next returns false:
+//
+//	it := serviceURL.ListSharesAll(ctx, azfile.ListSharesOptions{})
+//	for it.Next() {
+//		share := it.Share()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SharesIterator struct {
+	ctx    context.Context
+	s      ServiceURL
+	o      ListSharesOptions
+	marker Marker
+	shares []ShareItem
+	share  *ShareItem
+	err    error
+}
+
+// Next advances to the next share, fetching the next segment of the listing if necessary. It returns false when the
+// listing is complete, when a request fails, or when the iterator's context is done; Err tells these apart.
+func (it *SharesIterator) Next() bool {
+	it.share = nil
+	for len(it.shares) == 0 {
+		if it.err != nil || !it.marker.NotDone() {
+			return false
+		}
+		if it.err = it.ctx.Err(); it.err != nil {
+			return false
+		}
+		resp, err := it.s.ListSharesSegment(it.ctx, it.marker, it.o)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.marker = resp.NextMarker
+		it.shares = resp.ShareItems
+	}
+	it.share, it.shares = &it.shares[0], it.shares[1:]
+	return true
+}
+
+// Share returns the current share.
+func (it *SharesIterator) Share() *ShareItem {
+	return it.share
+}
+
+// Err returns the error that ended the iteration, or nil if the listing completed.
+func (it *SharesIterator) Err() error {
+	return it.err
+}
+
+// NewShareLister returns a ShareLister over all of the account's shares. Unlike ListSharesAll's iterator, a
+// ShareLister takes a context with each call, so one lister can be used across requests' contexts.
+// o's Prefix, Detail and MaxResults apply to every segment.
+func (s ServiceURL) NewShareLister(o ListSharesOptions) *ShareLister {
+	return &ShareLister{s: s, o: o}
+}
+
+// ShareLister lists the shares of an account in lexicographic order, one at a time; see ServiceURL.NewShareLister.
+// It buffers a segment of the listing, and fetches the next segment with ListSharesSegment when the buffer is empty:
+//
+//	lister := serviceURL.NewShareLister(azfile.ListSharesOptions{})
+//	for {
+//		share, ok, err := lister.Next(ctx)
+//		if err != nil {
+//			...
+//		}
+//		if !ok {
+//			break
+//		}
+//		...
+//	}
+type ShareLister struct {
+	s      ServiceURL
+	o      ListSharesOptions
+	marker Marker
+	shares []ShareItem
+}
+
+// Next returns the next share and true, fetching the next segment of the listing if necessary. It returns false with
+// a nil error once the final segment, the one without a NextMarker, is exhausted. If a request fails, Next returns
+// false and the error; calling Next again retries the failed request.
+func (l *ShareLister) Next(ctx context.Context) (ShareItem, bool, error) {
+	for len(l.shares) == 0 {
+		if !l.marker.NotDone() {
+			return ShareItem{}, false, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return ShareItem{}, false, err
+		}
+		resp, err := l.s.ListSharesSegment(ctx, l.marker, l.o)
+		if err != nil {
+			return ShareItem{}, false, err
+		}
+		l.marker = resp.NextMarker
+		l.shares = resp.ShareItems
+	}
+	share := l.shares[0]
+	l.shares = l.shares[1:]
+	return share, true, nil
+}
+
 // ListSharesOptions defines options available when calling ListSharesSegment.
 type ListSharesOptions struct {
 	Detail     ListSharesDetail // No IncludeType header is produced if ""
@@ -100,19 +222,23 @@ func (o *ListSharesOptions) pointers() (prefix *string, include []ListSharesIncl
 }
 
 // ListSharesDetail indicates what additional information the service should return with each share.
+// Deleted also lists the soft-deleted shares, whose ShareItem has Deleted set to true and the Version to restore.
 type ListSharesDetail struct {
-	Metadata, Snapshots bool
+	Metadata, Snapshots, Deleted bool
 }
 
 // toArray produces the Include query parameter's value.
 func (d *ListSharesDetail) toArray() []ListSharesIncludeType {
-	items := make([]ListSharesIncludeType, 0, 2)
+	items := make([]ListSharesIncludeType, 0, 3)
 	if d.Metadata {
 		items = append(items, ListSharesIncludeMetadata)
 	}
 	if d.Snapshots {
 		items = append(items, ListSharesIncludeSnapshots)
 	}
+	if d.Deleted {
+		items = append(items, ListSharesIncludeDeleted)
+	}
 
 	return items
 }
@@ -129,6 +255,7 @@ func (ssp *StorageServiceProperties) toFsp() *FileServiceProperties {
 		HourMetrics:   ssp.HourMetrics.toMp(),
 		MinuteMetrics: ssp.MinuteMetrics.toMp(),
 		Cors:          ssp.Cors,
+		Protocol:      ssp.Protocol,
 	}
 }
 
@@ -160,7 +287,27 @@ func (fsp *FileServiceProperties) toSsp() *StorageServiceProperties {
 		HourMetrics:   fsp.HourMetrics.toM(),
 		MinuteMetrics: fsp.MinuteMetrics.toM(),
 		Cors:          fsp.Cors,
+		Protocol:      fsp.Protocol,
+	}
+}
+
+// MarshalXML implements the xml.Marshaler interface for StorageServiceProperties.
+// It writes an empty Cors element for an empty, non-nil Cors, which the service takes as a request to remove all CORS
+// rules; without the element, the service leaves them unchanged.
+func (ssp StorageServiceProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type corsRules struct {
+		CorsRule []CorsRule `xml:"CorsRule"`
+	}
+	props := struct {
+		HourMetrics   *Metrics               `xml:"HourMetrics"`
+		MinuteMetrics *Metrics               `xml:"MinuteMetrics"`
+		Cors          *corsRules             `xml:"Cors"`
+		Protocol      *ShareProtocolSettings `xml:"ProtocolSettings"`
+	}{HourMetrics: ssp.HourMetrics, MinuteMetrics: ssp.MinuteMetrics, Protocol: ssp.Protocol}
+	if ssp.Cors != nil {
+		props.Cors = &corsRules{CorsRule: ssp.Cors}
 	}
+	return e.EncodeElement(props, start)
 }
 
 // toM converts MetricProperties to Metrics.
@@ -191,6 +338,7 @@ func (s ServiceURL) GetProperties(ctx context.Context) (*FileServiceProperties,
 }
 
 // SetProperties sets the properties of the File service.
+// Cors and Protocol are only changed if they are not nil; HourMetrics and MinuteMetrics are always set.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/set-file-service-properties.
 func (s ServiceURL) SetProperties(ctx context.Context, properties FileServiceProperties) (*ServiceSetPropertiesResponse, error) {
 	return s.client.SetProperties(ctx, *properties.toSsp(), nil)
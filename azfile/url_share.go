@@ -3,12 +3,22 @@ package azfile
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
+const (
+	// ShareInfiniteLeaseDuration is the lease duration to pass to ShareURL's AcquireLease for a lease that never expires.
+	ShareInfiniteLeaseDuration int32 = -1
+
+	// LeaseBreakDefault is the break period to pass to ShareURL's BreakLease to use the service's default behavior.
+	LeaseBreakDefault int32 = -1
+)
+
 // A ShareURL represents a URL to the Azure Storage share allowing you to manipulate its directories and files.
 type ShareURL struct {
 	shareClient shareClient
@@ -38,7 +48,8 @@ func (s ShareURL) WithPipeline(p pipeline.Pipeline) ShareURL {
 }
 
 // WithSnapshot creates a new ShareURL object identical to the source but with the specified snapshot timestamp.
-// Pass time.Time{} to remove the snapshot returning a URL to the base share.
+// Pass "" to remove the snapshot returning a URL to the base share.
+// DirectoryURLs and FileURLs created from the returned ShareURL target the same snapshot.
 func (s ShareURL) WithSnapshot(snapshot string) ShareURL {
 	p := NewFileURLParts(s.URL())
 	p.ShareSnapshot = snapshot
@@ -55,6 +66,15 @@ func (s ShareURL) NewDirectoryURL(directoryName string) DirectoryURL {
 	return NewDirectoryURL(directoryURL, s.shareClient.Pipeline())
 }
 
+// NewValidatedDirectoryURL is like NewDirectoryURL, but it first checks directoryName, which may be a path such as
+// "dir/sub", with ValidateFilePath, and returns its error rather than a DirectoryURL the service would reject.
+func (s ShareURL) NewValidatedDirectoryURL(directoryName string) (DirectoryURL, error) {
+	if err := validatePath(NewFileURLParts(s.URL()), directoryName); err != nil {
+		return DirectoryURL{}, err
+	}
+	return s.NewDirectoryURL(directoryName), nil
+}
+
 // NewRootDirectoryURL creates a new DirectoryURL object using ShareURL's URL.
 // The new DirectoryURL uses the same request policy pipeline as the
 // ShareURL. To change the pipeline, create the DirectoryURL and then call its WithPipeline method
@@ -63,51 +83,203 @@ func (s ShareURL) NewRootDirectoryURL() DirectoryURL {
 	return NewDirectoryURL(s.URL(), s.shareClient.Pipeline())
 }
 
+// ShareCreateOptions defines options available when calling ShareURL's Create.
+type ShareCreateOptions struct {
+	// QuotaInGB specifies the maximum size of the share in gigabytes, 0 means you accept service's default quota.
+	QuotaInGB int32
+
+	// AccessTier is the share's access tier. AccessTierNone means you accept the service's default tier, which is
+	// TransactionOptimized on a general purpose account and Premium on a FileStorage account.
+	AccessTier AccessTierType
+
+	// EnabledProtocols is the protocol the share is accessed with; it can't be changed once the share is created.
+	// ShareEnabledProtocolsNone means you accept the service's default, SMB. An NFS share requires a FileStorage
+	// (premium) account, on which the service fails the request otherwise.
+	EnabledProtocols ShareEnabledProtocolsType
+
+	// RootSquash is how an NFS share maps the root user of its clients. ShareRootSquashNone means you accept the
+	// service's default, NoRootSquash. It's only valid for an NFS share.
+	RootSquash ShareRootSquashType
+
+	// EncryptionScope, if not "", is the name of the account's encryption scope with which the service encrypts the
+	// share's data. A scope the account doesn't have fails the request.
+	EncryptionScope string
+}
+
 // Create creates a new share within a storage account. If a share with the same name already exists, the operation fails.
-// quotaInGB specifies the maximum size of the share in gigabytes, 0 means you accept service's default quota.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/create-share.
-func (s ShareURL) Create(ctx context.Context, metadata Metadata, quotaInGB int32) (*ShareCreateResponse, error) {
+func (s ShareURL) Create(ctx context.Context, metadata Metadata, o ShareCreateOptions) (*ShareCreateResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
 	var quota *int32
-	if quotaInGB != 0 {
-		quota = &quotaInGB
+	if o.QuotaInGB != 0 {
+		quota = &o.QuotaInGB
+	}
+	return s.shareClient.Create(ctx, nil, metadata, quota, o.AccessTier, o.EnabledProtocols, o.RootSquash,
+		optionalString(o.EncryptionScope))
+}
+
+// CreateIfNotExists creates the share like Create, unless a share with the same name already exists.
+// It returns true if it created the share, and false with a nil error if the share existed.
+func (s ShareURL) CreateIfNotExists(ctx context.Context, metadata Metadata, o ShareCreateOptions) (bool, error) {
+	_, err := s.Create(ctx, metadata, o)
+	if storageErr, ok := err.(StorageError); ok && storageErr.ServiceCode() == ServiceCodeShareAlreadyExists {
+		return false, nil
 	}
-	return s.shareClient.Create(ctx, nil, metadata, quota)
+	return err == nil, err
 }
 
 // CreateSnapshot creates a read-only snapshot of a share.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/snapshot-share.
 func (s ShareURL) CreateSnapshot(ctx context.Context, metadata Metadata) (*ShareCreateSnapshotResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
 	return s.shareClient.CreateSnapshot(ctx, nil, metadata)
 }
 
 // Delete marks the specified share or share snapshot for deletion.
 // The share or share snapshot and any files contained within it are later deleted during garbage collection.
+// A share that has snapshots can only be deleted together with them, by passing DeleteSnapshotsOptionInclude;
+// with DeleteSnapshotsOptionNone the service fails the request with ServiceCodeShareHasSnapshots.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/delete-share.
-func (s ShareURL) Delete(ctx context.Context, deleteSnapshotsOption DeleteSnapshotsOptionType) (*ShareDeleteResponse, error) {
-	return s.shareClient.Delete(ctx, nil, nil, deleteSnapshotsOption)
+func (s ShareURL) Delete(ctx context.Context, deleteSnapshotsOption DeleteSnapshotsOptionType, ac ShareAccessConditions) (*ShareDeleteResponse, error) {
+	if err := s.checkAccessConditions(ctx, ac.ModifiedAccessConditions); err != nil {
+		return nil, err
+	}
+	return s.shareClient.Delete(ctx, nil, nil, deleteSnapshotsOption, ac.LeaseAccessConditions.pointers())
+}
+
+// checkAccessConditions evaluates ac against the share's properties, if any of the conditions is set.
+func (s ShareURL) checkAccessConditions(ctx context.Context, ac ModifiedAccessConditions) error {
+	return ac.check(ctx, false, func(ctx context.Context) (propertiesResponse, error) {
+		return s.GetProperties(ctx)
+	})
+}
+
+// DeleteIfExists deletes the share like Delete, unless the share doesn't exist.
+// It returns true if it deleted the share, and false with a nil error if the share didn't exist.
+func (s ShareURL) DeleteIfExists(ctx context.Context, deleteSnapshotsOption DeleteSnapshotsOptionType, ac ShareAccessConditions) (bool, error) {
+	_, err := s.Delete(ctx, deleteSnapshotsOption, ac)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Restore restores the soft-deleted share deletedShareName, as it was in version deletedShareVersion, to this
+// ShareURL's share. The deleted versions of a share are listed by ServiceURL.ListSharesSegment with
+// ListSharesDetail.Deleted, as ShareItems whose Deleted is true; their Version is the one to pass here.
+// The service fails the request with a conflict error if a share with this ShareURL's name exists.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/restore-share.
+func (s ShareURL) Restore(ctx context.Context, deletedShareName string, deletedShareVersion string) (*ShareRestoreResponse, error) {
+	if deletedShareName == "" || deletedShareVersion == "" {
+		return nil, errors.New("invalid argument, deletedShareName and deletedShareVersion must not be empty")
+	}
+	return s.shareClient.Restore(ctx, nil, nil, &deletedShareName, &deletedShareVersion)
 }
 
 // GetProperties returns all user-defined metadata and system properties for the specified share or share snapshot.
+// The provisioned IOPS and bandwidth, and the burst IOPS and credits, are only returned for premium shares; for a
+// standard share their accessors return -1, and the NextAllowed...DowngradeTime accessors return the zero time.
+// IncludedBurstIops is the IOPS a premium share can burst to, and MaxBurstCreditsForIops the credits it can
+// accumulate to burst with; GetStatistics doesn't return them.
+// AccessTierChangeTime and AccessTierTransitionState report the last change of the share's AccessTier; while the
+// share is moving to a new tier, the transition state is "pending-from-" followed by the previous tier.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/get-share-properties.
 func (s ShareURL) GetProperties(ctx context.Context) (*ShareGetPropertiesResponse, error) {
 	return s.shareClient.GetProperties(ctx, nil, nil)
 }
 
+// Exists reports whether the share, or the share snapshot the ShareURL targets, exists, with GetProperties. It
+// returns false with a nil error if the service says it doesn't exist; any other failure, such as a 403 (Forbidden)
+// for a request that isn't authorized, is returned as an error.
+func (s ShareURL) Exists(ctx context.Context) (bool, error) {
+	_, err := s.GetProperties(ctx)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // SetQuota sets service-defined properties for the specified share.
 // quotaInGB specifies the maximum size of the share in gigabytes, 0 means no quote and uses service's default value.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/set-share-properties.
-func (s ShareURL) SetQuota(ctx context.Context, quotaInGB int32) (*ShareSetQuotaResponse, error) {
+func (s ShareURL) SetQuota(ctx context.Context, quotaInGB int32, ac ShareAccessConditions) (*ShareSetQuotaResponse, error) {
+	if err := s.checkAccessConditions(ctx, ac.ModifiedAccessConditions); err != nil {
+		return nil, err
+	}
 	var quota *int32
 	if quotaInGB != 0 {
 		quota = &quotaInGB
 	}
-	return s.shareClient.SetQuota(ctx, nil, quota)
+	return s.shareClient.SetQuota(ctx, nil, quota, ac.LeaseAccessConditions.pointers())
 }
 
-// SetMetadata sets the share's metadata.
+// ShareSetPropertiesOptions defines options available when calling ShareURL's SetProperties.
+// A zero field leaves the corresponding property of the share unchanged.
+type ShareSetPropertiesOptions struct {
+	// QuotaInGB is the maximum size of the share in gigabytes. For a premium share it is also the provisioned size,
+	// from which the service derives the share's baseline IOPS and bandwidth.
+	QuotaInGB int32
+
+	// AccessTier is the share's access tier.
+	AccessTier AccessTierType
+
+	// ProvisionedIops and ProvisionedBandwidthMibps are the IOPS and the bandwidth, in MiB/s, provisioned for a premium
+	// share on an account that provisions them independently of the share's size.
+	ProvisionedIops, ProvisionedBandwidthMibps int32
+
+	// RootSquash is how an NFS share maps the root user of its clients. It's only valid for an NFS share; a share's
+	// EnabledProtocols can only be set by Create.
+	RootSquash ShareRootSquashType
+
+	// ShareAccessConditions' LeaseAccessConditions must identify the share's lease while it is leased.
+	ShareAccessConditions
+}
+
+// SetProperties sets service-defined properties for the specified share. Properties the share's account doesn't
+// support, like provisioned IOPS on a standard share, are only rejected by the service if they are set.
+// For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/set-share-properties.
+func (s ShareURL) SetProperties(ctx context.Context, o ShareSetPropertiesOptions) (*ShareSetPropertiesResponse, error) {
+	if err := s.checkAccessConditions(ctx, o.ModifiedAccessConditions); err != nil {
+		return nil, err
+	}
+	var quota, provisionedIops, provisionedBandwidthMibps *int32
+	if o.QuotaInGB != 0 {
+		quota = &o.QuotaInGB
+	}
+	if o.ProvisionedIops != 0 {
+		provisionedIops = &o.ProvisionedIops
+	}
+	if o.ProvisionedBandwidthMibps != 0 {
+		provisionedBandwidthMibps = &o.ProvisionedBandwidthMibps
+	}
+	return s.shareClient.SetProperties(ctx, nil, quota, o.AccessTier, provisionedIops, provisionedBandwidthMibps,
+		o.RootSquash, o.LeaseAccessConditions.pointers())
+}
+
+// SetMetadata sets the share's metadata, replacing all of its metadata; an empty or nil metadata clears it.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/set-share-metadata.
-func (s ShareURL) SetMetadata(ctx context.Context, metadata Metadata) (*ShareSetMetadataResponse, error) {
-	return s.shareClient.SetMetadata(ctx, nil, metadata)
+func (s ShareURL) SetMetadata(ctx context.Context, metadata Metadata, ac ShareAccessConditions) (*ShareSetMetadataResponse, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAccessConditions(ctx, ac.ModifiedAccessConditions); err != nil {
+		return nil, err
+	}
+	return s.shareClient.SetMetadata(ctx, nil, metadata, ac.LeaseAccessConditions.pointers())
+}
+
+// GetMetadata returns the metadata of the share, or of the share snapshot the ShareURL targets, from
+// GetProperties. Its keys are lowercase; look them up with Metadata.Get, which ignores case.
+func (s ShareURL) GetMetadata(ctx context.Context) (Metadata, error) {
+	props, err := s.GetProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return props.NewMetadata(), nil
 }
 
 // GetPermissions returns information about stored access policies specified on the share.
@@ -153,14 +325,91 @@ func (p *AccessPolicyPermission) Parse(s string) {
 	p.List = strings.ContainsRune(s, 'l')
 }
 
-// SetPermissions sets a stored access policy for use with shared access signatures.
+// ShareMaxSignedIdentifiers is the largest number of stored access policies that a share can have.
+const ShareMaxSignedIdentifiers = 5
+
+// SetPermissions sets a stored access policy for use with shared access signatures. permissions replaces all of the
+// share's policies; a SAS refers to one by its ID, in FileSASSignatureValues.Identifier, and stops working when the
+// policy is removed. More than ShareMaxSignedIdentifiers policies return an error without sending a request.
 // For more information, see https://docs.microsoft.com/rest/api/storageservices/set-share-acl.
 func (s ShareURL) SetPermissions(ctx context.Context, permissions []SignedIdentifier) (*ShareSetAccessPolicyResponse, error) {
+	if len(permissions) > ShareMaxSignedIdentifiers {
+		return nil, fmt.Errorf("invalid argument, a share can have at most %d stored access policies, not %d",
+			ShareMaxSignedIdentifiers, len(permissions))
+	}
 	return s.shareClient.SetAccessPolicy(ctx, permissions, nil)
 }
 
 // GetStatistics retrieves statistics related to the share.
+// The result's ShareUsageBytes is the approximate size of the data stored on the share; ShareUsage is the same size
+// rounded up to gigabytes.
 // For more information, see https://docs.microsoft.com/en-us/rest/api/storageservices/get-share-stats.
 func (s ShareURL) GetStatistics(ctx context.Context) (*ShareStats, error) {
 	return s.shareClient.GetStatistics(ctx, nil)
 }
+
+// CreatePermission stores a security descriptor, in SDDL form, on the share. The response's FilePermissionKey
+// identifies the descriptor and can be passed as SMBProperties.FilePermissionKey when creating files and directories
+// in the share. The descriptor is sent in the request body, so this is also the way to apply an SDDL longer than
+// FilePermissionMaxInlineSize, which is too large for the x-ms-file-permission header.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/create-permission.
+func (s ShareURL) CreatePermission(ctx context.Context, sddl string) (*ShareCreatePermissionResponse, error) {
+	if sddl == "" {
+		return nil, errors.New("invalid argument, sddl must not be empty")
+	}
+	return s.shareClient.CreatePermission(ctx, SharePermission{Permission: sddl}, nil)
+}
+
+// GetPermission returns the security descriptor, in SDDL form, that the share stores under the specified key.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/get-permission.
+func (s ShareURL) GetPermission(ctx context.Context, filePermissionKey string) (*SharePermission, error) {
+	if filePermissionKey == "" {
+		return nil, errors.New("invalid argument, filePermissionKey must not be empty")
+	}
+	return s.shareClient.GetPermission(ctx, filePermissionKey, nil)
+}
+
+// Share leases behave like blob container leases: duration is between 15 and 60 seconds, or ShareInfiniteLeaseDuration.
+// Only the base share can be leased; share snapshots cannot themselves be leased, so these methods must be called on a
+// ShareURL without a snapshot. While a share holds an active lease, Delete and SetQuota require the lease ID in their
+// LeaseAccessConditions.
+
+// AcquireLease acquires a lease on the share for delete and set-properties operations. proposedID may be "" to let
+// the service pick the lease ID.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-share.
+func (s ShareURL) AcquireLease(ctx context.Context, proposedID string, duration int32) (*ShareAcquireLeaseResponse, error) {
+	var proposedLeaseID *string
+	if proposedID != "" {
+		proposedLeaseID = &proposedID
+	}
+	return s.shareClient.AcquireLease(ctx, nil, &duration, proposedLeaseID, nil)
+}
+
+// RenewLease renews the share's previously-acquired lease.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-share.
+func (s ShareURL) RenewLease(ctx context.Context, leaseID string) (*ShareRenewLeaseResponse, error) {
+	return s.shareClient.RenewLease(ctx, leaseID, nil, nil)
+}
+
+// ReleaseLease releases the share's previously-acquired lease.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-share.
+func (s ShareURL) ReleaseLease(ctx context.Context, leaseID string) (*ShareReleaseLeaseResponse, error) {
+	return s.shareClient.ReleaseLease(ctx, leaseID, nil, nil)
+}
+
+// ChangeLease changes the share's lease ID from leaseID to proposedID.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-share.
+func (s ShareURL) ChangeLease(ctx context.Context, leaseID string, proposedID string) (*ShareChangeLeaseResponse, error) {
+	return s.shareClient.ChangeLease(ctx, leaseID, nil, &proposedID, nil)
+}
+
+// BreakLease breaks the share's previously-acquired lease (if it exists). Pass the LeaseBreakDefault (-1) constant
+// to break a fixed-duration lease when it expires or an infinite lease immediately.
+// For more information, see https://docs.microsoft.com/rest/api/storageservices/lease-share.
+func (s ShareURL) BreakLease(ctx context.Context, breakPeriodInSeconds int32) (*ShareBreakLeaseResponse, error) {
+	var breakPeriod *int32
+	if breakPeriodInSeconds != LeaseBreakDefault {
+		breakPeriod = &breakPeriodInSeconds
+	}
+	return s.shareClient.BreakLease(ctx, nil, breakPeriod, nil, nil)
+}
@@ -0,0 +1,60 @@
+package azfile
+
+import (
+	"context"
+	"sync"
+)
+
+// BufferManager hands out the buffers that the high-level transfer functions read ranges into, keeping at most
+// MaxBuffers of them in use at a time. Released buffers are pooled, and reused by every BufferManager of the same
+// buffer size, so that a process running many transfers doesn't allocate a buffer per range. A BufferManager is safe
+// for concurrent use; share one between transfers to bound their memory together.
+type BufferManager struct {
+	bufferSize int
+	slots      chan struct{}
+	pool       *sync.Pool
+}
+
+// bufferPools holds a *sync.Pool of buffers for each buffer size.
+var bufferPools sync.Map
+
+// NewBufferManager creates a BufferManager of buffers of bufferSize bytes, at most maxBuffers of which are in use at a
+// time. If 0 is provided, bufferSize defaults to FileMaxUploadRangeBytes and maxBuffers to 5.
+func NewBufferManager(bufferSize int, maxBuffers int) *BufferManager {
+	if bufferSize <= 0 {
+		bufferSize = FileMaxUploadRangeBytes
+	}
+	if maxBuffers <= 0 {
+		maxBuffers = defaultParallelCount
+	}
+	pool, _ := bufferPools.LoadOrStore(bufferSize, &sync.Pool{New: func() interface{} {
+		return make([]byte, bufferSize)
+	}})
+	return &BufferManager{bufferSize: bufferSize, slots: make(chan struct{}, maxBuffers), pool: pool.(*sync.Pool)}
+}
+
+// BufferSize returns the size of the manager's buffers.
+func (m *BufferManager) BufferSize() int {
+	return m.bufferSize
+}
+
+// Acquire returns a zeroed buffer, waiting until fewer than the manager's maximum number are in use, or returns
+// ctx's error if ctx is done first. Pass the buffer to Release once it's no longer used.
+func (m *BufferManager) Acquire(ctx context.Context) ([]byte, error) {
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.pool.Get().([]byte), nil
+}
+
+// Release zeroes b, so that its data can't leak into another transfer, and returns it to the pool.
+func (m *BufferManager) Release(b []byte) {
+	b = b[:cap(b)]
+	for i := range b {
+		b[i] = 0
+	}
+	m.pool.Put(b) // nolint:staticcheck // The slice header's allocation is negligible next to the buffer's
+	<-m.slots
+}
@@ -0,0 +1,27 @@
+package azfile
+
+import "time"
+
+// Clock tells the time, and waits. The package's time-dependent logic, like the retry policy's delays, takes one so
+// that tests can drive it with a fake clock rather than sleep. A fake Clock's Sleep would usually advance the time
+// its Now returns, and return at once.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep blocks for d.
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the Clock of the system's time. It's the default wherever a Clock can be given.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
@@ -6,31 +6,77 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"hash"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
-// NewSharedKeyCredential creates an immutable SharedKeyCredential containing the
+// NewSharedKeyCredential creates a SharedKeyCredential containing the
 // storage account's name and either its primary or secondary key.
 func NewSharedKeyCredential(accountName, accountKey string) (*SharedKeyCredential, error) {
 	bytes, err := base64.StdEncoding.DecodeString(accountKey)
 	if err != nil {
 		return &SharedKeyCredential{}, err
 	}
-	return &SharedKeyCredential{accountName: accountName, accountKey: bytes}, nil
+	f := &SharedKeyCredential{accountName: accountName, accountKey: &atomic.Value{}}
+	f.accountKey.Store(newSigningKey(bytes))
+	return f, nil
 }
 
 // SharedKeyCredential contains an account's name and its primary or secondary key.
-// It is immutable making it shareable and goroutine-safe.
+// It is goroutine-safe, so it can be shared by any number of pipelines, and its key can be rotated with
+// SetAccountKey while they are in use.
 type SharedKeyCredential struct {
 	// Only the NewSharedKeyCredential method should set these; all other methods should treat them as read-only
 	accountName string
-	accountKey  []byte
+	accountKey  *atomic.Value // Holds the key's *signingKey; SetAccountKey replaces it
+}
+
+// signingKey is an account key, with a pool of HMAC-SHA256 hashes keyed with it. Keying a hash is as costly as
+// signing a short message, so reusing the keyed hashes spares it to each signature, e.g. when minting many SASs.
+type signingKey struct {
+	key    []byte
+	hashes sync.Pool
+}
+
+func newSigningKey(key []byte) *signingKey {
+	k := &signingKey{key: key}
+	k.hashes.New = func() interface{} { return hmac.New(sha256.New, key) }
+	return k
+}
+
+// sign returns the base64 HMAC-SHA256 of message, keyed with the key.
+func (k *signingKey) sign(message string) string {
+	h := k.hashes.Get().(hash.Hash)
+	h.Reset() // Back to the keyed state
+	h.Write([]byte(message))
+	sum := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	k.hashes.Put(h)
+	return sum
+}
+
+// SetAccountKey replaces the credential's key, e.g. with the account's other key while the first is regenerated.
+// Each try of a request is signed with the key the credential has when the try is sent: the tries in flight finish
+// with the old key, and later tries, including retries of earlier requests, use the new one. The pipelines and URLs
+// using the credential don't need to be rebuilt. If accountKey isn't valid base64, the key is left unchanged.
+func (f *SharedKeyCredential) SetAccountKey(accountKey string) error {
+	bytes, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return err
+	}
+	if f.accountKey == nil {
+		return errors.New("invalid argument, the SharedKeyCredential wasn't created by NewSharedKeyCredential")
+	}
+	f.accountKey.Store(newSigningKey(bytes))
+	return nil
 }
 
 // AccountName returns the Storage account's name.
@@ -64,30 +110,36 @@ func (*SharedKeyCredential) credentialMarker() {}
 
 // Constants ensuring that header names are correctly spelled and consistently cased.
 const (
-	headerAuthorization      = "Authorization"
-	headerCacheControl       = "Cache-Control"
-	headerContentEncoding    = "Content-Encoding"
-	headerContentDisposition = "Content-Disposition"
-	headerContentLanguage    = "Content-Language"
-	headerContentLength      = "Content-Length"
-	headerContentMD5         = "Content-MD5"
-	headerContentType        = "Content-Type"
-	headerDate               = "Date"
-	headerIfMatch            = "If-Match"
-	headerIfModifiedSince    = "If-Modified-Since"
-	headerIfNoneMatch        = "If-None-Match"
-	headerIfUnmodifiedSince  = "If-Unmodified-Since"
-	headerRange              = "Range"
-	headerUserAgent          = "User-Agent"
-	headerXmsDate            = "x-ms-date"
-	headerXmsVersion         = "x-ms-version"
+	headerAuthorization        = "Authorization"
+	headerCacheControl         = "Cache-Control"
+	headerContentEncoding      = "Content-Encoding"
+	headerContentDisposition   = "Content-Disposition"
+	headerContentLanguage      = "Content-Language"
+	headerContentLength        = "Content-Length"
+	headerContentMD5           = "Content-MD5"
+	headerContentType          = "Content-Type"
+	headerDate                 = "Date"
+	headerIfMatch              = "If-Match"
+	headerIfModifiedSince      = "If-Modified-Since"
+	headerIfNoneMatch          = "If-None-Match"
+	headerIfUnmodifiedSince    = "If-Unmodified-Since"
+	headerRange                = "Range"
+	headerUserAgent            = "User-Agent"
+	headerXmsDate              = "x-ms-date"
+	headerXmsFileRequestIntent = "x-ms-file-request-intent"
+	headerXmsVersion           = "x-ms-version"
 )
 
 // ComputeHMACSHA256 generates a hash signature for an HTTP request or for a SAS.
+// It's safe for concurrent use, and reuses the hashes keyed with the credential's key across calls, so sharing one
+// credential between the SASs being minted, and the requests being signed, is cheaper than creating one for each.
 func (f *SharedKeyCredential) ComputeHMACSHA256(message string) (base64String string) {
-	h := hmac.New(sha256.New, f.accountKey)
-	h.Write([]byte(message))
-	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if f.accountKey == nil {
+		h := hmac.New(sha256.New, nil)
+		h.Write([]byte(message))
+		return base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return f.accountKey.Load().(*signingKey).sign(message)
 }
 
 func (f *SharedKeyCredential) buildStringToSign(request pipeline.Request) string {
@@ -0,0 +1,39 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package azfile
+
+import (
+	"os"
+	"time"
+)
+
+// localSMBProperties returns the SMB properties of a local file or directory that the local file system has: its
+// last write time, and for a file without write permission, the ReadOnly attribute.
+func localSMBProperties(info os.FileInfo) SMBProperties {
+	lastWriteTime := info.ModTime()
+	p := SMBProperties{FileLastWriteTime: &lastWriteTime}
+	if !info.IsDir() && info.Mode().Perm()&0200 == 0 {
+		attributes := FileAttributeReadOnly
+		p.FileAttributes = &attributes
+	}
+	return p
+}
+
+// setLocalSMBProperties sets the SMB properties of p that the local file system has on the local file or directory
+// at path: its last write time, and for a file with the ReadOnly attribute, the removal of its write permission.
+func setLocalSMBProperties(path string, p SMBProperties) error {
+	if p.FileLastWriteTime != nil {
+		if err := os.Chtimes(path, time.Now(), *p.FileLastWriteTime); err != nil {
+			return err
+		}
+	}
+	if p.FileAttributes == nil || *p.FileAttributes&FileAttributeReadOnly == 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return err
+	}
+	return os.Chmod(path, info.Mode().Perm()&^0222)
+}
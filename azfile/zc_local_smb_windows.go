@@ -0,0 +1,66 @@
+package azfile
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// localSMBProperties returns the SMB properties of a local file or directory: its attributes, and its creation and
+// last write times.
+func localSMBProperties(info os.FileInfo) SMBProperties {
+	lastWriteTime := info.ModTime()
+	p := SMBProperties{FileLastWriteTime: &lastWriteTime}
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		attributes := FileAttributeFlags(data.FileAttributes)
+		creationTime := time.Unix(0, data.CreationTime.Nanoseconds())
+		p.FileAttributes, p.FileCreationTime = &attributes, &creationTime
+	}
+	return p
+}
+
+// settableFileAttributes are the attributes SetFileAttributes can set.
+const settableFileAttributes = FileAttributeReadOnly | FileAttributeHidden | FileAttributeSystem | FileAttributeArchive |
+	FileAttributeTemporary | FileAttributeOffline | FileAttributeNotContentIndexed
+
+// setLocalSMBProperties sets the SMB properties of p on the local file or directory at path: its creation and last
+// write times, and its attributes.
+func setLocalSMBProperties(path string, p SMBProperties) error {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	if p.FileCreationTime != nil || p.FileLastWriteTime != nil {
+		var creationTime, lastWriteTime *syscall.Filetime
+		if p.FileCreationTime != nil {
+			t := syscall.NsecToFiletime(p.FileCreationTime.UnixNano())
+			creationTime = &t
+		}
+		if p.FileLastWriteTime != nil {
+			t := syscall.NsecToFiletime(p.FileLastWriteTime.UnixNano())
+			lastWriteTime = &t
+		}
+		// FILE_FLAG_BACKUP_SEMANTICS is needed to open a directory.
+		h, err := syscall.CreateFile(name, syscall.FILE_WRITE_ATTRIBUTES,
+			syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE, nil, syscall.OPEN_EXISTING,
+			syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+		if err != nil {
+			return &os.PathError{Op: "open", Path: path, Err: err}
+		}
+		err = syscall.SetFileTime(h, creationTime, nil, lastWriteTime)
+		syscall.CloseHandle(h)
+		if err != nil {
+			return &os.PathError{Op: "settime", Path: path, Err: err}
+		}
+	}
+	if p.FileAttributes != nil {
+		attributes := uint32(*p.FileAttributes & settableFileAttributes)
+		if attributes == 0 {
+			attributes = syscall.FILE_ATTRIBUTE_NORMAL
+		}
+		if err := syscall.SetFileAttributes(name, attributes); err != nil {
+			return &os.PathError{Op: "setattributes", Path: path, Err: err}
+		}
+	}
+	return nil
+}
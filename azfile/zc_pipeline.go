@@ -1,6 +1,9 @@
 package azfile
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
@@ -17,16 +20,89 @@ type PipelineOptions struct {
 
 	// Telemetry configures the built-in telemetry policy behavior.
 	Telemetry TelemetryOptions
+
+	// RequestID configures the built-in policy that sets each request's x-ms-client-request-id.
+	RequestID RequestIDOptions
+
+	// Tracing configures the optional tracing policy, which emits spans through a Tracer.
+	Tracing TracingOptions
+
+	// HTTPSender, if not nil, sends the pipeline's requests in place of the package's http.Client. Use
+	// NewHTTPClientSenderFactory to send them with an http.Client of your own, e.g. one that uses a corporate proxy.
+	HTTPSender pipeline.Factory
+
+	// Transport tunes the connection pool of the package's http.Client. It is ignored when HTTPSender is set.
+	Transport TransportOptions
+
+	// ServiceVersion, if not empty, is sent as the x-ms-version of the pipeline's requests in place of the package's
+	// ServiceVersion. It must be one of SupportedServiceVersions, whose doc lists what each version introduced;
+	// otherwise every request fails.
+	ServiceVersion string
+}
+
+// NewHTTPClientSenderFactory returns a pipeline.Factory, for PipelineOptions.HTTPSender, that sends requests with
+// client. To use an http.RoundTripper, wrap it in an http.Client: &http.Client{Transport: rt}.
+// Note: client can't be nil.
+func NewHTTPClientSenderFactory(client *http.Client) pipeline.Factory {
+	return httpClientSender{client: client}
+}
+
+// httpClientSender is the pipeline.Factory NewHTTPClientSenderFactory returns. NewPipeline gets the client from it,
+// for CloseIdleConnections.
+type httpClientSender struct {
+	client *http.Client
+}
+
+// New implements the pipeline.Factory interface.
+func (s httpClientSender) New(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.Policy {
+	return pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		r, err := s.client.Do(request.WithContext(ctx))
+		if err != nil {
+			err = pipeline.NewError(err, "HTTP request failed")
+		}
+		return pipeline.NewHTTPResponse(r), err
+	})
+}
+
+// clientPipeline is a Pipeline created by NewPipeline that sends its requests with client.
+type clientPipeline struct {
+	pipeline.Pipeline
+	client *http.Client
+}
+
+// CloseIdleConnections closes the idle (keep-alive) connections of the http.Client with which p sends its requests,
+// so that they don't hold up the exit of a short-lived process, such as a CLI invocation or a test, or linger once
+// p is discarded. Calling it is optional: idle connections are closed anyway after TransportOptions.IdleConnTimeout.
+// p remains usable, and dials connections again as it needs them, unless the transport of the client passed to
+// NewHTTPClientSenderFactory has itself been shut down. The package's clients are shared by the pipelines with
+// equal TransportOptions, whose idle connections are closed too. CloseIdleConnections does nothing if p wasn't
+// created by NewPipeline, or sends its requests with a PipelineOptions.HTTPSender of another kind.
+func CloseIdleConnections(p pipeline.Pipeline) {
+	if cp, ok := p.(clientPipeline); ok {
+		cp.client.CloseIdleConnections()
+	}
 }
 
 // NewPipeline creates a Pipeline using the specified credentials and options.
-// Note: c can't be nil.
+// Note: c can't be nil. To send requests to URLs that already carry a SAS, pass NewAnonymousCredential();
+// requests are then not signed, but still go through the retry, telemetry and logging policies.
+// A pipeline, and the URLs using it, are safe for concurrent use by any number of goroutines. To rotate the
+// credential of a pipeline in use, call SetAccountKey on its SharedKeyCredential, or SetToken on its
+// TokenCredential, rather than creating a new pipeline; the tries of requests sent after the call use the new secret.
 func NewPipeline(c Credential, o PipelineOptions) pipeline.Pipeline {
 	// Closest to API goes first; closest to the wire goes last
 	f := []pipeline.Factory{
 		NewTelemetryPolicyFactory(o.Telemetry),
-		NewUniqueRequestIDPolicyFactory(),
-		NewRetryPolicyFactory(o.Retry),
+		newUniqueRequestIDPolicyFactory(o.RequestID),
+	}
+	if o.ServiceVersion != "" && o.ServiceVersion != ServiceVersion {
+		f = append(f, newServiceVersionPolicyFactory(o.ServiceVersion))
+	}
+	if o.Tracing.Tracer != nil {
+		f = append(f, newOperationSpanPolicyFactory(o.Tracing.Tracer), NewRetryPolicyFactory(o.Retry),
+			newTrySpanPolicyFactory(o.Tracing.Tracer))
+	} else {
+		f = append(f, NewRetryPolicyFactory(o.Retry))
 	}
 
 	if _, ok := c.(*anonymousCredentialPolicyFactory); !ok {
@@ -40,5 +116,12 @@ func NewPipeline(c Credential, o PipelineOptions) pipeline.Pipeline {
 		pipeline.MethodFactoryMarker()) // indicates at what stage in the pipeline the method factory is invoked
 
 
-	return pipeline.NewPipeline(f, pipeline.Options{HTTPSender: nil, Log: o.Log})
+	if o.HTTPSender == nil {
+		o.HTTPSender = NewHTTPClientSenderFactory(o.Transport.httpClient())
+	}
+	p := pipeline.NewPipeline(f, pipeline.Options{HTTPSender: o.HTTPSender, Log: o.Log})
+	if sender, ok := o.HTTPSender.(httpClientSender); ok {
+		return clientPipeline{Pipeline: p, client: sender.client}
+	}
+	return p
 }
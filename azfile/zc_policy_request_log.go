@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -14,10 +15,34 @@ import (
 )
 
 // RequestLogOptions configures the retry policy's behavior.
+// Secrets never reach the log: the 'sig' query parameter of SAS URLs, wherever a URL is rendered, and the values of
+// the Authorization and x-ms-copy-source-authorization headers are replaced with REDACTED.
 type RequestLogOptions struct {
 	// LogWarningIfTryOverThreshold logs a warning if a tried operation takes longer than the specified
 	// duration (-1=no logging; 0=default threshold).
 	LogWarningIfTryOverThreshold time.Duration
+
+	// SyslogDisabled, if true, stops the policy from also writing warnings and errors to the system log (syslog,
+	// or the Windows Event Log), which it does by default even if PipelineOptions.Log doesn't log them.
+	SyslogDisabled bool
+}
+
+// correlationIDKey is the context key of the value set by WithCorrelationID.
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx with which the request log policy includes correlationID in the log entries
+// of each try of the requests made with the context, to tie them to the operation that made the requests. Unlike
+// WithClientRequestID, it isn't sent to the service. The entries of requests made without it are unchanged.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// logCorrelation returns the ", CorrelationID=..." the log entries of the requests made with ctx include, or "".
+func logCorrelation(ctx context.Context) string {
+	if id, _ := ctx.Value(correlationIDKey{}).(string); id != "" {
+		return ", CorrelationID=" + id
+	}
+	return ""
 }
 
 func (o RequestLogOptions) defaults() RequestLogOptions {
@@ -38,13 +63,14 @@ func NewRequestLogPolicyFactory(o RequestLogOptions) pipeline.Factory {
 		operationStart := time.Now() // If this is the 1st try, record the operation state time
 		return func(ctx context.Context, request pipeline.Request) (response pipeline.Response, err error) {
 			try++ // The first try is #1 (not #0)
+			correlation := logCorrelation(ctx)
 
 			// Log the outgoing request as informational
 			if po.ShouldLog(pipeline.LogInfo) {
 				b := &bytes.Buffer{}
-				fmt.Fprintf(b, "==> OUTGOING REQUEST (Try=%d)\n", try)
+				fmt.Fprintf(b, "==> OUTGOING REQUEST (Try=%d%s)\n", try, correlation)
 				pipeline.WriteRequestWithResponse(b, prepareRequestForLogging(request), nil, nil)
-				po.Log(pipeline.LogInfo, b.String())
+				po.Log(pipeline.LogInfo, redactSigInLog(b.String()))
 			}
 
 			// Set the time for this particular retry operation and then Do the operation.
@@ -80,7 +106,7 @@ func NewRequestLogPolicyFactory(o RequestLogOptions) pipeline.Factory {
 				if o.LogWarningIfTryOverThreshold > 0 && tryDuration > o.LogWarningIfTryOverThreshold {
 					slow = fmt.Sprintf("[SLOW >%v]", o.LogWarningIfTryOverThreshold)
 				}
-				fmt.Fprintf(b, "==> REQUEST/RESPONSE (Try=%d/%v%s, OpTime=%v) -- ", try, tryDuration, slow, opDuration)
+				fmt.Fprintf(b, "==> REQUEST/RESPONSE (Try=%d/%v%s, OpTime=%v%s) -- ", try, tryDuration, slow, opDuration, correlation)
 				if err != nil { // This HTTP request did not get a response from the service
 					fmt.Fprint(b, "REQUEST ERROR\n")
 				} else {
@@ -91,13 +117,17 @@ func NewRequestLogPolicyFactory(o RequestLogOptions) pipeline.Factory {
 					}
 				}
 
-				pipeline.WriteRequestWithResponse(b, prepareRequestForLogging(request), response.Response(), err)
+				var httpResponse *http.Response
+				if response != nil { // A policy may return no response with an error
+					httpResponse = response.Response()
+				}
+				pipeline.WriteRequestWithResponse(b, prepareRequestForLogging(request), httpResponse, err)
 				if logLevel <= pipeline.LogError {
 					b.Write(stack()) // For errors (or lower levels), we append the stack trace (an expensive operation)
 				}
-				msg := b.String()
+				msg := redactSigInLog(b.String()) // Errors, like those of the HTTP client, may render the request's URL
 
-				if forceLog {
+				if forceLog && !o.SyslogDisabled {
 					pipeline.ForceLog(logLevel, msg)
 				}
 				if shouldLog {
@@ -129,6 +159,14 @@ func RedactSigQueryParam(rawQuery string) (bool, string) {
 	return sigFound, values.Encode()
 }
 
+// sigInLog matches the value of the 'sig' query parameter of a URL in a log message.
+var sigInLog = regexp.MustCompile(`(?i)([?&]sig=)[^&\s"']+`)
+
+// redactSigInLog redacts the value of the 'sig' query parameter of any URL in a log message.
+func redactSigInLog(msg string) string {
+	return sigInLog.ReplaceAllString(msg, "${1}REDACTED")
+}
+
 func prepareRequestForLogging(request pipeline.Request) *http.Request {
 	req := request
 	if sigFound, rawQuery := RedactSigQueryParam(req.URL.RawQuery); sigFound {
@@ -167,11 +205,21 @@ func prepareRequestForServiceLogging(request pipeline.Request) *http.Request {
 			}
 		}
 	}
+	for _, header := range []string{"Authorization", xMsCopySourceAuthorizationHeader} {
+		if exist, key := doesHeaderExistCaseInsensitive(req.Header, header); exist {
+			if req.Request == request.Request {
+				req = request.Copy() // Make copy so we don't destroy the headers we actually need to send in the request
+			}
+			req.Header[key] = []string{"REDACTED"}
+		}
+	}
 	return req.Request
 }
 
 const xMsCopySourceHeader = "x-ms-copy-source"
 
+const xMsCopySourceAuthorizationHeader = "x-ms-copy-source-authorization"
+
 func doesHeaderExistCaseInsensitive(header http.Header, key string) (bool, string) {
 	for keyInHeader := range header {
 		if strings.EqualFold(keyInHeader, key) {
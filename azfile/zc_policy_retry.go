@@ -25,6 +25,10 @@ const (
 	RetryPolicyFixed RetryPolicy = 1
 )
 
+// ThrottledNotifier is a function type that represents the notification function called before a throttled request
+// is retried.
+type ThrottledNotifier func(try int32, delay time.Duration, serviceCode ServiceCodeType)
+
 // RetryOptions configures the retry policy's behavior.
 type RetryOptions struct {
 	// Policy tells the pipeline what kind of retry policy to use. See the RetryPolicy* constants.\
@@ -40,6 +44,7 @@ type RetryOptions struct {
 	// of data, the default TryTimeout will probably not be sufficient. You should override this value
 	// based on the bandwidth available to the host machine and proximity to the Storage service. A good
 	// starting point may be something like (60 seconds per MB of anticipated-payload-size).
+	// Use WithTryTimeout to override TryTimeout for the requests made with a context.
 	TryTimeout time.Duration
 
 	// RetryDelay specifies the amount of delay to use before retrying an operation (0=default).
@@ -53,10 +58,63 @@ type RetryOptions struct {
 	// MaxRetryDelay specifies the maximum delay allowed before retrying an operation (0=default).
 	// If you specify 0, then you must also specify 0 for RetryDelay.
 	MaxRetryDelay time.Duration
+
+	// RetryReadsFromSecondaryHost specifies whether the retry policy should retry a read operation against another host.
+	// If RetryReadsFromSecondaryHost is "" (the default) then operations are not retried against another host.
+	// Otherwise retries of GET and HEAD requests alternate between the primary host and this one, like the
+	// "myaccount-secondary.file.core.windows.net" secondary of a read-access geo-redundant (RA-GRS) account. Other
+	// requests are never sent to it. If the secondary returns 404 (Not Found), which may be replication lag, the
+	// remaining retries only go to the primary.
+	// NOTE: Before setting this field, make sure you understand the issues around reading stale & potentially-inconsistent
+	// data at this webpage: https://docs.microsoft.com/en-us/azure/storage/common/storage-designing-ha-apps-with-ragrs
+	RetryReadsFromSecondaryHost string
+
+	// FullJitter, if true, delays each retry by a random duration between 0 and the delay RetryDelay and
+	// MaxRetryDelay give, rather than by that delay times a random factor between 0.8 and 1.3. This spreads out the
+	// retries of many requests that failed together, like those of parallel transfers when the service throttles them.
+	FullJitter bool
+
+	// ShouldRetry, if not nil, decides whether to retry a try that ended with resp and err, in place of the built-in
+	// decision, which retries connection failures, timeouts and the temporary StorageErrors of HTTP status 500 and 503.
+	// resp is nil if the try got no response, and err is nil if the try succeeded. A request whose context is done is
+	// never retried, and no request is tried more than MaxTries times.
+	ShouldRetry func(resp *http.Response, err error) bool
+
+	// NotifyThrottled is called, if non-nil, before the delay of each retry of a try that the service throttled, with
+	// HTTP status 503 (Service Unavailable, e.g. ServiceCodeServerBusy) or 429 (Too Many Requests). try is the number
+	// of the try about to be made, delay how long it waits first, and serviceCode the throttled try's ServiceCode,
+	// if it has one. Expected usage is metrics, and reducing the parallelism of transfers while the account is
+	// throttled. It's called on the request's goroutine, and the retry waits for it to return.
+	NotifyThrottled ThrottledNotifier
+
+	// Clock, if not nil, is the clock the retry policy waits with between tries, in place of SystemClock. Tests can
+	// give a fake one to check the delays without waiting for them.
+	Clock Clock
+}
+
+// tryTimeoutKey is the context key of the value set by WithTryTimeout.
+type tryTimeoutKey struct{}
+
+// WithTryTimeout returns a copy of ctx with which each try of a request is allowed tryTimeout, in place of the
+// TryTimeout of the pipeline's RetryOptions. Like TryTimeout, it sets both the server-side timeout query parameter and
+// the deadline of the try's HTTP request, and each retry gets a fresh tryTimeout. ctx's own deadline, if any,
+// still bounds all the tries together. For example, to give a large upload more time than the pipeline's other requests:
+//
+//	_, err := fileURL.UploadRange(azfile.WithTryTimeout(ctx, 10*time.Minute), 0, body, nil, azfile.UploadRangeOptions{})
+func WithTryTimeout(ctx context.Context, tryTimeout time.Duration) context.Context {
+	return context.WithValue(ctx, tryTimeoutKey{}, tryTimeout)
+}
+
+// tryTimeout returns the maximum time allowed for a single try of a request made with ctx.
+func (o RetryOptions) tryTimeout(ctx context.Context) time.Duration {
+	if tryTimeout, ok := ctx.Value(tryTimeoutKey{}).(time.Duration); ok && tryTimeout > 0 {
+		return tryTimeout
+	}
+	return o.TryTimeout
 }
 
 func (o RetryOptions) retryReadsFromSecondaryHost() string {
-	return ""
+	return o.RetryReadsFromSecondaryHost
 }
 
 func (o RetryOptions) defaults() RetryOptions {
@@ -88,6 +146,9 @@ func (o RetryOptions) defaults() RetryOptions {
 		IfDefault(&o.RetryDelay, 30*time.Second)
 		IfDefault(&o.MaxRetryDelay, 120*time.Second)
 	}
+	if o.Clock == nil {
+		o.Clock = SystemClock
+	}
 	return o
 }
 
@@ -111,6 +172,14 @@ func (o RetryOptions) calcDelay(try int32) time.Duration { // try is >=1; never
 		}
 	}
 
+	if o.FullJitter {
+		// Full jitter: [0, min(delay, MaxRetryDelay)]
+		if delay > o.MaxRetryDelay {
+			delay = o.MaxRetryDelay
+		}
+		return time.Duration(rand.Int63n(int64(delay) + 1)) // NOTE: We want math/rand; not crypto/rand
+	}
+
 	// Introduce some jitter:  [0.0, 1.0) / 2 = [0.0, 0.5) + 0.8 = [0.8, 1.3)
 	// For casts and rounding - be careful, as per https://github.com/golang/go/issues/20757
 	delay = time.Duration(float32(delay) * (rand.Float32()/2 + 0.8)) // NOTE: We want math/rand; not crypto/rand
@@ -128,6 +197,9 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 			// Before each try, we'll select either the primary or secondary URL.
 			primaryTry := int32(0) // This indicates how many tries we've attempted against the primary DC
 
+			// Whether the previous try was throttled, and with which service code, for o.NotifyThrottled.
+			throttledCode, throttled := ServiceCodeNone, false
+
 			// We only consider retrying against a secondary if we have a read request (GET/HEAD) AND this policy has a Secondary URL it can use
 			considerSecondary := (request.Method == http.MethodGet || request.Method == http.MethodHead) && o.retryReadsFromSecondaryHost() != ""
 
@@ -144,17 +216,20 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 				// Determine which endpoint to try. It's primary if there is no secondary or if it is an add # attempt.
 				tryingPrimary := !considerSecondary || (try%2 == 1)
 				// Select the correct host and delay
+				var delay time.Duration
 				if tryingPrimary {
 					primaryTry++
-					delay := o.calcDelay(primaryTry)
+					delay = o.calcDelay(primaryTry) // The 1st try returns 0 delay
 					logf("Primary try=%d, Delay=%v\n", primaryTry, delay)
-					time.Sleep(delay) // The 1st try returns 0 delay
 				} else {
 					// For casts and rounding - be careful, as per https://github.com/golang/go/issues/20757
-					delay := time.Duration(float32(time.Second) * (rand.Float32()/2 + 0.8))
+					delay = time.Duration(float32(time.Second) * (rand.Float32()/2 + 0.8)) // Delay with some jitter before trying secondary
 					logf("Secondary try=%d, Delay=%v\n", try-primaryTry, delay)
-					time.Sleep(delay) // Delay with some jitter before trying secondary
 				}
+				if throttled && o.NotifyThrottled != nil {
+					o.NotifyThrottled(try, delay, throttledCode)
+				}
+				o.Clock.Sleep(delay)
 
 				// Clone the original request to ensure that each try starts with the original (unmutated) request.
 				requestCopy := request.Copy()
@@ -171,8 +246,8 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 				}
 
 				// Set the server-side timeout query parameter "timeout=[seconds]"
-				timeout := int32(o.TryTimeout.Seconds()) // Max seconds per try
-				if deadline, ok := ctx.Deadline(); ok {  // If user's ctx has a deadline, make the timeout the smaller of the two
+				timeout := int32(o.tryTimeout(ctx).Seconds()) // Max seconds per try
+				if deadline, ok := ctx.Deadline(); ok {       // If user's ctx has a deadline, make the timeout the smaller of the two
 					t := int32(deadline.Sub(time.Now()).Seconds()) // Duration from now until user's ctx reaches its deadline
 					logf("MaxTryTimeout=%d secs, TimeTilDeadline=%d sec\n", timeout, t)
 					if t < timeout {
@@ -208,6 +283,12 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 					// case, we'll never try the secondary again for this operation.
 					considerSecondary = false
 					action = "Retry: Secondary URL returned 404"
+				case o.ShouldRetry != nil:
+					if o.ShouldRetry(tryResponse(response, err), err) {
+						action = "Retry: ShouldRetry returned true"
+					} else {
+						action = "NoRetry: ShouldRetry returned false"
+					}
 				case err != nil:
 					// NOTE: Protocol Responder returns non-nil if REST API returns invalid status code for the invoked operation.
 					// Use ServiceCode to verify if the error is related to storage service-side,
@@ -258,6 +339,7 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 					}
 					break // Don't retry
 				}
+				throttledCode, throttled = throttledServiceCode(tryResponse(response, err), err)
 				if response != nil && response.Response() != nil && response.Response().Body != nil {
 					// If we're going to retry and we got a previous response, then flush its body to avoid leaking its TCP connection
 					body := response.Response().Body
@@ -272,6 +354,29 @@ func NewRetryPolicyFactory(o RetryOptions) pipeline.Factory {
 	})
 }
 
+// tryResponse returns the HTTP response of a try that returned response and err, or nil if it got none.
+func tryResponse(response pipeline.Response, err error) *http.Response {
+	if response != nil && response.Response() != nil {
+		return response.Response()
+	}
+	if respErr, ok := err.(ResponseError); ok {
+		return respErr.Response()
+	}
+	return nil
+}
+
+// throttledServiceCode reports whether a try that got resp and err was throttled by the service, and if so, returns the
+// ServiceCode of its error, or ServiceCodeNone if it has none.
+func throttledServiceCode(resp *http.Response, err error) (ServiceCodeType, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusServiceUnavailable && resp.StatusCode != http.StatusTooManyRequests) {
+		return ServiceCodeNone, false
+	}
+	if stErr, ok := err.(StorageError); ok {
+		return stErr.ServiceCode(), true
+	}
+	return ServiceCodeType(resp.Header.Get("x-ms-error-code")), true
+}
+
 // contextCancelReadCloser helps to invoke context's cancelFunc properly when the ReadCloser is closed.
 type contextCancelReadCloser struct {
 	cf   context.CancelFunc
@@ -0,0 +1,47 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+)
+
+// SupportedServiceVersions lists, oldest first, the service versions that PipelineOptions.ServiceVersion accepts.
+// The package's operations are written against ServiceVersion; pinning an older version keeps the wire format of
+// that version, but the features it introduced and later fail with an error from the service. Among others:
+//   - 2019-02-02: SMBProperties, file leases and UploadRangeFromURL. This is the oldest supported version.
+//   - 2019-12-12: share soft delete (ShareURL.Restore and listing deleted shares) and share access tiers.
+//   - 2020-02-10: share leases, GetRangeListOptions.PrevShareSnapshot, and NFS protocols and root squash on shares.
+//   - 2020-04-08: ListFilesAndDirectoriesOptions.Include.
+//   - 2021-04-10: FileURL.Rename and DirectoryURL.Rename, which always send at least this version.
+//   - 2021-06-08: SMBProperties.FileChangeTime, whose requests always send at least this version.
+//   - 2022-11-02: OAuth with NewTokenCredential, and CopySourceAuthorization, whose requests always send at least
+//     this version.
+var SupportedServiceVersions = []string{"2019-02-02", "2019-07-07", "2019-12-12", "2020-02-10", "2020-04-08", "2021-04-10", "2021-06-08", "2022-11-02"}
+
+// newServiceVersionPolicyFactory creates a factory whose policy sends version as each request's x-ms-version.
+// Requests that need a newer version than version, such as Rename's, keep theirs.
+func newServiceVersionPolicyFactory(version string) pipeline.Factory {
+	var err error
+	supported := false
+	for _, v := range SupportedServiceVersions {
+		supported = supported || v == version
+	}
+	if !supported {
+		err = errors.New("invalid argument, PipelineOptions.ServiceVersion " + version + " isn't one of SupportedServiceVersions")
+	}
+
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			if err != nil {
+				return nil, err
+			}
+			// Versions are dates in the form YYYY-MM-DD, so they compare as strings.
+			if v := request.Header.Get("x-ms-version"); v == ServiceVersion || v < version {
+				request.Header.Set("x-ms-version", version)
+			}
+			return next.Do(ctx, request)
+		}
+	})
+}
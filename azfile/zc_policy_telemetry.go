@@ -12,25 +12,48 @@ import (
 
 // TelemetryOptions configures the telemetry policy's behavior.
 type TelemetryOptions struct {
-	// Value is a string prepended to each request's User-Agent and sent to the service.
-	// The service records the user-agent in logs for diagnostics and tracking of client requests.
+	// Value is a string prepended to each request's User-Agent and sent to the service, ahead of the SDK's own
+	// telemetry, which it never replaces. An application's name and version, e.g. "myapp/1.0", identify its requests
+	// in the service's logs for diagnostics and tracking of client requests.
 	Value string
 }
 
-// NewTelemetryPolicyFactory creates a factory that can create telemetry policy objects
-// which add telemetry information to outgoing HTTP requests.
-func NewTelemetryPolicyFactory(o TelemetryOptions) pipeline.Factory {
+// UserAgent returns the User-Agent the telemetry policy sends: o.Value, if any, followed by the SDK's version and
+// platform, e.g. "myapp/1.0 Azure-Storage/0.6.0 (go1.16.5; linux/amd64)". The ID of WithApplicationID comes first.
+func (o TelemetryOptions) UserAgent() string {
 	b := &bytes.Buffer{}
 	b.WriteString(o.Value)
 	if b.Len() > 0 {
 		b.WriteRune(' ')
 	}
 	fmt.Fprintf(b, "Azure-Storage/%s %s", serviceLibVersion, platformInfo)
-	telemetryValue := b.String()
+	return b.String()
+}
+
+// applicationIDKey is the context key of the value set by WithApplicationID.
+type applicationIDKey struct{}
+
+// WithApplicationID returns a copy of ctx with which each request's User-Agent starts with applicationID, ahead of
+// the pipeline's TelemetryOptions.Value and the SDK's own telemetry. It tags individual requests, e.g. with the
+// tenant that caused them, without a pipeline for each tag:
+//
+//	_, err := fileURL.GetProperties(azfile.WithApplicationID(ctx, "tenant-42"))
+func WithApplicationID(ctx context.Context, applicationID string) context.Context {
+	return context.WithValue(ctx, applicationIDKey{}, applicationID)
+}
+
+// NewTelemetryPolicyFactory creates a factory that can create telemetry policy objects
+// which add telemetry information to outgoing HTTP requests.
+func NewTelemetryPolicyFactory(o TelemetryOptions) pipeline.Factory {
+	telemetryValue := o.UserAgent()
 
 	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
 		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
-			request.Header.Set("User-Agent", telemetryValue)
+			if id, ok := ctx.Value(applicationIDKey{}).(string); ok && id != "" {
+				request.Header.Set("User-Agent", id+" "+telemetryValue)
+			} else {
+				request.Header.Set("User-Agent", telemetryValue)
+			}
 			return next.Do(ctx, request)
 		}
 	})
@@ -38,8 +61,8 @@ func NewTelemetryPolicyFactory(o TelemetryOptions) pipeline.Factory {
 
 // NOTE: the ONLY function that should write to this variable is this func
 var platformInfo = func() string {
-	// Azure-Storage/version (runtime; os type and version)”
-	// Azure-Storage/1.4.0 (NODE-VERSION v4.5.0; Windows_NT 10.0.14393)'
+	// Azure-Storage/version (runtime; os type and version/architecture)”
+	// Azure-Storage/0.6.0 (go1.16.5; Windows_NT/amd64)'
 	operatingSystem := runtime.GOOS // Default OS string
 	switch operatingSystem {
 	case "windows":
@@ -47,5 +70,5 @@ var platformInfo = func() string {
 	case "linux": // accept default OS info
 	case "freebsd": //  accept default OS info
 	}
-	return fmt.Sprintf("(%s; %s)", runtime.Version(), operatingSystem)
+	return fmt.Sprintf("(%s; %s/%s)", runtime.Version(), operatingSystem, runtime.GOARCH)
 }()
@@ -0,0 +1,121 @@
+package azfile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+)
+
+// Tracer starts the spans of the tracing policy. Implement it over a tracing library, like OpenTelemetry, to trace
+// the pipeline's requests; this package doesn't depend on any.
+type Tracer interface {
+	// Start starts a span named name, a child of the span ctx carries, if any, and returns a copy of ctx that
+	// carries the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of the span. value is a string, an int or an int32.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records that the span's work failed with err.
+	RecordError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// Names of the spans and attributes of the tracing policy.
+const (
+	// TracingOperationSpanName is the name of the span of an operation, which is the parent of the spans of its tries.
+	TracingOperationSpanName = "azfile.Operation"
+
+	// TracingTrySpanName is the name of the span of a single try of an operation's HTTP request.
+	TracingTrySpanName = "azfile.Try"
+
+	TracingAttributeHTTPMethod     = "http.method"
+	TracingAttributeHTTPURL        = "http.url" // With the SAS signature redacted
+	TracingAttributeHTTPStatusCode = "http.status_code"
+	TracingAttributeRequestID      = "az.request_id"  // The service's x-ms-request-id
+	TracingAttributeTry            = "az.try"         // The try's number, 1 for the first try
+	TracingAttributeRetryCount     = "az.retry_count" // The number of retries the operation made
+)
+
+// TracingOptions configures the tracing policy.
+type TracingOptions struct {
+	// Tracer, if not nil, starts a span for each operation and a child span for each try of its HTTP request.
+	Tracer Tracer
+}
+
+// newOperationSpanPolicyFactory creates a factory whose policies, placed before the retry policy, start the span of
+// an operation and pass it on in the context, so the try spans are its children.
+func newOperationSpanPolicyFactory(tracer Tracer) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			ctx, span := tracer.Start(ctx, TracingOperationSpanName)
+			defer span.End()
+			tries := 0
+			ctx = context.WithValue(ctx, traceTriesKey{}, &tries)
+			setRequestAttributes(span, request)
+
+			response, err := next.Do(ctx, request)
+			if tries > 0 {
+				span.SetAttribute(TracingAttributeRetryCount, tries-1)
+			}
+			setResponseAttributes(span, response, err)
+			return response, err
+		}
+	})
+}
+
+// traceTriesKey is the context key of the number of tries of an operation that is traced.
+type traceTriesKey struct{}
+
+// newTrySpanPolicyFactory creates a factory whose policies, placed after the retry policy, start the span of each try.
+func newTrySpanPolicyFactory(tracer Tracer) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			ctx, span := tracer.Start(ctx, TracingTrySpanName)
+			defer span.End()
+			if tries, ok := ctx.Value(traceTriesKey{}).(*int); ok {
+				*tries++
+				span.SetAttribute(TracingAttributeTry, *tries)
+			}
+			setRequestAttributes(span, request)
+
+			response, err := next.Do(ctx, request)
+			setResponseAttributes(span, response, err)
+			return response, err
+		}
+	})
+}
+
+// setRequestAttributes sets the attributes of span that describe request.
+func setRequestAttributes(span Span, request pipeline.Request) {
+	span.SetAttribute(TracingAttributeHTTPMethod, request.Method)
+	span.SetAttribute(TracingAttributeHTTPURL, redactSigInLog(request.URL.String()))
+}
+
+// setResponseAttributes sets the attributes of span that describe the response and error of its request.
+func setResponseAttributes(span Span, response pipeline.Response, err error) {
+	var httpResponse *http.Response
+	if response != nil {
+		httpResponse = response.Response()
+	}
+	if httpResponse == nil {
+		if respErr, ok := err.(ResponseError); ok {
+			httpResponse = respErr.Response()
+		}
+	}
+	if httpResponse != nil {
+		span.SetAttribute(TracingAttributeHTTPStatusCode, httpResponse.StatusCode)
+		if requestID := httpResponse.Header.Get("x-ms-request-id"); requestID != "" {
+			span.SetAttribute(TracingAttributeRequestID, requestID)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+}
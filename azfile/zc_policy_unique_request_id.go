@@ -2,21 +2,55 @@ package azfile
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
+// RequestIDOptions configures the unique request ID policy's behavior.
+type RequestIDOptions struct {
+	// VerifyEcho, if true, logs a warning for each response whose x-ms-client-request-id doesn't echo the request's,
+	// e.g. because a proxy dropped the header or the response isn't for the request.
+	VerifyEcho bool
+}
+
+// clientRequestIDKey is the context key of the value set by WithClientRequestID.
+type clientRequestIDKey struct{}
+
+// WithClientRequestID returns a copy of ctx with which requests are sent with clientRequestID as their
+// x-ms-client-request-id, in place of a generated UUID, to correlate them with upstream systems. Every request made
+// with the context, including each try of a retried request, has the same ID. The service's responses echo it,
+// and it is returned by the responses' ClientRequestID method, alongside the service's own RequestID.
+func WithClientRequestID(ctx context.Context, clientRequestID string) context.Context {
+	return context.WithValue(ctx, clientRequestIDKey{}, clientRequestID)
+}
+
 // NewUniqueRequestIDPolicyFactory creates a UniqueRequestIDPolicyFactory object
 // that sets the request's x-ms-client-request-id header if it doesn't already exist.
 func NewUniqueRequestIDPolicyFactory() pipeline.Factory {
+	return newUniqueRequestIDPolicyFactory(RequestIDOptions{})
+}
+
+// newUniqueRequestIDPolicyFactory is NewUniqueRequestIDPolicyFactory with options.
+func newUniqueRequestIDPolicyFactory(o RequestIDOptions) pipeline.Factory {
 	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
 		// This is Policy's Do method:
 		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
 			id := request.Header.Get(xMsClientRequestID)
 			if id == "" { // Add a unique request ID if the caller didn't specify one already
-				request.Header.Set(xMsClientRequestID, newUUID().String())
+				if id, _ = ctx.Value(clientRequestIDKey{}).(string); id == "" {
+					id = newUUID().String()
+				}
+				request.Header.Set(xMsClientRequestID, id)
+			}
+			response, err := next.Do(ctx, request)
+			if o.VerifyEcho && response != nil && response.Response() != nil && po.ShouldLog(pipeline.LogWarning) {
+				if echo := response.Response().Header.Get(xMsClientRequestID); echo != id {
+					po.Log(pipeline.LogWarning, fmt.Sprintf("The response's %s %q doesn't match the request's %q",
+						xMsClientRequestID, echo, id))
+				}
 			}
-			return next.Do(ctx, request)
+			return response, err
 		}
 	})
 }
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
 )
 
 // HTTPGetter is a function type that refers to a method that performs an HTTP GET operation.
@@ -23,8 +25,9 @@ type HTTPGetterInfo struct {
 	// the end offset when creating the HTTP GET request's Range header
 	Count int64
 
-	// ETag specifies the resource's etag that should be used when creating
-	// the HTTP GET request's If-Match header
+	// ETag specifies the resource's etag that a retried HTTP GET request's
+	// response is expected to match. The File service does not evaluate If-Match
+	// on Get File, so getters should compare the ETag of the response instead.
 	ETag ETag
 }
 
@@ -41,6 +44,8 @@ type RetryReaderOptions struct {
 	doInjectErrorRound int
 
 	// NotifyFailedRead is called, if non-nil, after any failure to read. Expected usage is diagnostic logging.
+	// failureCount is one-based, offset and count describe the range that remains to be read, and willRetry
+	// reports whether another ranged GET will be issued.
 	NotifyFailedRead FailedReadNotifier
 
 	// TreatEarlyCloseAsError can be set to true to prevent retries after "read on closed response body". By default,
@@ -53,6 +58,15 @@ type RetryReaderOptions struct {
 	// from the same "thread" (goroutine) as Read.  Concurrent Close calls from other goroutines may instead produce network errors
 	// which will be retried.
 	TreatEarlyCloseAsError bool
+
+	// ValidateContentMD5, if true, makes DownloadResponse's Body check the data it reads against the response's
+	// ContentMD5, if the response has one. A download returns the MD5 of the range it reads if rangeGetContentMD5
+	// is passed to Download; otherwise only a download of the whole file returns one, the file's stored ContentMD5.
+	ValidateContentMD5 bool
+
+	// Progress, if not nil, is invoked as DownloadResponse's Body is read, with the number of bytes read so far.
+	// Bytes that a retry downloads again aren't counted twice, as only the bytes returned to the caller are counted.
+	Progress pipeline.ProgressReceiver
 }
 
 // retryReader implements io.ReaderCloser methods.
@@ -175,6 +189,10 @@ func (s *retryReader) wasRetryableEarlyClose(err error) bool {
 
 const ReadOnClosedBodyMessage = "read on closed response body"
 
+// FileModifiedDuringReadMessage ends the error returned by a retrying download body when the file's ETag changed
+// between the original download and a retried one.
+const FileModifiedDuringReadMessage = "file was modified during the read"
+
 func (s *retryReader) Close() error {
 	s.responseMu.Lock()
 	defer s.responseMu.Unlock()
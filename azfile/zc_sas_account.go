@@ -21,12 +21,20 @@ type AccountSASSignatureValues struct {
 	ResourceTypes string      `param:"srt"` // Create by initializing AccountSASResourceTypes and then call String()
 }
 
+// Sign signs the signature values with the account's shared key credential; it's the same as NewSASQueryParameters.
+func (v AccountSASSignatureValues) Sign(sharedKeyCredential *SharedKeyCredential) (SASQueryParameters, error) {
+	return v.NewSASQueryParameters(sharedKeyCredential)
+}
+
 // NewSASQueryParameters uses an account's shared key credential to sign this signature values to produce
 // the proper SAS query parameters.
 func (v AccountSASSignatureValues) NewSASQueryParameters(sharedKeyCredential *SharedKeyCredential) (SASQueryParameters, error) {
 	// https://docs.microsoft.com/en-us/rest/api/storageservices/Constructing-an-Account-SAS
-	if v.ExpiryTime.IsZero() || v.Permissions == "" || v.ResourceTypes == "" || v.Services == "" {
-		return SASQueryParameters{}, errors.New("Account SAS is missing at least one of these: ExpiryTime, Permissions, Service, or ResourceType")
+	if sharedKeyCredential == nil {
+		return SASQueryParameters{}, errors.New("sharedKeyCredential can't be nil")
+	}
+	if err := v.Validate(); err != nil {
+		return SASQueryParameters{}, err
 	}
 	if v.Version == "" {
 		v.Version = SASVersion
@@ -37,6 +45,18 @@ func (v AccountSASSignatureValues) NewSASQueryParameters(sharedKeyCredential *Sh
 	}
 	v.Permissions = perms.String()
 
+	// Make sure the services and resource types characters are in the correct order
+	services := &AccountSASServices{}
+	if err := services.Parse(v.Services); err != nil {
+		return SASQueryParameters{}, err
+	}
+	v.Services = services.String()
+	resourceTypes := &AccountSASResourceTypes{}
+	if err := resourceTypes.Parse(v.ResourceTypes); err != nil {
+		return SASQueryParameters{}, err
+	}
+	v.ResourceTypes = resourceTypes.String()
+
 	startTime, expiryTime := FormatTimesForSASSigning(v.StartTime, v.ExpiryTime)
 
 	stringToSign := strings.Join([]string{
@@ -72,6 +92,26 @@ func (v AccountSASSignatureValues) NewSASQueryParameters(sharedKeyCredential *Sh
 	return p, nil
 }
 
+// Validate returns an error if the service would reject a SAS with v's values: if ExpiryTime, Permissions, Services
+// or ResourceTypes is missing, if one of them has an invalid character, if Protocol isn't one of the SASProtocol
+// constants, if IPRange isn't a range of IPv4 addresses, or if StartTime isn't before ExpiryTime.
+// NewSASQueryParameters calls Validate, and refuses to sign invalid values.
+func (v AccountSASSignatureValues) Validate() error {
+	if v.ExpiryTime.IsZero() || v.Permissions == "" || v.ResourceTypes == "" || v.Services == "" {
+		return errors.New("Account SAS is missing at least one of these: ExpiryTime, Permissions, Service, or ResourceType")
+	}
+	if err := (&AccountSASPermissions{}).Parse(v.Permissions); err != nil {
+		return err
+	}
+	if err := (&AccountSASServices{}).Parse(v.Services); err != nil {
+		return err
+	}
+	if err := (&AccountSASResourceTypes{}).Parse(v.ResourceTypes); err != nil {
+		return err
+	}
+	return validateSASValues(v.Protocol, v.IPRange, v.StartTime, v.ExpiryTime)
+}
+
 // The AccountSASPermissions type simplifies creating the permissions string for an Azure Storage Account SAS.
 // Initialize an instance of this type and then call its String method to set AccountSASSignatureValues's Permissions field.
 type AccountSASPermissions struct {
@@ -131,7 +171,7 @@ func (p *AccountSASPermissions) Parse(s string) error {
 		case 'p':
 			p.Process = true
 		default:
-			return fmt.Errorf("Invalid permission character: '%v'", r)
+			return fmt.Errorf("Invalid permission character: '%c'", r)
 		}
 	}
 	return nil
@@ -171,7 +211,7 @@ func (a *AccountSASServices) Parse(s string) error {
 		case 'f':
 			a.File = true
 		default:
-			return fmt.Errorf("Invalid service character: '%v'", r)
+			return fmt.Errorf("Invalid service character: '%c'", r)
 		}
 	}
 	return nil
@@ -211,7 +251,7 @@ func (rt *AccountSASResourceTypes) Parse(s string) error {
 		case 'o':
 			rt.Object = true
 		default:
-			return fmt.Errorf("Invalid resource type: '%v'", r)
+			return fmt.Errorf("Invalid resource type: '%c'", r)
 		}
 	}
 	return nil
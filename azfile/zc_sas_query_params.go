@@ -2,6 +2,7 @@ package azfile
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"net/url"
 	"strings"
@@ -72,7 +73,7 @@ func parseSASTimeString(val string) (t time.Time, timeFormat string, err error)
 
 // A SASQueryParameters object represents the components that make up an Azure Storage SAS' query parameters.
 // You parse a map of query parameters into its fields by calling NewSASQueryParameters(). You add the components
-// to a query parameter map by calling AddToValues().
+// to a query string by calling Encode().
 // NOTE: Changing any field requires computing a new SAS signature using a XxxSASSignatureValues type.
 //
 // This type defines the components used by all Azure Storage resources (Containers, Blobs, Files, & Queues).
@@ -120,6 +121,13 @@ func (p *SASQueryParameters) ExpiryTime() time.Time {
 	return p.expiryTime
 }
 
+// ValidAt reports whether t is within the SAS's validity window: at or after its StartTime, if it has one, and before
+// its ExpiryTime, if it has one. A SAS whose times come from a stored access policy may be valid at other times. To
+// check a SAS against a Clock, use p.ValidAt(clock.Now()).
+func (p *SASQueryParameters) ValidAt(t time.Time) bool {
+	return (p.startTime.IsZero() || !t.Before(p.startTime)) && (p.expiryTime.IsZero() || t.Before(p.expiryTime))
+}
+
 func (p *SASQueryParameters) IPRange() IPRange {
 	return p.ipRange
 }
@@ -160,13 +168,16 @@ func (p *SASQueryParameters) ContentType() string {
 }
 
 // IPRange represents a SAS IP range's start IP and (optionally) end IP.
+// The service accepts IPv4 addresses only; an IPv4 address is rendered in dotted decimal form whether it is held
+// in its 4-byte or 16-byte representation.
 type IPRange struct {
 	Start net.IP // Not specified if length = 0
 	End   net.IP // Not specified if length = 0
 }
 
-// String returns a string representation of an IPRange.
-func (ipr *IPRange) String() string {
+// String returns a string representation of an IPRange: "" if Start isn't specified, "start" if End isn't specified,
+// and "start-end" otherwise. This is the value of a SAS's sip query parameter.
+func (ipr IPRange) String() string {
 	if len(ipr.Start) == 0 {
 		return ""
 	}
@@ -177,11 +188,34 @@ func (ipr *IPRange) String() string {
 	return start + "-" + ipr.End.String()
 }
 
-// NewSASQueryParameters creates and initializes a SASQueryParameters object based on the
-// query parameter map's passed-in values. If deleteSASParametersFromValues is true,
-// all SAS-related query parameters are removed from the passed-in map. If
-// deleteSASParametersFromValues is false, the map passed-in map is unaltered.
-func newSASQueryParameters(values url.Values, deleteSASParametersFromValues bool) SASQueryParameters {
+// validateSASValues returns an error if protocol isn't one of the SASProtocol constants, if ipRange has an End but no
+// Start or isn't IPv4, or if startTime isn't before expiryTime.
+func validateSASValues(protocol SASProtocol, ipRange IPRange, startTime, expiryTime time.Time) error {
+	switch protocol {
+	case "", SASProtocolHTTPS, SASProtocolHTTPSandHTTP:
+	default:
+		return fmt.Errorf("invalid argument, SAS protocol %q isn't %q or %q", protocol, SASProtocolHTTPS, SASProtocolHTTPSandHTTP)
+	}
+	if len(ipRange.Start) == 0 && len(ipRange.End) != 0 {
+		return errors.New("invalid argument, the SAS's IPRange has an End but no Start")
+	}
+	for _, ip := range []net.IP{ipRange.Start, ipRange.End} {
+		if len(ip) != 0 && ip.To4() == nil {
+			return fmt.Errorf("invalid argument, the SAS's IPRange has %s, which isn't an IPv4 address", ip)
+		}
+	}
+	if !startTime.IsZero() && !expiryTime.IsZero() && !startTime.Before(expiryTime) {
+		st, se := FormatTimesForSASSigning(startTime, expiryTime)
+		return fmt.Errorf("invalid argument, the SAS's StartTime %s isn't before its ExpiryTime %s", st, se)
+	}
+	return nil
+}
+
+// NewSASQueryParameters creates and initializes a SASQueryParameters object from the SAS query parameters in values.
+// If deleteSASParametersFromValues is true, they are removed from values; otherwise values is left unaltered. The
+// result's Encode reproduces the parameters, so a SAS parsed from a URL can be inspected, for example for its
+// ExpiryTime, and then appended to a URL again.
+func NewSASQueryParameters(values url.Values, deleteSASParametersFromValues bool) SASQueryParameters {
 	p := SASQueryParameters{}
 	for k, v := range values {
 		val := v[0]
@@ -24,6 +24,9 @@ const (
 	// ServiceCodeConditionNotMet means the condition specified in the conditional header(s) was not met for a read/write operation (304/412).
 	ServiceCodeConditionNotMet ServiceCodeType = "ConditionNotMet"
 
+	// ServiceCodeCrc64Mismatch means the CRC64 value specified in the request did not match the CRC64 value calculated by the server (400).
+	ServiceCodeCrc64Mismatch ServiceCodeType = "Crc64Mismatch"
+
 	// ServiceCodeEmptyMetadataKey means the key for one of the metadata key-value pairs is empty (400).
 	ServiceCodeEmptyMetadataKey ServiceCodeType = "EmptyMetadataKey"
 
@@ -3,9 +3,12 @@ package azfile
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-pipeline-go/pipeline"
 )
@@ -26,6 +29,55 @@ type StorageError interface {
 
 	// ServiceCode returns a service error code. Your code can use this to make error recovery decisions.
 	ServiceCode() ServiceCodeType
+
+	// Details returns the additional information the service returned about the error, keyed by XML element name;
+	// for example the AuthenticationErrorDetail of an authentication failure, or the QueryParameterName and
+	// HeaderName of a validation failure. The caller may examine the map but should not modify it.
+	Details() map[string]string
+
+	// RequestID returns the ID the service assigned to the failed request, which identifies it in the service's logs.
+	RequestID() string
+
+	// Timestamp returns when the service failed the request, or the zero time if the service didn't say.
+	Timestamp() time.Time
+}
+
+// IsNotFound reports whether err is, or wraps, a StorageError for a resource that doesn't exist: one with HTTP status
+// 404 (Not Found), or a ServiceCode like ServiceCodeResourceNotFound, ServiceCodeShareNotFound or ServiceCodeParentNotFound.
+func IsNotFound(err error) bool {
+	return isStorageError(err, http.StatusNotFound,
+		ServiceCodeResourceNotFound, ServiceCodeShareNotFound, ServiceCodeParentNotFound)
+}
+
+// IsConflict reports whether err is, or wraps, a StorageError for a request that conflicts with the resource's
+// state: one with HTTP status 409 (Conflict), or a ServiceCode like ServiceCodeResourceAlreadyExists or
+// ServiceCodeShareAlreadyExists.
+func IsConflict(err error) bool {
+	return isStorageError(err, http.StatusConflict,
+		ServiceCodeResourceAlreadyExists, ServiceCodeShareAlreadyExists, ServiceCodeShareBeingDeleted)
+}
+
+// IsPreconditionFailed reports whether err is, or wraps, a StorageError for a request whose access conditions aren't
+// met: one with HTTP status 412 (Precondition Failed), or ServiceCodeConditionNotMet.
+func IsPreconditionFailed(err error) bool {
+	return isStorageError(err, http.StatusPreconditionFailed, ServiceCodeConditionNotMet)
+}
+
+// isStorageError reports whether err is, or wraps, a StorageError with status statusCode or one of serviceCodes.
+func isStorageError(err error, statusCode int, serviceCodes ...ServiceCodeType) bool {
+	var storageErr StorageError
+	if !errors.As(err, &storageErr) {
+		return false
+	}
+	if resp := storageErr.Response(); resp != nil && resp.StatusCode == statusCode {
+		return true
+	}
+	for _, code := range serviceCodes {
+		if storageErr.ServiceCode() == code {
+			return true
+		}
+	}
+	return false
 }
 
 // storageError is the internal struct that implements the public StorageError interface.
@@ -33,6 +85,8 @@ type storageError struct {
 	responseError
 	serviceCode ServiceCodeType
 	details     map[string]string
+	requestID   string
+	timestamp   time.Time
 }
 
 // newStorageError creates an error object that implements the error interface.
@@ -44,19 +98,52 @@ func newStorageError(cause error, response *http.Response, description string) e
 			description: description,
 		},
 		serviceCode: ServiceCodeType(response.Header.Get("x-ms-error-code")),
+		requestID:   response.Header.Get("x-ms-request-id"),
+		timestamp:   parseErrorDate(response.Header.Get("Date")),
 	}
 }
 
+// parseErrorDate returns the time of a Date header, or the zero time if there is none.
+func parseErrorDate(date string) time.Time {
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // ServiceCode returns service-error information. The caller may examine these values but should not modify any of them.
 func (e *storageError) ServiceCode() ServiceCodeType {
 	return e.serviceCode
 }
 
+// Details returns the additional information the service returned about the error, keyed by XML element name.
+func (e *storageError) Details() map[string]string {
+	return e.details
+}
+
+// RequestID returns the ID the service assigned to the failed request.
+func (e *storageError) RequestID() string {
+	return e.requestID
+}
+
+// Timestamp returns when the service failed the request, or the zero time if the service didn't say.
+func (e *storageError) Timestamp() time.Time {
+	return e.timestamp
+}
+
 // Error implements the error interface's Error method to return a string representation of the error.
 func (e *storageError) Error() string {
 	b := &bytes.Buffer{}
 	fmt.Fprintf(b, "===== RESPONSE ERROR (ServiceCode=%s) =====\n", e.serviceCode)
-	fmt.Fprintf(b, "Description=%s, Details: ", e.description)
+	fmt.Fprintf(b, "Description=%s\n", e.description)
+	if e.requestID != "" {
+		fmt.Fprintf(b, "RequestID=%s\n", e.requestID)
+	}
+	if !e.timestamp.IsZero() {
+		fmt.Fprintf(b, "Timestamp=%s\n", e.timestamp.Format(time.RFC3339Nano))
+	}
+	b.WriteString("Details: ")
 	if len(e.details) == 0 {
 		b.WriteString("(none)\n")
 	} else {
@@ -87,6 +174,8 @@ func (e *storageError) Temporary() bool {
 }
 
 // UnmarshalXML performs custom unmarshalling of XML-formatted Azure storage request errors.
+// The Message element is the description, less the RequestId and Time lines the service appends to it. Every other
+// element with text, at any depth, is a detail.
 func (e *storageError) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
 	tokName := ""
 	var t xml.Token
@@ -94,22 +183,46 @@ func (e *storageError) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err
 		switch tt := t.(type) {
 		case xml.StartElement:
 			tokName = tt.Name.Local
-			break
+		case xml.EndElement:
+			tokName = ""
 		case xml.CharData:
-			switch tokName {
-			case "Message":
-				e.description = string(tt)
+			text := strings.TrimSpace(string(tt))
+			switch {
+			case tokName == "" || text == "":
+			case tokName == "Message":
+				e.description = e.parseMessage(text)
 			default:
 				if e.details == nil {
 					e.details = map[string]string{}
 				}
-				e.details[tokName] = string(tt)
+				e.details[tokName] = text
 			}
 		}
 	}
 	return nil
 }
 
+// parseMessage takes the request ID and time from the trailing lines of message, and returns the rest.
+func (e *storageError) parseMessage(message string) string {
+	lines := strings.Split(message, "\n")
+	for len(lines) > 1 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if strings.HasPrefix(last, "RequestId:") {
+			if e.requestID == "" {
+				e.requestID = strings.TrimPrefix(last, "RequestId:")
+			}
+		} else if strings.HasPrefix(last, "Time:") {
+			if t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(last, "Time:")); err == nil {
+				e.timestamp = t // More precise than the Date header
+			}
+		} else {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 // Make it clear that a panic occurred due to sanity checks failing
 // This means the user should correct the programming errors
 func sanityCheckFailed(msg string) {
@@ -0,0 +1,76 @@
+package azfile
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-ieproxy"
+)
+
+const (
+	// DefaultMaxIdleConnsPerHost is TransportOptions' default MaxIdleConnsPerHost. It keeps enough connections open
+	// to a storage account for the package's parallel transfers to reuse, instead of redialing, them.
+	DefaultMaxIdleConnsPerHost = 500
+
+	// DefaultIdleConnTimeout is TransportOptions' default IdleConnTimeout.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// TransportOptions tunes the connection pool of the http.Client with which a pipeline sends its requests, when
+// PipelineOptions.HTTPSender is nil. Pipelines with equal TransportOptions share one client, and so one pool.
+type TransportOptions struct {
+	// MaxIdleConns limits the number of idle (keep-alive) connections across all hosts; 0 means no limit.
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits the number of idle (keep-alive) connections to each host. A connection that finishes
+	// a request while the limit is reached is closed, and the next request has to dial a new one, so the limit should
+	// be at least the number of requests sent to an account in parallel. 0 means DefaultMaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the number of connections to each host, idle or not; a request that would exceed it
+	// waits for a connection. 0 means no limit.
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept open. 0 means DefaultIdleConnTimeout.
+	IdleConnTimeout time.Duration
+}
+
+// defaults returns o with its zero fields replaced by their default values.
+func (o TransportOptions) defaults() TransportOptions {
+	if o.MaxIdleConnsPerHost == 0 {
+		o.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout == 0 {
+		o.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	return o
+}
+
+// httpClients holds the *http.Client for each TransportOptions, so that pipelines don't each get their own pool.
+var httpClients sync.Map
+
+// httpClient returns the shared http.Client for o.
+func (o TransportOptions) httpClient() *http.Client {
+	o = o.defaults()
+	if client, ok := httpClients.Load(o); ok {
+		return client.(*http.Client)
+	}
+	client, _ := httpClients.LoadOrStore(o, &http.Client{
+		Transport: &http.Transport{
+			Proxy: ieproxy.GetProxyFunc(),
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          o.MaxIdleConns,
+			MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       o.MaxConnsPerHost,
+			IdleConnTimeout:       o.IdleConnTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	})
+	return client.(*http.Client)
+}
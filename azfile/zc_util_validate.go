@@ -1,6 +1,7 @@
 package azfile
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -42,27 +43,64 @@ func toRange(offset int64, count int64) *string {
 	return &r
 }
 
-func validateSeekableStreamAt0AndGetCount(body io.ReadSeeker) int64 {
-	if body == nil { // nil body's are "logically" seekable to 0 and are 0 bytes long
-		return 0
+// optionalString returns a pointer to s, or nil if s is "", for the optional headers of the generated operations.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
-	validateSeekableStreamAt0(body)
-	count, err := body.Seek(0, io.SeekEnd)
+	return &s
+}
+
+// seekableStreamSection returns the data of body from its current position to its end, and the data's length. The
+// returned ReadSeeker's position 0 is where the data starts, so that rewinding a body for a retry doesn't resend any
+// data before it. It fails, rather than the request failing later, if body can't seek, or is positioned beyond its end.
+func seekableStreamSection(body io.ReadSeeker) (io.ReadSeeker, int64, error) {
+	start, err := body.Seek(0, io.SeekCurrent)
 	if err != nil {
-		sanityCheckFailed(err.Error())
+		return nil, 0, fmt.Errorf("invalid argument, body must be seekable: %v", err)
+	}
+	end, err := body.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = body.Seek(start, io.SeekStart)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid argument, body must be seekable: %v", err)
+	}
+	if start > end {
+		return nil, 0, fmt.Errorf("invalid argument, body's position %d is beyond its end at %d", start, end)
+	}
+	if start == 0 {
+		return body, end, nil
 	}
-	body.Seek(0, io.SeekStart)
-	return count
+	return &sectionReadSeeker{body: body, start: start, count: end - start}, end - start, nil
 }
 
-func validateSeekableStreamAt0(body io.ReadSeeker) {
-	if body == nil { // nil body's are "logically" seekable to 0
-		return
-	}
-	if pos, err := body.Seek(0, io.SeekCurrent); pos != 0 || err != nil {
+// sectionReadSeeker is the section of a ReadSeeker that starts at start and is count bytes long.
+type sectionReadSeeker struct {
+	body         io.ReadSeeker
+	start, count int64
+}
+
+func (s *sectionReadSeeker) Read(p []byte) (int, error) {
+	return s.body.Read(p)
+}
+
+func (s *sectionReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		offset += s.start
+	case io.SeekEnd:
+		offset += s.start + s.count
+	case io.SeekCurrent:
+		pos, err := s.body.Seek(0, io.SeekCurrent)
 		if err != nil {
-			sanityCheckFailed(err.Error())
+			return 0, err
 		}
-		sanityCheckFailed("stream must be set to position 0")
+		offset += pos
+	}
+	if offset < s.start {
+		return 0, errors.New("seek before the start of the section")
 	}
+	pos, err := s.body.Seek(offset, io.SeekStart)
+	return pos - s.start, err
 }
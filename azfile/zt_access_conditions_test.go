@@ -0,0 +1,146 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type accessConditionsSuite struct{}
+
+var _ = chk.Suite(&accessConditionsSuite{})
+
+// newTestConditionsPipeline returns a pipeline that answers GetProperties requests, the HEAD or GET ones, with etag
+// and lastModified, or with 404 (Not Found) if etag is ETagNone, and other requests with status. It records each request's method in *methods.
+func newTestConditionsPipeline(etag ETag, lastModified time.Time, status int, methods *[]string) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				*methods = append(*methods, request.Method)
+				header := http.Header{}
+				statusCode := status
+				if request.Method == http.MethodHead || request.Method == http.MethodGet {
+					statusCode = http.StatusOK
+					if etag == ETagNone {
+						statusCode = http.StatusNotFound
+						header.Set("x-ms-error-code", string(ServiceCodeResourceNotFound))
+					}
+					header.Set("ETag", string(etag))
+					header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+				}
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: statusCode,
+					Header:     header,
+					Body:       http.NoBody,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *accessConditionsSuite) TestModifiedAccessConditionsMet(c *chk.C) {
+	lastModified := time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)
+	for ac, met := range map[ModifiedAccessConditions]bool{
+		{}:                              true,
+		{IfMatch: "etag"}:               true,
+		{IfMatch: ETagAny}:              true,
+		{IfMatch: "other"}:              false,
+		{IfNoneMatch: "other"}:          true,
+		{IfNoneMatch: "etag"}:           false,
+		{IfNoneMatch: ETagAny}:          false,
+		{IfModifiedSince: lastModified}: false,
+		{IfModifiedSince: lastModified.Add(-time.Second)}:   true,
+		{IfUnmodifiedSince: lastModified}:                   true,
+		{IfUnmodifiedSince: lastModified.Add(-time.Second)}: false,
+	} {
+		c.Assert(ac.met("etag", lastModified), chk.Equals, met, chk.Commentf("%+v", ac))
+	}
+}
+
+func (s *accessConditionsSuite) TestFileAccessConditions(c *chk.C) {
+	var methods []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestConditionsPipeline("etag", time.Now(), http.StatusOK, &methods))
+
+	// The file's properties are only read when a condition is set.
+	_, err := fileURL.SetMetadata(context.Background(), Metadata{"foo": "bar"}, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodPut})
+
+	methods = nil
+	ac := FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfMatch: "etag"}}
+	_, err = fileURL.SetMetadata(context.Background(), Metadata{"foo": "bar"}, ac)
+	c.Assert(err, chk.IsNil)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead, http.MethodPut})
+
+	// Another writer has changed the file.
+	methods = nil
+	ac.IfMatch = "other"
+	_, err = fileURL.SetMetadata(context.Background(), Metadata{"foo": "bar"}, ac)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	c.Assert(err.(StorageError).Response().StatusCode, chk.Equals, http.StatusPreconditionFailed)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead})
+	_, err = fileURL.UploadRange(context.Background(), 0, bytes.NewReader([]byte{1}), nil, UploadRangeOptions{FileAccessConditions: ac})
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	_, err = fileURL.SetProperties(context.Background(), FileHTTPHeaders{}, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	_, err = fileURL.Delete(context.Background(), ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead, http.MethodHead, http.MethodHead, http.MethodHead})
+
+	// The file exists, so it isn't created.
+	ac = FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfNoneMatch: ETagAny}}
+	_, err = fileURL.Create(context.Background(), 0, FileHTTPHeaders{}, nil, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+}
+
+func (s *accessConditionsSuite) TestFileAccessConditionsNotFound(c *chk.C) {
+	var methods []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestConditionsPipeline(ETagNone, time.Time{}, http.StatusCreated, &methods))
+
+	ac := FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfNoneMatch: ETagAny}}
+	_, err := fileURL.Create(context.Background(), 0, FileHTTPHeaders{}, nil, ac)
+	c.Assert(err, chk.IsNil)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead, http.MethodPut})
+
+	methods = nil
+	ac = FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfMatch: ETagAny}}
+	_, err = fileURL.Create(context.Background(), 0, FileHTTPHeaders{}, nil, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead})
+
+	// Operations on a file that doesn't exist fail as they would without conditions.
+	_, err = fileURL.SetMetadata(context.Background(), nil, FileAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfNoneMatch: "etag"}})
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeResourceNotFound)
+}
+
+func (s *accessConditionsSuite) TestShareAccessConditions(c *chk.C) {
+	var methods []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	lastModified := time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)
+	shareURL := NewShareURL(*u, newTestConditionsPipeline("etag", lastModified, http.StatusOK, &methods))
+
+	ac := ShareAccessConditions{ModifiedAccessConditions: ModifiedAccessConditions{IfUnmodifiedSince: lastModified}}
+	_, err := shareURL.SetMetadata(context.Background(), Metadata{"foo": "bar"}, ac)
+	c.Assert(err, chk.IsNil)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodGet, http.MethodPut})
+
+	ac.IfUnmodifiedSince = lastModified.Add(-time.Hour)
+	_, err = shareURL.SetMetadata(context.Background(), Metadata{"foo": "bar"}, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	_, err = shareURL.SetQuota(context.Background(), 1, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	_, err = shareURL.SetProperties(context.Background(), ShareSetPropertiesOptions{QuotaInGB: 1, ShareAccessConditions: ac})
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+	_, err = shareURL.Delete(context.Background(), DeleteSnapshotsOptionNone, ac)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeConditionNotMet)
+}
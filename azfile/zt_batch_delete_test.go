@@ -0,0 +1,176 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type batchDeleteSuite struct{}
+
+var _ = chk.Suite(&batchDeleteSuite{})
+
+// newTestDeletePipeline returns a pipeline that records the name of each file deleted in *deleted and answers with
+// 404 for the files named "missing", and 202 for the others. It records the most deletes in flight in *maxInFlight.
+func newTestDeletePipeline(deleted *[]string, maxInFlight *int) pipeline.Pipeline {
+	mu := sync.Mutex{}
+	inFlight := 0
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				name := path.Base(request.URL.Path)
+				*deleted = append(*deleted, name)
+				if inFlight++; inFlight > *maxInFlight {
+					*maxInFlight = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+
+				if name == "missing" {
+					response := newStatusResponse(request, http.StatusNotFound)
+					response.Response().Header.Set("x-ms-error-code", string(ServiceCodeResourceNotFound))
+					return response, nil
+				}
+				return newStatusResponse(request, http.StatusAccepted), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *batchDeleteSuite) TestDeleteFilesContinueOnError(c *chk.C) {
+	var deleted []string
+	maxInFlight := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dir := NewDirectoryURL(*u, newTestDeletePipeline(&deleted, &maxInFlight))
+	names := []string{"a", "missing", "b", "c", "d", "e"}
+
+	results, err := DeleteFiles(context.Background(), dir, names, BatchDeleteOptions{Parallelism: 2, ContinueOnError: true})
+	c.Assert(err, chk.NotNil)
+	c.Assert(deleted, chk.HasLen, len(names))
+	c.Assert(maxInFlight <= 2, chk.Equals, true)
+	c.Assert(results, chk.HasLen, len(names))
+	for i, result := range results {
+		c.Assert(result.Name, chk.Equals, names[i])
+		c.Assert(result.Error != nil, chk.Equals, result.Name == "missing")
+	}
+
+	batchErr, ok := err.(*BatchDeleteError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(batchErr.Count, chk.Equals, len(names))
+	c.Assert(batchErr.Failures, chk.HasLen, 1)
+	c.Assert(batchErr.Failures[0].Name, chk.Equals, "missing")
+	c.Assert(IsNotFound(err), chk.Equals, true) // Through Unwrap
+
+	_, err = DeleteFiles(context.Background(), dir, []string{"a", "b"}, BatchDeleteOptions{})
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *batchDeleteSuite) TestDeleteFilesStopOnError(c *chk.C) {
+	var deleted []string
+	maxInFlight := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dir := NewDirectoryURL(*u, newTestDeletePipeline(&deleted, &maxInFlight))
+
+	results, err := DeleteFiles(context.Background(), dir, []string{"a", "missing", "b", "c"}, BatchDeleteOptions{Parallelism: 1})
+	c.Assert(err, chk.NotNil)
+	c.Assert(deleted, chk.DeepEquals, []string{"a", "missing"})
+	c.Assert(results[0].Error, chk.IsNil)
+	c.Assert(IsNotFound(results[1].Error), chk.Equals, true)
+	c.Assert(results[2].Error, chk.Equals, context.Canceled)
+	c.Assert(results[3].Error, chk.Equals, context.Canceled)
+	c.Assert(err.(*BatchDeleteError).Failures, chk.HasLen, 3)
+
+	// A done context deletes nothing.
+	deleted = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = DeleteFiles(ctx, dir, []string{"a", "b"}, BatchDeleteOptions{ContinueOnError: true})
+	c.Assert(errors.Is(err, context.Canceled), chk.Equals, true)
+	c.Assert(deleted, chk.HasLen, 0)
+	c.Assert(results[1].Error, chk.Equals, context.Canceled)
+}
+
+// newTestTreePipeline returns a pipeline that serves a share whose directories list the entries of listings, keyed
+// by the directory's path. It records the method and path of each request in *sent, and fails the delete of the path
+// blocked with ServiceCodeSharingViolation.
+func newTestTreePipeline(listings map[string]string, blocked string, sent *[]string) pipeline.Pipeline {
+	mu := sync.Mutex{}
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				*sent = append(*sent, request.Method+" "+request.URL.Path)
+				mu.Unlock()
+				response := newStatusResponse(request, http.StatusAccepted)
+				switch {
+				case request.Method == http.MethodGet:
+					response = newStatusResponse(request, http.StatusOK)
+					response.Response().Body = ioutil.NopCloser(strings.NewReader(
+						"<EnumerationResults><Entries>" + listings[request.URL.Path] + "</Entries><NextMarker/></EnumerationResults>"))
+				case request.Method == http.MethodPut:
+					response = newStatusResponse(request, http.StatusOK)
+				case request.URL.Path == blocked:
+					response = newStatusResponse(request, http.StatusConflict)
+					response.Response().Header.Set("x-ms-error-code", string(ServiceCodeSharingViolation))
+				}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *batchDeleteSuite) TestDeleteRecursive(c *chk.C) {
+	listings := map[string]string{
+		"/share/dir":           `<File><Name>f1</Name></File><Directory><Name>sub</Name></Directory><File><Name>f2</Name></File>`,
+		"/share/dir/sub":       `<Directory><Name>empty</Name></Directory><File><Name>f3</Name></File>`,
+		"/share/dir/sub/empty": ``,
+	}
+	var sent []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dir := NewDirectoryURL(*u, newTestTreePipeline(listings, "", &sent))
+
+	err := dir.DeleteRecursive(context.Background(), DeleteRecursiveOptions{ForceCloseHandles: true, Parallelism: 1})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent, chk.DeepEquals, []string{
+		"PUT /share/dir", // Closes the handles
+		"GET /share/dir", "DELETE /share/dir/f1", "DELETE /share/dir/f2",
+		"GET /share/dir/sub", "DELETE /share/dir/sub/f3",
+		"GET /share/dir/sub/empty", "DELETE /share/dir/sub/empty",
+		"DELETE /share/dir/sub",
+		"DELETE /share/dir",
+	})
+
+	// A file with an open handle blocks the delete of its directories.
+	sent = nil
+	dir = NewDirectoryURL(*u, newTestTreePipeline(listings, "/share/dir/sub/f3", &sent))
+	err = dir.DeleteRecursive(context.Background(), DeleteRecursiveOptions{})
+	c.Assert(err, chk.NotNil)
+	recursiveErr, ok := err.(*DeleteRecursiveError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(recursiveErr.Path, chk.Equals, "dir/sub/f3")
+	c.Assert(IsConflict(err), chk.Equals, true)
+	c.Assert(sent[len(sent)-1], chk.Equals, "DELETE /share/dir/sub/f3")
+
+	// A done context stops the walk before it starts.
+	sent = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = dir.DeleteRecursive(ctx, DeleteRecursiveOptions{})
+	c.Assert(errors.Is(err, context.Canceled), chk.Equals, true)
+	c.Assert(sent, chk.HasLen, 0)
+}
@@ -0,0 +1,129 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type bufferManagerSuite struct{}
+
+var _ = chk.Suite(&bufferManagerSuite{})
+
+// newTestStreamUploadPipeline returns a pipeline that accepts the requests of UploadStreamToAzureFile, and writes the
+// body of each range uploaded into file, if file isn't nil.
+func newTestStreamUploadPipeline(file []byte) pipeline.Pipeline {
+	mu := sync.Mutex{}
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				switch request.URL.Query().Get("comp") {
+				case "properties":
+					return newStatusResponse(request, http.StatusOK), nil
+				case "range":
+					var offset int64
+					if file != nil {
+						offset = parseRangeStart(request.Header.Get("x-ms-range"))
+						mu.Lock()
+						defer mu.Unlock()
+						io.ReadFull(request.Body, file[offset:offset+request.ContentLength])
+					} else {
+						io.Copy(ioutil.Discard, request.Body)
+					}
+				}
+				return newStatusResponse(request, http.StatusCreated), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+// parseRangeStart returns the offset at which a "bytes=start-end" range starts.
+func parseRangeStart(r string) (start int64) {
+	for _, c := range strings.TrimPrefix(r, "bytes=") {
+		if c == '-' {
+			break
+		}
+		start = start*10 + int64(c-'0')
+	}
+	return start
+}
+
+// zeroReader is an endless stream of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func (s *bufferManagerSuite) TestBufferManager(c *chk.C) {
+	m := NewBufferManager(1024, 2)
+	c.Assert(m.BufferSize(), chk.Equals, 1024)
+	b1, err := m.Acquire(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(b1, chk.HasLen, 1024)
+	b2, err := m.Acquire(context.Background())
+	c.Assert(err, chk.IsNil)
+
+	// No more than 2 buffers are in use at a time.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = m.Acquire(ctx)
+	c.Assert(err, chk.Equals, context.DeadlineExceeded)
+
+	// Released buffers come back zeroed.
+	copy(b1, "previous file's contents")
+	m.Release(b1)
+	m.Release(b2)
+	for i := 0; i < 2; i++ {
+		b, err := m.Acquire(context.Background())
+		c.Assert(err, chk.IsNil)
+		c.Assert(bytes.Count(b, []byte{0}), chk.Equals, len(b))
+	}
+
+	c.Assert(NewBufferManager(0, 0).BufferSize(), chk.Equals, FileMaxUploadRangeBytes)
+}
+
+func (s *bufferManagerSuite) TestUploadStreamBufferManager(c *chk.C) {
+	data := []byte(strings.Repeat("0123456789", 1000))
+	file := make([]byte, len(data))
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestStreamUploadPipeline(file))
+	m := NewBufferManager(512, 3)
+
+	err := UploadStreamToAzureFile(context.Background(), bytes.NewReader(data), fileURL, UploadStreamOptions{BufferManager: m})
+	c.Assert(err, chk.IsNil)
+	c.Assert(bytes.Equal(file, data), chk.Equals, true)
+	c.Assert(m.slots, chk.HasLen, 0) // Every buffer was released
+
+	// The manager's buffers must fit in a range.
+	err = UploadStreamToAzureFile(context.Background(), bytes.NewReader(data), fileURL,
+		UploadStreamOptions{BufferManager: NewBufferManager(FileMaxUploadRangeBytes+1, 1)})
+	c.Assert(err, chk.NotNil)
+}
+
+// BenchmarkUploadStream uploads 64MiB per iteration. Run it with -check.b -check.bmem: since the buffers are pooled,
+// the bytes allocated per upload stay a small fraction of the bytes uploaded.
+func (s *bufferManagerSuite) BenchmarkUploadStream(c *chk.C) {
+	const size = 64 * 1024 * 1024
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestStreamUploadPipeline(nil))
+	c.SetBytes(size)
+	c.ResetTimer()
+	for i := 0; i < c.N; i++ {
+		err := UploadStreamToAzureFile(context.Background(), io.LimitReader(zeroReader{}, size), fileURL, UploadStreamOptions{})
+		c.Assert(err, chk.IsNil)
+	}
+}
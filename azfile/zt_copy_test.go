@@ -0,0 +1,400 @@
+package azfile
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type copySuite struct{}
+
+var _ = chk.Suite(&copySuite{})
+
+func (s *copySuite) TestUploadRangeFromURLHeaders(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent))
+	source, _ := url.Parse("https://source.file.core.windows.net/share/src?sig=signature")
+
+	crc := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	_, err := fileURL.UploadRangeFromURL(context.Background(), *source, 512, 1024, 2048, UploadRangeFromURLOptions{
+		SourceIfMatchCRC64:      crc,
+		CopySourceAuthorization: "token",
+		LeaseAccessConditions:   LeaseAccessConditions{LeaseID: "lease"},
+	})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-copy-source"), chk.Equals, source.String())
+	c.Assert(sent.Get("x-ms-range"), chk.Equals, "bytes=1024-3071")
+	c.Assert(sent.Get("x-ms-source-range"), chk.Equals, "bytes=512-2559")
+	c.Assert(sent.Get("x-ms-write"), chk.Equals, "update")
+	c.Assert(sent.Get("Content-Length"), chk.Equals, "0")
+	c.Assert(sent.Get("x-ms-source-if-match-crc64"), chk.Equals, base64.StdEncoding.EncodeToString(crc))
+	c.Assert(sent.Get("x-ms-source-if-none-match-crc64"), chk.Equals, "")
+	c.Assert(sent.Get("x-ms-copy-source-authorization"), chk.Equals, "Bearer token")
+	c.Assert(sent.Get("x-ms-version"), chk.Equals, copySourceAuthorizationServiceVersion)
+	c.Assert(sent.Get("x-ms-lease-id"), chk.Equals, "lease")
+}
+
+// testCRC64 returns the CRC64 of data as the service computes it.
+func testCRC64(data []byte) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, crc64.Checksum(data, crc64.MakeTable(0x9A6C9329AC4BC9B5)))
+	return b
+}
+
+// newTestCRC64Pipeline returns a pipeline that copies ranges of source the way the service does, failing with
+// ServiceCodeCrc64Mismatch a request whose x-ms-source-content-crc64 doesn't match the source range.
+func newTestCRC64Pipeline(source []byte) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				var start, end int
+				fmt.Sscanf(request.Header.Get("x-ms-source-range"), "bytes=%d-%d", &start, &end)
+				crc := base64.StdEncoding.EncodeToString(testCRC64(source[start : end+1]))
+				header := http.Header{}
+				status := http.StatusCreated
+				if sent := request.Header.Get("x-ms-source-content-crc64"); sent != "" && sent != crc {
+					status = http.StatusBadRequest
+					header.Set("x-ms-error-code", string(ServiceCodeCrc64Mismatch))
+				} else {
+					header.Set("x-ms-content-crc64", crc)
+				}
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: status,
+					Header:     header,
+					Body:       http.NoBody,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *copySuite) TestUploadRangeFromURLSourceContentCRC64(c *chk.C) {
+	source := make([]byte, 2048)
+	for i := range source {
+		source[i] = byte(i)
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	fileURL := NewFileURL(*u, newTestCRC64Pipeline(source))
+	sourceURL, _ := url.Parse(testRetryErrorMockURL + "share/src")
+
+	crc := testCRC64(source[512:1536])
+	resp, err := fileURL.UploadRangeFromURL(context.Background(), *sourceURL, 512, 0, 1024, UploadRangeFromURLOptions{SourceContentCRC64: crc})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.XMsContentCrc64(), chk.DeepEquals, crc)
+
+	// The range has changed since its CRC64 was computed.
+	tampered := append([]byte(nil), source[512:1536]...)
+	tampered[0]++
+	_, err = fileURL.UploadRangeFromURL(context.Background(), *sourceURL, 512, 0, 1024,
+		UploadRangeFromURLOptions{SourceContentCRC64: testCRC64(tampered)})
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.(StorageError).ServiceCode(), chk.Equals, ServiceCodeCrc64Mismatch)
+}
+
+func (s *copySuite) TestUploadRangeFromURLNegative(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent))
+
+	for _, r := range []struct{ sourceOffset, destOffset, count int64 }{
+		{0, 0, 0},
+		{0, 0, FileMaxUploadRangeBytes + 1},
+		{-1, 0, 512},
+		{0, -1, 512},
+	} {
+		_, err := fileURL.UploadRangeFromURL(context.Background(), *u, r.sourceOffset, r.destOffset, r.count, UploadRangeFromURLOptions{})
+		c.Assert(err, chk.NotNil)
+	}
+	c.Assert(sent, chk.IsNil)
+}
+
+func (s *copySuite) TestCopySourceAuthorization(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusAccepted, http.Header{}, &sent))
+	source, _ := url.Parse("https://source.blob.core.windows.net/container/blob")
+
+	_, err := fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{CopySourceAuthorization: "token"})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-copy-source"), chk.Equals, source.String())
+	c.Assert(sent.Get("x-ms-copy-source-authorization"), chk.Equals, "Bearer token")
+	c.Assert(sent.Get("x-ms-version"), chk.Equals, copySourceAuthorizationServiceVersion)
+
+	sent = nil
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-copy-source-authorization"), chk.Equals, "")
+	c.Assert(sent.Get("x-ms-version"), chk.Equals, ServiceVersion)
+
+	// The token is never sent for a source read over plain http.
+	sent = nil
+	source.Scheme = "http"
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{CopySourceAuthorization: "token"})
+	c.Assert(err, chk.NotNil)
+	_, err = fileURL.UploadRangeFromURL(context.Background(), *source, 0, 0, 512, UploadRangeFromURLOptions{CopySourceAuthorization: "token"})
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.IsNil)
+}
+
+func (s *copySuite) TestStartCopySMBProperties(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusAccepted, http.Header{}, &sent))
+	source, _ := url.Parse(testRetryErrorMockURL + "share/src")
+
+	// Nothing is sent by default, so the destination gets the service defaults.
+	_, err := fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{})
+	c.Assert(err, chk.IsNil)
+	for _, h := range []string{"x-ms-file-permission", "x-ms-file-permission-key", "x-ms-file-permission-copy-mode",
+		"x-ms-file-copy-ignore-read-only", "x-ms-file-attributes", "x-ms-file-creation-time", "x-ms-file-last-write-time"} {
+		c.Assert(sent.Get(h), chk.Equals, "")
+	}
+
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{
+		PermissionCopyMode: PermissionCopyModeSource,
+		CopyFileAttributes: true,
+		CopyCreationTime:   true,
+		CopyLastWriteTime:  true,
+		IgnoreReadOnly:     true,
+	})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-permission-copy-mode"), chk.Equals, "source")
+	c.Assert(sent.Get("x-ms-file-copy-ignore-read-only"), chk.Equals, "true")
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "source")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "source")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "source")
+
+	attributes := FileAttributeReadOnly | FileAttributeArchive
+	creationTime := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	key := "key"
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{
+		SMBProperties:      SMBProperties{FileAttributes: &attributes, FileCreationTime: &creationTime, FilePermissionKey: &key},
+		PermissionCopyMode: PermissionCopyModeOverride,
+		CopyLastWriteTime:  true,
+	})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-permission-copy-mode"), chk.Equals, "override")
+	c.Assert(sent.Get("x-ms-file-permission-key"), chk.Equals, key)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "ReadOnly|Archive")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "2020-01-02T03:04:05.6000000Z")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "source")
+	c.Assert(sent.Get("x-ms-file-copy-ignore-read-only"), chk.Equals, "")
+
+	// A security descriptor is sent only to override the source's.
+	sent = nil
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{PermissionCopyMode: PermissionCopyModeOverride})
+	c.Assert(err, chk.NotNil)
+	_, err = fileURL.StartCopy(context.Background(), *source, nil, StartCopyOptions{SMBProperties: SMBProperties{FilePermissionKey: &key}})
+	c.Assert(err, chk.NotNil)
+	_, err = fileURL.AbortCopy(context.Background(), "", LeaseAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.IsNil)
+}
+
+// newTestCopyStatusPipeline returns a pipeline that answers each GetProperties request with the next of statuses for
+// copy "id", counting requests in *polls. The last status is repeated once the others have been returned.
+func newTestCopyStatusPipeline(statuses []CopyStatusType, polls *int) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				status := statuses[len(statuses)-1]
+				if *polls < len(statuses) {
+					status = statuses[*polls]
+				}
+				*polls++
+				header := http.Header{}
+				header.Set("x-ms-copy-id", "id")
+				header.Set("x-ms-copy-status", string(status))
+				header.Set("x-ms-copy-status-description", "500 InternalError")
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       http.NoBody,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *copySuite) TestWaitForCopy(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	polls := 0
+	fileURL := NewFileURL(*u, newTestCopyStatusPipeline([]CopyStatusType{CopyStatusPending, CopyStatusPending, CopyStatusSuccess}, &polls))
+
+	props, err := WaitForCopy(context.Background(), fileURL, "id", time.Millisecond)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.CopyStatus(), chk.Equals, CopyStatusSuccess)
+	c.Assert(polls, chk.Equals, 3)
+
+	// Another copy has replaced the one being waited for.
+	polls = 0
+	_, err = WaitForCopy(context.Background(), fileURL, "other", time.Millisecond)
+	c.Assert(err, chk.NotNil)
+	c.Assert(polls, chk.Equals, 1)
+
+	_, err = WaitForCopy(context.Background(), fileURL, "", time.Millisecond)
+	c.Assert(err, chk.NotNil)
+	_, err = WaitForCopy(context.Background(), fileURL, "id", 0)
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *copySuite) TestWaitForCopyFailed(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	polls := 0
+	fileURL := NewFileURL(*u, newTestCopyStatusPipeline([]CopyStatusType{CopyStatusPending, CopyStatusFailed}, &polls))
+
+	_, err := WaitForCopy(context.Background(), fileURL, "id", time.Millisecond)
+	copyErr, ok := err.(*CopyError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(copyErr.CopyID, chk.Equals, "id")
+	c.Assert(copyErr.Status, chk.Equals, CopyStatusFailed)
+	c.Assert(copyErr.StatusDescription, chk.Equals, "500 InternalError")
+	c.Assert(copyErr.Error(), chk.Equals, "copy id failed: 500 InternalError")
+}
+
+func (s *copySuite) TestWaitForCopyCancel(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	polls := 0
+	fileURL := NewFileURL(*u, newTestCopyStatusPipeline([]CopyStatusType{CopyStatusPending}, &polls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := WaitForCopy(ctx, fileURL, "id", time.Hour)
+	c.Assert(err, chk.Equals, context.DeadlineExceeded)
+	c.Assert(polls, chk.Equals, 1) // The first poll's backoff outlasts the context.
+	c.Assert(time.Since(start) < time.Second, chk.Equals, true)
+}
+
+func (s *copySuite) TestFileGetPropertiesLeaseAndCopy(c *chk.C) {
+	var sent *http.Request
+	header := http.Header{}
+	header.Set("x-ms-lease-duration", "infinite")
+	header.Set("x-ms-lease-state", "leased")
+	header.Set("x-ms-lease-status", "locked")
+	header.Set("x-ms-copy-id", "copy-1")
+	header.Set("x-ms-copy-status", "pending")
+	header.Set("x-ms-copy-progress", "512/1024")
+	header.Set("x-ms-copy-source", "https://account.file.core.windows.net/share/source")
+	header.Set("x-ms-copy-completion-time", "Wed, 09 Sep 2020 22:56:16 GMT")
+	header.Set("x-ms-copy-status-description", "")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestHandlesPipeline(header, "", &sent))
+
+	props, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.LeaseDuration(), chk.Equals, LeaseDurationInfinite)
+	c.Assert(props.LeaseState(), chk.Equals, LeaseStateLeased)
+	c.Assert(props.LeaseStatus(), chk.Equals, LeaseStatusLocked)
+	c.Assert(props.CopyID(), chk.Equals, "copy-1")
+	c.Assert(props.CopyStatus(), chk.Equals, CopyStatusPending)
+	c.Assert(props.CopyProgress(), chk.Equals, "512/1024")
+	c.Assert(props.CopySource(), chk.Equals, "https://account.file.core.windows.net/share/source")
+	c.Assert(props.CopyCompletionTime().Equal(time.Date(2020, 9, 9, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+
+	dr, err := fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	c.Assert(dr.LeaseState(), chk.Equals, LeaseStateLeased)
+	c.Assert(dr.LeaseStatus(), chk.Equals, LeaseStatusLocked)
+	c.Assert(dr.LeaseDuration(), chk.Equals, LeaseDurationInfinite)
+
+	header = http.Header{}
+	props, err = NewFileURL(*u, newTestHandlesPipeline(header, "", &sent)).GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.LeaseState(), chk.Equals, LeaseStateNone)
+	c.Assert(props.CopyStatus(), chk.Equals, CopyStatusNone)
+}
+
+func (s *copySuite) TestCopyTree(c *chk.C) {
+	share, source := newTestDownloadShare()
+	lastWrite := time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+	share.lastWrite["src/sub"] = lastWrite.Format(smbTimeFormat)
+	share.attributes["src/sub"] = "Directory | Hidden"
+	share.permissions["src/sub"] = "key-sub"
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	dest := NewDirectoryURL(*u, newTestSharePipeline(share))
+
+	resultsMu := sync.Mutex{}
+	var copied []string
+	err := CopyTree(context.Background(), source, dest, CopyTreeOptions{
+		PollInterval:          time.Millisecond,
+		PreserveSMBProperties: true,
+		FileCopied: func(result FileTransferResult) {
+			c.Check(result.Error, chk.IsNil)
+			resultsMu.Lock()
+			copied = append(copied, result.Path)
+			resultsMu.Unlock()
+		},
+	})
+	c.Assert(err, chk.IsNil)
+	sort.Strings(copied)
+	c.Assert(copied, chk.DeepEquals, []string{"a.txt", "sub/b.txt", "sub/deep/c.txt", "zero"})
+	for _, d := range []string{"dest", "dest/empty", "dest/sub", "dest/sub/deep"} {
+		c.Assert(share.dirs[d], chk.Equals, true)
+	}
+	c.Assert(share.files["dest/sub/b.txt"], chk.DeepEquals, []byte(strings.Repeat("b", 10)))
+
+	// Files are copied with the source's security descriptors, attributes and times.
+	sent := share.copies["dest/sub/deep/c.txt"]
+	c.Assert(sent.Get("x-ms-copy-source"), chk.Equals, testRetryErrorMockURL+"share/src/sub/deep/c.txt")
+	c.Assert(sent.Get("x-ms-file-permission-copy-mode"), chk.Equals, "source")
+	for _, h := range []string{"x-ms-file-attributes", "x-ms-file-creation-time", "x-ms-file-last-write-time"} {
+		c.Assert(sent.Get(h), chk.Equals, "source")
+	}
+
+	// Directories get the listed properties, and, in the same share, their permission keys.
+	c.Assert(share.lastWrite["dest/sub"], chk.Equals, share.lastWrite["src/sub"])
+	c.Assert(share.attributes["dest/sub"], chk.Equals, "Hidden|Directory")
+	c.Assert(share.permissions["dest/sub"], chk.Equals, "key-sub")
+
+	// Between shares, the security descriptors are read from the source's share, once per key.
+	share.sddl["key-sub"] = "O:BAG:BAD:(A;;FA;;;SY)"
+	share.permissions["src/sub/deep"] = "key-sub"
+	other, _ := url.Parse(testRetryErrorMockURL + "other/dest")
+	err = CopyTree(context.Background(), source, NewDirectoryURL(*other, newTestSharePipeline(share)), CopyTreeOptions{PreserveSMBProperties: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(share.permissions["/other/dest/sub"], chk.Equals, "set:O:BAG:BAD:(A;;FA;;;SY)")
+	c.Assert(share.permissions["/other/dest/sub/deep"], chk.Equals, "set:O:BAG:BAD:(A;;FA;;;SY)")
+
+	// Without PreserveSMBProperties, the service defaults apply.
+	err = CopyTree(context.Background(), source, dest, CopyTreeOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(share.copies["dest/a.txt"].Get("x-ms-file-permission-copy-mode"), chk.Equals, "")
+}
+
+func (s *copySuite) TestCopyTreeErrors(c *chk.C) {
+	share, source := newTestDownloadShare()
+	share.dirs["src/baddir"] = true
+	share.files["src/bad.txt"] = []byte("fails")
+	share.failPattern = "bad"
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	dest := NewDirectoryURL(*u, newTestSharePipeline(share))
+
+	err := CopyTree(context.Background(), source, dest, CopyTreeOptions{ContinueOnError: true})
+	c.Assert(err, chk.FitsTypeOf, &DirectoryTransferError{})
+	transferErr := err.(*DirectoryTransferError)
+	c.Assert(transferErr.Failures, chk.HasLen, 2)
+	c.Assert(transferErr.Failures[0].Path, chk.Equals, "bad.txt")
+	c.Assert(transferErr.Failures[1].Path, chk.Equals, "baddir")
+	c.Assert(isStorageError(transferErr.Failures[0].Error, http.StatusForbidden), chk.Equals, true)
+	c.Assert(share.files["dest/sub/deep/c.txt"], chk.DeepEquals, []byte("c"))
+
+	err = CopyTree(context.Background(), source, dest, CopyTreeOptions{PollInterval: -1})
+	c.Assert(err, chk.NotNil)
+}
@@ -0,0 +1,247 @@
+package azfile
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type credentialSuite struct{}
+
+var _ = chk.Suite(&credentialSuite{})
+
+// sendThroughCredential sends a request to rawURL through a pipeline containing only the credential,
+// and returns the request as it would have gone to the wire.
+func sendThroughCredential(c *chk.C, credential Credential, rawURL string) (*http.Request, error) {
+	var sent *http.Request
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		credential,
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				sent = request.Request
+				return pipeline.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK, Request: request.Request}), nil
+			}
+		}),
+	}, pipeline.Options{})
+
+	u, _ := url.Parse(rawURL)
+	request, err := pipeline.NewRequest(http.MethodGet, *u, nil)
+	c.Assert(err, chk.IsNil)
+	_, err = p.Do(context.Background(), nil, request)
+	return sent, err
+}
+
+func (s *credentialSuite) TestTokenCredentialSetsBearerAndIntentHeaders(c *chk.C) {
+	credential := NewTokenCredential("token1", nil)
+	request, err := sendThroughCredential(c, credential, testRetryErrorMockURL)
+	c.Assert(err, chk.IsNil)
+	c.Assert(request.Header.Get(headerAuthorization), chk.Equals, "Bearer token1")
+	c.Assert(request.Header.Get(headerXmsFileRequestIntent), chk.Equals, "backup")
+	c.Assert(request.Header.Get("x-ms-version"), chk.Equals, tokenServiceVersion)
+
+	credential.SetToken("token2")
+	request, err = sendThroughCredential(c, credential, testRetryErrorMockURL)
+	c.Assert(err, chk.IsNil)
+	c.Assert(request.Header.Get(headerAuthorization), chk.Equals, "Bearer token2")
+}
+
+func (s *credentialSuite) TestTokenCredentialServiceVersion(c *chk.C) {
+	var sent []string
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Header.Get("x-ms-version"))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+
+	// Bearer requests are sent with at least the version that accepts OAuth, even when the pipeline pins an older one.
+	for _, version := range []string{"", "2019-12-12", tokenServiceVersion} {
+		fileURL := NewFileURL(*u, NewPipeline(NewTokenCredential("token", nil), PipelineOptions{
+			HTTPSender: NewHTTPClientSenderFactory(client), ServiceVersion: version, Retry: RetryOptions{MaxTries: 1}}))
+		_, err := fileURL.GetProperties(context.Background())
+		c.Assert(err, chk.IsNil)
+	}
+	c.Assert(sent, chk.DeepEquals, []string{tokenServiceVersion, tokenServiceVersion, tokenServiceVersion})
+}
+
+func (s *credentialSuite) TestTokenCredentialNegativeHTTP(c *chk.C) {
+	request, err := sendThroughCredential(c, NewTokenCredential("token", nil), "http://mockaccount.file.core.windows.net/")
+	c.Assert(err, chk.NotNil)
+	c.Assert(request, chk.IsNil)
+}
+
+func (s *credentialSuite) TestTokenCredentialRefresh(c *chk.C) {
+	var refreshes int32
+	done := make(chan struct{})
+	credential := NewTokenCredential("initial", func(credential TokenCredential) time.Duration {
+		switch atomic.AddInt32(&refreshes, 1) {
+		case 1:
+			credential.SetToken("refreshed")
+			return 10 * time.Millisecond
+		case 2:
+			credential.SetToken("refreshed again")
+			close(done)
+		}
+		return 0 // Stop refreshing
+	})
+
+	// The refresher is called once before NewTokenCredential returns.
+	c.Assert(credential.Token(), chk.Equals, "refreshed")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("the token was not refreshed on the schedule returned by the refresher")
+	}
+	c.Assert(credential.Token(), chk.Equals, "refreshed again")
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(atomic.LoadInt32(&refreshes), chk.Equals, int32(2))
+}
+
+func (s *credentialSuite) TestAnonymousCredentialPipelineRetriesAndSendsTelemetry(c *chk.C) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get(headerAuthorization), chk.Equals, "")
+		c.Check(strings.HasPrefix(r.Header.Get(headerUserAgent), "anonymous-test"), chk.Equals, true)
+		c.Check(r.URL.Query().Get("sig"), chk.Equals, "signature")
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // The retry policy must retry this.
+			return
+		}
+		w.Write([]byte("Hello"))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/share/file?sv=2020-02-10&sig=signature")
+	fileURL := NewFileURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{
+		Retry:     RetryOptions{MaxTries: 2, RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond},
+		Telemetry: TelemetryOptions{Value: "anonymous-test"},
+	}))
+
+	resp, err := fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	data, err := ioutil.ReadAll(resp.Body(RetryReaderOptions{}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(data), chk.Equals, "Hello")
+	c.Assert(atomic.LoadInt32(&requests), chk.Equals, int32(2))
+}
+
+func (s *credentialSuite) TestSharedKeyCredentialSetAccountKey(c *chk.C) {
+	key1, key2 := "a2V5MQ==", "a2V5Mg=="
+	credential, err := NewSharedKeyCredential("mockaccount", key1)
+	c.Assert(err, chk.IsNil)
+	rotated, err := NewSharedKeyCredential("mockaccount", key2)
+	c.Assert(err, chk.IsNil)
+	c.Assert(credential.ComputeHMACSHA256("message"), chk.Not(chk.Equals), rotated.ComputeHMACSHA256("message"))
+
+	c.Assert(credential.SetAccountKey(key2), chk.IsNil)
+	c.Assert(credential.ComputeHMACSHA256("message"), chk.Equals, rotated.ComputeHMACSHA256("message"))
+	c.Assert(credential.SetAccountKey("not base64!"), chk.NotNil)
+	c.Assert(credential.ComputeHMACSHA256("message"), chk.Equals, rotated.ComputeHMACSHA256("message"))
+
+	// Requests can be signed while the key is rotated.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			credential.SetAccountKey([]string{key1, key2}[i%2])
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		request, err := sendThroughCredential(c, credential, testRetryErrorMockURL+"share")
+		c.Assert(err, chk.IsNil)
+		c.Assert(strings.HasPrefix(request.Header.Get(headerAuthorization), "SharedKey mockaccount:"), chk.Equals, true)
+	}
+	<-done
+}
+
+// referenceHMACSHA256 signs message with a hash keyed for it alone, as ComputeHMACSHA256 did before it pooled them.
+func referenceHMACSHA256(key string, message string) string {
+	k, _ := base64.StdEncoding.DecodeString(key)
+	h := hmac.New(sha256.New, k)
+	h.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (s *credentialSuite) TestComputeHMACSHA256ReusesKeyedHashes(c *chk.C) {
+	credential, err := NewSharedKeyCredential("mockaccount", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+
+	// The signatures made concurrently with the pooled hashes are those of hashes keyed for each.
+	var wg sync.WaitGroup
+	mismatches := int32(0)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				message := fmt.Sprintf("/file/mockaccount/share/file-%d-%d", g, i)
+				if credential.ComputeHMACSHA256(message) != referenceHMACSHA256(testSASAccountKey, message) {
+					atomic.AddInt32(&mismatches, 1)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	c.Assert(mismatches, chk.Equals, int32(0))
+	c.Assert(credential.ComputeHMACSHA256(""), chk.Equals, referenceHMACSHA256(testSASAccountKey, ""))
+	c.Assert((&SharedKeyCredential{}).ComputeHMACSHA256("message"), chk.Equals, referenceHMACSHA256("", "message"))
+}
+
+// BenchmarkFileSAS measures minting a file SAS with a credential shared by all of them.
+func BenchmarkFileSAS(b *testing.B) {
+	credential, _ := NewSharedKeyCredential("mockaccount", testSASAccountKey)
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			_, err := FileSASSignatureValues{Protocol: SASProtocolHTTPS, ExpiryTime: expiry, Permissions: "r",
+				ShareName: "share", FilePath: "dir/file-" + strconv.Itoa(i)}.NewSASQueryParameters(credential)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkComputeHMACSHA256 measures the signature of a SAS with the credential's pooled hashes; compare it with
+// BenchmarkUnpooledHMACSHA256, which keys a hash for each signature.
+func BenchmarkComputeHMACSHA256(b *testing.B) {
+	credential, _ := NewSharedKeyCredential("mockaccount", testSASAccountKey)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			credential.ComputeHMACSHA256(benchmarkStringToSign + strconv.Itoa(i))
+		}
+	})
+}
+
+func BenchmarkUnpooledHMACSHA256(b *testing.B) {
+	key, _ := base64.StdEncoding.DecodeString(testSASAccountKey)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			h := hmac.New(sha256.New, key)
+			h.Write([]byte(benchmarkStringToSign + strconv.Itoa(i)))
+			base64.StdEncoding.EncodeToString(h.Sum(nil))
+		}
+	})
+}
+
+// benchmarkStringToSign is the start of the string-to-sign of a read-only file SAS, to which the benchmarks append
+// a file name.
+const benchmarkStringToSign = "r\n\n2030-01-01T00:00:00Z\n/file/mockaccount/share/dir/file-"
@@ -0,0 +1,344 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type directoryListSuite struct{}
+
+var _ = chk.Suite(&directoryListSuite{})
+
+// newTestListPipeline returns a pipeline that answers list requests with the segment for each request's marker,
+// and counts the requests in *requests. A marker without a segment fails the request. Segments may also be keyed by
+// the directory's path and the marker, e.g. "/share/dir?m1", to serve several directories.
+func newTestListPipeline(segments map[string]string, requests *int) pipeline.Pipeline {
+	var mu sync.Mutex
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				*requests++
+				mu.Unlock()
+				marker := request.URL.Query().Get("marker")
+				segment, ok := segments[marker]
+				if !ok {
+					segment, ok = segments[request.URL.Path+"?"+marker]
+				}
+				if !ok {
+					return nil, errors.New("unexpected marker")
+				}
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader(segment)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func listNames(it *FilesAndDirectoriesIterator) (names []string) {
+	for it.Next() {
+		if f := it.File(); f != nil {
+			names = append(names, "f:"+f.Name)
+		} else {
+			names = append(names, "d:"+it.Directory().Name)
+		}
+	}
+	return
+}
+
+func (s *directoryListSuite) TestListAllFollowsNextMarker(c *chk.C) {
+	requests := 0
+	segments := map[string]string{
+		"": `<EnumerationResults><Entries><File><Name>a</Name></File><Directory><Name>b</Name></Directory>` +
+			`<File><Name>c</Name></File></Entries><NextMarker>m1</NextMarker></EnumerationResults>`,
+		"m1": `<EnumerationResults><Entries></Entries><NextMarker>m2</NextMarker></EnumerationResults>`,
+		"m2": `<EnumerationResults><Entries><Directory><Name>d</Name></Directory></Entries><NextMarker /></EnumerationResults>`,
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestListPipeline(segments, &requests))
+
+	it := dirURL.ListAll(context.Background(), ListFilesAndDirectoriesOptions{MaxResults: 3})
+	c.Assert(listNames(it), chk.DeepEquals, []string{"f:a", "d:b", "f:c", "d:d"})
+	c.Assert(it.Err(), chk.IsNil)
+	c.Assert(requests, chk.Equals, 3)
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(requests, chk.Equals, 3)
+}
+
+func (s *directoryListSuite) TestListSegmentsResumeFromSavedMarker(c *chk.C) {
+	requests := 0
+	segments := map[string]string{
+		"":   `<EnumerationResults><Entries><File><Name>a</Name></File></Entries><NextMarker>m1</NextMarker></EnumerationResults>`,
+		"m1": `<EnumerationResults><Entries><File><Name>b</Name></File></Entries><NextMarker /></EnumerationResults>`,
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestListPipeline(segments, &requests))
+
+	resp, err := dirURL.ListFilesAndDirectoriesSegment(context.Background(), Marker{}, ListFilesAndDirectoriesOptions{})
+	c.Assert(err, chk.IsNil)
+	saved := *resp.NextMarker.GetVal()
+	c.Assert(saved, chk.Equals, "m1")
+
+	names := []string{}
+	for marker := NewMarker(saved); marker.NotDone(); {
+		resp, err := dirURL.ListFilesAndDirectoriesSegment(context.Background(), marker, ListFilesAndDirectoriesOptions{})
+		c.Assert(err, chk.IsNil)
+		for _, f := range resp.FileItems {
+			names = append(names, f.Name)
+		}
+		marker = resp.NextMarker
+	}
+	c.Assert(names, chk.DeepEquals, []string{"b"})
+	c.Assert(requests, chk.Equals, 2)
+	c.Assert(NewMarker("").GetVal(), chk.IsNil)
+	c.Assert(NewMarker("").NotDone(), chk.Equals, true)
+}
+
+func (s *directoryListSuite) TestListAllStopsOnError(c *chk.C) {
+	requests := 0
+	segments := map[string]string{
+		"": `<EnumerationResults><Entries><File><Name>a</Name></File></Entries><NextMarker>missing</NextMarker></EnumerationResults>`,
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestListPipeline(segments, &requests))
+
+	it := dirURL.ListAll(context.Background(), ListFilesAndDirectoriesOptions{})
+	c.Assert(listNames(it), chk.DeepEquals, []string{"f:a"})
+	c.Assert(it.Err(), chk.NotNil)
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(requests, chk.Equals, 2)
+}
+
+func (s *directoryListSuite) TestListAllStopsWhenContextIsDone(c *chk.C) {
+	requests := 0
+	segments := map[string]string{
+		"":   `<EnumerationResults><Entries><File><Name>a</Name></File></Entries><NextMarker>m1</NextMarker></EnumerationResults>`,
+		"m1": `<EnumerationResults><Entries><File><Name>b</Name></File></Entries><NextMarker /></EnumerationResults>`,
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestListPipeline(segments, &requests))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := dirURL.ListAll(ctx, ListFilesAndDirectoriesOptions{})
+	c.Assert(it.Next(), chk.Equals, true)
+	cancel()
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(it.Err(), chk.Equals, context.Canceled)
+	c.Assert(requests, chk.Equals, 1)
+}
+
+// walkTestSegments is a tree of /share/root, with files a, b and c in each directory:
+// root/{a,b,c}, root/d1/{a,b,c}, root/d1/d11/{a,b,c} and root/d2/{a,b,c}. root's listing takes two segments.
+var walkTestSegments = map[string]string{
+	"/share/root?": `<EnumerationResults><Entries><File><Name>a</Name></File><File><Name>b</Name></File>` +
+		`<Directory><Name>d1</Name></Directory></Entries><NextMarker>m1</NextMarker></EnumerationResults>`,
+	"/share/root?m1": `<EnumerationResults><Entries><File><Name>c</Name></File><Directory><Name>d2</Name></Directory>` +
+		`</Entries><NextMarker /></EnumerationResults>`,
+	"/share/root/d1?": `<EnumerationResults><Entries><File><Name>a</Name></File><File><Name>b</Name></File>` +
+		`<File><Name>c</Name></File><Directory><Name>d11</Name></Directory></Entries><NextMarker /></EnumerationResults>`,
+	"/share/root/d1/d11?": `<EnumerationResults><Entries><File><Name>a</Name></File><File><Name>b</Name></File>` +
+		`<File><Name>c</Name></File></Entries><NextMarker /></EnumerationResults>`,
+	"/share/root/d2?": `<EnumerationResults><Entries><File><Name>a</Name></File><File><Name>b</Name></File>` +
+		`<File><Name>c</Name></File></Entries><NextMarker /></EnumerationResults>`,
+}
+
+func (s *directoryListSuite) TestWalkFiles(c *chk.C) {
+	requests := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/root")
+	root := NewDirectoryURL(*u, newTestListPipeline(walkTestSegments, &requests))
+
+	var paths []string
+	err := WalkFiles(context.Background(), root, func(path string, item FileItem) error {
+		paths = append(paths, path)
+		return nil
+	})
+	c.Assert(err, chk.IsNil)
+	sort.Strings(paths)
+	c.Assert(paths, chk.DeepEquals, []string{
+		"root/a", "root/b", "root/c",
+		"root/d1/a", "root/d1/b", "root/d1/c",
+		"root/d1/d11/a", "root/d1/d11/b", "root/d1/d11/c",
+		"root/d2/a", "root/d2/b", "root/d2/c"})
+	c.Assert(requests, chk.Equals, 5)
+}
+
+func (s *directoryListSuite) TestWalkFilesSkipDir(c *chk.C) {
+	requests := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/root")
+	root := NewDirectoryURL(*u, newTestListPipeline(walkTestSegments, &requests))
+
+	var paths []string
+	err := WalkFiles(context.Background(), root, func(path string, item FileItem) error {
+		paths = append(paths, path)
+		if path == "root/d1/b" {
+			return SkipDir
+		}
+		return nil
+	})
+	c.Assert(err, chk.IsNil)
+	sort.Strings(paths)
+	c.Assert(paths, chk.DeepEquals, []string{
+		"root/a", "root/b", "root/c",
+		"root/d1/a", "root/d1/b",
+		"root/d2/a", "root/d2/b", "root/d2/c"})
+	c.Assert(requests, chk.Equals, 4) // root/d1/d11 is never listed
+}
+
+func (s *directoryListSuite) TestWalkFilesStopsOnError(c *chk.C) {
+	requests := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/root")
+	root := NewDirectoryURL(*u, newTestListPipeline(walkTestSegments, &requests))
+
+	stop := errors.New("stop")
+	calls := 0
+	err := WalkFiles(context.Background(), root, func(path string, item FileItem) error {
+		calls++
+		return stop
+	})
+	c.Assert(err, chk.Equals, stop)
+	c.Assert(calls, chk.Equals, 1)
+
+	// A failed listing ends the walk with the listing's error.
+	u, _ = url.Parse(testRetryErrorMockURL + "share/missing")
+	err = WalkFiles(context.Background(), NewDirectoryURL(*u, newTestListPipeline(walkTestSegments, &requests)),
+		func(path string, item FileItem) error { return nil })
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *directoryListSuite) TestWalkFilesStopsWhenContextIsDone(c *chk.C) {
+	requests := 0
+	u, _ := url.Parse(testRetryErrorMockURL + "share/root")
+	root := NewDirectoryURL(*u, newTestListPipeline(walkTestSegments, &requests))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := WalkFiles(ctx, root, func(path string, item FileItem) error {
+		calls++
+		cancel()
+		return nil
+	})
+	c.Assert(err, chk.Equals, context.Canceled)
+	c.Assert(calls, chk.Equals, 1)
+	c.Assert(requests, chk.Equals, 2) // Only root is listed
+}
+
+func (s *directoryListSuite) TestListIncludeDetails(c *chk.C) {
+	var sent *http.Request
+	body := `<EnumerationResults DirectoryId="13835128424026341376"><Entries>` +
+		`<Directory><FileId>13835093239654252544</FileId><Name>d</Name><Properties>` +
+		`<CreationTime>2020-09-08T22:56:16.2103572Z</CreationTime><LastAccessTime>2020-09-08T22:56:16.2103572Z</LastAccessTime>` +
+		`<LastWriteTime>2020-09-08T22:56:16.2103572Z</LastWriteTime><ChangeTime>2020-09-08T22:56:16.2103572Z</ChangeTime>` +
+		`<Last-Modified>Tue, 08 Sep 2020 22:56:16 GMT</Last-Modified><Etag>"0x8D8544A79C9A1C4"</Etag></Properties>` +
+		`<Attributes>Directory</Attributes><PermissionKey>dirkey</PermissionKey></Directory>` +
+		`<File><FileId>11529285414812647424</FileId><Name>f</Name><Properties><Content-Length>5</Content-Length>` +
+		`<CreationTime>2020-09-08T22:56:17.0000000Z</CreationTime><Etag>"0x8D8544A79D0F2A7"</Etag></Properties>` +
+		`<Attributes>ReadOnly | Archive</Attributes><PermissionKey>filekey</PermissionKey></File>` +
+		`</Entries><NextMarker /></EnumerationResults>`
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				sent = request.Request
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, p)
+
+	// An empty Include doesn't change the request.
+	_, err := dirURL.ListFilesAndDirectoriesSegment(context.Background(), Marker{}, ListFilesAndDirectoriesOptions{})
+	c.Assert(err, chk.IsNil)
+	_, present := sent.URL.Query()["include"]
+	c.Assert(present, chk.Equals, false)
+
+	resp, err := dirURL.ListFilesAndDirectoriesSegment(context.Background(), Marker{}, ListFilesAndDirectoriesOptions{
+		Include: ListFilesAndDirectoriesDetail{Timestamps: true, ETag: true, Attributes: true, PermissionKey: true}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("include"), chk.Equals, "Timestamps,Etag,Attributes,PermissionKey")
+
+	c.Assert(resp.DirectoryItems, chk.HasLen, 1)
+	d := resp.DirectoryItems[0]
+	c.Assert(*d.FileID, chk.Equals, "13835093239654252544")
+	c.Assert(*d.Attributes, chk.Equals, "Directory")
+	c.Assert(*d.PermissionKey, chk.Equals, "dirkey")
+	changed := time.Date(2020, 9, 8, 22, 56, 16, 210357200, time.UTC)
+	c.Assert(d.Properties.CreationTime.Equal(changed), chk.Equals, true)
+	c.Assert(d.Properties.LastAccessTime.Equal(changed), chk.Equals, true)
+	c.Assert(d.Properties.LastWriteTime.Equal(changed), chk.Equals, true)
+	c.Assert(d.Properties.ChangeTime.Equal(changed), chk.Equals, true)
+	c.Assert(d.Properties.LastModified.Equal(time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(*d.Properties.Etag, chk.Equals, ETag(`"0x8D8544A79C9A1C4"`))
+
+	c.Assert(resp.FileItems, chk.HasLen, 1)
+	f := resp.FileItems[0]
+	c.Assert(f.Properties.ContentLength, chk.Equals, int64(5))
+	c.Assert(f.Properties.LastWriteTime, chk.IsNil)
+	attributes, err := ParseFileAttributeFlags(*f.Attributes)
+	c.Assert(err, chk.IsNil)
+	c.Assert(attributes, chk.Equals, FileAttributeReadOnly|FileAttributeArchive)
+	c.Assert(*f.PermissionKey, chk.Equals, "filekey")
+}
+
+func (s *directoryListSuite) TestListDeleted(c *chk.C) {
+	var sent *http.Request
+	body := `<EnumerationResults><Entries>` +
+		`<Directory><Name>d</Name><Deleted>true</Deleted><Properties>` +
+		`<DeletedTime>Tue, 08 Sep 2020 22:56:16 GMT</DeletedTime><RemainingRetentionDays>6</RemainingRetentionDays></Properties></Directory>` +
+		`<File><Name>f</Name><Properties><Content-Length>5</Content-Length></Properties></File>` +
+		`</Entries><NextMarker /></EnumerationResults>`
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestHandlesPipeline(http.Header{}, body, &sent))
+
+	resp, err := dirURL.ListFilesAndDirectoriesSegment(context.Background(), Marker{}, ListFilesAndDirectoriesOptions{
+		Include: ListFilesAndDirectoriesDetail{Attributes: true, Deleted: true}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("include"), chk.Equals, "Attributes,Deleted")
+	d := resp.DirectoryItems[0]
+	c.Assert(*d.Deleted, chk.Equals, true)
+	c.Assert(d.Properties.DeletedTime.Equal(time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(*d.Properties.RemainingRetentionDays, chk.Equals, int32(6))
+	f := resp.FileItems[0]
+	c.Assert(f.Deleted, chk.IsNil)
+	c.Assert(f.Properties.DeletedTime, chk.IsNil)
+
+	// Undelete can't be taken for a Create: it's sent with comp=undelete.
+	_, err = dirURL.Undelete(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Method, chk.Equals, http.MethodPut)
+	c.Assert(sent.URL.Query().Get("restype"), chk.Equals, "directory")
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "undelete")
+	c.Assert(sent.Header.Get("x-ms-undelete"), chk.Equals, "true")
+	fileURL := NewFileURL(dirURL.NewFileURL("f").URL(), newTestHandlesPipeline(http.Header{}, "", &sent))
+	_, err = fileURL.Undelete(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Path, chk.Equals, "/share/dir/f")
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "undelete")
+	c.Assert(sent.Header.Get("x-ms-undelete"), chk.Equals, "true")
+
+	// Without the feature, the service's error is returned.
+	header := http.Header{}
+	header.Set("x-ms-error-code", string(ServiceCodeInvalidQueryParameterValue))
+	_, err = NewFileURL(fileURL.URL(), newTestErrorPipeline(http.StatusBadRequest, header, "")).Undelete(context.Background())
+	c.Assert(isStorageError(err, http.StatusBadRequest, ServiceCodeInvalidQueryParameterValue), chk.Equals, true)
+}
@@ -0,0 +1,162 @@
+package azfile
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	chk "gopkg.in/check.v1"
+)
+
+type downloadDirectorySuite struct{}
+
+var _ = chk.Suite(&downloadDirectorySuite{})
+
+// newTestDownloadShare returns a share holding a tree under "src", and the DirectoryURL of "src".
+func newTestDownloadShare() (*testShare, DirectoryURL) {
+	share := newTestShare()
+	for _, d := range []string{"src", "src/sub", "src/sub/deep", "src/empty"} {
+		share.dirs[d] = true
+	}
+	share.files["src/a.txt"] = []byte("hello")
+	share.files["src/zero"] = []byte{}
+	share.files["src/sub/b.txt"] = []byte(strings.Repeat("b", 10))
+	share.files["src/sub/deep/c.txt"] = []byte("c")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/src")
+	return share, NewDirectoryURL(*u, newTestSharePipeline(share))
+}
+
+// readTestTree returns the contents of the files under root, by slash-separated path, and its directories.
+func readTestTree(c *chk.C, root string) (files map[string]string, dirs []string) {
+	files = map[string]string{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		c.Assert(err, chk.IsNil)
+		rel, _ := filepath.Rel(root, p)
+		if rel == "." {
+			return nil
+		}
+		if info.IsDir() {
+			dirs = append(dirs, filepath.ToSlash(rel))
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		c.Assert(err, chk.IsNil)
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	c.Assert(err, chk.IsNil)
+	sort.Strings(dirs)
+	return files, dirs
+}
+
+func (s *downloadDirectorySuite) TestDownloadShareToDirectory(c *chk.C) {
+	share, dir := newTestDownloadShare()
+	lastWrite := time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+	share.lastWrite["src/a.txt"] = lastWrite.Format(smbTimeFormat)
+	share.lastWrite["src/sub"] = lastWrite.Add(time.Hour).Format(smbTimeFormat)
+	share.attributes["src/a.txt"] = "ReadOnly | Archive"
+	root := filepath.Join(c.MkDir(), "out")
+
+	progressMu := sync.Mutex{}
+	progress := map[string]int64{}
+	err := DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{
+		RangeSize: 4,
+		Progress: func(path string, bytesTransferred int64) {
+			progressMu.Lock()
+			progress[path] = bytesTransferred
+			progressMu.Unlock()
+		},
+		PreserveSMBProperties: true,
+	})
+	c.Assert(err, chk.IsNil)
+
+	files, dirs := readTestTree(c, root)
+	c.Assert(dirs, chk.DeepEquals, []string{"empty", "sub", "sub/deep"})
+	c.Assert(files, chk.DeepEquals, map[string]string{
+		"a.txt":          "hello",
+		"zero":           "",
+		"sub/b.txt":      strings.Repeat("b", 10),
+		"sub/deep/c.txt": "c",
+	})
+	c.Assert(progress["sub/b.txt"], chk.Equals, int64(10))
+
+	// The last write times and the ReadOnly attribute are set on the local copies.
+	info, err := os.Stat(filepath.Join(root, "a.txt"))
+	c.Assert(err, chk.IsNil)
+	c.Assert(info.ModTime().Equal(lastWrite), chk.Equals, true)
+	c.Assert(info.Mode().Perm()&0222, chk.Equals, os.FileMode(0))
+	info, err = os.Stat(filepath.Join(root, "sub"))
+	c.Assert(err, chk.IsNil)
+	c.Assert(info.ModTime().Equal(lastWrite.Add(time.Hour)), chk.Equals, true)
+}
+
+func (s *downloadDirectorySuite) TestDownloadShareToDirectoryIncremental(c *chk.C) {
+	share, dir := newTestDownloadShare()
+	share.lastWrite["src/a.txt"] = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC).Format(smbTimeFormat)
+	root := c.MkDir()
+	err := DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{Incremental: true})
+	c.Assert(err, chk.IsNil)
+
+	// A file whose size and last write time are unchanged isn't downloaded again; the others are.
+	share.files["src/a.txt"] = []byte("HELLO")
+	share.files["src/sub/b.txt"] = []byte("changed")
+	err = DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{Incremental: true})
+	c.Assert(err, chk.IsNil)
+	files, _ := readTestTree(c, root)
+	c.Assert(files["a.txt"], chk.Equals, "hello")
+	c.Assert(files["sub/b.txt"], chk.Equals, "changed")
+
+	share.lastWrite["src/a.txt"] = time.Date(2021, 6, 2, 12, 30, 0, 0, time.UTC).Format(smbTimeFormat)
+	err = DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{Incremental: true})
+	c.Assert(err, chk.IsNil)
+	files, _ = readTestTree(c, root)
+	c.Assert(files["a.txt"], chk.Equals, "HELLO")
+}
+
+func (s *downloadDirectorySuite) TestDownloadShareToDirectoryErrors(c *chk.C) {
+	share, dir := newTestDownloadShare()
+	share.dirs["src/baddir"] = true
+	share.files["src/bad.txt"] = []byte("fails")
+	share.failPattern = "bad"
+	root := c.MkDir()
+
+	err := DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{ContinueOnError: true})
+	c.Assert(err, chk.FitsTypeOf, &DirectoryTransferError{})
+	transferErr := err.(*DirectoryTransferError)
+	c.Assert(transferErr.Failures, chk.HasLen, 2)
+	c.Assert(transferErr.Failures[0].Path, chk.Equals, "bad.txt")
+	c.Assert(transferErr.Failures[1].Path, chk.Equals, "baddir")
+	c.Assert(isStorageError(transferErr.Failures[0].Error, http.StatusForbidden), chk.Equals, true)
+	files, _ := readTestTree(c, root)
+	c.Assert(files, chk.HasLen, 4) // The failed file leaves nothing behind
+	c.Assert(files["sub/deep/c.txt"], chk.Equals, "c")
+
+	// Cancelling stops the download, and removes the partial file.
+	share.failPattern = ""
+	root = c.MkDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	err = DownloadShareToDirectory(ctx, dir, root, DownloadDirOptions{
+		RangeSize:   4,
+		Parallelism: 1,
+		Progress: func(path string, bytesTransferred int64) {
+			if path == "sub/b.txt" && bytesTransferred > 0 {
+				cancel()
+			}
+		},
+	})
+	c.Assert(err, chk.NotNil)
+	_, statErr := os.Stat(filepath.Join(root, "sub", "b.txt"))
+	c.Assert(os.IsNotExist(statErr), chk.Equals, true)
+	_, statErr = os.Stat(filepath.Join(root, "sub", "b.txt"+DownloadTempFileSuffix))
+	c.Assert(os.IsNotExist(statErr), chk.Equals, true)
+
+	err = DownloadShareToDirectory(context.Background(), dir, root, DownloadDirOptions{RangeSize: -1})
+	c.Assert(err, chk.NotNil)
+}
@@ -0,0 +1,511 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type downloadSuite struct{}
+
+var _ = chk.Suite(&downloadSuite{})
+
+func (s *downloadSuite) TestDownloadValidateContentMD5(c *chk.C) {
+	var sent *http.Request
+	data := "0123456789"
+	sum := md5.Sum([]byte(data))
+	header := http.Header{}
+	header.Set("Content-Length", "10")
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestHandlesPipeline(header, data, &sent))
+
+	resp, err := fileURL.Download(context.Background(), 512, 10, true)
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Header.Get("x-ms-range-get-content-md5"), chk.Equals, "true")
+	download, err := ioutil.ReadAll(resp.Body(RetryReaderOptions{ValidateContentMD5: true}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(download), chk.Equals, data)
+
+	// The body doesn't match the MD5 returned for it.
+	fileURL = NewFileURL(*u, newTestHandlesPipeline(header, "012345678X", &sent))
+	resp, err = fileURL.Download(context.Background(), 512, 10, true)
+	c.Assert(err, chk.IsNil)
+	_, err = ioutil.ReadAll(resp.Body(RetryReaderOptions{ValidateContentMD5: true}))
+	integrityErr, ok := err.(*IntegrityError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(integrityErr.Offset, chk.Equals, int64(512))
+	c.Assert(integrityErr.Count, chk.Equals, int64(10))
+	c.Assert(integrityErr.ExpectedMD5, chk.DeepEquals, sum[:])
+
+	// The check is opt-in.
+	resp, err = fileURL.Download(context.Background(), 512, 10, true)
+	c.Assert(err, chk.IsNil)
+	_, err = ioutil.ReadAll(resp.Body(RetryReaderOptions{}))
+	c.Assert(err, chk.IsNil)
+
+	// The service can't return the MD5 of a range over 4MB.
+	sent = nil
+	_, err = fileURL.Download(context.Background(), 0, FileMaxRangeGetContentMD5Bytes+1, true)
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.IsNil)
+}
+
+// newTestRangeDownloadPipeline returns a pipeline that serves ranged downloads and the properties of a file with the
+// specified data, and records the method of each request in *methods.
+func newTestRangeDownloadPipeline(data []byte, methods *[]string) pipeline.Pipeline {
+	var mu sync.Mutex
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				*methods = append(*methods, request.Method)
+				mu.Unlock()
+				body, length := []byte(nil), len(data)
+				if request.Method == http.MethodGet {
+					r := strings.TrimPrefix(request.Header.Get("x-ms-range"), "bytes=")
+					end, _ := strconv.ParseInt(r[strings.Index(r, "-")+1:], 10, 64)
+					body = data[parseRangeStart(r) : end+1]
+					length = len(body)
+				}
+				response := newStatusResponse(request, http.StatusOK)
+				response.Response().Header.Set("Content-Length", strconv.Itoa(length))
+				response.Response().Body = ioutil.NopCloser(bytes.NewReader(body))
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *downloadSuite) TestDownloadWithKnownProperties(c *chk.C) {
+	methods := []string{}
+	data := []byte("0123456789")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestRangeDownloadPipeline(data, &methods))
+
+	props, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	methods = methods[:0]
+
+	b := make([]byte, 4)
+	resp, err := DownloadAzureFileToBuffer(context.Background(), fileURL, 6, CountToEnd, b,
+		DownloadFromAzureFileOptions{RangeSize: 2, Properties: props})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp, chk.Equals, props)
+	c.Assert(string(b), chk.Equals, "6789")
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodGet, http.MethodGet})
+
+	methods = methods[:0]
+	r, err := fileURL.NewReaderAt(context.Background(), ReaderAtOptions{Properties: props})
+	c.Assert(err, chk.IsNil)
+	n, err := r.ReadAt(b, 2)
+	c.Assert(err, chk.IsNil)
+	c.Assert(n, chk.Equals, 4)
+	c.Assert(string(b), chk.Equals, "2345")
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodGet})
+
+	// Without properties, the length still comes from GetProperties.
+	methods = methods[:0]
+	_, err = fileURL.NewReaderAt(context.Background(), ReaderAtOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead})
+}
+
+// closeCountingBody counts the times a response body is closed.
+type closeCountingBody struct {
+	io.Reader
+	closes *int
+}
+
+func (b closeCountingBody) Close() error {
+	*b.closes++
+	return nil
+}
+
+// limitedWriter accepts limit bytes, and then fails.
+type limitedWriter struct {
+	written int
+	limit   int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		return 0, errors.New("client went away")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func (s *downloadSuite) TestDownloadToWriter(c *chk.C) {
+	data := strings.Repeat("0123456789", 100)
+	var ranges []string
+	closes := 0
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				r := request.Header.Get("x-ms-range")
+				ranges = append(ranges, r)
+				offset := int(parseRangeStart(r))
+				var body io.Reader = strings.NewReader(data[offset:])
+				if len(ranges) == 1 {
+					// The first download's connection drops halfway through its body.
+					body = &failingReader{r: strings.NewReader(data[offset : len(data)/2]), err: &net.DNSError{IsTemporary: true}}
+				}
+				response := newStatusResponse(request, http.StatusOK)
+				response.Response().Header.Set("Content-Length", strconv.Itoa(len(data)-offset))
+				response.Response().Header.Set("ETag", `"0x1"`)
+				response.Response().Body = closeCountingBody{Reader: body, closes: &closes}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, p)
+
+	w := &bytes.Buffer{}
+	var reports []int64
+	n, err := fileURL.DownloadToWriter(context.Background(), w, 100, CountToEnd, DownloadToWriterOptions{MaxRetryRequests: 1,
+		Progress: func(bytesTransferred int64) { reports = append(reports, bytesTransferred) }})
+	c.Assert(err, chk.IsNil)
+	c.Assert(n, chk.Equals, int64(900))
+	c.Assert(w.String(), chk.Equals, data[100:])
+	c.Assert(ranges, chk.DeepEquals, []string{"bytes=100-", "bytes=500-"})
+	c.Assert(reports[len(reports)-1], chk.Equals, int64(900))
+	c.Assert(closes, chk.Equals, 2)
+
+	// A failed write ends the download, and closes its connection.
+	ranges, closes = nil, 0
+	lw := &limitedWriter{limit: 10}
+	n, err = fileURL.DownloadToWriter(context.Background(), lw, 0, CountToEnd, DownloadToWriterOptions{MaxRetryRequests: 1})
+	c.Assert(err, chk.ErrorMatches, "client went away")
+	c.Assert(n, chk.Equals, int64(lw.written))
+	c.Assert(ranges, chk.HasLen, 1)
+	c.Assert(closes, chk.Equals, 1)
+
+	_, err = fileURL.DownloadToWriter(context.Background(), nil, 0, CountToEnd, DownloadToWriterOptions{})
+	c.Assert(err, chk.NotNil)
+}
+
+// newTestVerifiedDownloadPipeline returns a pipeline that serves the properties and ranges of a file with the
+// specified data, with each range's MD5 if it's requested, and records the range of each download in *ranges.
+// A range starting at corruptAt is returned with one byte changed; a negative corruptAt corrupts nothing.
+func newTestVerifiedDownloadPipeline(data []byte, corruptAt int64, ranges *[]string) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				response := newStatusResponse(request, http.StatusOK)
+				header := response.Response().Header
+				header.Set("ETag", `"0x1"`)
+				body := []byte(nil)
+				if request.Method == http.MethodGet {
+					r := request.Header.Get("x-ms-range")
+					*ranges = append(*ranges, r)
+					start := parseRangeStart(r)
+					end, _ := strconv.ParseInt(r[strings.Index(r, "-")+1:], 10, 64)
+					if start >= int64(len(data)) {
+						return newStatusResponse(request, http.StatusRequestedRangeNotSatisfiable), nil
+					}
+					if end >= int64(len(data)) {
+						end = int64(len(data)) - 1 // The service clamps a range to the file's end
+					}
+					header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+					body = append(body, data[start:end+1]...)
+					if request.Header.Get("x-ms-range-get-content-md5") == "true" {
+						sum := md5.Sum(body)
+						header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+					}
+					if start == corruptAt {
+						body[0] ^= 0xff
+					}
+					header.Set("Content-Length", strconv.Itoa(len(body)))
+				} else {
+					header.Set("Content-Length", strconv.Itoa(len(data)))
+				}
+				response.Response().Body = ioutil.NopCloser(bytes.NewReader(body))
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *downloadSuite) TestDownloadAndVerify(c *chk.C) {
+	data := make([]byte, 2*FileMaxRangeGetContentMD5Bytes+100)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	var ranges []string
+
+	fileURL := NewFileURL(*u, newTestVerifiedDownloadPipeline(data, -1, &ranges))
+	r, err := fileURL.DownloadAndVerify(context.Background(), 50, CountToEnd, DownloadAndVerifyOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(ranges, chk.HasLen, 1)
+	download, err := ioutil.ReadAll(r)
+	c.Assert(err, chk.IsNil)
+	c.Assert(r.Close(), chk.IsNil)
+	c.Assert(bytes.Equal(download, data[50:]), chk.Equals, true)
+	c.Assert(ranges, chk.DeepEquals, []string{"bytes=50-4194353", "bytes=4194354-8388657", "bytes=8388658-8388707"})
+
+	// The corrupt range is identified.
+	ranges = nil
+	fileURL = NewFileURL(*u, newTestVerifiedDownloadPipeline(data, FileMaxRangeGetContentMD5Bytes, &ranges))
+	r, err = fileURL.DownloadAndVerify(context.Background(), 0, 2*FileMaxRangeGetContentMD5Bytes, DownloadAndVerifyOptions{})
+	c.Assert(err, chk.IsNil)
+	_, err = ioutil.ReadAll(r)
+	integrityErr, ok := err.(*IntegrityError)
+	c.Assert(ok, chk.Equals, true, chk.Commentf("%v", err))
+	c.Assert(integrityErr.Offset, chk.Equals, int64(FileMaxRangeGetContentMD5Bytes))
+	c.Assert(integrityErr.Count, chk.Equals, int64(FileMaxRangeGetContentMD5Bytes))
+	c.Assert(r.Close(), chk.IsNil)
+
+	// A count that runs past the end of the file stops at its end.
+	ranges = nil
+	fileURL = NewFileURL(*u, newTestVerifiedDownloadPipeline(data[:1000], -1, &ranges))
+	r, err = fileURL.DownloadAndVerify(context.Background(), 0, 10*FileMaxRangeGetContentMD5Bytes, DownloadAndVerifyOptions{})
+	c.Assert(err, chk.IsNil)
+	download, err = ioutil.ReadAll(r)
+	c.Assert(err, chk.IsNil)
+	c.Assert(r.Close(), chk.IsNil)
+	c.Assert(bytes.Equal(download, data[:1000]), chk.Equals, true)
+	c.Assert(ranges, chk.DeepEquals, []string{"bytes=0-4194303"})
+
+	_, err = fileURL.DownloadAndVerify(context.Background(), int64(len(data))+1, CountToEnd, DownloadAndVerifyOptions{})
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *downloadSuite) TestDownloadContentRange(c *chk.C) {
+	var sent *http.Request
+	header := http.Header{}
+	header.Set("Content-Length", "6")
+	header.Set("Content-Range", "bytes 1024-1029/1030") // The service clamped the requested range to the file's end
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestHandlesPipeline(header, "abcdef", &sent))
+
+	resp, err := fileURL.Download(context.Background(), 1024, 512, false)
+	c.Assert(err, chk.IsNil)
+	r, err := resp.NewContentRange()
+	c.Assert(err, chk.IsNil)
+	c.Assert(r, chk.Equals, FileContentRange{Start: 1024, End: 1029, Size: 1030})
+	c.Assert(r.Count(), chk.Equals, int64(6))
+
+	reader, err := resp.NewRangeReader(RetryReaderOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(reader.Range(), chk.Equals, r)
+	c.Assert(reader.Offset(), chk.Equals, int64(1024))
+	b := make([]byte, 4)
+	_, err = io.ReadFull(reader, b)
+	c.Assert(err, chk.IsNil)
+	c.Assert(reader.Offset(), chk.Equals, int64(1028))
+	c.Assert(reader.Close(), chk.IsNil)
+
+	// A download of the whole file has no Content-Range.
+	header.Del("Content-Range")
+	resp, err = fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	r, err = resp.NewContentRange()
+	c.Assert(err, chk.IsNil)
+	c.Assert(r, chk.Equals, FileContentRange{Start: 0, End: 5, Size: 6})
+
+	for _, malformed := range []string{"bytes */1030", "bytes 10-5/1030", "bytes 0-1030/1030", "items 0-5/6", "bytes 0-5/6x"} {
+		header.Set("Content-Range", malformed)
+		resp, err = fileURL.Download(context.Background(), 0, 6, false)
+		c.Assert(err, chk.IsNil)
+		_, err = resp.NewContentRange()
+		c.Assert(err, chk.ErrorMatches, "malformed Content-Range .*", chk.Commentf(malformed))
+		_, err = resp.NewRangeReader(RetryReaderOptions{})
+		c.Assert(err, chk.NotNil)
+	}
+}
+
+func (s *downloadSuite) TestDownloadChangedRanges(c *chk.C) {
+	data := []byte("0123456789abcdefghij")
+	etag := "\"v1\""
+	var prevSnapshots []string
+	var mu sync.Mutex
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				response := newStatusResponse(request, http.StatusOK)
+				response.Response().Header.Set("ETag", etag)
+				switch {
+				case request.URL.Query().Get("comp") == "rangelist":
+					prevSnapshots = append(prevSnapshots, request.URL.Query().Get("prevsharesnapshot"))
+					response.Response().Body = ioutil.NopCloser(strings.NewReader(
+						"<Ranges><Range><Start>2</Start><End>6</End></Range><Range><Start>15</Start><End>16</End></Range></Ranges>"))
+				case request.Method == http.MethodGet:
+					r := strings.TrimPrefix(request.Header.Get("x-ms-range"), "bytes=")
+					end, _ := strconv.ParseInt(r[strings.Index(r, "-")+1:], 10, 64)
+					body := data[parseRangeStart(r) : end+1]
+					response.Response().Header.Set("Content-Length", strconv.Itoa(len(body)))
+					response.Response().Body = ioutil.NopCloser(bytes.NewReader(body))
+				default:
+					response.Response().Header.Set("Content-Length", strconv.Itoa(len(data)))
+				}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, p)
+
+	// The destination holds the file as of the snapshot; only the changed ranges are written.
+	dest, err := ioutil.TempFile(c.MkDir(), "backup")
+	c.Assert(err, chk.IsNil)
+	defer dest.Close()
+	_, err = dest.WriteString(strings.Repeat("-", len(data)))
+	c.Assert(err, chk.IsNil)
+	var progress int64
+	ranges, err := DownloadChangedRanges(context.Background(), fileURL, "2021-01-01T00:00:00.0000000Z", dest,
+		DownloadFromAzureFileOptions{RangeSize: 2, Progress: func(bytesTransferred int64) { progress = bytesTransferred }})
+	c.Assert(err, chk.IsNil)
+	c.Assert(ranges, chk.DeepEquals, []Range{{Start: 2, End: 6}, {Start: 15, End: 16}})
+	c.Assert(prevSnapshots, chk.DeepEquals, []string{"2021-01-01T00:00:00.0000000Z"})
+	c.Assert(progress, chk.Equals, int64(7))
+	backup, err := ioutil.ReadFile(dest.Name())
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(backup), chk.Equals, "--23456--------fg---")
+
+	// The file changed after its properties were read.
+	props, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	mu.Lock()
+	etag = "\"v2\""
+	mu.Unlock()
+	_, err = DownloadChangedRanges(context.Background(), fileURL, "2021-01-01T00:00:00.0000000Z", dest,
+		DownloadFromAzureFileOptions{Properties: props})
+	c.Assert(err, chk.ErrorMatches, ".*"+FileModifiedDuringReadMessage)
+
+	_, err = DownloadChangedRanges(context.Background(), fileURL, "", dest, DownloadFromAzureFileOptions{})
+	c.Assert(err, chk.NotNil)
+	_, err = DownloadChangedRanges(context.Background(), fileURL, "snapshot", nil, DownloadFromAzureFileOptions{})
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *downloadSuite) TestDownloadMaxChunkRetries(c *chk.C) {
+	data := []byte("0123456789")
+	var mu sync.Mutex
+	failures := map[string]int{} // The number of times the download of each range is still to fail
+	partial := map[string]int{}  // The number of times the body of each range is still to fail midway
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				r := request.Header.Get("x-ms-range")
+				mu.Lock()
+				defer mu.Unlock()
+				if failures[r] > 0 {
+					failures[r]--
+					return newStatusResponse(request, http.StatusServiceUnavailable), nil
+				}
+				start := parseRangeStart(strings.TrimPrefix(r, "bytes="))
+				end := start + 4
+				if end > int64(len(data)) {
+					end = int64(len(data))
+				}
+				response := newStatusResponse(request, http.StatusPartialContent)
+				response.Response().Header.Set("Content-Length", strconv.FormatInt(end-start, 10))
+				response.Response().Body = ioutil.NopCloser(bytes.NewReader(data[start:end]))
+				if partial[r] > 0 {
+					partial[r]--
+					response.Response().Body = ioutil.NopCloser(&errorAfterReader{r: bytes.NewReader(data[start:end]), limit: 3, err: errors.New("connection reset")})
+				}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, p)
+	props := &FileGetPropertiesResponse{rawResponse: &http.Response{Header: http.Header{"Content-Length": []string{"10"}}}}
+
+	// A range that fails is started over, up to MaxChunkRetries times, without failing the others.
+	failures["bytes=4-7"] = 2
+	b := make([]byte, len(data))
+	_, err := DownloadAzureFileToBuffer(context.Background(), fileURL, 0, CountToEnd, b,
+		DownloadFromAzureFileOptions{RangeSize: 4, Properties: props, MaxChunkRetries: 2})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(b), chk.Equals, string(data))
+
+	// A range started over after part of it was received doesn't make the progress go backwards.
+	partial["bytes=0-3"] = 1
+	var reported []int64
+	_, err = DownloadAzureFileToBuffer(context.Background(), fileURL, 0, CountToEnd, b,
+		DownloadFromAzureFileOptions{RangeSize: 4, Parallelism: 1, Properties: props, MaxChunkRetries: 1,
+			Progress: func(bytesTransferred int64) { reported = append(reported, bytesTransferred) }})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(b), chk.Equals, string(data))
+	c.Assert(partial["bytes=0-3"], chk.Equals, 0)
+	for i := 1; i < len(reported); i++ {
+		c.Assert(reported[i] > reported[i-1], chk.Equals, true, chk.Commentf("%v", reported))
+	}
+	c.Assert(reported[len(reported)-1], chk.Equals, int64(10))
+
+	// A range that fails more times than that fails the download, naming the range.
+	failures["bytes=8-9"] = 2
+	_, err = DownloadAzureFileToBuffer(context.Background(), fileURL, 0, CountToEnd, b,
+		DownloadFromAzureFileOptions{RangeSize: 4, Properties: props, MaxChunkRetries: 1})
+	c.Assert(err, chk.FitsTypeOf, &RangeDownloadError{})
+	rangeErr := err.(*RangeDownloadError)
+	c.Assert(rangeErr.Offset, chk.Equals, int64(8))
+	c.Assert(rangeErr.Count, chk.Equals, int64(2))
+	c.Assert(isStorageError(err, http.StatusServiceUnavailable), chk.Equals, true)
+	c.Assert(strings.HasPrefix(err.Error(), "failed to download the 2 bytes at offset 8: "), chk.Equals, true)
+
+	_, err = DownloadAzureFileToBuffer(context.Background(), fileURL, 0, CountToEnd, b,
+		DownloadFromAzureFileOptions{Properties: props, MaxChunkRetries: -1})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, o.MaxChunkRetries must be >= 0")
+}
+
+func (s *downloadSuite) TestDownloadMaxFileSize(c *chk.C) {
+	methods := []string{}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestRangeDownloadPipeline([]byte("0123456789"), &methods))
+
+	// A file over the limit is refused once its size is known, even to download a part of it.
+	b := make([]byte, 10)
+	_, err := DownloadAzureFileToBuffer(context.Background(), fileURL, 0, 2, b, DownloadFromAzureFileOptions{MaxFileSize: 9})
+	c.Assert(err, chk.FitsTypeOf, &FileTooLargeError{})
+	c.Assert(*err.(*FileTooLargeError), chk.Equals, FileTooLargeError{Size: 10, MaxSize: 9})
+	c.Assert(err, chk.ErrorMatches, "the file's 10 bytes exceed the limit of 9 bytes")
+	c.Assert(methods, chk.DeepEquals, []string{http.MethodHead})
+
+	// The local file isn't touched.
+	local, err := ioutil.TempFile(c.MkDir(), "download")
+	c.Assert(err, chk.IsNil)
+	defer local.Close()
+	_, err = local.WriteString("old")
+	c.Assert(err, chk.IsNil)
+	_, err = DownloadAzureFileToFile(context.Background(), fileURL, 0, CountToEnd, local, DownloadFromAzureFileOptions{MaxFileSize: 9})
+	c.Assert(err, chk.FitsTypeOf, &FileTooLargeError{})
+	info, err := local.Stat()
+	c.Assert(err, chk.IsNil)
+	c.Assert(info.Size(), chk.Equals, int64(3))
+
+	_, err = DownloadAzureFileToFile(context.Background(), fileURL, 0, CountToEnd, local, DownloadFromAzureFileOptions{MaxFileSize: 10})
+	c.Assert(err, chk.IsNil)
+	content, err := ioutil.ReadFile(local.Name())
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(content), chk.Equals, "0123456789")
+
+	_, err = DownloadAzureFileToBuffer(context.Background(), fileURL, 0, CountToEnd, b, DownloadFromAzureFileOptions{MaxFileSize: -1})
+	c.Assert(err, chk.NotNil)
+}
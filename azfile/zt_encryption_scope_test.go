@@ -0,0 +1,95 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type encryptionScopeSuite struct{}
+
+var _ = chk.Suite(&encryptionScopeSuite{})
+
+func (s *encryptionScopeSuite) TestEncryptionScopeHeaders(c *chk.C) {
+	var sent http.Header
+	responseHeader := http.Header{}
+	responseHeader.Set("x-ms-encryption-scope", "scope")
+	responseHeader.Set("x-ms-request-server-encrypted", "true")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	p := newTestCapturePipeline(http.StatusCreated, responseHeader, &sent)
+	fileURL := NewFileURL(*u, p)
+
+	_, err := NewShareURL(*u, p).Create(context.Background(), nil, ShareCreateOptions{EncryptionScope: "scope"})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-encryption-scope"), chk.Equals, "scope")
+
+	createResp, err := fileURL.Create(context.Background(), 4, FileHTTPHeaders{EncryptionScope: "scope"}, nil, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-encryption-scope"), chk.Equals, "scope")
+	c.Assert(createResp.EncryptionScope(), chk.Equals, "scope")
+	c.Assert(createResp.IsServerEncrypted(), chk.Equals, "true")
+
+	uploadResp, err := fileURL.UploadRange(context.Background(), 0, strings.NewReader("data"), nil,
+		UploadRangeOptions{EncryptionScope: "scope"})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-encryption-scope"), chk.Equals, "scope")
+	c.Assert(uploadResp.EncryptionScope(), chk.Equals, "scope")
+
+	fileURL = fileURL.WithPipeline(newTestCapturePipeline(http.StatusOK, responseHeader, &sent))
+	setResp, err := fileURL.SetProperties(context.Background(), FileHTTPHeaders{EncryptionScope: "scope"}, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-encryption-scope"), chk.Equals, "scope")
+	c.Assert(setResp.EncryptionScope(), chk.Equals, "scope")
+
+	// Requests without a scope don't send the header, and the file's scope is returned with its HTTP headers.
+	_, err = fileURL.Resize(context.Background(), 8, ResizeOptions{})
+	c.Assert(err, chk.IsNil)
+	_, ok := sent["X-Ms-Encryption-Scope"]
+	c.Assert(ok, chk.Equals, false)
+	getResp, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(getResp.NewHTTPHeaders().EncryptionScope, chk.Equals, "scope")
+}
+
+func (s *encryptionScopeSuite) TestUploadBufferEncryptionScope(c *chk.C) {
+	mu := sync.Mutex{}
+	var scopes []string
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				scopes = append(scopes, request.Header.Get("x-ms-encryption-scope"))
+				return newStatusResponse(request, http.StatusCreated), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+
+	// The file and each of its ranges are encrypted with the scope of the file's HTTP headers.
+	err := UploadBufferToAzureFile(context.Background(), bytes.Repeat([]byte("a"), 10), NewFileURL(*u, p),
+		UploadToAzureFileOptions{RangeSize: 4, FileHTTPHeaders: FileHTTPHeaders{EncryptionScope: "scope"}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(scopes, chk.DeepEquals, []string{"scope", "scope", "scope", "scope"})
+}
+
+func (s *encryptionScopeSuite) TestEncryptionScopeUnknown(c *chk.C) {
+	// The service's error for a scope the account doesn't have is returned as is.
+	responseHeader := http.Header{}
+	responseHeader.Set("x-ms-error-code", "EncryptionScopeNotFound")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestErrorPipeline(http.StatusConflict, responseHeader, ""))
+
+	_, err := fileURL.Create(context.Background(), 4, FileHTTPHeaders{EncryptionScope: "unknown"}, nil, FileAccessConditions{})
+	storageErr, ok := err.(StorageError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(storageErr.ServiceCode(), chk.Equals, ServiceCodeType("EncryptionScopeNotFound"))
+	c.Assert(storageErr.Response().StatusCode, chk.Equals, http.StatusConflict)
+}
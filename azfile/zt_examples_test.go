@@ -57,7 +57,7 @@ func Example() {
 	shareURL := serviceURL.NewShareURL("mysharehelloworld") // Share names require lowercase
 
 	// Create the share on the service (with no metadata and default quota size)
-	_, err = shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err = shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	if err != nil && err.(azfile.StorageError) != nil && err.(azfile.StorageError).ServiceCode() != azfile.ServiceCodeShareAlreadyExists {
 		log.Fatal(err)
 	}
@@ -73,12 +73,12 @@ func Example() {
 	// Create the file with string (plain text) content.
 	data := "Hello World!"
 	length := int64(len(data))
-	_, err = fileURL.Create(ctx, length, azfile.FileHTTPHeaders{ContentType: "text/plain"}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, length, azfile.FileHTTPHeaders{ContentType: "text/plain"}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	_, err = fileURL.UploadRange(ctx, 0, strings.NewReader(data), nil)
+	_, err = fileURL.UploadRange(ctx, 0, strings.NewReader(data), nil, azfile.UploadRangeOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -99,7 +99,7 @@ func Example() {
 
 	// New a reference to a directory with name DemoDir in share, and create the directory.
 	directoryDemoURL := shareURL.NewDirectoryURL("DemoDir")
-	_, err = directoryDemoURL.Create(ctx, azfile.Metadata{})
+	_, err = directoryDemoURL.Create(ctx, azfile.Metadata{}, azfile.SMBProperties{})
 	if err != nil && err.(azfile.StorageError) != nil && err.(azfile.StorageError).ServiceCode() != azfile.ServiceCodeResourceAlreadyExists {
 		log.Fatal(err)
 	}
@@ -127,13 +127,13 @@ func Example() {
 	}
 
 	// Delete the file we created earlier.
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Delete the share we created earlier (with azfile.DeleteSnapshotsOptionNone as no snapshot exists and needs to be deleted).
-	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -241,7 +241,7 @@ func ExampleStorageError() {
 
 	u, _ := url.Parse("http://myaccount.file.core.windows.net/myshare") // Suppose there is an existing storage account with name myaccount
 	shareURL := azfile.NewShareURL(*u, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
-	create, err := shareURL.Create(context.Background(), azfile.Metadata{}, 0)
+	create, err := shareURL.Create(context.Background(), azfile.Metadata{}, azfile.ShareCreateOptions{})
 
 	if err != nil { // Suppose there is an error occurred
 		if serr, ok := err.(azfile.StorageError); ok { // This error is a Service-specific error
@@ -411,7 +411,7 @@ func ExampleFileSASSignatureValues() {
 	// If you have a SAS query parameter string, you can parse it into its parts:
 	fileURLParts := azfile.NewFileURLParts(fileURL.URL())
 	fmt.Printf("SAS expiry time=%v", fileURLParts.SAS.ExpiryTime())
-	fmt.Printf(urlToSendToSomeone)
+	fmt.Print(urlToSendToSomeone)
 
 	_ = fileURL // Avoid compiler's "declared and not used" error
 }
@@ -434,7 +434,7 @@ func ExampleShareURL() {
 	// Create a share with some metadata (string key/value pairs) and default quota.
 	// NOTE: Metadata key names are always converted to lowercase before being sent to the Storage Service.
 	// Therefore, you should always use lowercase letters; especially when querying a map for a metadata key.
-	_, err = shareURL.Create(ctx, azfile.Metadata{"createdby": "Jeffrey&Jiachen"}, 0)
+	_, err = shareURL.Create(ctx, azfile.Metadata{"createdby": "Jeffrey&Jiachen"}, azfile.ShareCreateOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -453,7 +453,7 @@ func ExampleShareURL() {
 
 	// Update the metadata and write it back to the share
 	metadata["updateby"] = "Jiachen" // NOTE: The keyname is in all lowercase letters
-	_, err = shareURL.SetMetadata(ctx, metadata)
+	_, err = shareURL.SetMetadata(ctx, metadata, azfile.ShareAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -461,7 +461,7 @@ func ExampleShareURL() {
 	// NOTE: The SetMetadata & SetQuota methods update the share's ETag & LastModified properties
 
 	// Delete the share
-	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -488,7 +488,7 @@ func ExampleShareURL_SetQuota() {
 
 	ctx := context.Background() // This example uses a never-expiring context
 
-	_, err = shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err = shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -498,7 +498,7 @@ func ExampleShareURL_SetQuota() {
 	if statistics, err := shareURL.GetStatistics(ctx); err == nil {
 		fmt.Printf("Current share usage: %d GB\n", statistics.ShareUsage)
 
-		shareURL.SetQuota(ctx, 10+statistics.ShareUsage)
+		shareURL.SetQuota(ctx, 10+statistics.ShareUsage, azfile.ShareAccessConditions{})
 
 		properties, err := shareURL.GetProperties(ctx)
 		if err != nil {
@@ -508,7 +508,7 @@ func ExampleShareURL_SetQuota() {
 		fmt.Printf("Updated share usage: %d GB\n", properties.Quota())
 	}
 
-	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -535,16 +535,16 @@ func ExampleShareURL_CreateSnapshot() {
 	shareName := "baseshare"
 	shareURL := serviceURL.NewShareURL(shareName)
 
-	_, err = shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err = shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	defer shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionInclude)
+	defer shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionInclude, azfile.ShareAccessConditions{})
 
 	// Let's create a file in the base share.
 	fileURL := shareURL.NewRootDirectoryURL().NewFileURL("myfile")
-	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -562,7 +562,7 @@ func ExampleShareURL_CreateSnapshot() {
 	}
 
 	// Delete file in base share.
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -589,13 +589,13 @@ func ExampleShareURL_CreateSnapshot() {
 	sourceURL := fileParts.URL()
 
 	// Do restore.
-	fileURL.StartCopy(ctx, sourceURL, azfile.Metadata{})
+	fileURL.StartCopy(ctx, sourceURL, azfile.Metadata{}, azfile.StartCopyOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Delete share snapshot. To delete individual share snapshot, please use azfile.DeleteSnapshotsOptionNone
-	_, err = shareURL.WithSnapshot(snapshotShare.Snapshot()).Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = shareURL.WithSnapshot(snapshotShare.Snapshot()).Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -616,7 +616,7 @@ func ExampleFileURL() {
 	u, _ := url.Parse(fmt.Sprintf("https://%s.file.core.windows.net/myshare", accountName))
 	shareURL := azfile.NewShareURL(*u, azfile.NewPipeline(credential, azfile.PipelineOptions{}))
 
-	_, err = shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err = shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	if err != nil && err.(azfile.StorageError) != nil && err.(azfile.StorageError).ServiceCode() != azfile.ServiceCodeShareAlreadyExists {
 		log.Fatal(err)
 	}
@@ -628,14 +628,14 @@ func ExampleFileURL() {
 	// Create the file with string (plain text) content.
 	d1 := "Hello "
 	d1Length := int64(len(d1))
-	_, err = fileURL.Create(ctx, d1Length, azfile.FileHTTPHeaders{ContentType: "text/plain"}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, d1Length, azfile.FileHTTPHeaders{ContentType: "text/plain"}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// UploadRange updates data in the file with the range for d1.
 	// In this stage, file created has one range: [0, d1Length-1]
-	_, err = fileURL.UploadRange(ctx, 0, strings.NewReader(d1), nil)
+	_, err = fileURL.UploadRange(ctx, 0, strings.NewReader(d1), nil, azfile.UploadRangeOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -647,14 +647,14 @@ func ExampleFileURL() {
 	totalLength := d1Length + d2Length
 
 	// Resize the file, as we want to save more data in this file.
-	_, err = fileURL.Resize(ctx, totalLength)
+	_, err = fileURL.Resize(ctx, totalLength, azfile.ResizeOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// UploadRange updates data in the file with the range for d2.
 	// In this stage, file created has two ranges: [0, length-1] for data and [d2Offset, totalLength-1] for d2.
-	_, err = fileURL.UploadRange(ctx, d2Offset, strings.NewReader(d2), nil)
+	_, err = fileURL.UploadRange(ctx, d2Offset, strings.NewReader(d2), nil, azfile.UploadRangeOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -701,7 +701,7 @@ func ExampleFileURL_GetProperties() {
 	// Create a file with metadata (string key/value pairs)
 	// NOTE: Metadata key names are always converted to lowercase before being sent to the Storage Service.
 	// Therefore, you should always use lowercase letters; especially when querying a map for a metadata key.
-	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{"createdby": "Jeffrey&Jiachen"}) // With size 0
+	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{"createdby": "Jeffrey&Jiachen"}, azfile.FileAccessConditions{}) // With size 0
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -723,14 +723,14 @@ func ExampleFileURL_GetProperties() {
 
 	// Update the file's metadata and write it back to the file
 	metadata["updatedby"] = "Jiachen" // Add a new key/value; NOTE: The keyname is in all lowercase letters
-	_, err = fileURL.SetMetadata(ctx, metadata)
+	_, err = fileURL.SetMetadata(ctx, metadata, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// NOTE: The SetMetadata method updates the file's ETag & LastModified properties
 
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -758,7 +758,7 @@ func ExampleFileURL_SetHTTPHeaders() {
 			ContentType:        "text/html; charset=utf-8",
 			ContentDisposition: "attachment",
 		},
-		azfile.Metadata{}) // With size 0
+		azfile.Metadata{}, azfile.FileAccessConditions{}) // With size 0
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -775,14 +775,14 @@ func ExampleFileURL_SetHTTPHeaders() {
 
 	// Update the file's HTTP Headers and write them back to the file
 	httpHeaders.ContentType = "text/plain"
-	_, err = fileURL.SetHTTPHeaders(ctx, httpHeaders)
+	_, err = fileURL.SetHTTPHeaders(ctx, httpHeaders, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// NOTE: The SetHTTPHeaders method updates the file's ETag & LastModified properties
 
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -811,21 +811,21 @@ func ExampleFileURL_progressUploadDownload() {
 	requestBody := strings.NewReader("Some text to write")
 	size := requestBody.Len()
 
-	// Wrap the request body in a RequestBodyProgress and pass a callback function for progress reporting.
 	_, err = fileURL.Create(ctx, int64(size),
 		azfile.FileHTTPHeaders{
 			ContentType:        "text/html; charset=utf-8",
 			ContentDisposition: "attachment",
 		},
-		azfile.Metadata{})
+		azfile.Metadata{}, azfile.FileAccessConditions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	_, err = fileURL.UploadRange(ctx, 0,
-		pipeline.NewRequestBodyProgress(requestBody, func(bytesTransferred int64) {
+	// Pass a callback function for progress reporting in UploadRangeOptions.
+	_, err = fileURL.UploadRange(ctx, 0, requestBody, nil,
+		azfile.UploadRangeOptions{Progress: func(bytesTransferred int64) {
 			fmt.Printf("Wrote %d of %d bytes.\n", bytesTransferred, size)
-		}), nil)
+		}})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -835,10 +835,10 @@ func ExampleFileURL_progressUploadDownload() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	// Wrap the response body in a ResponseBodyProgress and pass a callback function for progress reporting.
-	responseBody := pipeline.NewResponseBodyProgress(get.Body(azfile.RetryReaderOptions{}), func(bytesTransferred int64) {
+	// Pass a callback function for progress reporting in RetryReaderOptions.
+	responseBody := get.Body(azfile.RetryReaderOptions{MaxRetryRequests: 3, Progress: func(bytesTransferred int64) {
 		fmt.Printf("Read %d of %d bytes.\n", bytesTransferred, get.ContentLength())
-	})
+	}})
 
 	downloadedData := &bytes.Buffer{}
 	downloadedData.ReadFrom(responseBody)
@@ -863,7 +863,7 @@ func ExampleFileURL_StartCopy() {
 	ctx := context.Background() // This example uses a never-expiring context
 
 	src, _ := url.Parse("https://cdn2.auth0.com/docs/media/addons/azure_file.svg") // Suppose this is an accessible source resource
-	startCopy, err := fileURL.StartCopy(ctx, *src, nil)
+	startCopy, err := fileURL.StartCopy(ctx, *src, nil, azfile.StartCopyOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -997,7 +997,7 @@ func ExampleDownloadAzureFileToFile() {
 
 	// Trigger parallel download with Parallelism set to 3, MaxRetryRequestsPerRange means the count of retry requests
 	// could be sent if there is error during reading stream.
-	downloadResponse, err := azfile.DownloadAzureFileToFile(context.Background(), fileURL, file,
+	downloadResponse, err := azfile.DownloadAzureFileToFile(context.Background(), fileURL, 0, azfile.CountToEnd, file,
 		azfile.DownloadFromAzureFileOptions{
 			Parallelism:              3,
 			MaxRetryRequestsPerRange: 2,
@@ -0,0 +1,97 @@
+package azfile
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type handlesSuite struct{}
+
+var _ = chk.Suite(&handlesSuite{})
+
+// newTestHandlesPipeline returns a pipeline that records each request in *sent and answers with responseHeader and body.
+func newTestHandlesPipeline(responseHeader http.Header, body string, sent **http.Request) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				*sent = request.Request
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     responseHeader,
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *handlesSuite) TestListHandles(c *chk.C) {
+	var sent *http.Request
+	body := `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Entries><Handle>` +
+		`<HandleId>77059027969</HandleId><Path>dir/file</Path><FileId>11529285414812647424</FileId>` +
+		`<ParentId>13835128424026341376</ParentId><SessionId>9385737614310506553</SessionId><ClientIp>10.0.0.4:49721</ClientIp>` +
+		`<OpenTime>Tue, 08 Sep 2020 22:56:16 GMT</OpenTime></Handle></Entries><NextMarker>m1</NextMarker></EnumerationResults>`
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	p := newTestHandlesPipeline(http.Header{}, body, &sent)
+
+	resp, err := NewDirectoryURL(*u, p).ListHandles(context.Background(), Marker{}, ListHandlesOptions{MaxResults: 1, Recursive: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "listhandles")
+	c.Assert(sent.URL.Query().Get("maxresults"), chk.Equals, "1")
+	c.Assert(sent.Header.Get("x-ms-recursive"), chk.Equals, "true")
+	c.Assert(resp.HandleList, chk.HasLen, 1)
+	h := resp.HandleList[0]
+	c.Assert(h.HandleID, chk.Equals, "77059027969")
+	c.Assert(h.Path, chk.Equals, "dir/file")
+	c.Assert(h.FileID, chk.Equals, "11529285414812647424")
+	c.Assert(*h.ParentID, chk.Equals, "13835128424026341376")
+	c.Assert(h.ClientIP, chk.Equals, "10.0.0.4:49721")
+	c.Assert(h.OpenTime.Equal(time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(h.LastReconnectTime, chk.IsNil)
+	c.Assert(resp.NextMarker.NotDone(), chk.Equals, true)
+
+	_, err = NewFileURL(*u, p).ListHandles(context.Background(), resp.NextMarker, ListHandlesOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("marker"), chk.Equals, "m1")
+	c.Assert(sent.Header.Get("x-ms-recursive"), chk.Equals, "")
+}
+
+func (s *handlesSuite) TestForceCloseHandles(c *chk.C) {
+	var sent *http.Request
+	header := http.Header{}
+	header.Set("x-ms-number-of-handles-closed", "3")
+	header.Set("x-ms-number-of-handles-failed", "0")
+	header.Set("x-ms-marker", "m1")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	p := newTestHandlesPipeline(header, "", &sent)
+
+	resp, err := NewDirectoryURL(*u, p).ForceCloseAllHandles(context.Background(), Marker{}, ForceCloseHandlesOptions{Recursive: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Method, chk.Equals, http.MethodPut)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "forceclosehandles")
+	c.Assert(sent.Header.Get("x-ms-handle-id"), chk.Equals, "*")
+	c.Assert(sent.Header.Get("x-ms-recursive"), chk.Equals, "true")
+	c.Assert(resp.NumberOfHandlesClosed(), chk.Equals, int32(3))
+	c.Assert(resp.NumberOfHandlesFailedToClose(), chk.Equals, int32(0))
+	marker := resp.NextMarker()
+	c.Assert(marker.NotDone(), chk.Equals, true)
+
+	header.Del("x-ms-marker")
+	fileResp, err := NewFileURL(*u, p).ForceCloseHandles(context.Background(), "77059027969", marker, ForceCloseHandlesOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("marker"), chk.Equals, "m1")
+	c.Assert(sent.Header.Get("x-ms-handle-id"), chk.Equals, "77059027969")
+	c.Assert(fileResp.NextMarker().NotDone(), chk.Equals, false)
+
+	_, err = NewFileURL(*u, p).ForceCloseHandles(context.Background(), "", Marker{}, ForceCloseHandlesOptions{})
+	c.Assert(err, chk.NotNil)
+}
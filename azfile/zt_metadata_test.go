@@ -0,0 +1,105 @@
+package azfile
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	chk "gopkg.in/check.v1"
+)
+
+type metadataSuite struct{}
+
+var _ = chk.Suite(&metadataSuite{})
+
+func (s *metadataSuite) TestMetadataValidate(c *chk.C) {
+	c.Assert(Metadata(nil).Validate(), chk.IsNil)
+	c.Assert(Metadata{"foo": "bar", "_Foo2": "", "BAR_baz": "x"}.Validate(), chk.IsNil)
+
+	for _, key := range []string{"", "2foo", "foo-bar", "foo bar", "föo", "!@#$%^&*()"} {
+		err := Metadata{key: "v"}.Validate()
+		c.Assert(err, chk.NotNil, chk.Commentf("%q", key))
+		c.Assert(strings.Contains(err.Error(), "C# identifier"), chk.Equals, true)
+	}
+
+	err := Metadata{"Owner": "a", "owner": "b"}.Validate()
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.Error(), chk.Equals, `invalid argument, metadata keys "Owner" and "owner" differ only by case`)
+}
+
+func (s *metadataSuite) TestInvalidMetadataIsNotSent(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/file")
+	p := newTestHandlesPipeline(http.Header{}, "", &sent)
+	md := Metadata{"foo-bar": "v"}
+	ctx := context.Background()
+
+	_, err := NewFileURL(*u, p).SetMetadata(ctx, md, FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	_, err = NewFileURL(*u, p).Create(ctx, 0, FileHTTPHeaders{}, md, FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	_, _, err = NewFileURL(*u, p).Rename(ctx, "renamed", RenameOptions{Metadata: md})
+	c.Assert(err, chk.NotNil)
+	_, err = NewDirectoryURL(*u, p).SetMetadata(ctx, md)
+	c.Assert(err, chk.NotNil)
+	_, err = NewShareURL(*u, p).SetMetadata(ctx, md, ShareAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	_, err = NewShareURL(*u, p).Create(ctx, md, ShareCreateOptions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.IsNil)
+
+	_, err = NewFileURL(*u, p).SetMetadata(ctx, Metadata{"foo_bar": "v"}, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Header.Get("x-ms-meta-foo_bar"), chk.Equals, "v")
+}
+
+func (s *metadataSuite) TestMetadataGet(c *chk.C) {
+	header := http.Header{}
+	header.Set("x-ms-meta-CreatedBy", "importer")
+	md := FileGetPropertiesResponse{rawResponse: &http.Response{Header: header}}.NewMetadata()
+
+	v, ok := md.Get("CreatedBy")
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(v, chk.Equals, "importer")
+	v, ok = md.Get("createdby")
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(v, chk.Equals, "importer")
+	_, ok = md.Get("owner")
+	c.Assert(ok, chk.Equals, false)
+}
+
+func (s *metadataSuite) TestGetSetMetadata(c *chk.C) {
+	var sent http.Header
+	header := http.Header{}
+	header.Set("x-ms-meta-Owner", "alice")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/file")
+	p := newTestCapturePipeline(http.StatusOK, header, &sent)
+	ctx := context.Background()
+
+	getters := []func(context.Context) (Metadata, error){
+		NewFileURL(*u, p).GetMetadata, NewDirectoryURL(*u, p).GetMetadata, NewShareURL(*u, p).GetMetadata}
+	for _, get := range getters {
+		md, err := get(ctx)
+		c.Assert(err, chk.IsNil)
+		v, ok := md.Get("OWNER")
+		c.Assert(ok, chk.Equals, true)
+		c.Assert(v, chk.Equals, "alice")
+	}
+
+	// Empty metadata clears it, so no metadata header is sent; the lease is.
+	lac := LeaseAccessConditions{LeaseID: "lease-id"}
+	_, err := NewFileURL(*u, p).SetMetadata(ctx, Metadata{}, FileAccessConditions{LeaseAccessConditions: lac})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-lease-id"), chk.Equals, "lease-id")
+	_, err = NewShareURL(*u, p).SetMetadata(ctx, nil, ShareAccessConditions{LeaseAccessConditions: lac})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-lease-id"), chk.Equals, "lease-id")
+	for name := range sent {
+		c.Assert(strings.HasPrefix(strings.ToLower(name), "x-ms-meta-"), chk.Equals, false, chk.Commentf(name))
+	}
+
+	// A failed GetProperties is returned as is.
+	_, err = NewFileURL(*u, newTestCapturePipeline(http.StatusNotFound, http.Header{}, &sent)).GetMetadata(ctx)
+	c.Assert(IsNotFound(err), chk.Equals, true)
+}
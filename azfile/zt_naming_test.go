@@ -0,0 +1,81 @@
+package azfile
+
+import (
+	"net/url"
+	"strings"
+
+	chk "gopkg.in/check.v1"
+)
+
+type namingSuite struct{}
+
+var _ = chk.Suite(&namingSuite{})
+
+func (s *namingSuite) TestValidateShareName(c *chk.C) {
+	for _, name := range []string{"abc", "my-share-1", "0share", strings.Repeat("a", 63)} {
+		c.Assert(ValidateShareName(name), chk.IsNil, chk.Commentf(name))
+	}
+	for _, name := range []string{"", "ab", strings.Repeat("a", 64), "MyShare", "my_share", "my share", "-share", "share-",
+		"my--share", "share.", "share/"} {
+		c.Assert(ValidateShareName(name), chk.NotNil, chk.Commentf(name))
+	}
+	c.Assert(ValidateShareName("my--share"), chk.ErrorMatches, `invalid argument, share name "my--share" must start and end .*`)
+}
+
+func (s *namingSuite) TestValidateDirectoryName(c *chk.C) {
+	for _, name := range []string{"file.txt", "My Dir", "100%.txt", "a#b", "naïve", "CON.txt", ".hidden", strings.Repeat("é", 255)} {
+		c.Assert(ValidateDirectoryName(name), chk.IsNil, chk.Commentf(name))
+	}
+	for _, name := range []string{"", "a/b", `a\b`, "a:b", "a|b", "a<b", "a>b", "a*b", "a?b", `a"b`, "a\tb", "a\x7fb",
+		".", "..", "con", "Lpt1", "CLOCK$", strings.Repeat("a", 256), "\xff"} {
+		c.Assert(ValidateDirectoryName(name), chk.NotNil, chk.Commentf("%q", name))
+	}
+	c.Assert(ValidateDirectoryName("nul"), chk.ErrorMatches, `invalid argument, "nul" is a reserved name`)
+}
+
+func (s *namingSuite) TestValidateFilePath(c *chk.C) {
+	c.Assert(ValidateFilePath("dir/sub/file.txt"), chk.IsNil)
+	c.Assert(ValidateFilePath("file"), chk.IsNil)
+	c.Assert(ValidateFilePath(strings.Repeat("d/", 250)+"f"), chk.IsNil)
+
+	c.Assert(ValidateFilePath(strings.Repeat("d/", 251)+"f"), chk.ErrorMatches, ".* has 251 directories, more than 250")
+	c.Assert(ValidateFilePath(strings.Repeat(strings.Repeat("a", 200)+"/", 11)+"f"), chk.ErrorMatches, ".* characters long, more than 2048")
+	c.Assert(ValidateFilePath("dir/../file"), chk.ErrorMatches, `invalid argument, ".." is a reserved name, in path "dir/../file"`)
+	for _, path := range []string{"", "/file", "dir/", "dir//file"} {
+		c.Assert(ValidateFilePath(path), chk.NotNil, chk.Commentf(path))
+	}
+}
+
+func (s *namingSuite) TestNewValidatedURLs(c *chk.C) {
+	u, _ := url.Parse("https://account.file.core.windows.net/")
+	serviceURL := NewServiceURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{}))
+	_, err := serviceURL.NewValidatedShareURL("My_Share")
+	c.Assert(err, chk.NotNil)
+	shareURL, err := serviceURL.NewValidatedShareURL("share")
+	c.Assert(err, chk.IsNil)
+	c.Assert(shareURL.String(), chk.Equals, "https://account.file.core.windows.net/share")
+
+	// Names are percent-encoded.
+	dirURL, err := shareURL.NewValidatedDirectoryURL("my dir/100%")
+	c.Assert(err, chk.IsNil)
+	c.Assert(dirURL.String(), chk.Equals, "https://account.file.core.windows.net/share/my%20dir/100%25")
+	fileURL, err := dirURL.NewValidatedFileURL("a#b.txt")
+	c.Assert(err, chk.IsNil)
+	c.Assert(fileURL.String(), chk.Equals, "https://account.file.core.windows.net/share/my%20dir/100%25/a%23b.txt")
+	subURL, err := dirURL.NewValidatedDirectoryURL("sub")
+	c.Assert(err, chk.IsNil)
+	c.Assert(NewFileURLParts(subURL.URL()).DirectoryOrFilePath, chk.Equals, "my dir/100%/sub")
+
+	_, err = shareURL.NewValidatedDirectoryURL("a:b")
+	c.Assert(err, chk.NotNil)
+	_, err = dirURL.NewValidatedFileURL("aux")
+	c.Assert(err, chk.NotNil)
+
+	// The depth counts the directories of the base URL.
+	deepURL, err := shareURL.NewValidatedDirectoryURL(strings.TrimSuffix(strings.Repeat("d/", 250), "/"))
+	c.Assert(err, chk.IsNil)
+	_, err = deepURL.NewValidatedFileURL("f")
+	c.Assert(err, chk.IsNil)
+	_, err = deepURL.NewValidatedDirectoryURL("d/f")
+	c.Assert(err, chk.ErrorMatches, ".* more than 250")
+}
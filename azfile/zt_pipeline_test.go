@@ -0,0 +1,302 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type pipelineSuite struct{}
+
+var _ = chk.Suite(&pipelineSuite{})
+
+// roundTripperFunc is an http.RoundTripper implemented by a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func (s *pipelineSuite) TestHTTPClientSender(c *chk.C) {
+	var sent *http.Request
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = r
+		header := http.Header{}
+		header.Set("x-ms-share-quota", "5")
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	p := NewPipeline(NewAnonymousCredential(), PipelineOptions{HTTPSender: NewHTTPClientSenderFactory(client)})
+
+	resp, err := NewShareURL(*u, p).GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.Quota(), chk.Equals, int32(5))
+	c.Assert(sent, chk.NotNil)
+	c.Assert(sent.URL.Path, chk.Equals, "/share")
+	c.Assert(sent.Header.Get("x-ms-client-request-id"), chk.Not(chk.Equals), "")
+
+	// Transport errors are returned like the default sender's.
+	client.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("proxy unreachable")
+	})
+	p = NewPipeline(NewAnonymousCredential(), PipelineOptions{
+		HTTPSender: NewHTTPClientSenderFactory(client), Retry: RetryOptions{MaxTries: 1}})
+	_, err = NewShareURL(*u, p).GetProperties(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(strings.Contains(err.Error(), "proxy unreachable"), chk.Equals, true)
+}
+
+func (s *pipelineSuite) TestWithApplicationID(c *chk.C) {
+	var userAgents []string
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		userAgents = append(userAgents, r.Header.Get(headerUserAgent))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{
+		HTTPSender: NewHTTPClientSenderFactory(client), Telemetry: TelemetryOptions{Value: "myapp/1.0"}}))
+
+	_, err := shareURL.GetProperties(WithApplicationID(context.Background(), "tenant-42"))
+	c.Assert(err, chk.IsNil)
+	_, err = shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(userAgents, chk.HasLen, 2)
+	c.Assert(strings.HasPrefix(userAgents[0], "tenant-42 myapp/1.0 Azure-Storage/"+serviceLibVersion+" "), chk.Equals, true,
+		chk.Commentf(userAgents[0]))
+	c.Assert(userAgents[1], chk.Equals, strings.TrimPrefix(userAgents[0], "tenant-42 "))
+	c.Assert(userAgents[1], chk.Equals, TelemetryOptions{Value: "myapp/1.0"}.UserAgent())
+}
+
+func (s *pipelineSuite) TestTelemetryUserAgent(c *chk.C) {
+	platform := "(" + runtime.Version() + "; "
+	sdk := "Azure-Storage/" + serviceLibVersion + " "
+	c.Assert(strings.HasPrefix(TelemetryOptions{}.UserAgent(), sdk+platform), chk.Equals, true)
+	c.Assert(strings.HasSuffix(TelemetryOptions{}.UserAgent(), "/"+runtime.GOARCH+")"), chk.Equals, true)
+	c.Assert(TelemetryOptions{Value: "myapp/1.0"}.UserAgent(), chk.Equals, "myapp/1.0 "+TelemetryOptions{}.UserAgent())
+}
+
+func (s *pipelineSuite) TestClientRequestID(c *chk.C) {
+	var sent []string
+	echo := true
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Header.Get(xMsClientRequestID))
+		header := http.Header{}
+		if echo {
+			header.Set(xMsClientRequestID, r.Header.Get(xMsClientRequestID))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	var warnings []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{
+		HTTPSender: NewHTTPClientSenderFactory(client),
+		RequestID:  RequestIDOptions{VerifyEcho: true},
+		Log: pipeline.LogOptions{
+			Log:       func(level pipeline.LogLevel, message string) { warnings = append(warnings, message) },
+			ShouldLog: func(level pipeline.LogLevel) bool { return level <= pipeline.LogWarning },
+		},
+	}))
+
+	resp, err := shareURL.GetProperties(WithClientRequestID(context.Background(), "upstream-1"))
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.ClientRequestID(), chk.Equals, "upstream-1")
+	resp, err = shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent, chk.HasLen, 2)
+	c.Assert(sent[0], chk.Equals, "upstream-1")
+	c.Assert(sent[1], chk.HasLen, 36)
+	c.Assert(resp.ClientRequestID(), chk.Equals, sent[1])
+	c.Assert(warnings, chk.HasLen, 0)
+
+	echo = false
+	_, err = shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(warnings, chk.HasLen, 1)
+	c.Assert(strings.Contains(warnings[0], sent[2]), chk.Equals, true)
+}
+
+func (s *pipelineSuite) TestServiceVersion(c *chk.C) {
+	var sent []string
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Header.Get("x-ms-version"))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	newFileURL := func(version string) FileURL {
+		return NewFileURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{
+			HTTPSender: NewHTTPClientSenderFactory(client), ServiceVersion: version, Retry: RetryOptions{MaxTries: 1}}))
+	}
+
+	for _, version := range []string{"", "2019-12-12", "2021-04-10"} {
+		fileURL := newFileURL(version)
+		_, err := fileURL.GetProperties(context.Background())
+		c.Assert(err, chk.IsNil)
+		_, _, err = fileURL.Rename(context.Background(), "renamed", RenameOptions{})
+		c.Assert(err, chk.IsNil)
+	}
+	// Rename never goes below the version it needs.
+	c.Assert(sent, chk.DeepEquals, []string{ServiceVersion, renameServiceVersion, "2019-12-12", renameServiceVersion,
+		"2021-04-10", "2021-04-10"})
+
+	sent = nil
+	_, err := newFileURL("2018-11-09").GetProperties(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(strings.Contains(err.Error(), "SupportedServiceVersions"), chk.Equals, true)
+	c.Assert(sent, chk.HasLen, 0)
+}
+
+// newTestConnCountingServer returns a server that answers every request with 201 Created, and counts the connections
+// made to it in *conns.
+func newTestConnCountingServer(conns *int64) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(conns, 1)
+		}
+	}
+	server.Start()
+	return server
+}
+
+// uploadTestFiles uploads count small files, parallelism at a time, through a pipeline with the transport options.
+func uploadTestFiles(serverURL string, count int, parallelism int, o TransportOptions) error {
+	u, _ := url.Parse(serverURL + "/share/dir")
+	dir := NewDirectoryURL(*u, NewPipeline(NewAnonymousCredential(), PipelineOptions{Transport: o}))
+	data := make([]byte, 1024)
+	files := make(chan int)
+	errs := make(chan error, parallelism)
+	wg := sync.WaitGroup{}
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range files {
+				err := UploadBufferToAzureFile(context.Background(), data, dir.NewFileURL("file"+strconv.Itoa(i)),
+					UploadToAzureFileOptions{})
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(files)
+		for i := 0; i < count; i++ {
+			files <- i
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func (s *pipelineSuite) TestTransportOptions(c *chk.C) {
+	c.Assert(TransportOptions{}.httpClient(), chk.Equals,
+		TransportOptions{MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost, IdleConnTimeout: DefaultIdleConnTimeout}.httpClient())
+	c.Assert(TransportOptions{}.httpClient(), chk.Not(chk.Equals), TransportOptions{MaxConnsPerHost: 1}.httpClient())
+
+	var conns int64
+	server := newTestConnCountingServer(&conns)
+	defer server.Close()
+
+	// Connections are reused rather than redialed for each file.
+	c.Assert(uploadTestFiles(server.URL, 500, 32, TransportOptions{MaxConnsPerHost: 8}), chk.IsNil)
+	c.Assert(atomic.LoadInt64(&conns) <= 8, chk.Equals, true, chk.Commentf("%d connections", conns))
+}
+
+func (s *pipelineSuite) TestCloseIdleConnections(c *chk.C) {
+	var conns int64
+	server := newTestConnCountingServer(&conns)
+	defer server.Close()
+	u, _ := url.Parse(server.URL + "/share/file")
+	createFile := func(p pipeline.Pipeline) {
+		_, err := NewFileURL(*u, p).Create(context.Background(), 0, FileHTTPHeaders{}, nil, FileAccessConditions{})
+		c.Assert(err, chk.IsNil)
+	}
+
+	// The connection is reused until it's closed, after which the pipeline dials another.
+	p := NewPipeline(NewAnonymousCredential(), PipelineOptions{Transport: TransportOptions{MaxIdleConns: 1}})
+	createFile(p)
+	createFile(p)
+	c.Assert(atomic.LoadInt64(&conns), chk.Equals, int64(1))
+	CloseIdleConnections(p)
+	createFile(p)
+	c.Assert(atomic.LoadInt64(&conns), chk.Equals, int64(2))
+
+	// The connections of a client passed to NewHTTPClientSenderFactory are closed too.
+	p = NewPipeline(NewAnonymousCredential(), PipelineOptions{HTTPSender: NewHTTPClientSenderFactory(&http.Client{Transport: &http.Transport{}})})
+	createFile(p)
+	CloseIdleConnections(p)
+	createFile(p)
+	c.Assert(atomic.LoadInt64(&conns), chk.Equals, int64(4))
+
+	// Other pipelines are left alone.
+	CloseIdleConnections(pipeline.NewPipeline(nil, pipeline.Options{}))
+}
+
+// benchmarkUploadFiles uploads c.N files, 128 at a time, and logs the number of connections it made.
+func benchmarkUploadFiles(c *chk.C, o TransportOptions) {
+	var conns int64
+	server := newTestConnCountingServer(&conns)
+	defer server.Close()
+	c.ResetTimer()
+	c.Assert(uploadTestFiles(server.URL, c.N, 128, o), chk.IsNil)
+	c.StopTimer()
+	c.Logf("%d files, %d connections", c.N, atomic.LoadInt64(&conns))
+}
+
+// BenchmarkUploadFilesSmallIdlePool uploads with net/http's default of 2 idle connections per host, which closes
+// most connections after each request. Run with -v -check.b -check.vv to see the connection counts.
+func (s *pipelineSuite) BenchmarkUploadFilesSmallIdlePool(c *chk.C) {
+	benchmarkUploadFiles(c, TransportOptions{MaxIdleConnsPerHost: 2})
+}
+
+// BenchmarkUploadFilesDefaultTransport uploads with the default TransportOptions.
+func (s *pipelineSuite) BenchmarkUploadFilesDefaultTransport(c *chk.C) {
+	benchmarkUploadFiles(c, TransportOptions{})
+}
+
+func (s *pipelineSuite) TestResponseHeaderPassthrough(c *chk.C) {
+	// Headers the package doesn't model are read from the raw response, which every operation's response returns.
+	header := http.Header{}
+	header.Set("x-ms-not-yet-modeled", "value")
+	header.Set("Content-Length", "3")
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestHandlesPipeline(header, "abc", &sent))
+
+	props, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.Response().Header.Get("x-ms-not-yet-modeled"), chk.Equals, "value")
+
+	// Reading the headers of a download leaves its body to be read.
+	dr, err := fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	c.Assert(dr.Response().Header.Get("x-ms-not-yet-modeled"), chk.Equals, "value")
+	body, err := ioutil.ReadAll(dr.Body(RetryReaderOptions{}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(body), chk.Equals, "abc")
+
+	// So do the responses whose body is unmarshalled.
+	u, _ = url.Parse(testRetryErrorMockURL + "share")
+	header.Del("Content-Length")
+	shareURL := NewShareURL(*u, newTestErrorPipeline(http.StatusOK, header, "<ShareStats><ShareUsageBytes>5</ShareUsageBytes></ShareStats>"))
+	stats, err := shareURL.GetStatistics(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(stats.ShareUsageBytes, chk.Equals, int64(5))
+	c.Assert(stats.Response().Header.Get("x-ms-not-yet-modeled"), chk.Equals, "value")
+}
@@ -0,0 +1,106 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type requestLogSuite struct{}
+
+var _ = chk.Suite(&requestLogSuite{})
+
+// newTestRequestLogPipeline returns a pipeline whose request log policy logs to *msgs, and whose requests take delay
+// and then fail with err, or succeed with status if err is nil.
+func newTestRequestLogPipeline(o RequestLogOptions, delay time.Duration, status int, err error, msgs *[]string, levels *[]pipeline.LogLevel) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		NewRequestLogPolicyFactory(o),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				time.Sleep(delay)
+				if err != nil {
+					return nil, &url.Error{Op: "Put", URL: request.URL.String(), Err: err}
+				}
+				return newStatusResponse(request, status), nil
+			}
+		}),
+	}, pipeline.Options{Log: pipeline.LogOptions{
+		Log: func(level pipeline.LogLevel, message string) {
+			*msgs = append(*msgs, message)
+			*levels = append(*levels, level)
+		},
+		ShouldLog: func(level pipeline.LogLevel) bool { return true },
+	}})
+}
+
+func (s *requestLogSuite) TestRequestLogRedaction(c *chk.C) {
+	var msgs []string
+	var levels []pipeline.LogLevel
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest?sv=2019-12-12&sig=destsecret")
+	source, _ := url.Parse("https://source.file.core.windows.net/share/src?sig=sourcesecret")
+	p := newTestRequestLogPipeline(RequestLogOptions{SyslogDisabled: true}, 0, http.StatusCreated, nil, &msgs, &levels)
+
+	_, err := NewFileURL(*u, p).UploadRangeFromURL(context.Background(), *source, 0, 0, 512,
+		UploadRangeFromURLOptions{CopySourceAuthorization: "tokensecret"})
+	c.Assert(err, chk.IsNil)
+	c.Assert(msgs, chk.HasLen, 2) // The outgoing request, and the request with its response
+	for _, msg := range msgs {
+		c.Assert(strings.Contains(msg, "secret"), chk.Equals, false, chk.Commentf(msg))
+		c.Assert(strings.Contains(msg, "sig=REDACTED"), chk.Equals, true)
+		c.Assert(strings.Contains(msg, "X-Ms-Copy-Source-Authorization: [REDACTED]"), chk.Equals, true)
+	}
+
+	// An error from the HTTP client renders the URL too.
+	msgs, levels = nil, nil
+	p = newTestRequestLogPipeline(RequestLogOptions{SyslogDisabled: true}, 0, 0, errors.New("connection reset"), &msgs, &levels)
+	_, err = NewFileURL(*u, p).Delete(context.Background(), FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(levels[1], chk.Equals, pipeline.LogError)
+	c.Assert(strings.Contains(msgs[1], "connection reset"), chk.Equals, true)
+	c.Assert(strings.Contains(msgs[1], "secret"), chk.Equals, false, chk.Commentf(msgs[1]))
+}
+
+func (s *requestLogSuite) TestRequestLogSlowTry(c *chk.C) {
+	var msgs []string
+	var levels []pipeline.LogLevel
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	o := RequestLogOptions{LogWarningIfTryOverThreshold: 10 * time.Millisecond, SyslogDisabled: true}
+
+	_, err := NewFileURL(*u, newTestRequestLogPipeline(o, 20*time.Millisecond, http.StatusAccepted, nil, &msgs, &levels)).Delete(context.Background(), FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(levels, chk.DeepEquals, []pipeline.LogLevel{pipeline.LogInfo, pipeline.LogWarning})
+	c.Assert(strings.Contains(msgs[1], "[SLOW >10ms]"), chk.Equals, true)
+
+	msgs, levels = nil, nil
+	_, err = NewFileURL(*u, newTestRequestLogPipeline(o, 0, http.StatusAccepted, nil, &msgs, &levels)).Delete(context.Background(), FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(levels, chk.DeepEquals, []pipeline.LogLevel{pipeline.LogInfo, pipeline.LogInfo})
+}
+
+func (s *requestLogSuite) TestRequestLogCorrelationID(c *chk.C) {
+	var msgs []string
+	var levels []pipeline.LogLevel
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestRequestLogPipeline(RequestLogOptions{SyslogDisabled: true}, 0, http.StatusAccepted, nil, &msgs, &levels))
+
+	_, err := fileURL.Delete(WithCorrelationID(context.Background(), "order-42"), FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(msgs, chk.HasLen, 2)
+	c.Assert(strings.HasPrefix(msgs[0], "==> OUTGOING REQUEST (Try=1, CorrelationID=order-42)\n"), chk.Equals, true, chk.Commentf(msgs[0]))
+	c.Assert(strings.Contains(msgs[1], ", CorrelationID=order-42) -- RESPONSE SUCCESSFULLY RECEIVED"), chk.Equals, true, chk.Commentf(msgs[1]))
+
+	// The ID isn't sent, and the entries of requests made without it are unchanged.
+	c.Assert(strings.Count(msgs[0], "order-42"), chk.Equals, 1, chk.Commentf(msgs[0]))
+	msgs, levels = nil, nil
+	_, err = fileURL.Delete(context.Background(), FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(strings.HasPrefix(msgs[0], "==> OUTGOING REQUEST (Try=1)\n"), chk.Equals, true)
+	c.Assert(strings.Contains(msgs[1], "CorrelationID"), chk.Equals, false)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -124,3 +125,258 @@ func (s *policyRetrySuite) TestExponentialRetry(c *chk.C) {
 	c.Assert(strings.Contains(str, "try=4, Delay=2s"), chk.Equals, true) // Min: 0.512 * 7 = 3.584
 	// TODO add assertion here about minimum time taken
 }
+
+func (s *policyRetrySuite) TestWithTryTimeout(c *chk.C) {
+	var timeouts []string
+	var deadlines []time.Duration
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{MaxTries: 2, TryTimeout: time.Minute, RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				timeouts = append(timeouts, request.URL.Query().Get("timeout"))
+				deadline, _ := ctx.Deadline()
+				deadlines = append(deadlines, time.Until(deadline))
+				return nil, &testRetryTempError{}
+			}
+		}),
+	}, pipeline.Options{})
+	mockURL, _ := url.Parse(testRetryErrorMockURL)
+	fsu := NewServiceURL(*mockURL, p)
+
+	_, err := fsu.GetProperties(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(timeouts, chk.DeepEquals, []string{"61", "61"})
+
+	// Each try of the request gets the overridden timeout.
+	timeouts, deadlines = nil, nil
+	_, err = fsu.GetProperties(WithTryTimeout(context.Background(), 10*time.Minute))
+	c.Assert(err, chk.NotNil)
+	c.Assert(timeouts, chk.DeepEquals, []string{"601", "601"})
+	for _, d := range deadlines {
+		c.Assert(d > 9*time.Minute && d <= 10*time.Minute, chk.Equals, true)
+	}
+
+	// The context's deadline still bounds the tries.
+	timeouts = nil
+	ctx, cancel := context.WithTimeout(WithTryTimeout(context.Background(), 10*time.Minute), 30*time.Second)
+	defer cancel()
+	_, err = fsu.GetProperties(ctx)
+	c.Assert(err, chk.NotNil)
+	c.Assert(timeouts[0], chk.Equals, "30")
+}
+
+func (s *policyRetrySuite) TestShouldRetry(c *chk.C) {
+	tries := 0
+	var responses []*http.Response
+	var errs []error
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{
+			MaxTries:      3,
+			RetryDelay:    time.Millisecond,
+			MaxRetryDelay: time.Millisecond,
+			ShouldRetry: func(resp *http.Response, err error) bool {
+				responses, errs = append(responses, resp), append(errs, err)
+				return resp != nil && resp.StatusCode == http.StatusConflict
+			},
+		}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				tries++
+				status := http.StatusConflict // Not retried by default
+				if tries == 2 {
+					status = http.StatusOK
+				}
+				return newStatusResponse(request, status), nil
+			}
+		}),
+	}, pipeline.Options{})
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*mockURL, p)
+
+	_, err := shareURL.GetStatistics(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(tries, chk.Equals, 2)
+	c.Assert(responses[0].StatusCode, chk.Equals, http.StatusConflict)
+	c.Assert(IsConflict(errs[0]), chk.Equals, true)
+	c.Assert(responses[1].StatusCode, chk.Equals, http.StatusOK)
+	c.Assert(errs[1], chk.IsNil)
+
+	// The predicate can't retry more than MaxTries times.
+	tries = 2 // Every try conflicts
+	_, err = shareURL.GetStatistics(context.Background())
+	c.Assert(IsConflict(err), chk.Equals, true)
+	c.Assert(tries, chk.Equals, 5)
+}
+
+// newStatusResponse returns a response to request with statusCode and no body.
+func newStatusResponse(request pipeline.Request, statusCode int) pipeline.Response {
+	return pipeline.NewHTTPResponse(&http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    request.Request,
+	})
+}
+
+func (s *policyRetrySuite) TestFullJitter(c *chk.C) {
+	o := RetryOptions{Policy: RetryPolicyExponential, FullJitter: true}.defaults()
+	c.Assert(o.calcDelay(1), chk.Equals, time.Duration(0))
+	for i := 0; i < 100; i++ {
+		delay := o.calcDelay(3) // Up to (2^2-1) * 4 seconds
+		c.Assert(delay >= 0 && delay <= 12*time.Second, chk.Equals, true)
+		delay = o.calcDelay(10) // Capped by MaxRetryDelay
+		c.Assert(delay >= 0 && delay <= o.MaxRetryDelay, chk.Equals, true)
+	}
+
+	// By default the delay only varies by -20% to +30%.
+	o.FullJitter = false
+	for i := 0; i < 100; i++ {
+		delay := o.calcDelay(3)
+		c.Assert(delay >= 9600*time.Millisecond && delay <= 15600*time.Millisecond, chk.Equals, true)
+	}
+}
+
+func (s *policyRetrySuite) TestRetryReadsFromSecondaryHost(c *chk.C) {
+	const secondary = "mockaccount-secondary.file.core.windows.net"
+	var hosts []string
+	secondaryStatus := http.StatusServiceUnavailable
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{MaxTries: 4, RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond,
+			RetryReadsFromSecondaryHost: secondary}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				hosts = append(hosts, request.URL.Host)
+				status := http.StatusServiceUnavailable
+				if request.URL.Host == secondary {
+					status = secondaryStatus
+				}
+				return newStatusResponse(request, status), nil
+			}
+		}),
+	}, pipeline.Options{})
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*mockURL, p)
+	primary := mockURL.Host
+
+	// Reads alternate between the primary and the secondary.
+	_, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(hosts, chk.DeepEquals, []string{primary, secondary, primary, secondary})
+
+	// Once the secondary doesn't have the file, only the primary is retried.
+	hosts, secondaryStatus = nil, http.StatusNotFound
+	_, err = fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(hosts, chk.DeepEquals, []string{primary, secondary, primary, primary})
+
+	// Writes never go to the secondary.
+	hosts = nil
+	_, err = fileURL.SetMetadata(context.Background(), nil, FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(hosts, chk.DeepEquals, []string{primary, primary, primary, primary})
+}
+
+func (s *policyRetrySuite) TestNotifyThrottled(c *chk.C) {
+	type notification struct {
+		try         int32
+		delay       time.Duration
+		serviceCode ServiceCodeType
+	}
+	var notifications []notification
+	statuses := []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK}
+	tries := 0
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{
+			MaxTries:      5,
+			RetryDelay:    time.Millisecond,
+			MaxRetryDelay: 2 * time.Millisecond,
+			NotifyThrottled: func(try int32, delay time.Duration, serviceCode ServiceCodeType) {
+				c.Assert(tries, chk.Equals, int(try)-1) // Before the retry
+				notifications = append(notifications, notification{try, delay, serviceCode})
+			},
+		}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				response := newStatusResponse(request, statuses[tries])
+				if tries == 0 {
+					response.Response().Header.Set("x-ms-error-code", string(ServiceCodeServerBusy))
+				}
+				tries++
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share")
+
+	_, err := NewShareURL(*mockURL, p).GetStatistics(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(tries, chk.Equals, 4)
+	// The retry after the 500 isn't throttling, so it isn't notified.
+	c.Assert(notifications, chk.HasLen, 2)
+	c.Assert(notifications[0].try, chk.Equals, int32(2))
+	c.Assert(notifications[0].serviceCode, chk.Equals, ServiceCodeServerBusy)
+	c.Assert(notifications[1].try, chk.Equals, int32(4))
+	c.Assert(notifications[1].serviceCode, chk.Equals, ServiceCodeNone)
+	for _, n := range notifications {
+		c.Assert(n.delay > 0 && n.delay <= 2*time.Millisecond, chk.Equals, true)
+	}
+
+	// A 429, which isn't retried by default, is throttling too.
+	_, throttled := throttledServiceCode(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, nil)
+	c.Assert(throttled, chk.Equals, true)
+}
+
+// testClock is a fake Clock whose Sleep records the duration and advances Now by it.
+type testClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (t *testClock) Now() time.Time {
+	return t.now
+}
+
+func (t *testClock) Sleep(d time.Duration) {
+	t.sleeps = append(t.sleeps, d)
+	t.now = t.now.Add(d)
+}
+
+func (s *policyRetrySuite) TestRetryClock(c *chk.C) {
+	clock := &testClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tries := 0
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{
+			Policy:        RetryPolicyFixed,
+			MaxTries:      3,
+			RetryDelay:    time.Hour,
+			MaxRetryDelay: 2 * time.Hour,
+			FullJitter:    true,
+			Clock:         clock,
+		}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				tries++
+				return newStatusResponse(request, http.StatusServiceUnavailable), nil
+			}
+		}),
+	}, pipeline.Options{})
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share")
+
+	// The hour-long delays are waited with the fake clock, so the test doesn't wait for them.
+	_, err := NewShareURL(*mockURL, p).GetStatistics(context.Background())
+	c.Assert(isStorageError(err, http.StatusServiceUnavailable), chk.Equals, true)
+	c.Assert(tries, chk.Equals, 3)
+	c.Assert(clock.sleeps, chk.HasLen, 3)
+	c.Assert(clock.sleeps[0], chk.Equals, time.Duration(0)) // The 1st try doesn't wait
+	var waited time.Duration
+	for _, d := range clock.sleeps[1:] {
+		c.Assert(d >= 0 && d <= time.Hour, chk.Equals, true)
+		waited += d
+	}
+	c.Assert(clock.Now().Sub(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), chk.Equals, waited)
+}
@@ -0,0 +1,86 @@
+package azfile
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type tracingSuite struct{}
+
+var _ = chk.Suite(&tracingSuite{})
+
+type testSpanKey struct{}
+
+// testSpan records the attributes and errors of a span, and the span that was its parent.
+type testSpan struct {
+	name       string
+	parent     *testSpan
+	attributes map[string]interface{}
+	errs       []error
+	ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *testSpan) RecordError(err error)                      { s.errs = append(s.errs, err) }
+func (s *testSpan) End()                                       { s.ended = true }
+
+// testTracer records the spans it starts.
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{name: name, parent: parent, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (s *tracingSuite) TestTracingSpans(c *chk.C) {
+	tracer := &testTracer{}
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		newOperationSpanPolicyFactory(tracer),
+		NewRetryPolicyFactory(RetryOptions{MaxTries: 3, RetryDelay: time.Millisecond}),
+		newTrySpanPolicyFactory(tracer),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				response := newStatusResponse(request, statuses[0])
+				response.Response().Header.Set("x-ms-request-id", http.StatusText(statuses[0]))
+				statuses = statuses[1:]
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file?sig=secret")
+
+	_, err := NewFileURL(*u, p).Delete(context.Background(), FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(tracer.spans, chk.HasLen, 3)
+	op, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	c.Assert(op.name, chk.Equals, TracingOperationSpanName)
+	c.Assert(op.parent, chk.IsNil)
+	c.Assert(op.attributes[TracingAttributeRetryCount], chk.Equals, 1)
+	c.Assert(op.attributes[TracingAttributeHTTPStatusCode], chk.Equals, http.StatusOK)
+	for i, try := range []*testSpan{first, second} {
+		c.Assert(try.name, chk.Equals, TracingTrySpanName)
+		c.Assert(try.parent, chk.Equals, op)
+		c.Assert(try.ended, chk.Equals, true)
+		c.Assert(try.attributes[TracingAttributeTry], chk.Equals, i+1)
+		c.Assert(try.attributes[TracingAttributeHTTPMethod], chk.Equals, http.MethodDelete)
+		c.Assert(strings.Contains(try.attributes[TracingAttributeHTTPURL].(string), "secret"), chk.Equals, false)
+	}
+	c.Assert(first.attributes[TracingAttributeHTTPStatusCode], chk.Equals, http.StatusServiceUnavailable)
+	c.Assert(first.attributes[TracingAttributeRequestID], chk.Equals, http.StatusText(http.StatusServiceUnavailable))
+	c.Assert(first.errs, chk.HasLen, 1) // The responder's error for the 503
+	c.Assert(second.attributes[TracingAttributeRequestID], chk.Equals, http.StatusText(http.StatusOK))
+	c.Assert(second.errs, chk.HasLen, 0)
+	c.Assert(op.ended, chk.Equals, true)
+}
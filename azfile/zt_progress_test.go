@@ -0,0 +1,114 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type progressSuite struct{}
+
+var _ = chk.Suite(&progressSuite{})
+
+// failingReader returns the data of r, and then err rather than io.EOF.
+type failingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		err = f.err
+	}
+	return n, err
+}
+
+// newTestUploadRetryPipeline returns a pipeline that records the body and Content-Length of each request in *sent,
+// and fails the first try of each request with 503.
+func newTestUploadRetryPipeline(sent *[]string) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		NewRetryPolicyFactory(RetryOptions{MaxTries: 3, RetryDelay: time.Millisecond}),
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				body, _ := ioutil.ReadAll(request.Body)
+				*sent = append(*sent, request.Header.Get("Content-Length")+":"+string(body))
+				if len(*sent)%2 == 1 {
+					return newStatusResponse(request, http.StatusServiceUnavailable), nil
+				}
+				return newStatusResponse(request, http.StatusCreated), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *progressSuite) TestUploadRangeProgress(c *chk.C) {
+	var sentBodies []string
+	p := newTestUploadRetryPipeline(&sentBodies)
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	data := strings.Repeat("0123456789", 100)
+
+	var reports []int64
+	_, err := NewFileURL(*u, p).UploadRange(context.Background(), 0, strings.NewReader(data), nil,
+		UploadRangeOptions{Progress: func(bytesTransferred int64) { reports = append(reports, bytesTransferred) }})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sentBodies, chk.DeepEquals, []string{"1000:" + data, "1000:" + data})
+	c.Assert(reports, chk.Not(chk.HasLen), 0)
+	for i := 1; i < len(reports); i++ {
+		c.Assert(reports[i] > reports[i-1], chk.Equals, true) // The retry doesn't start over
+	}
+	c.Assert(reports[len(reports)-1], chk.Equals, int64(len(data)))
+}
+
+func (s *progressSuite) TestDownloadProgress(c *chk.C) {
+	data := strings.Repeat("0123456789", 100)
+	downloads := 0
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				downloads++
+				var offset int
+				if r := request.Header.Get("x-ms-range"); r != "" {
+					offset, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r, "bytes="), "-"))
+				}
+				var body io.Reader = strings.NewReader(data[offset:])
+				if downloads == 1 {
+					// The first download fails halfway through its body.
+					body = &failingReader{r: strings.NewReader(data[:len(data)/2]), err: &net.DNSError{IsTemporary: true}}
+				}
+				header := http.Header{}
+				header.Set("Content-Length", strconv.Itoa(len(data)-offset))
+				return pipeline.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK, Header: header,
+					Body: ioutil.NopCloser(body), Request: request.Request}), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+
+	resp, err := NewFileURL(*u, p).Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	var reports []int64
+	body := resp.Body(RetryReaderOptions{MaxRetryRequests: 1,
+		Progress: func(bytesTransferred int64) { reports = append(reports, bytesTransferred) }})
+	download := &bytes.Buffer{}
+	_, err = download.ReadFrom(body)
+	c.Assert(err, chk.IsNil)
+	c.Assert(download.String(), chk.Equals, data)
+	c.Assert(downloads, chk.Equals, 2)
+	for i := 1; i < len(reports); i++ {
+		c.Assert(reports[i] >= reports[i-1], chk.Equals, true)
+	}
+	c.Assert(reports[len(reports)-1], chk.Equals, int64(len(data)))
+}
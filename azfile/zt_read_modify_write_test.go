@@ -0,0 +1,188 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type readModifyWriteSuite struct{}
+
+var _ = chk.Suite(&readModifyWriteSuite{})
+
+// testFile is an in-memory file served by the File service's download, properties, lease, resize and range APIs.
+type testFile struct {
+	mu      sync.Mutex
+	data    []byte
+	version int
+	leaseID string
+}
+
+// write replaces the file's data, as another writer would.
+func (f *testFile) write(data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data = data
+	f.version++
+}
+
+func (f *testFile) pipeline() pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				status, body := http.StatusOK, []byte(nil)
+				leaseID := request.Header.Get("x-ms-lease-id")
+				switch comp := request.URL.Query().Get("comp"); {
+				case request.Method == http.MethodGet:
+					body = f.data
+				case request.Method == http.MethodHead:
+				case comp == "lease" && request.Header.Get("x-ms-lease-action") == "acquire":
+					if f.leaseID != "" {
+						return f.errorResponse(request, http.StatusConflict, ServiceCodeLeaseAlreadyPresent), nil
+					}
+					f.leaseID, status = request.Header.Get("x-ms-proposed-lease-id"), http.StatusCreated
+				case comp == "lease":
+					f.leaseID = ""
+				case leaseID != f.leaseID:
+					return f.errorResponse(request, http.StatusPreconditionFailed, ServiceCodeLeaseIDMissing), nil
+				case comp == "properties":
+					length, _ := strconv.Atoi(request.Header.Get("x-ms-content-length"))
+					f.data = append(f.data, make([]byte, length)...)[:length]
+					f.version++
+				case comp == "range":
+					b, _ := ioutil.ReadAll(request.Body)
+					copy(f.data[parseRangeStart(request.Header.Get("x-ms-range")):], b)
+					f.version++
+					status = http.StatusCreated
+				}
+				response := newStatusResponse(request, status)
+				response.Response().Header.Set("ETag", `"`+strconv.Itoa(f.version)+`"`)
+				response.Response().Header.Set("Content-Length", strconv.Itoa(len(body)))
+				response.Response().ContentLength = int64(len(body))
+				if request.Method == http.MethodHead {
+					response.Response().Header.Set("Content-Length", strconv.Itoa(len(f.data)))
+				}
+				response.Response().Body = ioutil.NopCloser(bytes.NewReader(body))
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (f *testFile) errorResponse(request pipeline.Request, status int, code ServiceCodeType) pipeline.Response {
+	response := newStatusResponse(request, status)
+	response.Response().Header.Set("x-ms-error-code", string(code))
+	return response
+}
+
+func (s *readModifyWriteSuite) TestReadModifyWrite(c *chk.C) {
+	file := &testFile{data: []byte("count=1")}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, file.pipeline())
+	increment := func(old []byte) ([]byte, error) {
+		n, _ := strconv.Atoi(string(old[len("count="):]))
+		return []byte("count=" + strconv.Itoa(n+1)), nil
+	}
+
+	err := ReadModifyWrite(context.Background(), fileURL, increment, ReadModifyWriteOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(file.data), chk.Equals, "count=2")
+	c.Assert(file.leaseID, chk.Equals, "")
+
+	// Another writer wins the race of the first attempt, which starts over with its data.
+	attempts := 0
+	err = ReadModifyWrite(context.Background(), fileURL, func(old []byte) ([]byte, error) {
+		if attempts++; attempts == 1 {
+			file.write([]byte("count=10"))
+		}
+		return increment(old)
+	}, ReadModifyWriteOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(attempts, chk.Equals, 2)
+	c.Assert(string(file.data), chk.Equals, "count=11")
+
+	// A shorter result truncates the file.
+	err = ReadModifyWrite(context.Background(), fileURL, func(old []byte) ([]byte, error) { return []byte("x"), nil }, ReadModifyWriteOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(file.data), chk.Equals, "x")
+}
+
+func (s *readModifyWriteSuite) TestReadModifyWriteConflict(c *chk.C) {
+	file := &testFile{data: []byte("data")}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, file.pipeline())
+
+	// Another writer always wins the race.
+	attempts := 0
+	err := ReadModifyWrite(context.Background(), fileURL, func(old []byte) ([]byte, error) {
+		attempts++
+		file.write([]byte("theirs"))
+		return []byte("mine"), nil
+	}, ReadModifyWriteOptions{MaxAttempts: 2})
+	c.Assert(IsPreconditionFailed(err), chk.Equals, true)
+	c.Assert(attempts, chk.Equals, 2)
+	c.Assert(string(file.data), chk.Equals, "theirs")
+	c.Assert(file.leaseID, chk.Equals, "")
+
+	// The file is leased by another client.
+	file.leaseID = "theirs"
+	err = ReadModifyWrite(context.Background(), fileURL, func(old []byte) ([]byte, error) { return old, nil }, ReadModifyWriteOptions{})
+	c.Assert(IsConflict(err), chk.Equals, true)
+	file.leaseID = ""
+
+	// An error from fn writes nothing.
+	fnErr := errors.New("fn failed")
+	err = ReadModifyWrite(context.Background(), fileURL, func(old []byte) ([]byte, error) { return nil, fnErr }, ReadModifyWriteOptions{})
+	c.Assert(err, chk.Equals, fnErr)
+	c.Assert(string(file.data), chk.Equals, "theirs")
+}
+
+func (s *readModifyWriteSuite) TestAppend(c *chk.C) {
+	file := &testFile{data: []byte("line 1\n")}
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, file.pipeline())
+
+	result, err := fileURL.Append(context.Background(), strings.NewReader("line 2\n"), AppendOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(result.Offset, chk.Equals, int64(7))
+	c.Assert(result.ETag, chk.Equals, ETag(`"2"`)) // Resized, and written
+	c.Assert(string(file.data), chk.Equals, "line 1\nline 2\n")
+	c.Assert(file.leaseID, chk.Equals, "")
+
+	// Appends made with the ETag of the previous one detect another writer's.
+	result, err = fileURL.Append(context.Background(), strings.NewReader("line 3\n"), AppendOptions{IfMatch: result.ETag})
+	c.Assert(err, chk.IsNil)
+	file.write(append(file.data, "theirs\n"...))
+	_, err = fileURL.Append(context.Background(), strings.NewReader("line 4\n"), AppendOptions{IfMatch: result.ETag})
+	c.Assert(IsPreconditionFailed(err), chk.Equals, true)
+	c.Assert(string(file.data), chk.Equals, "line 1\nline 2\nline 3\ntheirs\n")
+	c.Assert(file.leaseID, chk.Equals, "")
+
+	// A concurrent appender, or another client, holding the lease makes Append fail without writing.
+	file.leaseID = "theirs"
+	_, err = fileURL.Append(context.Background(), strings.NewReader("line 4\n"), AppendOptions{})
+	c.Assert(IsConflict(err), chk.Equals, true)
+
+	// Under the caller's lease, the lease is used as is.
+	_, err = fileURL.Append(context.Background(), strings.NewReader("line 4\n"), AppendOptions{LeaseAccessConditions: LeaseAccessConditions{LeaseID: "theirs"}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(file.leaseID, chk.Equals, "theirs")
+	c.Assert(string(file.data), chk.Equals, "line 1\nline 2\nline 3\ntheirs\nline 4\n")
+
+	_, err = fileURL.Append(context.Background(), strings.NewReader(""), AppendOptions{})
+	c.Assert(err, chk.NotNil)
+	_, err = fileURL.Append(context.Background(), bytes.NewReader(make([]byte, FileMaxUploadRangeBytes+1)), AppendOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, .* exceed the maximum range size .*")
+}
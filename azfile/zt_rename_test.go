@@ -0,0 +1,79 @@
+package azfile
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	chk "gopkg.in/check.v1"
+)
+
+type renameSuite struct{}
+
+var _ = chk.Suite(&renameSuite{})
+
+func (s *renameSuite) TestFileRename(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/old.txt?sv=2019-12-12&sig=c2lnbmF0dXJl")
+	p := newTestHandlesPipeline(http.Header{}, "", &sent)
+
+	dest, _, err := NewFileURL(*u, p).Rename(context.Background(), "other/new #1.txt", RenameOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Method, chk.Equals, http.MethodPut)
+	c.Assert(sent.URL.Path, chk.Equals, "/share/other/new #1.txt")
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "rename")
+	c.Assert(sent.URL.Query().Get("restype"), chk.Equals, "")
+	c.Assert(sent.URL.Query().Get("sig"), chk.Equals, "c2lnbmF0dXJl")
+	c.Assert(sent.Header.Get("x-ms-version"), chk.Equals, renameServiceVersion)
+	c.Assert(sent.Header.Get("x-ms-file-rename-source"), chk.Equals, u.String())
+	for _, h := range []string{"x-ms-file-rename-replace-if-exists", "x-ms-file-rename-ignore-readonly",
+		"x-ms-file-attributes", "x-ms-file-creation-time", "x-ms-file-permission", "x-ms-source-lease-id"} {
+		c.Assert(sent.Header.Get(h), chk.Equals, "", chk.Commentf(h))
+	}
+	destURL := dest.URL()
+	c.Assert(destURL.Path, chk.Equals, "/share/other/new #1.txt")
+}
+
+func (s *renameSuite) TestDirectoryRenameOptions(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	p := newTestHandlesPipeline(http.Header{}, "", &sent)
+	attributes := FileAttributeHidden
+	permission := "O:BAG:BAD:(A;;FA;;;BA)"
+
+	_, _, err := NewDirectoryURL(*u, p).Rename(context.Background(), testRetryErrorMockURL+"share/renamed", RenameOptions{
+		ReplaceIfExists:                  true,
+		IgnoreReadOnly:                   true,
+		SMBProperties:                    SMBProperties{FileAttributes: &attributes, FilePermission: &permission},
+		Metadata:                         Metadata{"foo": "bar"},
+		DestinationLeaseAccessConditions: LeaseAccessConditions{LeaseID: "lease"},
+	})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Path, chk.Equals, "/share/renamed")
+	c.Assert(sent.URL.Query().Get("restype"), chk.Equals, "directory")
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "rename")
+	c.Assert(sent.Header.Get("x-ms-file-rename-replace-if-exists"), chk.Equals, "true")
+	c.Assert(sent.Header.Get("x-ms-file-rename-ignore-readonly"), chk.Equals, "true")
+	c.Assert(sent.Header.Get("x-ms-file-attributes"), chk.Equals, "Hidden|Directory")
+	c.Assert(sent.Header.Get("x-ms-file-last-write-time"), chk.Equals, "")
+	c.Assert(sent.Header.Get("x-ms-file-permission"), chk.Equals, permission)
+	c.Assert(sent.Header.Get("x-ms-destination-lease-id"), chk.Equals, "lease")
+	c.Assert(sent.Header.Get("x-ms-meta-foo"), chk.Equals, "bar")
+}
+
+func (s *renameSuite) TestRenameInvalidDestination(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/file")
+	p := newTestHandlesPipeline(http.Header{}, "", &sent)
+	f := NewFileURL(*u, p)
+
+	for _, dest := range []string{testRetryErrorMockURL + "othershare/file", "https://otheraccount.file.core.windows.net/share/file", "/"} {
+		_, _, err := f.Rename(context.Background(), dest, RenameOptions{})
+		c.Assert(err, chk.NotNil, chk.Commentf(dest))
+	}
+	_, _, err := NewDirectoryURL(*u, p).Rename(context.Background(), "dir2", RenameOptions{IgnoreReadOnly: true})
+	c.Assert(err, chk.NotNil)
+	_, _, err = f.WithSnapshot("2020-09-08T22:56:16.0000000Z").Rename(context.Background(), "file2", RenameOptions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.IsNil)
+}
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	chk "gopkg.in/check.v1"
 )
 
@@ -349,4 +353,75 @@ func (r *retryReaderSuite) TestRetryReaderReadWithForcedRetry(c *chk.C) {
 	}
 }
 
+// newTestDownloadPipeline returns a pipeline that serves content from memory, which lets Download be tested without
+// going to the wire. etags is consulted on each request, so a test can simulate the file changing between a download
+// and its retries. The first response's body fails with a temporary error after failAt bytes.
+func newTestDownloadPipeline(content []byte, failAt int, etags func(request int) string) pipeline.Pipeline {
+	requests := 0
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				offset := 0
+				if rangeHeader := request.Header.Get("x-ms-range"); rangeHeader != "" {
+					fmt.Sscanf(rangeHeader, "bytes=%d-", &offset)
+				}
+				body := newSingleUsePerByteReader(content)
+				body.currentByteIndex = offset
+				if requests == 0 {
+					body.doInjectError = true
+					body.doInjectErrorByteIndex = failAt
+					body.doInjectTimes = 1
+					body.injectedError = &net.DNSError{IsTemporary: true}
+				}
+				header := http.Header{}
+				header.Set("ETag", etags(requests))
+				requests++
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       body,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (r *retryReaderSuite) TestDownloadBodyRetriesFromLastOffset(c *chk.C) {
+	content := make([]byte, 10)
+	_, _ = rand.Read(content)
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*mockURL, newTestDownloadPipeline(content, 5, func(int) string { return "\"0x1\"" }))
+
+	resp, err := fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+
+	var failedOffsets []int64
+	notify := func(failureCount int, lastError error, offset int64, count int64, willRetry bool) {
+		c.Assert(willRetry, chk.Equals, true)
+		failedOffsets = append(failedOffsets, offset)
+	}
+	download, err := ioutil.ReadAll(resp.Body(RetryReaderOptions{MaxRetryRequests: 1, NotifyFailedRead: notify}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(download, chk.DeepEquals, content)
+	c.Assert(failedOffsets, chk.DeepEquals, []int64{5})
+}
+
+func (r *retryReaderSuite) TestDownloadBodyFailsWhenFileChanges(c *chk.C) {
+	content := make([]byte, 10)
+	_, _ = rand.Read(content)
+	mockURL, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*mockURL, newTestDownloadPipeline(content, 5, func(request int) string {
+		return fmt.Sprintf("\"0x%d\"", request+1)
+	}))
+
+	resp, err := fileURL.Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+
+	_, err = ioutil.ReadAll(resp.Body(RetryReaderOptions{MaxRetryRequests: 3}))
+	c.Assert(err, chk.NotNil)
+	c.Assert(strings.HasSuffix(err.Error(), FileModifiedDuringReadMessage), chk.Equals, true)
+}
+
 // End testings for RetryReader
@@ -0,0 +1,394 @@
+package azfile
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type sasSuite struct{}
+
+var _ = chk.Suite(&sasSuite{})
+
+// A well-known, non-secret key: the storage emulator's account key.
+const testSASAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+func (s *sasSuite) TestFileSASStringToSign(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := start.Add(time.Hour)
+	p, err := FileSASSignatureValues{
+		Protocol:    SASProtocolHTTPS,
+		StartTime:   start,
+		ExpiryTime:  expiry,
+		Permissions: "dwcr", // Reordered to the canonical "rcwd" before signing
+		ShareName:   "share",
+		FilePath:    `dir\file`,
+		Identifier:  "policy",
+		ContentType: "text/plain",
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+
+	stringToSign := strings.Join([]string{
+		"rcwd",
+		"2020-01-01T00:00:00Z",
+		"2020-01-01T01:00:00Z",
+		"/file/account/share/dir/file",
+		"policy",
+		"",
+		"https",
+		SASVersion,
+		"", "", "", "",
+		"text/plain"}, "\n")
+	c.Assert(p.Signature(), chk.Equals, credential.ComputeHMACSHA256(stringToSign))
+	c.Assert(p.Permissions(), chk.Equals, "rcwd")
+	c.Assert(p.Resource(), chk.Equals, "f")
+	c.Assert(p.Version(), chk.Equals, SASVersion)
+
+	values, err := url.ParseQuery(p.Encode())
+	c.Assert(err, chk.IsNil)
+	c.Assert(values.Get("sr"), chk.Equals, "f")
+	c.Assert(values.Get("rsct"), chk.Equals, "text/plain")
+	c.Assert(values.Get("sig"), chk.Equals, p.Signature())
+}
+
+func (s *sasSuite) TestFileSASResponseHeaderOverrides(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+
+	expiry := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+	p, err := FileSASSignatureValues{
+		ExpiryTime:         expiry,
+		Permissions:        FileSASPermissions{Read: true}.String(),
+		ShareName:          "share",
+		FilePath:           "dir/report.pdf",
+		CacheControl:       "no-cache",
+		ContentDisposition: `attachment; filename="report.pdf"`,
+		ContentEncoding:    "gzip",
+		ContentLanguage:    "en-US",
+		ContentType:        "application/pdf",
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+
+	// The overrides are signed after the version, in this order.
+	stringToSign := strings.Join([]string{
+		"r",
+		"",
+		"2020-01-01T01:00:00Z",
+		"/file/account/share/dir/report.pdf",
+		"",
+		"",
+		"",
+		SASVersion,
+		"no-cache",
+		`attachment; filename="report.pdf"`,
+		"gzip",
+		"en-US",
+		"application/pdf"}, "\n")
+	c.Assert(p.Signature(), chk.Equals, credential.ComputeHMACSHA256(stringToSign))
+
+	u, _ := url.Parse("https://account.file.core.windows.net/share/dir/report.pdf?" + p.Encode())
+	parts := NewFileURLParts(*u)
+	c.Assert(parts.SAS.CacheControl(), chk.Equals, "no-cache")
+	c.Assert(parts.SAS.ContentDisposition(), chk.Equals, `attachment; filename="report.pdf"`)
+	c.Assert(parts.SAS.ContentEncoding(), chk.Equals, "gzip")
+	c.Assert(parts.SAS.ContentLanguage(), chk.Equals, "en-US")
+	c.Assert(parts.SAS.ContentType(), chk.Equals, "application/pdf")
+
+	// The service answers a download through the SAS with the overridden headers.
+	pl := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				q := request.URL.Query()
+				if q.Get("sig") != p.Signature() {
+					return newStatusResponse(request, http.StatusForbidden), nil
+				}
+				response := newStatusResponse(request, http.StatusOK)
+				for name, param := range map[string]string{"Cache-Control": "rscc", "Content-Disposition": "rscd",
+					"Content-Encoding": "rsce", "Content-Language": "rscl", "Content-Type": "rsct"} {
+					response.Response().Header.Set(name, q.Get(param))
+				}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	resp, err := NewFileURL(parts.URL(), pl).Download(context.Background(), 0, CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.ContentDisposition(), chk.Equals, `attachment; filename="report.pdf"`)
+	c.Assert(resp.CacheControl(), chk.Equals, "no-cache")
+	c.Assert(resp.ContentEncoding(), chk.Equals, "gzip")
+	c.Assert(resp.ContentLanguage(), chk.Equals, "en-US")
+	c.Assert(resp.ContentType(), chk.Equals, "application/pdf")
+}
+
+func (s *sasSuite) TestSASValidAt(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+	clock := &testClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	p, err := FileSASSignatureValues{
+		StartTime:   clock.Now().Add(time.Minute),
+		ExpiryTime:  clock.Now().Add(time.Hour),
+		Permissions: FileSASPermissions{Read: true}.String(),
+		ShareName:   "share",
+		FilePath:    "file",
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+	c.Assert(p.ValidAt(clock.Now()), chk.Equals, false) // Not yet
+	clock.Sleep(time.Minute)
+	c.Assert(p.ValidAt(clock.Now()), chk.Equals, true)
+	clock.Sleep(59 * time.Minute)
+	c.Assert(p.ValidAt(clock.Now()), chk.Equals, false) // Expired
+
+	// A SAS without times, like one whose times are in a stored access policy, isn't limited by them.
+	p, err = FileSASSignatureValues{ShareName: "share", Identifier: "policy"}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+	c.Assert(p.ValidAt(clock.Now()), chk.Equals, true)
+}
+
+func (s *sasSuite) TestShareSASResource(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+
+	p, err := FileSASSignatureValues{
+		ExpiryTime:  time.Now().UTC().Add(time.Hour),
+		Permissions: ShareSASPermissions{List: true, Read: true}.String(),
+		ShareName:   "share",
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+	c.Assert(p.Resource(), chk.Equals, "s")
+	c.Assert(p.Permissions(), chk.Equals, "rl")
+}
+
+func (s *sasSuite) TestFileSASPermissions(c *chk.C) {
+	c.Assert(FileSASPermissions{Delete: true, Write: true, Create: true, Read: true}.String(), chk.Equals, "rcwd")
+	c.Assert(FileSASPermissions{}.String(), chk.Equals, "")
+
+	p := FileSASPermissions{}
+	c.Assert(p.Parse("wr"), chk.IsNil)
+	c.Assert(p, chk.Equals, FileSASPermissions{Read: true, Write: true})
+
+	err := p.Parse("rl") // List is a share permission only
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.Error(), chk.Equals, "Invalid permission: 'l'")
+}
+
+func (s *sasSuite) TestFileSASNegativeNilCredential(c *chk.C) {
+	_, err := FileSASSignatureValues{ShareName: "share"}.NewSASQueryParameters(nil)
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *sasSuite) TestFileSASValidate(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := FileSASSignatureValues{
+		StartTime:   start,
+		ExpiryTime:  start.Add(time.Hour),
+		Permissions: FileSASPermissions{Read: true}.String(),
+		ShareName:   "share",
+		FilePath:    "file",
+	}
+	c.Assert(valid.Validate(), chk.IsNil)
+
+	// A stored access policy can provide the times and permissions.
+	policy := FileSASSignatureValues{ShareName: "share", FilePath: "file", Identifier: "policy"}
+	c.Assert(policy.Validate(), chk.IsNil)
+
+	list := valid
+	list.Permissions = "rl"
+	c.Assert(list.Validate(), chk.ErrorMatches, "invalid argument, a file SAS can't grant List.*")
+	list.FilePath = ""
+	c.Assert(list.Validate(), chk.IsNil)
+
+	for name, bad := range map[string]FileSASSignatureValues{
+		"a file or share SAS needs a ShareName":                           {ExpiryTime: valid.ExpiryTime, Permissions: "r", FilePath: "file"},
+		"a SAS without an Identifier needs an ExpiryTime and Permissions": {ShareName: "share", Permissions: "r"},
+		`SAS protocol "http" isn't "https" or "https,http"`:               {ExpiryTime: valid.ExpiryTime, Permissions: "r", ShareName: "share", Protocol: "http"},
+		"the SAS's IPRange has an End but no Start":                       {ExpiryTime: valid.ExpiryTime, Permissions: "r", ShareName: "share", IPRange: IPRange{End: net.ParseIP("10.0.0.1")}},
+		"the SAS's IPRange has ::1, which isn't an IPv4 address":          {ExpiryTime: valid.ExpiryTime, Permissions: "r", ShareName: "share", IPRange: IPRange{Start: net.ParseIP("::1")}},
+		"the SAS's StartTime 2020-01-01T01:00:00Z isn't before its ExpiryTime 2020-01-01T00:00:00Z": {
+			StartTime: valid.ExpiryTime, ExpiryTime: start, Permissions: "r", ShareName: "share"},
+	} {
+		c.Assert(bad.Validate(), chk.ErrorMatches, "invalid argument, "+regexp.QuoteMeta(name))
+		_, err = bad.NewSASQueryParameters(credential)
+		c.Assert(err, chk.ErrorMatches, "invalid argument, "+regexp.QuoteMeta(name))
+	}
+
+	account := AccountSASSignatureValues{ExpiryTime: start.Add(time.Hour), Permissions: "r", Services: "f", ResourceTypes: "o"}
+	c.Assert(account.Validate(), chk.IsNil)
+	account.StartTime = account.ExpiryTime
+	c.Assert(account.Validate(), chk.ErrorMatches, "invalid argument, the SAS's StartTime .* isn't before its ExpiryTime .*")
+	_, err = account.NewSASQueryParameters(credential)
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *sasSuite) TestAccountSASStringToSign(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, err := AccountSASSignatureValues{
+		Protocol:      SASProtocolHTTPS,
+		StartTime:     start,
+		ExpiryTime:    start.Add(time.Hour),
+		Permissions:   "wcl", // Reordered to the canonical "wlc" before signing
+		Services:      "f",
+		ResourceTypes: "oc", // Reordered to the canonical "co" before signing
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+
+	stringToSign := strings.Join([]string{
+		"account",
+		"wlc",
+		"f",
+		"co",
+		"2020-01-01T00:00:00Z",
+		"2020-01-01T01:00:00Z",
+		"",
+		"https",
+		SASVersion,
+		""}, "\n")
+	c.Assert(p.Signature(), chk.Equals, credential.ComputeHMACSHA256(stringToSign))
+
+	values, err := url.ParseQuery(p.Encode())
+	c.Assert(err, chk.IsNil)
+	c.Assert(values.Get("ss"), chk.Equals, "f")
+	c.Assert(values.Get("srt"), chk.Equals, "co")
+	c.Assert(values.Get("sp"), chk.Equals, "wlc")
+
+	signed, err := AccountSASSignatureValues{Protocol: SASProtocolHTTPS, StartTime: start, ExpiryTime: start.Add(time.Hour),
+		Permissions: "wcl", Services: "f", ResourceTypes: "oc"}.Sign(credential)
+	c.Assert(err, chk.IsNil)
+	c.Assert(signed.Encode(), chk.Equals, p.Encode())
+}
+
+func (s *sasSuite) TestAccountSASNegative(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+	valid := AccountSASSignatureValues{
+		ExpiryTime:    time.Now().UTC().Add(time.Hour),
+		Permissions:   AccountSASPermissions{Read: true}.String(),
+		Services:      AccountSASServices{File: true}.String(),
+		ResourceTypes: AccountSASResourceTypes{Object: true}.String(),
+	}
+
+	_, err = valid.NewSASQueryParameters(nil)
+	c.Assert(err, chk.NotNil)
+
+	missingServices := valid
+	missingServices.Services = ""
+	_, err = missingServices.NewSASQueryParameters(credential)
+	c.Assert(err, chk.NotNil)
+
+	badServices := valid
+	badServices.Services = "fx"
+	_, err = badServices.NewSASQueryParameters(credential)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.Error(), chk.Equals, "Invalid service character: 'x'")
+
+	badResourceTypes := valid
+	badResourceTypes.ResourceTypes = "f"
+	_, err = badResourceTypes.NewSASQueryParameters(credential)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.Error(), chk.Equals, "Invalid resource type: 'f'")
+}
+
+func (s *sasSuite) TestNewSASQueryParametersRoundTrip(c *chk.C) {
+	query := "sv=2020-02-10&ss=f&srt=co&sp=rwl&st=2020-01-01T00:00Z&se=2020-01-02&sip=168.1.5.60-168.1.5.70" +
+		"&spr=https&sig=c2lnbmF0dXJl&comp=list&restype=share"
+	values, err := url.ParseQuery(query)
+	c.Assert(err, chk.IsNil)
+
+	p := NewSASQueryParameters(values, true)
+	c.Assert(p.Version(), chk.Equals, "2020-02-10")
+	c.Assert(p.Services(), chk.Equals, "f")
+	c.Assert(p.ResourceTypes(), chk.Equals, "co")
+	c.Assert(p.Permissions(), chk.Equals, "rwl")
+	c.Assert(p.StartTime(), chk.Equals, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(p.ExpiryTime(), chk.Equals, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	c.Assert(p.IPRange().String(), chk.Equals, "168.1.5.60-168.1.5.70")
+	c.Assert(p.Protocol(), chk.Equals, SASProtocolHTTPS)
+	c.Assert(p.Signature(), chk.Equals, "c2lnbmF0dXJl")
+
+	// Only the non-SAS parameters remain.
+	c.Assert(values, chk.DeepEquals, url.Values{"comp": {"list"}, "restype": {"share"}})
+
+	// Encoding reproduces the SAS, including the original time formats.
+	encoded, err := url.ParseQuery(p.Encode())
+	c.Assert(err, chk.IsNil)
+	original, _ := url.ParseQuery(query)
+	delete(original, "comp")
+	delete(original, "restype")
+	c.Assert(encoded, chk.DeepEquals, original)
+}
+
+func (s *sasSuite) TestNewSASQueryParametersKeepsValues(c *chk.C) {
+	values := url.Values{"sv": {"2020-02-10"}, "sig": {"c2lnbmF0dXJl"}, "prefix": {"a"}}
+	p := NewSASQueryParameters(values, false)
+	c.Assert(p.Signature(), chk.Equals, "c2lnbmF0dXJl")
+	c.Assert(values, chk.HasLen, 3)
+}
+
+func (s *sasSuite) TestIPRangeString(c *chk.C) {
+	c.Assert(IPRange{}.String(), chk.Equals, "")
+	c.Assert(IPRange{Start: net.ParseIP("168.1.5.60")}.String(), chk.Equals, "168.1.5.60")
+	c.Assert(IPRange{Start: net.IPv4(168, 1, 5, 60).To4(), End: net.IPv4(168, 1, 5, 70)}.String(), chk.Equals, "168.1.5.60-168.1.5.70")
+	c.Assert(fmt.Sprint(IPRange{Start: net.ParseIP("10.0.0.1")}), chk.Equals, "10.0.0.1")
+}
+
+func (s *sasSuite) TestSASIPRangeSigning(c *chk.C) {
+	credential, err := NewSharedKeyCredential("account", testSASAccountKey)
+	c.Assert(err, chk.IsNil)
+	expiry := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, ipRange := range []IPRange{
+		{},
+		{Start: net.ParseIP("168.1.5.60")},
+		{Start: net.ParseIP("168.1.5.60"), End: net.ParseIP("168.1.5.70")},
+	} {
+		fileSAS, err := FileSASSignatureValues{
+			ExpiryTime:  expiry,
+			Permissions: "r",
+			ShareName:   "share",
+			FilePath:    "file",
+			IPRange:     ipRange,
+		}.NewSASQueryParameters(credential)
+		c.Assert(err, chk.IsNil)
+		fileStringToSign := strings.Join([]string{"r", "", "2020-01-01T00:00:00Z", "/file/account/share/file", "", ipRange.String(), "", SASVersion, "", "", "", "", ""}, "\n")
+		c.Assert(fileSAS.Signature(), chk.Equals, credential.ComputeHMACSHA256(fileStringToSign))
+
+		accountSAS, err := AccountSASSignatureValues{
+			ExpiryTime:    expiry,
+			Permissions:   "r",
+			Services:      "f",
+			ResourceTypes: "o",
+			IPRange:       ipRange,
+		}.NewSASQueryParameters(credential)
+		c.Assert(err, chk.IsNil)
+		accountStringToSign := strings.Join([]string{"account", "r", "f", "o", "", "2020-01-01T00:00:00Z", ipRange.String(), "", SASVersion, ""}, "\n")
+		c.Assert(accountSAS.Signature(), chk.Equals, credential.ComputeHMACSHA256(accountStringToSign))
+
+		for _, p := range []SASQueryParameters{fileSAS, accountSAS} {
+			values, err := url.ParseQuery(p.Encode())
+			c.Assert(err, chk.IsNil)
+			if len(ipRange.Start) == 0 {
+				_, present := values["sip"]
+				c.Assert(present, chk.Equals, false)
+			} else {
+				c.Assert(values.Get("sip"), chk.Equals, ipRange.String())
+			}
+		}
+	}
+}
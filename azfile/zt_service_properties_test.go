@@ -0,0 +1,70 @@
+package azfile
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	chk "gopkg.in/check.v1"
+)
+
+type servicePropertiesSuite struct{}
+
+var _ = chk.Suite(&servicePropertiesSuite{})
+
+func (s *servicePropertiesSuite) TestServiceSetPropertiesCorsAndProtocol(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL)
+	serviceURL := NewServiceURL(*u, newTestHandlesPipeline(http.Header{}, "", &sent))
+	sentBody := func() string {
+		b, err := ioutil.ReadAll(sent.Body)
+		c.Assert(err, chk.IsNil)
+		return string(b)
+	}
+
+	// Without Cors and Protocol, the service keeps its current settings.
+	_, err := serviceURL.SetProperties(context.Background(), FileServiceProperties{})
+	c.Assert(err, chk.IsNil)
+	body := sentBody()
+	c.Assert(strings.Contains(body, "<HourMetrics>"), chk.Equals, true)
+	c.Assert(strings.Contains(body, "<Cors"), chk.Equals, false)
+	c.Assert(strings.Contains(body, "<ProtocolSettings"), chk.Equals, false)
+
+	// An empty Cors removes all rules.
+	_, err = serviceURL.SetProperties(context.Background(), FileServiceProperties{Cors: []CorsRule{}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(strings.Contains(sentBody(), "<Cors></Cors>"), chk.Equals, true)
+
+	enabled := true
+	_, err = serviceURL.SetProperties(context.Background(), FileServiceProperties{
+		Cors:     []CorsRule{{AllowedOrigins: "*", AllowedMethods: "GET", MaxAgeInSeconds: 10}},
+		Protocol: &ShareProtocolSettings{Smb: &ShareSmbSettings{Multichannel: &SmbMultichannel{Enabled: &enabled}}},
+	})
+	c.Assert(err, chk.IsNil)
+	body = sentBody()
+	c.Assert(strings.HasPrefix(body, "<StorageServiceProperties>"), chk.Equals, true)
+	c.Assert(strings.Contains(body, "<Cors><CorsRule><AllowedOrigins>*</AllowedOrigins><AllowedMethods>GET</AllowedMethods>"), chk.Equals, true)
+	c.Assert(strings.Contains(body, "<ProtocolSettings><SMB><Multichannel><Enabled>true</Enabled></Multichannel></SMB></ProtocolSettings>"), chk.Equals, true)
+}
+
+func (s *servicePropertiesSuite) TestServiceGetPropertiesProtocol(c *chk.C) {
+	var sent *http.Request
+	body := `<?xml version="1.0" encoding="utf-8"?><StorageServiceProperties>` +
+		`<HourMetrics><Version>1.0</Version><Enabled>true</Enabled><IncludeAPIs>true</IncludeAPIs><RetentionPolicy><Enabled>true</Enabled><Days>7</Days></RetentionPolicy></HourMetrics>` +
+		`<MinuteMetrics><Version>1.0</Version><Enabled>false</Enabled><RetentionPolicy><Enabled>false</Enabled></RetentionPolicy></MinuteMetrics>` +
+		`<Cors><CorsRule><AllowedOrigins>*</AllowedOrigins><AllowedMethods>GET,PUT</AllowedMethods><AllowedHeaders>x-ms-meta-*</AllowedHeaders>` +
+		`<ExposedHeaders>x-ms-request-id</ExposedHeaders><MaxAgeInSeconds>60</MaxAgeInSeconds></CorsRule></Cors>` +
+		`<ProtocolSettings><SMB><Multichannel><Enabled>true</Enabled></Multichannel></SMB></ProtocolSettings></StorageServiceProperties>`
+	u, _ := url.Parse(testRetryErrorMockURL)
+	serviceURL := NewServiceURL(*u, newTestHandlesPipeline(http.Header{}, body, &sent))
+
+	props, err := serviceURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.HourMetrics, chk.DeepEquals, MetricProperties{MetricEnabled: true, IncludeAPIs: true, RetentionPolicyEnabled: true, RetentionDays: 7})
+	c.Assert(props.MinuteMetrics, chk.DeepEquals, MetricProperties{})
+	c.Assert(props.Cors, chk.DeepEquals, []CorsRule{{AllowedOrigins: "*", AllowedMethods: "GET,PUT", AllowedHeaders: "x-ms-meta-*",
+		ExposedHeaders: "x-ms-request-id", MaxAgeInSeconds: 60}})
+	c.Assert(*props.Protocol.Smb.Multichannel.Enabled, chk.Equals, true)
+}
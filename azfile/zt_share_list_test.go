@@ -0,0 +1,175 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type shareListSuite struct{}
+
+var _ = chk.Suite(&shareListSuite{})
+
+func shareListSegment(shares string, nextMarker string) string {
+	return `<?xml version="1.0" encoding="utf-8"?><EnumerationResults ServiceEndpoint="https://account.file.core.windows.net/">` +
+		`<Shares>` + shares + `</Shares><NextMarker>` + nextMarker + `</NextMarker></EnumerationResults>`
+}
+
+func (s *shareListSuite) TestListSharesAll(c *chk.C) {
+	requests := 0
+	segments := map[string]string{
+		"": shareListSegment(`<Share><Name>a</Name><Properties><Last-Modified>Wed, 09 Sep 2020 22:56:16 GMT</Last-Modified>`+
+			`<Etag>"0x1"</Etag><Quota>5120</Quota></Properties></Share>`, "m1"),
+		"m1": shareListSegment(``, "m2"),
+		"m2": shareListSegment(`<Share><Name>b</Name><Deleted>true</Deleted><Version>01D60F8BB59A4652</Version>`+
+			`<Properties><Quota>1</Quota></Properties></Share><Share><Name>c</Name></Share>`, ""),
+	}
+	u, _ := url.Parse(testRetryErrorMockURL)
+	serviceURL := NewServiceURL(*u, newTestListPipeline(segments, &requests))
+
+	it := serviceURL.ListSharesAll(context.Background(), ListSharesOptions{Detail: ListSharesDetail{Deleted: true}})
+	names := []string{}
+	for it.Next() {
+		names = append(names, it.Share().Name)
+		if it.Share().Name == "b" {
+			c.Assert(*it.Share().Deleted, chk.Equals, true)
+			c.Assert(*it.Share().Version, chk.Equals, "01D60F8BB59A4652")
+		} else {
+			c.Assert(it.Share().Deleted, chk.IsNil)
+		}
+	}
+	c.Assert(it.Err(), chk.IsNil)
+	c.Assert(names, chk.DeepEquals, []string{"a", "b", "c"})
+	c.Assert(requests, chk.Equals, 3)
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(requests, chk.Equals, 3)
+}
+
+func (s *shareListSuite) TestListSharesAllError(c *chk.C) {
+	requests := 0
+	segments := map[string]string{"": shareListSegment(`<Share><Name>a</Name></Share>`, "missing")}
+	u, _ := url.Parse(testRetryErrorMockURL)
+	it := NewServiceURL(*u, newTestListPipeline(segments, &requests)).ListSharesAll(context.Background(), ListSharesOptions{})
+
+	c.Assert(it.Next(), chk.Equals, true)
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(it.Err(), chk.NotNil)
+	c.Assert(it.Share(), chk.IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = NewServiceURL(*u, newTestListPipeline(segments, &requests)).ListSharesAll(ctx, ListSharesOptions{})
+	c.Assert(it.Next(), chk.Equals, false)
+	c.Assert(it.Err(), chk.Equals, context.Canceled)
+}
+
+func (s *shareListSuite) TestShareLister(c *chk.C) {
+	queries := []url.Values{}
+	segments := map[string]string{
+		"":   shareListSegment(`<Share><Name>a</Name></Share>`, "m1"),
+		"m1": shareListSegment(``, "m2"),
+		"m2": shareListSegment(`<Share><Name>b</Name></Share><Share><Name>c</Name></Share>`, ""),
+	}
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				queries = append(queries, request.URL.Query())
+				segment, ok := segments[request.URL.Query().Get("marker")]
+				if !ok {
+					return nil, errors.New("unexpected marker")
+				}
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader(segment)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL)
+	lister := NewServiceURL(*u, p).NewShareLister(ListSharesOptions{Prefix: "x", Detail: ListSharesDetail{Metadata: true}})
+
+	names := []string{}
+	for {
+		share, ok, err := lister.Next(context.Background())
+		c.Assert(err, chk.IsNil)
+		if !ok {
+			break
+		}
+		names = append(names, share.Name)
+	}
+	c.Assert(names, chk.DeepEquals, []string{"a", "b", "c"})
+	c.Assert(queries, chk.HasLen, 3)
+	for _, q := range queries {
+		c.Assert(q.Get("prefix"), chk.Equals, "x")
+		c.Assert(q.Get("include"), chk.Equals, "metadata")
+	}
+	_, ok, err := lister.Next(context.Background())
+	c.Assert(ok, chk.Equals, false)
+	c.Assert(err, chk.IsNil)
+	c.Assert(queries, chk.HasLen, 3)
+
+	// A failed request is retried by the next call.
+	segments = map[string]string{"": shareListSegment(`<Share><Name>a</Name></Share>`, "m1")}
+	lister = NewServiceURL(*u, p).NewShareLister(ListSharesOptions{})
+	share, ok, err := lister.Next(context.Background())
+	c.Assert(share.Name, chk.Equals, "a")
+	_, ok, err = lister.Next(context.Background())
+	c.Assert(ok, chk.Equals, false)
+	c.Assert(err, chk.NotNil)
+	segments["m1"] = shareListSegment(`<Share><Name>b</Name></Share>`, "")
+	share, ok, err = lister.Next(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(share.Name, chk.Equals, "b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok, err = NewServiceURL(*u, p).NewShareLister(ListSharesOptions{}).Next(ctx)
+	c.Assert(ok, chk.Equals, false)
+	c.Assert(err, chk.Equals, context.Canceled)
+}
+
+func (s *shareListSuite) TestListSharesDetail(c *chk.C) {
+	d := ListSharesDetail{Metadata: true, Snapshots: true, Deleted: true}
+	c.Assert(d.toArray(), chk.DeepEquals, []ListSharesIncludeType{ListSharesIncludeMetadata, ListSharesIncludeSnapshots, ListSharesIncludeDeleted})
+	c.Assert((&ListSharesDetail{}).toArray(), chk.HasLen, 0)
+}
+
+func (s *shareListSuite) TestShareRestore(c *chk.C) {
+	var sent *http.Request
+	requests := 0
+	segments := map[string]string{
+		"": shareListSegment(`<Share><Name>a</Name><Deleted>true</Deleted><Version>01D60F8BB59A4652</Version><Properties>`+
+			`<Last-Modified>Wed, 09 Sep 2020 22:56:16 GMT</Last-Modified><Etag>"0x1"</Etag><Quota>5120</Quota>`+
+			`<DeletedTime>Thu, 10 Sep 2020 08:00:00 GMT</DeletedTime><RemainingRetentionDays>6</RemainingRetentionDays></Properties></Share>`, ""),
+	}
+	u, _ := url.Parse(testRetryErrorMockURL)
+	it := NewServiceURL(*u, newTestListPipeline(segments, &requests)).ListSharesAll(context.Background(), ListSharesOptions{Detail: ListSharesDetail{Deleted: true}})
+	c.Assert(it.Next(), chk.Equals, true)
+	deleted := it.Share()
+	c.Assert(deleted.Properties.DeletedTime.Equal(time.Date(2020, 9, 10, 8, 0, 0, 0, time.UTC)), chk.Equals, true)
+	c.Assert(*deleted.Properties.RemainingRetentionDays, chk.Equals, int32(6))
+
+	shareURL := NewServiceURL(*u, newTestHandlesPipeline(http.Header{}, "", &sent)).NewShareURL(deleted.Name)
+	_, err := shareURL.Restore(context.Background(), deleted.Name, *deleted.Version)
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Method, chk.Equals, http.MethodPut)
+	c.Assert(sent.URL.Path, chk.Equals, "/a")
+	c.Assert(sent.URL.Query().Get("restype"), chk.Equals, "share")
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "undelete")
+	c.Assert(sent.Header.Get("x-ms-deleted-share-name"), chk.Equals, "a")
+	c.Assert(sent.Header.Get("x-ms-deleted-share-version"), chk.Equals, "01D60F8BB59A4652")
+
+	_, err = shareURL.Restore(context.Background(), deleted.Name, "")
+	c.Assert(err, chk.NotNil)
+}
@@ -0,0 +1,196 @@
+package azfile
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	chk "gopkg.in/check.v1"
+)
+
+type sharePropertiesSuite struct{}
+
+var _ = chk.Suite(&sharePropertiesSuite{})
+
+func (s *sharePropertiesSuite) TestShareSetProperties(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestCapturePipeline(http.StatusOK, http.Header{}, &sent))
+
+	_, err := shareURL.SetProperties(context.Background(), ShareSetPropertiesOptions{
+		QuotaInGB: 100, ProvisionedIops: 3100, ProvisionedBandwidthMibps: 110})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-share-quota"), chk.Equals, "100")
+	c.Assert(sent.Get("x-ms-share-provisioned-iops"), chk.Equals, "3100")
+	c.Assert(sent.Get("x-ms-share-provisioned-bandwidth-mibps"), chk.Equals, "110")
+
+	// Unset properties, including the premium-only ones, aren't sent.
+	_, err = shareURL.SetProperties(context.Background(), ShareSetPropertiesOptions{QuotaInGB: 5})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-share-quota"), chk.Equals, "5")
+	c.Assert(sent.Get("x-ms-share-provisioned-iops"), chk.Equals, "")
+	c.Assert(sent.Get("x-ms-share-provisioned-bandwidth-mibps"), chk.Equals, "")
+	c.Assert(sent.Get("x-ms-access-tier"), chk.Equals, "")
+}
+
+func (s *sharePropertiesSuite) TestShareGetProvisionedProperties(c *chk.C) {
+	var sent http.Header
+	header := http.Header{}
+	header.Set("x-ms-share-quota", "100")
+	header.Set("x-ms-share-provisioned-iops", "500")
+	header.Set("x-ms-share-provisioned-ingress-mbps", "70")
+	header.Set("x-ms-share-provisioned-egress-mbps", "110")
+	header.Set("x-ms-share-provisioned-bandwidth-mibps", "125")
+	header.Set("x-ms-share-next-allowed-quota-downgrade-time", "Wed, 09 Sep 2020 22:56:16 GMT")
+	header.Set("x-ms-share-included-burst-iops", "4000")
+	header.Set("x-ms-share-max-burst-credits-for-iops", "14400000")
+	header.Set("x-ms-share-next-allowed-provisioned-iops-downgrade-time", "Thu, 10 Sep 2020 22:56:16 GMT")
+	header.Set("x-ms-share-next-allowed-provisioned-bandwidth-downgrade-time", "Fri, 11 Sep 2020 22:56:16 GMT")
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestCapturePipeline(http.StatusOK, header, &sent))
+
+	props, err := shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.Quota(), chk.Equals, int32(100))
+	c.Assert(props.ProvisionedIops(), chk.Equals, int32(500))
+	c.Assert(props.ProvisionedIngressMBps(), chk.Equals, int32(70))
+	c.Assert(props.ProvisionedEgressMBps(), chk.Equals, int32(110))
+	c.Assert(props.ProvisionedBandwidthMibps(), chk.Equals, int32(125))
+	c.Assert(props.NextAllowedQuotaDowngradeTime().Equal(time.Date(2020, 9, 9, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(props.IncludedBurstIops(), chk.Equals, int32(4000))
+	c.Assert(props.MaxBurstCreditsForIops(), chk.Equals, int64(14400000))
+	c.Assert(props.NextAllowedProvisionedIopsDowngradeTime().Equal(time.Date(2020, 9, 10, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(props.NextAllowedProvisionedBandwidthDowngradeTime().Equal(time.Date(2020, 9, 11, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+
+	// A standard share returns none of the premium properties.
+	for _, h := range []string{"x-ms-share-provisioned-iops", "x-ms-share-provisioned-ingress-mbps", "x-ms-share-provisioned-egress-mbps",
+		"x-ms-share-provisioned-bandwidth-mibps", "x-ms-share-next-allowed-quota-downgrade-time", "x-ms-share-included-burst-iops",
+		"x-ms-share-max-burst-credits-for-iops", "x-ms-share-next-allowed-provisioned-iops-downgrade-time",
+		"x-ms-share-next-allowed-provisioned-bandwidth-downgrade-time"} {
+		header.Del(h)
+	}
+	props, err = shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.ProvisionedIops(), chk.Equals, int32(-1))
+	c.Assert(props.ProvisionedBandwidthMibps(), chk.Equals, int32(-1))
+	c.Assert(props.NextAllowedQuotaDowngradeTime().IsZero(), chk.Equals, true)
+	c.Assert(props.IncludedBurstIops(), chk.Equals, int32(-1))
+	c.Assert(props.MaxBurstCreditsForIops(), chk.Equals, int64(-1))
+	c.Assert(props.NextAllowedProvisionedIopsDowngradeTime().IsZero(), chk.Equals, true)
+}
+
+func (s *sharePropertiesSuite) TestShareGetStatisticsUsageBytes(c *chk.C) {
+	var sent *http.Request
+	body := `<?xml version="1.0" encoding="utf-8"?><ShareStats><ShareUsage>1</ShareUsage><ShareUsageBytes>4096</ShareUsageBytes></ShareStats>`
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestHandlesPipeline(http.Header{}, body, &sent))
+
+	stats, err := shareURL.GetStatistics(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "stats")
+	c.Assert(stats.ShareUsage, chk.Equals, int32(1))
+	c.Assert(stats.ShareUsageBytes, chk.Equals, int64(4096))
+}
+
+func (s *sharePropertiesSuite) TestShareAccessTier(c *chk.C) {
+	var sent http.Header
+	header := http.Header{}
+	header.Set("x-ms-access-tier", "Cool")
+	header.Set("x-ms-access-tier-change-time", "Wed, 09 Sep 2020 22:56:16 GMT")
+	header.Set("x-ms-access-tier-transition-state", "pending-from-hot")
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestCapturePipeline(http.StatusCreated, header, &sent))
+
+	_, err := shareURL.Create(context.Background(), nil, ShareCreateOptions{QuotaInGB: 10, AccessTier: AccessTierHot})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-share-quota"), chk.Equals, "10")
+	c.Assert(sent.Get("x-ms-access-tier"), chk.Equals, "Hot")
+
+	_, err = shareURL.Create(context.Background(), nil, ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-share-quota"), chk.Equals, "")
+	c.Assert(sent.Get("x-ms-access-tier"), chk.Equals, "")
+
+	shareURL = NewShareURL(*u, newTestCapturePipeline(http.StatusOK, header, &sent))
+	_, err = shareURL.SetProperties(context.Background(), ShareSetPropertiesOptions{AccessTier: AccessTierCool})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-access-tier"), chk.Equals, "Cool")
+
+	props, err := shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(AccessTierType(props.AccessTier()), chk.Equals, AccessTierCool)
+	c.Assert(props.AccessTierChangeTime().Equal(time.Date(2020, 9, 9, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+	c.Assert(props.AccessTierTransitionState(), chk.Equals, "pending-from-hot")
+}
+
+func (s *sharePropertiesSuite) TestShareNFSProtocol(c *chk.C) {
+	var sent http.Header
+	header := http.Header{}
+	header.Set("x-ms-enabled-protocols", "NFS")
+	header.Set("x-ms-root-squash", "RootSquash")
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestCapturePipeline(http.StatusCreated, header, &sent))
+
+	_, err := shareURL.Create(context.Background(), nil, ShareCreateOptions{QuotaInGB: 100,
+		EnabledProtocols: ShareEnabledProtocolsNFS, RootSquash: ShareRootSquashAllSquash})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-enabled-protocols"), chk.Equals, "NFS")
+	c.Assert(sent.Get("x-ms-root-squash"), chk.Equals, "AllSquash")
+
+	// SMB remains the default.
+	_, err = shareURL.Create(context.Background(), nil, ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	_, protocolsSent := sent["X-Ms-Enabled-Protocols"]
+	c.Assert(protocolsSent, chk.Equals, false)
+	_, rootSquashSent := sent["X-Ms-Root-Squash"]
+	c.Assert(rootSquashSent, chk.Equals, false)
+
+	shareURL = NewShareURL(*u, newTestCapturePipeline(http.StatusOK, header, &sent))
+	_, err = shareURL.SetProperties(context.Background(), ShareSetPropertiesOptions{RootSquash: ShareRootSquashRootSquash})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-root-squash"), chk.Equals, "RootSquash")
+	c.Assert(sent.Get("x-ms-share-quota"), chk.Equals, "")
+
+	props, err := shareURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(ShareEnabledProtocolsType(props.EnabledProtocols()), chk.Equals, ShareEnabledProtocolsNFS)
+	c.Assert(ShareRootSquashType(props.RootSquash()), chk.Equals, ShareRootSquashRootSquash)
+
+	// Listing the shares returns the same properties.
+	requests := 0
+	segments := map[string]string{"": shareListSegment(`<Share><Name>nfs</Name><Properties><Quota>100</Quota>`+
+		`<EnabledProtocols>NFS</EnabledProtocols><RootSquash>RootSquash</RootSquash></Properties></Share>`, "")}
+	u, _ = url.Parse(testRetryErrorMockURL)
+	resp, err := NewServiceURL(*u, newTestListPipeline(segments, &requests)).ListSharesSegment(context.Background(), Marker{}, ListSharesOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.ShareItems[0].Properties.EnabledProtocols, chk.Equals, ShareEnabledProtocolsNFS)
+	c.Assert(resp.ShareItems[0].Properties.RootSquash, chk.Equals, ShareRootSquashRootSquash)
+}
+
+func (s *sharePropertiesSuite) TestShareSetPermissionsLimit(c *chk.C) {
+	var sent *http.Request
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, newTestHandlesPipeline(http.Header{}, "", &sent))
+	permission := AccessPolicyPermission{Read: true}.String()
+	permissions := make([]SignedIdentifier, ShareMaxSignedIdentifiers+1)
+	for i := range permissions {
+		permissions[i] = SignedIdentifier{ID: "policy" + strconv.Itoa(i), AccessPolicy: &AccessPolicy{Permission: &permission}}
+	}
+
+	_, err := shareURL.SetPermissions(context.Background(), permissions)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.Error(), chk.Equals, "invalid argument, a share can have at most 5 stored access policies, not 6")
+	c.Assert(sent, chk.IsNil)
+
+	_, err = shareURL.SetPermissions(context.Background(), permissions[:ShareMaxSignedIdentifiers])
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "acl")
+	body, err := ioutil.ReadAll(sent.Body)
+	c.Assert(err, chk.IsNil)
+	c.Assert(strings.Count(string(body), "<SignedIdentifier>"), chk.Equals, ShareMaxSignedIdentifiers)
+	c.Assert(strings.Contains(string(body), "<Id>policy4</Id>"), chk.Equals, true)
+}
@@ -0,0 +1,396 @@
+package azfile
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type smbPropertiesSuite struct{}
+
+var _ = chk.Suite(&smbPropertiesSuite{})
+
+// newTestCapturePipeline returns a pipeline that records each request's headers and answers with statusCode and
+// responseHeader, without going to the wire.
+func newTestCapturePipeline(statusCode int, responseHeader http.Header, sent *http.Header) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				*sent = request.Header
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: statusCode,
+					Header:     responseHeader,
+					Body:       http.NoBody,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *smbPropertiesSuite) TestFileAttributeFlagsString(c *chk.C) {
+	c.Assert(FileAttributeNone.String(), chk.Equals, "None")
+	c.Assert(FileAttributeArchive.String(), chk.Equals, "Archive")
+	c.Assert((FileAttributeArchive | FileAttributeReadOnly | FileAttributeNoScrubData).String(), chk.Equals, "ReadOnly|Archive|NoScrubData")
+	c.Assert(FileAttributeFlags(0x8).String(), chk.Equals, "None") // Not an attribute the service knows
+}
+
+func (s *smbPropertiesSuite) TestParseFileAttributeFlags(c *chk.C) {
+	for input, expected := range map[string]FileAttributeFlags{
+		"":                              FileAttributeNone,
+		"None":                          FileAttributeNone,
+		"Archive":                       FileAttributeArchive,
+		"ReadOnly | Hidden | System":    FileAttributeReadOnly | FileAttributeHidden | FileAttributeSystem,
+		"directory|notcontentindexed":   FileAttributeDirectory | FileAttributeNotContentIndexed,
+		"Temporary|Offline|NoScrubData": FileAttributeTemporary | FileAttributeOffline | FileAttributeNoScrubData,
+	} {
+		f, err := ParseFileAttributeFlags(input)
+		c.Assert(err, chk.IsNil)
+		c.Assert(f, chk.Equals, expected)
+
+		roundTrip, err := ParseFileAttributeFlags(f.String())
+		c.Assert(err, chk.IsNil)
+		c.Assert(roundTrip, chk.Equals, f)
+	}
+
+	_, err := ParseFileAttributeFlags("ReadOnly|Compressed")
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *smbPropertiesSuite) TestFileCreateSMBHeaders(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent))
+
+	_, err := fileURL.Create(context.Background(), 0, FileHTTPHeaders{}, nil, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "None")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "now")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "now")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "inherit")
+	c.Assert(sent.Get("x-ms-file-permission-key"), chk.Equals, "")
+
+	attributes := FileAttributeReadOnly | FileAttributeArchive
+	creationTime := time.Date(2019, 11, 19, 21, 25, 15, 236522700, time.FixedZone("PST", -8*60*60))
+	lastWriteTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := "12501538048846835188*422928105932735866"
+	_, err = fileURL.Create(context.Background(), 0, FileHTTPHeaders{SMBProperties: SMBProperties{
+		FileAttributes:    &attributes,
+		FileCreationTime:  &creationTime,
+		FileLastWriteTime: &lastWriteTime,
+		FilePermissionKey: &key,
+	}}, nil, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "ReadOnly|Archive")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "2019-11-20T05:25:15.2365227Z")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "2020-01-02T03:04:05.0000000Z")
+	c.Assert(sent.Get("x-ms-file-permission-key"), chk.Equals, key)
+	_, permissionSent := sent["X-Ms-File-Permission"]
+	c.Assert(permissionSent, chk.Equals, false)
+}
+
+func (s *smbPropertiesSuite) TestFileSetPropertiesSMBHeaders(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusOK, http.Header{}, &sent))
+
+	attributes := FileAttributeHidden
+	h := FileHTTPHeaders{ContentType: "text/plain", SMBProperties: SMBProperties{FileAttributes: &attributes}}
+	_, err := fileURL.SetProperties(context.Background(), h, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-content-type"), chk.Equals, "text/plain")
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "Hidden")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "preserve")
+
+	// SetHTTPHeaders ignores the SMB properties.
+	_, err = fileURL.SetHTTPHeaders(context.Background(), h, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-content-type"), chk.Equals, "text/plain")
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "preserve")
+}
+
+func (s *smbPropertiesSuite) TestFileResizeHeaders(c *chk.C) {
+	var sent http.Header
+	header := http.Header{}
+	header.Set("ETag", `"0x8D8"`)
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusOK, header, &sent))
+
+	resp, err := fileURL.Resize(context.Background(), 4096, ResizeOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.ETag(), chk.Equals, ETag(`"0x8D8"`))
+	c.Assert(sent.Get("x-ms-content-length"), chk.Equals, "4096")
+	for _, h := range []string{"x-ms-content-type", "x-ms-content-encoding", "x-ms-content-language",
+		"x-ms-cache-control", "x-ms-content-md5", "x-ms-content-disposition", "x-ms-lease-id"} {
+		c.Assert(sent.Get(h), chk.Equals, "", chk.Commentf(h)) // Sending any of these would clear the others
+	}
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "preserve")
+
+	_, err = fileURL.Resize(context.Background(), 0, ResizeOptions{FileAccessConditions{LeaseAccessConditions: LeaseAccessConditions{LeaseID: "lease"}}})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-content-length"), chk.Equals, "0")
+	c.Assert(sent.Get("x-ms-lease-id"), chk.Equals, "lease")
+}
+
+func (s *smbPropertiesSuite) TestFileGetPropertiesSMBProperties(c *chk.C) {
+	var sent http.Header
+	responseHeader := http.Header{}
+	responseHeader.Set("x-ms-file-attributes", "ReadOnly | Archive")
+	responseHeader.Set("x-ms-file-creation-time", "2019-11-19T21:25:15.2365227Z")
+	responseHeader.Set("x-ms-file-last-write-time", "2020-01-02T03:04:05.0000000Z")
+	responseHeader.Set("x-ms-file-permission-key", "key")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusOK, responseHeader, &sent))
+
+	resp, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	p := resp.NewSMBProperties()
+	c.Assert(*p.FileAttributes, chk.Equals, FileAttributeReadOnly|FileAttributeArchive)
+	c.Assert(p.FileCreationTime.Equal(time.Date(2019, 11, 19, 21, 25, 15, 236522700, time.UTC)), chk.Equals, true)
+	c.Assert(p.FileLastWriteTime.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), chk.Equals, true)
+	c.Assert(*p.FilePermissionKey, chk.Equals, "key")
+
+	// A response without SMB properties yields nil fields.
+	fileURL = NewFileURL(*u, newTestCapturePipeline(http.StatusOK, http.Header{}, &sent))
+	resp, err = fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.NewSMBProperties(), chk.DeepEquals, SMBProperties{})
+}
+
+func (s *smbPropertiesSuite) TestFileChangeTime(c *chk.C) {
+	var sent http.Header
+	responseHeader := http.Header{}
+	responseHeader.Set("x-ms-file-change-time", "2021-02-03T03:05:06.7000000Z")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusOK, responseHeader, &sent))
+	directoryURL := NewDirectoryURL(*u, newTestCapturePipeline(http.StatusOK, responseHeader, &sent))
+	changeTime := time.Date(2021, 2, 3, 4, 5, 6, 700000000, time.FixedZone("", 3600))
+	p := SMBProperties{FileChangeTime: &changeTime}
+
+	for _, send := range []func(SMBProperties) error{
+		func(p SMBProperties) error {
+			_, err := fileURL.SetProperties(context.Background(), FileHTTPHeaders{SMBProperties: p}, FileAccessConditions{})
+			return err
+		},
+		func(p SMBProperties) error {
+			_, err := fileURL.Create(context.Background(), 0, FileHTTPHeaders{SMBProperties: p}, nil, FileAccessConditions{})
+			return err
+		},
+		func(p SMBProperties) error {
+			_, err := directoryURL.SetProperties(context.Background(), p)
+			return err
+		},
+		func(p SMBProperties) error {
+			_, err := directoryURL.Create(context.Background(), nil, p)
+			return err
+		},
+		func(p SMBProperties) error {
+			_, _, err := fileURL.Rename(context.Background(), "renamed", RenameOptions{SMBProperties: p})
+			return err
+		},
+	} {
+		c.Assert(send(p), chk.IsNil)
+		c.Assert(sent.Get("x-ms-file-change-time"), chk.Equals, "2021-02-03T03:05:06.7000000Z")
+		c.Assert(sent.Get("x-ms-version"), chk.Equals, changeTimeServiceVersion)
+
+		// The change time can be set to the time of the request.
+		c.Assert(send(SMBProperties{FileChangeTimeNow: true}), chk.IsNil)
+		c.Assert(sent.Get("x-ms-file-change-time"), chk.Equals, "now")
+		c.Assert(sent.Get("x-ms-version"), chk.Equals, changeTimeServiceVersion)
+
+		// Without a change time, the header isn't sent, so the service keeps or sets the change time.
+		c.Assert(send(SMBProperties{}), chk.IsNil)
+		c.Assert(sent.Get("x-ms-file-change-time"), chk.Equals, "")
+		c.Assert(sent.Get("x-ms-version"), chk.Not(chk.Equals), changeTimeServiceVersion)
+	}
+
+	resp, err := fileURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.NewSMBProperties().FileChangeTime.Equal(changeTime), chk.Equals, true)
+}
+
+func (s *smbPropertiesSuite) TestDirectorySMBHeaders(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent))
+
+	_, err := dirURL.Create(context.Background(), nil, SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "Directory")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "now")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "now")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "inherit")
+
+	// The Directory attribute is always included.
+	attributes := FileAttributeHidden
+	_, err = dirURL.Create(context.Background(), nil, SMBProperties{FileAttributes: &attributes})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "Hidden|Directory")
+	c.Assert(attributes, chk.Equals, FileAttributeHidden)
+
+	dirURL = NewDirectoryURL(*u, newTestCapturePipeline(http.StatusOK, http.Header{}, &sent))
+	_, err = dirURL.SetProperties(context.Background(), SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-attributes"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-creation-time"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-last-write-time"), chk.Equals, "preserve")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "preserve")
+
+	key := "key"
+	_, err = dirURL.SetProperties(context.Background(), SMBProperties{FilePermissionKey: &key})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-file-permission-key"), chk.Equals, "key")
+	c.Assert(sent.Get("x-ms-file-permission"), chk.Equals, "")
+}
+
+func (s *smbPropertiesSuite) TestDirectoryProperties(c *chk.C) {
+	// The service returns the SMB properties the directory was created with, and its other properties.
+	var created http.Header
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				if request.Method == http.MethodPut {
+					created = request.Header
+					return newStatusResponse(request, http.StatusCreated), nil
+				}
+				response := newStatusResponse(request, http.StatusOK)
+				for _, h := range []string{"x-ms-file-attributes", "x-ms-file-creation-time", "x-ms-file-last-write-time", "x-ms-meta-owner"} {
+					response.Response().Header.Set(h, created.Get(h))
+				}
+				response.Response().Header.Set("x-ms-file-change-time", created.Get("x-ms-file-last-write-time"))
+				response.Response().Header.Set("x-ms-file-permission-key", "key")
+				response.Response().Header.Set("x-ms-file-id", "13835128424026341376")
+				response.Response().Header.Set("x-ms-file-parent-id", "0")
+				response.Response().Header.Set("x-ms-server-encrypted", "true")
+				response.Response().Header.Set("ETag", `"0x8D8"`)
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	dirURL := NewDirectoryURL(*u, p)
+
+	attributes := FileAttributeHidden | FileAttributeNotContentIndexed
+	creationTime := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	lastWriteTime := creationTime.Add(time.Hour)
+	_, err := dirURL.Create(context.Background(), Metadata{"owner": "me"},
+		SMBProperties{FileAttributes: &attributes, FileCreationTime: &creationTime, FileLastWriteTime: &lastWriteTime})
+	c.Assert(err, chk.IsNil)
+
+	resp, err := dirURL.GetProperties(context.Background())
+	c.Assert(err, chk.IsNil)
+	props := resp.NewDirectoryProperties()
+	c.Assert(*props.FileAttributes, chk.Equals, attributes|FileAttributeDirectory)
+	c.Assert(props.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(props.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(props.FileChangeTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(*props.FilePermissionKey, chk.Equals, "key")
+	c.Assert(props.FileID, chk.Equals, "13835128424026341376")
+	c.Assert(props.FileParentID, chk.Equals, "0")
+	c.Assert(props.ServerEncrypted, chk.Equals, true)
+	c.Assert(props.ETag, chk.Equals, ETag(`"0x8D8"`))
+	c.Assert(props.Metadata, chk.DeepEquals, Metadata{"owner": "me"})
+}
+
+func (s *smbPropertiesSuite) TestSharePermissionRequests(c *chk.C) {
+	var sent *http.Request
+	var sentBody []byte
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				sent = request.Request
+				sentBody = nil
+				if request.Body != nil {
+					sentBody, _ = ioutil.ReadAll(request.Body)
+				}
+				header := http.Header{}
+				header.Set("x-ms-file-permission-key", "key")
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"permission":"O:BAG:BAD:(A;;FA;;;SY)"}`)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	shareURL := NewShareURL(*u, p)
+
+	created, err := shareURL.CreatePermission(context.Background(), "O:BAG:BAD:(A;;FA;;;SY)")
+	c.Assert(err, chk.IsNil)
+	c.Assert(created.FilePermissionKey(), chk.Equals, "key")
+	c.Assert(sent.Method, chk.Equals, http.MethodPut)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "filepermission")
+	c.Assert(sent.Header.Get("Content-Type"), chk.Equals, "application/json")
+	c.Assert(string(sentBody), chk.Equals, `{"permission":"O:BAG:BAD:(A;;FA;;;SY)"}`)
+
+	got, err := shareURL.GetPermission(context.Background(), "key")
+	c.Assert(err, chk.IsNil)
+	c.Assert(got.Permission, chk.Equals, "O:BAG:BAD:(A;;FA;;;SY)")
+	c.Assert(sent.Method, chk.Equals, http.MethodGet)
+	c.Assert(sent.Header.Get("x-ms-file-permission-key"), chk.Equals, "key")
+}
+
+func (s *smbPropertiesSuite) TestFilePermissionInlineOrKey(c *chk.C) {
+	var sent []*http.Request
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				sent = append(sent, request.Request)
+				header := http.Header{}
+				header.Set("x-ms-file-permission-key", "created-key")
+				statusCode := http.StatusCreated
+				if request.URL.Query().Get("comp") == "properties" {
+					statusCode = http.StatusOK
+				}
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: statusCode,
+					Header:     header,
+					Body:       http.NoBody,
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/file?sharesnapshot=2020-01-01T00:00:00.0000000Z")
+	fileURL := NewFileURL(*u, p)
+
+	// A short descriptor is sent inline.
+	short := "O:BAG:BAD:(A;;FA;;;SY)"
+	_, err := fileURL.Create(context.Background(), 0, FileHTTPHeaders{SMBProperties: SMBProperties{FilePermission: &short}}, nil, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent, chk.HasLen, 1)
+	c.Assert(sent[0].Header.Get("x-ms-file-permission"), chk.Equals, short)
+
+	// A long descriptor is stored on the base share first, and sent by key.
+	long := short + strings.Repeat("(A;;FA;;;BA)", FilePermissionMaxInlineSize/len("(A;;FA;;;BA)"))
+	sent = nil
+	dirURL := NewDirectoryURL(*u, p)
+	_, err = dirURL.SetProperties(context.Background(), SMBProperties{FilePermission: &long})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent, chk.HasLen, 2)
+	c.Assert(sent[0].URL.Path, chk.Equals, "/share")
+	c.Assert(sent[0].URL.Query().Get("comp"), chk.Equals, "filepermission")
+	c.Assert(sent[0].URL.Query().Get("sharesnapshot"), chk.Equals, "")
+	c.Assert(sent[1].Header.Get("x-ms-file-permission-key"), chk.Equals, "created-key")
+	_, permissionSent := sent[1].Header["X-Ms-File-Permission"]
+	c.Assert(permissionSent, chk.Equals, false)
+
+	key := "key"
+	_, err = fileURL.SetProperties(context.Background(), FileHTTPHeaders{SMBProperties: SMBProperties{FilePermission: &short, FilePermissionKey: &key}}, FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+}
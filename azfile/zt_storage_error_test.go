@@ -0,0 +1,211 @@
+package azfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type storageErrorSuite struct{}
+
+var _ = chk.Suite(&storageErrorSuite{})
+
+// newTestErrorPipeline returns a pipeline that answers every request with statusCode, responseHeader and body.
+func newTestErrorPipeline(statusCode int, responseHeader http.Header, body string) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				return pipeline.NewHTTPResponse(&http.Response{
+					StatusCode: statusCode,
+					Status:     http.StatusText(statusCode),
+					Header:     responseHeader,
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+					Request:    request.Request,
+				}), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+func (s *storageErrorSuite) TestStorageErrorDetails(c *chk.C) {
+	header := http.Header{}
+	header.Set("x-ms-error-code", string(ServiceCodeAuthenticationFailed))
+	header.Set("x-ms-request-id", "3e8ba1e4-801a-0032-1b6b-869f7e000000")
+	header.Set("Date", "Tue, 08 Sep 2020 22:56:16 GMT")
+	body := "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<Error>\n  <Code>AuthenticationFailed</Code>\n" +
+		"  <Message>Server failed to authenticate the request.\nRequestId:3e8ba1e4-801a-0032-1b6b-869f7e000000\n" +
+		"Time:2020-09-08T22:56:16.1234567Z</Message>\n" +
+		"  <AuthenticationErrorDetail>Signature did not match.</AuthenticationErrorDetail>\n</Error>"
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+
+	_, err := NewShareURL(*u, newTestErrorPipeline(http.StatusForbidden, header, body)).GetStatistics(context.Background())
+	storageErr, ok := err.(StorageError)
+	c.Assert(ok, chk.Equals, true)
+	c.Assert(storageErr.ServiceCode(), chk.Equals, ServiceCodeAuthenticationFailed)
+	c.Assert(storageErr.Details(), chk.DeepEquals, map[string]string{
+		"Code":                      "AuthenticationFailed",
+		"AuthenticationErrorDetail": "Signature did not match.",
+	})
+	c.Assert(storageErr.RequestID(), chk.Equals, "3e8ba1e4-801a-0032-1b6b-869f7e000000")
+	c.Assert(storageErr.Timestamp().Equal(time.Date(2020, 9, 8, 22, 56, 16, 123456700, time.UTC)), chk.Equals, true)
+
+	msg := storageErr.Error()
+	c.Assert(strings.Contains(msg, "Description=Server failed to authenticate the request.\n"), chk.Equals, true)
+	c.Assert(strings.Contains(msg, "RequestID=3e8ba1e4-801a-0032-1b6b-869f7e000000\n"), chk.Equals, true)
+	c.Assert(strings.Contains(msg, "Timestamp=2020-09-08T22:56:16.1234567Z\n"), chk.Equals, true)
+	c.Assert(strings.Contains(msg, "   AuthenticationErrorDetail: Signature did not match.\n"), chk.Equals, true)
+}
+
+func (s *storageErrorSuite) TestStorageErrorAdditionalInfo(c *chk.C) {
+	body := `<?xml version="1.0" encoding="utf-8"?><Error><Code>InvalidHeaderValue</Code>` +
+		`<Message>The value for one of the HTTP headers is not in the correct format.</Message>` +
+		`<HeaderName>x-ms-version</HeaderName><HeaderValue>1999-01-01</HeaderValue></Error>`
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+
+	// The service didn't return a request ID or time in the message, so they are taken from the headers.
+	header := http.Header{}
+	header.Set("x-ms-request-id", "id")
+	header.Set("Date", "Tue, 08 Sep 2020 22:56:16 GMT")
+	_, err := NewShareURL(*u, newTestErrorPipeline(http.StatusBadRequest, header, body)).GetStatistics(context.Background())
+	storageErr := err.(StorageError)
+	c.Assert(storageErr.Details()["HeaderName"], chk.Equals, "x-ms-version")
+	c.Assert(storageErr.Details()["HeaderValue"], chk.Equals, "1999-01-01")
+	c.Assert(storageErr.RequestID(), chk.Equals, "id")
+	c.Assert(storageErr.Timestamp().Equal(time.Date(2020, 9, 8, 22, 56, 16, 0, time.UTC)), chk.Equals, true)
+
+	// A HEAD response has no body.
+	_, err = NewShareURL(*u, newTestErrorPipeline(http.StatusNotFound, http.Header{}, "")).GetStatistics(context.Background())
+	storageErr = err.(StorageError)
+	c.Assert(storageErr.Details(), chk.HasLen, 0)
+	c.Assert(storageErr.RequestID(), chk.Equals, "")
+	c.Assert(storageErr.Timestamp().IsZero(), chk.Equals, true)
+}
+
+func (s *storageErrorSuite) TestIsNotFoundConflictPreconditionFailed(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	for _, r := range []struct {
+		statusCode                             int
+		code                                   ServiceCodeType
+		notFound, conflict, preconditionFailed bool
+	}{
+		{http.StatusNotFound, ServiceCodeShareNotFound, true, false, false},
+		{http.StatusNotFound, ServiceCodeNone, true, false, false}, // A HEAD response without a code
+		{http.StatusConflict, ServiceCodeShareAlreadyExists, false, true, false},
+		{http.StatusConflict, ServiceCodeShareBeingDeleted, false, true, false},
+		{http.StatusPreconditionFailed, ServiceCodeConditionNotMet, false, false, true},
+		{http.StatusBadRequest, ServiceCodeInvalidHeaderValue, false, false, false},
+	} {
+		header := http.Header{}
+		header.Set("x-ms-error-code", string(r.code))
+		_, err := NewShareURL(*u, newTestErrorPipeline(r.statusCode, header, "")).GetStatistics(context.Background())
+		c.Assert(IsNotFound(err), chk.Equals, r.notFound, chk.Commentf("%d %s", r.statusCode, r.code))
+		c.Assert(IsConflict(err), chk.Equals, r.conflict, chk.Commentf("%d %s", r.statusCode, r.code))
+		c.Assert(IsPreconditionFailed(err), chk.Equals, r.preconditionFailed, chk.Commentf("%d %s", r.statusCode, r.code))
+
+		// Wrapped errors are unwrapped.
+		wrapped := fmt.Errorf("getting statistics: %w", err)
+		c.Assert(IsNotFound(wrapped), chk.Equals, r.notFound)
+		var storageErr StorageError
+		c.Assert(errors.As(wrapped, &storageErr), chk.Equals, true)
+		c.Assert(storageErr.ServiceCode(), chk.Equals, r.code)
+	}
+
+	for _, err := range []error{nil, errors.New("not a storage error"), context.Canceled} {
+		c.Assert(IsNotFound(err), chk.Equals, false)
+		c.Assert(IsConflict(err), chk.Equals, false)
+		c.Assert(IsPreconditionFailed(err), chk.Equals, false)
+	}
+}
+
+func (s *storageErrorSuite) TestCreateIfNotExistsDeleteIfExists(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir")
+	errorPipeline := func(statusCode int, code ServiceCodeType) pipeline.Pipeline {
+		header := http.Header{}
+		header.Set("x-ms-error-code", string(code))
+		return newTestErrorPipeline(statusCode, header, "")
+	}
+	var sent http.Header
+
+	created, err := NewShareURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent)).CreateIfNotExists(context.Background(), nil, ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, true)
+	created, err = NewShareURL(*u, errorPipeline(http.StatusConflict, ServiceCodeShareAlreadyExists)).CreateIfNotExists(context.Background(), nil, ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, false)
+	created, err = NewShareURL(*u, errorPipeline(http.StatusConflict, ServiceCodeShareBeingDeleted)).CreateIfNotExists(context.Background(), nil, ShareCreateOptions{})
+	c.Assert(IsConflict(err), chk.Equals, true)
+	c.Assert(created, chk.Equals, false)
+
+	deleted, err := NewShareURL(*u, newTestCapturePipeline(http.StatusAccepted, http.Header{}, &sent)).DeleteIfExists(context.Background(), DeleteSnapshotsOptionNone, ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, true)
+	deleted, err = NewShareURL(*u, errorPipeline(http.StatusNotFound, ServiceCodeShareNotFound)).DeleteIfExists(context.Background(), DeleteSnapshotsOptionNone, ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, false)
+	deleted, err = NewShareURL(*u, errorPipeline(http.StatusConflict, ServiceCodeShareHasSnapshots)).DeleteIfExists(context.Background(), DeleteSnapshotsOptionNone, ShareAccessConditions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(deleted, chk.Equals, false)
+
+	created, err = NewDirectoryURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent)).CreateIfNotExists(context.Background(), nil, SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, true)
+	created, err = NewDirectoryURL(*u, errorPipeline(http.StatusConflict, ServiceCodeResourceAlreadyExists)).CreateIfNotExists(context.Background(), nil, SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, false)
+	created, err = NewDirectoryURL(*u, errorPipeline(http.StatusConflict, ServiceCodeResourceTypeMismatch)).CreateIfNotExists(context.Background(), nil, SMBProperties{})
+	c.Assert(err, chk.NotNil) // A file has the directory's name.
+	c.Assert(created, chk.Equals, false)
+
+	deleted, err = NewDirectoryURL(*u, newTestCapturePipeline(http.StatusAccepted, http.Header{}, &sent)).DeleteIfExists(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, true)
+	deleted, err = NewDirectoryURL(*u, errorPipeline(http.StatusNotFound, ServiceCodeParentNotFound)).DeleteIfExists(context.Background())
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, false)
+	deleted, err = NewDirectoryURL(*u, errorPipeline(http.StatusConflict, ServiceCodeDirectoryNotEmpty)).DeleteIfExists(context.Background())
+	c.Assert(err, chk.NotNil)
+	c.Assert(deleted, chk.Equals, false)
+}
+
+func (s *storageErrorSuite) TestExists(c *chk.C) {
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dir/file")
+	var sent http.Header
+	exists := func(p pipeline.Pipeline) (results []bool, errs []error) {
+		for _, f := range []func(context.Context) (bool, error){
+			NewFileURL(*u, p).Exists, NewDirectoryURL(*u, p).Exists, NewShareURL(*u, p).Exists,
+		} {
+			ok, err := f(context.Background())
+			results, errs = append(results, ok), append(errs, err)
+		}
+		return results, errs
+	}
+
+	results, errs := exists(newTestCapturePipeline(http.StatusOK, http.Header{}, &sent))
+	c.Assert(results, chk.DeepEquals, []bool{true, true, true})
+	c.Assert(errs, chk.DeepEquals, []error{nil, nil, nil})
+
+	// A HEAD response has no body, so only its status and x-ms-error-code header tell what's missing.
+	header := http.Header{}
+	header.Set("x-ms-error-code", string(ServiceCodeResourceNotFound))
+	results, errs = exists(newTestErrorPipeline(http.StatusNotFound, header, ""))
+	c.Assert(results, chk.DeepEquals, []bool{false, false, false})
+	c.Assert(errs, chk.DeepEquals, []error{nil, nil, nil})
+
+	// A request that isn't authorized doesn't tell whether the resource exists.
+	header = http.Header{}
+	header.Set("x-ms-error-code", string(ServiceCodeAuthenticationFailed))
+	results, errs = exists(newTestErrorPipeline(http.StatusForbidden, header, ""))
+	c.Assert(results, chk.DeepEquals, []bool{false, false, false})
+	for _, err := range errs {
+		c.Assert(isStorageError(err, http.StatusForbidden), chk.Equals, true)
+	}
+}
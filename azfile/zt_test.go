@@ -163,7 +163,7 @@ func getFileURLFromDirectory(c *chk.C, directory azfile.DirectoryURL) (file azfi
 func createNewShare(c *chk.C, fsu azfile.ServiceURL) (share azfile.ShareURL, name string) {
 	share, name = getShareURL(c, fsu)
 
-	cResp, err := share.Create(ctx, nil, 0)
+	cResp, err := share.Create(ctx, nil, azfile.ShareCreateOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return share, name
@@ -173,7 +173,7 @@ func createNewShareWithPrefix(c *chk.C, fsu azfile.ServiceURL, prefix string) (s
 	name = generateName(prefix)
 	share = fsu.NewShareURL(name)
 
-	cResp, err := share.Create(ctx, nil, 0)
+	cResp, err := share.Create(ctx, nil, azfile.ShareCreateOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return share, name
@@ -183,7 +183,7 @@ func createNewDirectoryWithPrefix(c *chk.C, parentDirectory azfile.DirectoryURL,
 	name = generateName(prefix)
 	dir = parentDirectory.NewDirectoryURL(name)
 
-	cResp, err := dir.Create(ctx, azfile.Metadata{})
+	cResp, err := dir.Create(ctx, azfile.Metadata{}, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return dir, name
@@ -193,7 +193,7 @@ func createNewFileWithPrefix(c *chk.C, dir azfile.DirectoryURL, prefix string, s
 	name = generateName(prefix)
 	file = dir.NewFileURL(name)
 
-	cResp, err := file.Create(ctx, size, azfile.FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(ctx, size, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return file, name
@@ -202,7 +202,7 @@ func createNewFileWithPrefix(c *chk.C, dir azfile.DirectoryURL, prefix string, s
 func createNewDirectoryFromShare(c *chk.C, share azfile.ShareURL) (dir azfile.DirectoryURL, name string) {
 	dir, name = getDirectoryURLFromShare(c, share)
 
-	cResp, err := dir.Create(ctx, nil)
+	cResp, err := dir.Create(ctx, nil, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return dir, name
@@ -211,7 +211,7 @@ func createNewDirectoryFromShare(c *chk.C, share azfile.ShareURL) (dir azfile.Di
 func createNewDirectoryFromDirectory(c *chk.C, parentDirectory azfile.DirectoryURL) (dir azfile.DirectoryURL, name string) {
 	dir, name = getDirectoryURLFromDirectory(c, parentDirectory)
 
-	cResp, err := dir.Create(ctx, nil)
+	cResp, err := dir.Create(ctx, nil, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return dir, name
@@ -223,7 +223,7 @@ func createNewFileFromShare(c *chk.C, share azfile.ShareURL, fileSize int64) (fi
 
 	file, name = getFileURLFromDirectory(c, dir)
 
-	cResp, err := file.Create(ctx, fileSize, azfile.FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(ctx, fileSize, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 
@@ -236,11 +236,11 @@ func createNewFileFromShareWithDefaultData(c *chk.C, share azfile.ShareURL) (fil
 
 	file, name = getFileURLFromDirectory(c, dir)
 
-	cResp, err := file.Create(ctx, int64(len(fileDefaultData)), azfile.FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(ctx, int64(len(fileDefaultData)), azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 
-	_, err = file.UploadRange(ctx, 0, strings.NewReader(fileDefaultData), nil)
+	_, err = file.UploadRange(ctx, 0, strings.NewReader(fileDefaultData), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
 	return file, name
@@ -249,7 +249,7 @@ func createNewFileFromShareWithDefaultData(c *chk.C, share azfile.ShareURL) (fil
 func createNewFileFromDirectory(c *chk.C, directory azfile.DirectoryURL, fileSize int64) (file azfile.FileURL, name string) {
 	file, name = getFileURLFromDirectory(c, directory)
 
-	cResp, err := file.Create(ctx, fileSize, azfile.FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(ctx, fileSize, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 
@@ -0,0 +1,290 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type uploadDirectorySuite struct{}
+
+var _ = chk.Suite(&uploadDirectorySuite{})
+
+// testShare is an in-memory share, served by newTestSharePipeline. Paths are relative to the share, e.g. "dir/file".
+type testShare struct {
+	mu          sync.Mutex
+	dirs        map[string]bool
+	files       map[string][]byte
+	lastWrite   map[string]string      // The x-ms-file-last-write-time each file or directory was last set to
+	attributes  map[string]string      // The attributes listings return for each file or directory, if any
+	permissions map[string]string      // The permission keys listings return for each directory, set with its properties
+	sddl        map[string]string      // The security descriptors GetPermission returns, by permission key
+	copies      map[string]http.Header // The headers of the request that copied each file
+	failPattern string                 // Requests for paths that contain it fail with 403
+	failRange   string                 // The uploads of ranges whose x-ms-range is it fail with 500
+}
+
+func newTestShare() *testShare {
+	return &testShare{dirs: map[string]bool{}, files: map[string][]byte{}, lastWrite: map[string]string{}, attributes: map[string]string{},
+		permissions: map[string]string{}, sddl: map[string]string{}, copies: map[string]http.Header{}}
+}
+
+// listing returns the XML of the listing of the directory at p, with the entries' sizes, last write times and
+// attributes. The caller holds share.mu.
+func (share *testShare) listing(p string) string {
+	var names []string
+	entries := map[string]string{}
+	add := func(name, element, size string) {
+		rel := strings.TrimPrefix(name, p+"/")
+		if !strings.HasPrefix(name, p+"/") || strings.Contains(rel, "/") {
+			return
+		}
+		entry := "<" + element + "><Name>" + rel + "</Name><Properties><Content-Length>" + size + "</Content-Length>"
+		if t := share.lastWrite[name]; t != "" {
+			entry += "<LastWriteTime>" + t + "</LastWriteTime>"
+		}
+		entry += "</Properties>"
+		if a := share.attributes[name]; a != "" {
+			entry += "<Attributes>" + a + "</Attributes>"
+		}
+		if k := share.permissions[name]; k != "" {
+			entry += "<PermissionKey>" + k + "</PermissionKey>"
+		}
+		names = append(names, rel)
+		entries[rel] = entry + "</" + element + ">"
+	}
+	for d := range share.dirs {
+		add(d, "Directory", "0")
+	}
+	for f, content := range share.files {
+		add(f, "File", strconv.Itoa(len(content)))
+	}
+	sort.Strings(names)
+	xml := "<EnumerationResults><Entries>"
+	for _, name := range names {
+		xml += entries[name]
+	}
+	return xml + "</Entries><NextMarker/></EnumerationResults>"
+}
+
+// newTestSharePipeline returns a pipeline that answers the requests UploadDirectoryToShare and
+// DownloadShareToDirectory, and CopyTree, make from the contents of share: creating and listing directories, creating,
+// resizing and copying files, which copies end on the first poll, uploading and downloading ranges, getting and
+// setting properties, and getting permissions.
+func newTestSharePipeline(share *testShare) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				var body []byte
+				if request.Body != nil {
+					body, _ = ioutil.ReadAll(request.Body) // Reading the body reports upload progress
+				}
+				p := strings.TrimPrefix(request.URL.Path, "/share/")
+				q := request.URL.Query()
+				share.mu.Lock()
+				defer share.mu.Unlock()
+				if share.failPattern != "" && strings.Contains(p, share.failPattern) {
+					return newStatusResponse(request, http.StatusForbidden), nil
+				}
+				switch {
+				case q.Get("restype") == "directory" && q.Get("comp") == "list":
+					response := newStatusResponse(request, http.StatusOK)
+					response.Response().Body = ioutil.NopCloser(strings.NewReader(share.listing(p)))
+					return response, nil
+				case q.Get("comp") == "filepermission":
+					response := newStatusResponse(request, http.StatusOK)
+					response.Response().Body = ioutil.NopCloser(strings.NewReader(
+						`{"permission":"` + share.sddl[request.Header.Get("x-ms-file-permission-key")] + `"}`))
+					return response, nil
+				case request.Method == http.MethodHead:
+					response := newStatusResponse(request, http.StatusOK)
+					response.Response().Header.Set("Content-Length", strconv.Itoa(len(share.files[p])))
+					if share.copies[p] != nil {
+						response.Response().Header.Set("x-ms-copy-id", "copy-"+p)
+						response.Response().Header.Set("x-ms-copy-status", string(CopyStatusSuccess))
+					}
+					return response, nil
+				case request.Method == http.MethodGet:
+					content := share.files[p]
+					var start, end int
+					fmt.Sscanf(request.Header.Get("x-ms-range"), "bytes=%d-%d", &start, &end)
+					if end >= len(content) {
+						end = len(content) - 1
+					}
+					response := newStatusResponse(request, http.StatusPartialContent)
+					response.Response().Body = ioutil.NopCloser(bytes.NewReader(content[start : end+1]))
+					response.Response().Header.Set("Content-Length", strconv.Itoa(end+1-start))
+					return response, nil
+				case q.Get("restype") == "directory" && q.Get("comp") == "properties":
+					share.lastWrite[p] = request.Header.Get("x-ms-file-last-write-time")
+					share.attributes[p] = request.Header.Get("x-ms-file-attributes")
+					share.permissions[p] = request.Header.Get("x-ms-file-permission-key")
+					if sddl := request.Header.Get("x-ms-file-permission"); sddl != "" {
+						share.permissions[p] = "set:" + sddl
+					}
+					return newStatusResponse(request, http.StatusOK), nil
+				case q.Get("restype") == "directory":
+					if share.dirs[p] {
+						response := newStatusResponse(request, http.StatusConflict)
+						response.Response().Header.Set("x-ms-error-code", string(ServiceCodeResourceAlreadyExists))
+						return response, nil
+					}
+					share.dirs[p] = true
+					return newStatusResponse(request, http.StatusCreated), nil
+				case q.Get("comp") == "range" && request.Header.Get("x-ms-range") == share.failRange:
+					return newStatusResponse(request, http.StatusInternalServerError), nil
+				case q.Get("comp") == "range":
+					copy(share.files[p][parseRangeStart(request.Header.Get("x-ms-range")):], body)
+					return newStatusResponse(request, http.StatusCreated), nil
+				case q.Get("comp") == "properties":
+					share.lastWrite[p] = request.Header.Get("x-ms-file-last-write-time")
+					if size, err := strconv.Atoi(request.Header.Get("x-ms-content-length")); err == nil { // Resize
+						resized := make([]byte, size)
+						copy(resized, share.files[p])
+						share.files[p] = resized
+					}
+					return newStatusResponse(request, http.StatusOK), nil
+				case request.Header.Get("x-ms-copy-source") != "":
+					source, _ := url.Parse(request.Header.Get("x-ms-copy-source"))
+					share.files[p] = append([]byte{}, share.files[strings.TrimPrefix(source.Path, "/share/")]...)
+					share.copies[p] = request.Header
+					response := newStatusResponse(request, http.StatusAccepted)
+					response.Response().Header.Set("x-ms-copy-id", "copy-"+p)
+					response.Response().Header.Set("x-ms-copy-status", string(CopyStatusPending))
+					return response, nil
+				case request.Header.Get("x-ms-type") == "file":
+					size, _ := strconv.Atoi(request.Header.Get("x-ms-content-length"))
+					share.files[p] = make([]byte, size)
+					return newStatusResponse(request, http.StatusCreated), nil
+				}
+				return newStatusResponse(request, http.StatusBadRequest), nil
+			}
+		}),
+	}, pipeline.Options{})
+}
+
+// writeTestTree creates the files, mapped to their contents, under root. Paths are slash-separated.
+func writeTestTree(c *chk.C, root string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(p), 0755), chk.IsNil)
+		c.Assert(ioutil.WriteFile(p, []byte(content), 0644), chk.IsNil)
+	}
+}
+
+func (s *uploadDirectorySuite) TestUploadDirectoryToShare(c *chk.C) {
+	root := c.MkDir()
+	writeTestTree(c, root, map[string]string{
+		"a.txt":           "hello",
+		"empty":           "",
+		"sub/b.txt":       strings.Repeat("b", 10),
+		"sub/deep/c.txt":  "c",
+		"sub/skip.log":    "skipped by the filter",
+		"excluded/d.txt":  "skipped with its directory",
+		"links/target.md": "linked",
+	})
+	c.Assert(os.Symlink(filepath.Join(root, "links", "target.md"), filepath.Join(root, "links", "link.md")), chk.IsNil)
+	c.Assert(os.Symlink(filepath.Join(root, "sub"), filepath.Join(root, "links", "dirlink")), chk.IsNil)
+
+	share := newTestShare()
+	u, _ := url.Parse(testRetryErrorMockURL + "share/dest")
+	dir := NewDirectoryURL(*u, newTestSharePipeline(share))
+	progressMu := sync.Mutex{}
+	progress := map[string]int64{}
+	err := UploadDirectoryToShare(context.Background(), root, dir, UploadDirOptions{
+		RangeSize: 4,
+		Filter: func(path string, info os.FileInfo) bool {
+			return path != "excluded" && !strings.HasSuffix(path, ".log")
+		},
+		Progress: func(path string, bytesTransferred int64) {
+			progressMu.Lock()
+			progress[path] = bytesTransferred
+			progressMu.Unlock()
+		},
+		PreserveSMBProperties: true,
+	})
+	c.Assert(err, chk.IsNil)
+
+	var dirs []string
+	for d := range share.dirs {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	c.Assert(dirs, chk.DeepEquals, []string{"dest", "dest/links", "dest/sub", "dest/sub/deep"})
+	c.Assert(share.files, chk.DeepEquals, map[string][]byte{
+		"dest/a.txt":           []byte("hello"),
+		"dest/empty":           {},
+		"dest/sub/b.txt":       []byte(strings.Repeat("b", 10)),
+		"dest/sub/deep/c.txt":  []byte("c"),
+		"dest/links/target.md": []byte("linked"),
+		"dest/links/link.md":   []byte("linked"),
+	})
+	c.Assert(progress["a.txt"], chk.Equals, int64(5))
+	c.Assert(progress["sub/b.txt"], chk.Equals, int64(10))
+
+	// The last write times of the local files and directories, but not of the root, are set in the share.
+	info, err := os.Stat(filepath.Join(root, "sub", "b.txt"))
+	c.Assert(err, chk.IsNil)
+	c.Assert(share.lastWrite["dest/sub/b.txt"], chk.Equals, info.ModTime().UTC().Format(smbTimeFormat))
+	c.Assert(share.lastWrite["dest/sub/deep"], chk.Not(chk.Equals), "")
+	_, ok := share.lastWrite["dest"]
+	c.Assert(ok, chk.Equals, false)
+	c.Assert(share.lastWrite, chk.HasLen, 9)
+
+	// Symlinks can be skipped, and the directories that already exist are reused.
+	share.files = map[string][]byte{}
+	err = UploadDirectoryToShare(context.Background(), filepath.Join(root, "links"), dir, UploadDirOptions{SkipSymlinks: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(share.files, chk.DeepEquals, map[string][]byte{"dest/target.md": []byte("linked")})
+}
+
+func (s *uploadDirectorySuite) TestUploadDirectoryToShareErrors(c *chk.C) {
+	root := c.MkDir()
+	writeTestTree(c, root, map[string]string{
+		"a.txt":       "a",
+		"bad.txt":     "fails",
+		"baddir/c.go": "not attempted",
+		"d/e.txt":     "e",
+	})
+	share := newTestShare()
+	share.failPattern = "bad"
+	u, _ := url.Parse(testRetryErrorMockURL + "share")
+	dir := NewDirectoryURL(*u, newTestSharePipeline(share))
+
+	err := UploadDirectoryToShare(context.Background(), root, dir, UploadDirOptions{ContinueOnError: true})
+	c.Assert(err, chk.FitsTypeOf, &DirectoryTransferError{})
+	transferErr := err.(*DirectoryTransferError)
+	c.Assert(transferErr.Count, chk.Equals, 5) // a.txt, bad.txt, baddir, d and d/e.txt
+	c.Assert(transferErr.Failures, chk.HasLen, 2)
+	c.Assert(transferErr.Failures[0].Path, chk.Equals, "bad.txt")
+	c.Assert(transferErr.Failures[1].Path, chk.Equals, "baddir")
+	c.Assert(isStorageError(transferErr.Failures[0].Error, http.StatusForbidden), chk.Equals, true)
+	c.Assert(strings.HasPrefix(err.Error(), "failed to transfer 2 of 5 files and directories, the first of them bad.txt: "), chk.Equals, true)
+	c.Assert(share.files["d/e.txt"], chk.DeepEquals, []byte("e"))
+
+	// By default, the first failure stops the upload.
+	share.files = map[string][]byte{}
+	err = UploadDirectoryToShare(context.Background(), root, dir, UploadDirOptions{Parallelism: 1})
+	c.Assert(err, chk.FitsTypeOf, &DirectoryTransferError{})
+	c.Assert(err.(*DirectoryTransferError).Failures[0].Path, chk.Equals, "bad.txt")
+	_, ok := share.files["d/e.txt"]
+	c.Assert(ok, chk.Equals, false)
+
+	err = UploadDirectoryToShare(context.Background(), filepath.Join(root, "a.txt"), dir, UploadDirOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, .* isn't a directory")
+	err = UploadDirectoryToShare(context.Background(), root, dir, UploadDirOptions{RangeSize: -1})
+	c.Assert(err, chk.NotNil)
+}
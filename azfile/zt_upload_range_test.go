@@ -0,0 +1,336 @@
+package azfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	chk "gopkg.in/check.v1"
+)
+
+type uploadRangeSuite struct{}
+
+var _ = chk.Suite(&uploadRangeSuite{})
+
+// unseekableReader is a ReadSeeker that can't seek, like an os.File of a pipe.
+type unseekableReader struct {
+	io.Reader
+}
+
+func (unseekableReader) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New("illegal seek")
+}
+
+func (s *uploadRangeSuite) TestUploadRangeBodyPosition(c *chk.C) {
+	var sent []string
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestUploadRetryPipeline(&sent))
+
+	// The body's data starts at its position, and the retry resends it from there.
+	body := strings.NewReader("skipped|data")
+	body.Seek(int64(len("skipped|")), io.SeekStart)
+	_, err := fileURL.UploadRange(context.Background(), 512, body, nil, UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent, chk.DeepEquals, []string{"4:data", "4:data"})
+
+	// A body at its end has no data to upload.
+	sent = nil
+	_, err = fileURL.UploadRange(context.Background(), 0, body, nil, UploadRangeOptions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(sent, chk.HasLen, 0)
+
+	// So has a body positioned beyond its end.
+	body.Seek(100, io.SeekStart)
+	_, err = fileURL.UploadRange(context.Background(), 0, body, nil, UploadRangeOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, body's position 100 is beyond its end at 12")
+	c.Assert(sent, chk.HasLen, 0)
+
+	// A body that can't seek fails before anything is sent.
+	_, err = fileURL.UploadRange(context.Background(), 0, unseekableReader{strings.NewReader("data")}, nil, UploadRangeOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, body must be seekable: illegal seek")
+	c.Assert(sent, chk.HasLen, 0)
+}
+
+func (s *uploadRangeSuite) TestSectionReadSeeker(c *chk.C) {
+	body := strings.NewReader("0123456789")
+	body.Seek(4, io.SeekStart)
+	section, count, err := seekableStreamSection(body)
+	c.Assert(err, chk.IsNil)
+	c.Assert(count, chk.Equals, int64(6))
+
+	pos, err := section.Seek(0, io.SeekEnd)
+	c.Assert(err, chk.IsNil)
+	c.Assert(pos, chk.Equals, int64(6))
+	pos, err = section.Seek(-4, io.SeekCurrent)
+	c.Assert(err, chk.IsNil)
+	c.Assert(pos, chk.Equals, int64(2))
+	b := make([]byte, 2)
+	_, err = io.ReadFull(section, b)
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(b), chk.Equals, "67")
+	_, err = section.Seek(-1, io.SeekStart)
+	c.Assert(err, chk.NotNil)
+
+	// A body at position 0 is used as is.
+	body.Seek(0, io.SeekStart)
+	section, count, err = seekableStreamSection(body)
+	c.Assert(err, chk.IsNil)
+	c.Assert(section, chk.Equals, io.ReadSeeker(body))
+	c.Assert(count, chk.Equals, int64(10))
+}
+
+func (s *uploadRangeSuite) TestUploadRangeMaxRangeSize(c *chk.C) {
+	var sent http.Header
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestCapturePipeline(http.StatusCreated, http.Header{}, &sent))
+	data := make([]byte, FileMaxUploadRangeBytes+1)
+
+	_, err := fileURL.UploadRange(context.Background(), 0, bytes.NewReader(data), nil, UploadRangeOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, body's 4194305 bytes exceed the maximum range size of 4194304 bytes; .*")
+	c.Assert(sent, chk.IsNil)
+
+	// The limit can be raised for a service version that accepts larger ranges.
+	_, err = fileURL.UploadRange(context.Background(), 0, bytes.NewReader(data), nil,
+		UploadRangeOptions{MaxRangeSize: 2 * FileMaxUploadRangeBytes})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.Get("x-ms-range"), chk.Equals, "bytes=0-4194304")
+}
+
+func (s *uploadRangeSuite) TestUploadRangeSizeIsClamped(c *chk.C) {
+	share := newTestShare()
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestSharePipeline(share))
+	data := bytes.Repeat([]byte("0123456789"), FileMaxUploadRangeBytes/10+1)
+
+	// The ranges are no larger than UploadRange accepts, or it would fail them.
+	err := UploadBufferToAzureFile(context.Background(), data, fileURL, UploadToAzureFileOptions{RangeSize: 2 * FileMaxUploadRangeBytes})
+	c.Assert(err, chk.IsNil)
+	c.Assert(bytes.Equal(share.files["file"], data), chk.Equals, true)
+
+	err = UploadStreamToAzureFile(context.Background(), bytes.NewReader(data), fileURL, UploadStreamOptions{BufferSize: 2 * FileMaxUploadRangeBytes})
+	c.Assert(err, chk.IsNil)
+	c.Assert(bytes.Equal(share.files["file"], data), chk.Equals, true)
+
+	err = UploadBufferToAzureFile(context.Background(), data, fileURL, UploadToAzureFileOptions{RangeSize: -1})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, o.RangeSize must be >= 0")
+}
+
+func (s *uploadRangeSuite) TestUploadRangeGrowFile(c *chk.C) {
+	// The file's size, and its ETag, which changes with each resize. If grow isn't 0, another writer grows the file
+	// to grow bytes after the next HEAD is answered.
+	size, etag, grow := int64(4), 1, int64(0)
+	var requests []string
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		pipeline.MethodFactoryMarker(),
+		pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+			return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+				response := newStatusResponse(request, http.StatusOK)
+				switch {
+				case request.Method == http.MethodHead:
+					requests = append(requests, "HEAD")
+					response.Response().Header.Set("Content-Length", strconv.FormatInt(size, 10))
+					response.Response().Header.Set("ETag", strconv.Itoa(etag))
+					if grow != 0 {
+						size, etag, grow = grow, etag+1, 0
+					}
+				case request.URL.Query().Get("comp") == "lease":
+					requests = append(requests, request.Header.Get("x-ms-lease-action"))
+					if request.Header.Get("x-ms-lease-action") == "acquire" {
+						response = newStatusResponse(request, http.StatusCreated)
+					}
+				case request.URL.Query().Get("comp") == "properties":
+					size, _ = strconv.ParseInt(request.Header.Get("x-ms-content-length"), 10, 64)
+					etag++
+					requests = append(requests, "resize "+strconv.FormatInt(size, 10))
+				default:
+					requests = append(requests, "PUT "+request.Header.Get("x-ms-range"))
+				}
+				return response, nil
+			}
+		}),
+	}, pipeline.Options{})
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, p)
+
+	// A range ending beyond the file fails without being sent, or grows the file to fit.
+	_, err := fileURL.UploadRange(context.Background(), 2, strings.NewReader("data"), nil, UploadRangeOptions{CheckSize: true})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, the range ends at byte 6, beyond the end of the file's 4 bytes; .*")
+	c.Assert(requests, chk.DeepEquals, []string{"HEAD"})
+
+	requests = nil
+	_, err = fileURL.UploadRange(context.Background(), 2, strings.NewReader("data"), nil, UploadRangeOptions{GrowFile: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(requests, chk.DeepEquals, []string{"HEAD", "acquire", "HEAD", "resize 6", "release", "PUT bytes=2-5"})
+
+	// Under the caller's lease, the file is resized without another read.
+	requests = nil
+	_, err = fileURL.UploadRange(context.Background(), 4, strings.NewReader("data"), nil, UploadRangeOptions{
+		FileAccessConditions: FileAccessConditions{LeaseAccessConditions: LeaseAccessConditions{LeaseID: "lease"}}, GrowFile: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(requests, chk.DeepEquals, []string{"HEAD", "resize 8", "PUT bytes=4-7"})
+
+	// A range within the file is sent as is.
+	requests = nil
+	_, err = fileURL.UploadRange(context.Background(), 0, strings.NewReader("data"), nil, UploadRangeOptions{GrowFile: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(requests, chk.DeepEquals, []string{"HEAD", "PUT bytes=0-3"})
+
+	// A file another writer grows before the resize isn't shrunk.
+	requests = nil
+	grow = 100
+	_, err = fileURL.UploadRange(context.Background(), 10, strings.NewReader("data"), nil, UploadRangeOptions{GrowFile: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(requests, chk.DeepEquals, []string{"HEAD", "acquire", "HEAD", "release", "PUT bytes=10-13"})
+	c.Assert(size, chk.Equals, int64(100))
+}
+
+func (s *uploadRangeSuite) TestUploadReaderAtToAzureFile(c *chk.C) {
+	share := newTestShare()
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestSharePipeline(share))
+
+	// Only the first size bytes of the reader are uploaded.
+	reader := strings.NewReader("0123456789|ignored")
+	err := UploadReaderAtToAzureFile(context.Background(), reader, 10, fileURL, UploadToAzureFileOptions{RangeSize: 4, Parallelism: 3})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(share.files["file"]), chk.Equals, "0123456789")
+
+	// A range that fails is reported with its error.
+	share.failRange = "bytes=4-7"
+	err = UploadReaderAtToAzureFile(context.Background(), reader, 10, fileURL, UploadToAzureFileOptions{RangeSize: 4, Parallelism: 1})
+	c.Assert(err, chk.FitsTypeOf, &RangeUploadError{})
+	rangeErr := err.(*RangeUploadError)
+	c.Assert(rangeErr.Offset, chk.Equals, int64(4))
+	c.Assert(rangeErr.Count, chk.Equals, int64(4))
+	c.Assert(isStorageError(err, http.StatusInternalServerError), chk.Equals, true)
+
+	err = UploadReaderAtToAzureFile(context.Background(), reader, -1, fileURL, UploadToAzureFileOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, size must be >= 0")
+}
+
+func (s *uploadRangeSuite) TestRangeWriter(c *chk.C) {
+	share := newTestShare()
+	share.files["file"] = []byte("ab")
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestSharePipeline(share))
+
+	// The writes from a pipe are uploaded in ranges, growing the file, which Close trims to where they end.
+	w, err := fileURL.NewRangeWriter(context.Background(), 1, RangeWriterOptions{RangeSize: 4})
+	c.Assert(err, chk.IsNil)
+	pr, pw := io.Pipe()
+	go func() {
+		for _, chunk := range []string{"012", "3456", "789"} {
+			pw.Write([]byte(chunk))
+		}
+		pw.Close()
+	}()
+	n, err := io.Copy(w, pr)
+	c.Assert(err, chk.IsNil)
+	c.Assert(n, chk.Equals, int64(10))
+	c.Assert(w.Close(), chk.IsNil)
+	c.Assert(string(share.files["file"]), chk.Equals, "a0123456789")
+	_, err = w.Write([]byte("x"))
+	c.Assert(err, chk.ErrorMatches, "the range writer is closed")
+
+	// A range that fails to upload is kept, and uploaded again by the next Write or Close.
+	share.files["file"] = []byte{}
+	share.failRange = "bytes=4-7"
+	w, err = fileURL.NewRangeWriter(context.Background(), 0, RangeWriterOptions{RangeSize: 4})
+	c.Assert(err, chk.IsNil)
+	written, err := w.Write([]byte("01234567"))
+	c.Assert(written, chk.Equals, 8)
+	c.Assert(err, chk.FitsTypeOf, &RangeUploadError{})
+	c.Assert(err.(*RangeUploadError).Offset, chk.Equals, int64(4))
+	c.Assert(w.Close(), chk.NotNil)
+	share.failRange = ""
+	c.Assert(w.Close(), chk.IsNil)
+	c.Assert(string(share.files["file"]), chk.Equals, "01234567")
+
+	// So is a range for which the file fails to grow.
+	w, err = fileURL.NewRangeWriter(context.Background(), 8, RangeWriterOptions{RangeSize: 4})
+	c.Assert(err, chk.IsNil)
+	share.failPattern = "file"
+	_, err = w.Write([]byte("89ab"))
+	c.Assert(err, chk.FitsTypeOf, &RangeUploadError{})
+	c.Assert(err.(*RangeUploadError).Offset, chk.Equals, int64(8))
+	c.Assert(err.(*RangeUploadError).Count, chk.Equals, int64(4))
+	c.Assert(isStorageError(err, http.StatusForbidden), chk.Equals, true)
+	share.failPattern = ""
+	c.Assert(w.Close(), chk.IsNil)
+	c.Assert(string(share.files["file"]), chk.Equals, "0123456789ab")
+
+	_, err = fileURL.NewRangeWriter(context.Background(), -1, RangeWriterOptions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, startOffset must be >= 0")
+}
+
+func (s *uploadRangeSuite) TestZeroLengthFile(c *chk.C) {
+	share := newTestShare()
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestSharePipeline(share))
+
+	_, err := fileURL.Create(context.Background(), 0, FileHTTPHeaders{}, nil, FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(share.files["file"], chk.HasLen, 0)
+
+	// A range can only be written once the file has been grown to hold it.
+	_, err = fileURL.UploadRange(context.Background(), 0, strings.NewReader("x"), nil, UploadRangeOptions{CheckSize: true})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, the range ends at byte 1, beyond the end of the file's 0 bytes; .*")
+	_, err = fileURL.Resize(context.Background(), 1, ResizeOptions{})
+	c.Assert(err, chk.IsNil)
+	_, err = fileURL.UploadRange(context.Background(), 0, strings.NewReader("x"), nil, UploadRangeOptions{CheckSize: true})
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(share.files["file"]), chk.Equals, "x")
+
+	_, err = fileURL.Create(context.Background(), -1, FileHTTPHeaders{}, nil, FileAccessConditions{})
+	c.Assert(err, chk.ErrorMatches, "invalid argument, size must be >= 0 and <= .*")
+	_, err = fileURL.Create(context.Background(), FileMaxSizeInBytes+1, FileHTTPHeaders{}, nil, FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *uploadRangeSuite) TestGetRangeListDiff(c *chk.C) {
+	var sent *http.Request
+	body := `<?xml version="1.0" encoding="utf-8"?><Ranges><Range><Start>0</Start><End>511</End></Range>` +
+		`<ClearRange><Start>512</Start><End>1023</End></ClearRange><Range><Start>2048</Start><End>3071</End></Range></Ranges>`
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestHandlesPipeline(http.Header{}, body, &sent))
+
+	diff, err := fileURL.GetRangeListDiff(context.Background(), "2020-09-08T22:56:16.0000000Z", 0, 4096)
+	c.Assert(err, chk.IsNil)
+	c.Assert(sent.URL.Query().Get("comp"), chk.Equals, "rangelist")
+	c.Assert(sent.URL.Query().Get("prevsharesnapshot"), chk.Equals, "2020-09-08T22:56:16.0000000Z")
+	c.Assert(sent.Header.Get("x-ms-range"), chk.Equals, "bytes=0-4095")
+	c.Assert(diff.Ranges, chk.DeepEquals, []Range{{Start: 0, End: 511}, {Start: 2048, End: 3071}})
+	c.Assert(diff.ClearRanges, chk.DeepEquals, []Range{{Start: 512, End: 1023}})
+
+	// GetRangeList only returns the written ranges.
+	ranges, err := fileURL.GetRangeList(context.Background(), 0, CountToEnd, GetRangeListOptions{PrevShareSnapshot: "2020-09-08T22:56:16.0000000Z"})
+	c.Assert(err, chk.IsNil)
+	c.Assert(ranges.Items, chk.DeepEquals, diff.Ranges)
+
+	_, err = fileURL.GetRangeListDiff(context.Background(), "", 0, CountToEnd)
+	c.Assert(err, chk.NotNil)
+}
+
+func (s *uploadRangeSuite) TestUploadStreamMaxFileSize(c *chk.C) {
+	file := make([]byte, 2048)
+	u, _ := url.Parse(testRetryErrorMockURL + "share/file")
+	fileURL := NewFileURL(*u, newTestStreamUploadPipeline(file))
+
+	// The stream fails as soon as more than MaxFileSize bytes are read.
+	err := UploadStreamToAzureFile(context.Background(), bytes.NewReader(make([]byte, 2048)), fileURL,
+		UploadStreamOptions{BufferSize: 512, MaxBuffers: 1, MaxFileSize: 1000})
+	c.Assert(err, chk.FitsTypeOf, &FileTooLargeError{})
+	c.Assert(*err.(*FileTooLargeError), chk.Equals, FileTooLargeError{Size: 1024, MaxSize: 1000})
+
+	err = UploadStreamToAzureFile(context.Background(), bytes.NewReader(make([]byte, 1000)), fileURL,
+		UploadStreamOptions{BufferSize: 512, MaxFileSize: 1000})
+	c.Assert(err, chk.IsNil)
+	err = UploadStreamToAzureFile(context.Background(), bytes.NewReader(nil), fileURL, UploadStreamOptions{MaxFileSize: -1})
+	c.Assert(err, chk.NotNil)
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	chk "gopkg.in/check.v1"
@@ -52,13 +55,13 @@ func createNewLocalFile(c *chk.C, fileSizeInByte int64) (*os.File, []byte) {
 }
 
 func delFile(c *chk.C, file FileURL) {
-	resp, err := file.Delete(context.Background())
+	resp, err := file.Delete(context.Background(), FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Response().StatusCode, chk.Equals, 202)
 }
 
 func delShare(c *chk.C, share ShareURL, option DeleteSnapshotsOptionType) {
-	resp, err := share.Delete(context.Background(), option)
+	resp, err := share.Delete(context.Background(), option, ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Response().StatusCode, chk.Equals, 202)
 }
@@ -79,7 +82,7 @@ func getRandomDataAndReader(n int) (*bytes.Reader, []byte) {
 func createNewShare(c *chk.C, fsu ServiceURL) (share ShareURL, name string) {
 	share, name = getShareURL(c, fsu)
 
-	cResp, err := share.Create(ctx, nil, 0)
+	cResp, err := share.Create(ctx, nil, ShareCreateOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 	return share, name
@@ -166,7 +169,7 @@ func createNewFileFromShare(c *chk.C, share ShareURL, fileSize int64) (file File
 
 	file, name = getFileURLFromDirectory(c, dir)
 
-	cResp, err := file.Create(ctx, fileSize, FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(ctx, fileSize, FileHTTPHeaders{}, nil, FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.StatusCode(), chk.Equals, 201)
 
@@ -186,7 +189,7 @@ func (ud *uploadDownloadSuite) TestDownloadBasic(c *chk.C) {
 
 	contentR, contentD := getRandomDataAndReader(fileSize)
 
-	pResp, err := file.UploadRange(context.Background(), 0, contentR, nil)
+	pResp, err := file.UploadRange(context.Background(), 0, contentR, nil, UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(pResp.ContentMD5(), chk.Not(chk.Equals), nil)
 	c.Assert(pResp.StatusCode(), chk.Equals, http.StatusCreated)
@@ -212,7 +215,7 @@ func (ud *uploadDownloadSuite) TestDownloadBasic(c *chk.C) {
 	c.Assert(download, chk.DeepEquals, contentD[:1024])
 
 	// Set ContentMD5 for the entire file.
-	_, err = file.SetHTTPHeaders(context.Background(), FileHTTPHeaders{ContentMD5: pResp.ContentMD5()})
+	_, err = file.SetHTTPHeaders(context.Background(), FileHTTPHeaders{ContentMD5: pResp.ContentMD5()}, FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	// Test get with another type of range index, and validate if FileContentMD5 can be get correclty.
@@ -292,7 +295,7 @@ func (ud *uploadDownloadSuite) TestDownloadRetry(c *chk.C) {
 
 	contentR, contentD := getRandomDataAndReader(fileSize)
 
-	pResp, err := file.UploadRange(context.Background(), 0, contentR, nil)
+	pResp, err := file.UploadRange(context.Background(), 0, contentR, nil, UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(pResp.ContentMD5(), chk.Not(chk.Equals), nil)
 	c.Assert(pResp.StatusCode(), chk.Equals, http.StatusCreated)
@@ -303,7 +306,7 @@ func (ud *uploadDownloadSuite) TestDownloadRetry(c *chk.C) {
 	c.Assert(pResp.Version(), chk.Not(chk.Equals), "")
 	c.Assert(pResp.Date().IsZero(), chk.Equals, false)
 
-	_, err = file.SetHTTPHeaders(context.Background(), FileHTTPHeaders{ContentMD5: pResp.ContentMD5()})
+	_, err = file.SetHTTPHeaders(context.Background(), FileHTTPHeaders{ContentMD5: pResp.ContentMD5()}, FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	// Download entire file with retry, check status code 200.
@@ -450,7 +453,7 @@ func (ud *uploadDownloadSuite) TestUploadDownloadBufferParallelNonDefault(c *chk
 	c.Assert(err, chk.IsNil)
 
 	destBytes := make([]byte, fileSize)
-	resp, err := DownloadAzureFileToBuffer(ctx, file, destBytes, DownloadFromAzureFileOptions{})
+	resp, err := DownloadAzureFileToBuffer(ctx, file, 0, CountToEnd, destBytes, DownloadFromAzureFileOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.ContentType(), chk.Equals, "application/octet-stream")
 	c.Assert(resp.ContentLength(), chk.Equals, int64(fileSize))
@@ -468,7 +471,7 @@ func (ud *uploadDownloadSuite) TestUploadDownloadBufferParallelNonDefault(c *chk
 	c.Assert(err, chk.IsNil)
 
 	destBytes2 := make([]byte, fileSize2)
-	resp2, err := DownloadAzureFileToBuffer(ctx, file, destBytes2, DownloadFromAzureFileOptions{})
+	resp2, err := DownloadAzureFileToBuffer(ctx, file, 0, CountToEnd, destBytes2, DownloadFromAzureFileOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp2.ContentType(), chk.Equals, "test")
 	c.Assert(resp2.ContentLength(), chk.Equals, int64(fileSize2))
@@ -514,7 +517,7 @@ func (ud *uploadDownloadSuite) TestUploadDownloadBufferParallelCheckProgress(c *
 
 	destBytes := make([]byte, fileSize)
 	_, err = DownloadAzureFileToBuffer(
-		ctx, file, destBytes,
+		ctx, file, 0, CountToEnd, destBytes,
 		DownloadFromAzureFileOptions{
 			RangeSize:   int64(blockSize),
 			Parallelism: 3,
@@ -578,7 +581,7 @@ func testUploadDownloadFileParallelDefault(c *chk.C, fileSize int64) {
 	file2Name := generateFileName()
 	file2, err := os.Create(file2Name)
 	c.Assert(err, chk.IsNil)
-	resp, err := DownloadAzureFileToFile(ctx, fileURL, file2, DownloadFromAzureFileOptions{})
+	resp, err := DownloadAzureFileToFile(ctx, fileURL, 0, CountToEnd, file2, DownloadFromAzureFileOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.ETag(), chk.Not(chk.Equals), ETagNone)
 
@@ -611,16 +614,17 @@ func (ud *uploadDownloadSuite) TestUploadFileToAzureFileNegativeInvalidRangeSize
 	c.Assert(strings.Contains(err.Error(), "o.RangeSize must be >= 0"), chk.Equals, true)
 }
 
-func (ud *uploadDownloadSuite) TestUploadFileToAzureFileNegativeInvalidRangeSize2(c *chk.C) {
-	_, srcBytes := getRandomDataAndReader(1)
+func (ud *uploadDownloadSuite) TestUploadFileToAzureFileClampedRangeSize(c *chk.C) {
+	_, srcBytes := getRandomDataAndReader(FileMaxUploadRangeBytes + 1)
 
 	fsu := getFSU()
-	shareURL, _ := getShareURL(c, fsu)
-	fileURL, _ := getFileURLFromShare(c, shareURL)
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
 
+	// A RangeSize larger than FileMaxUploadRangeBytes is clamped to it.
 	err := UploadBufferToAzureFile(ctx, srcBytes, fileURL, UploadToAzureFileOptions{RangeSize: FileMaxUploadRangeBytes + 1})
-	c.Assert(err, chk.NotNil)
-	c.Assert(strings.Contains(err.Error(), "o.RangeSize must be >= 0 and <= 4194304, in bytes"), chk.Equals, true)
+	c.Assert(err, chk.IsNil)
 }
 
 func (ud *uploadDownloadSuite) TestUploadFileToAzureFileNegativeInvalidLocalFile(c *chk.C) {
@@ -646,7 +650,7 @@ func (ud *uploadDownloadSuite) TestDownloadAzureFileToFileNegativeInvalidLocalFi
 
 	fileURL, _ := createNewFileFromShare(c, share, 1)
 
-	_, err := DownloadAzureFileToFile(ctx, fileURL, nil, DownloadFromAzureFileOptions{})
+	_, err := DownloadAzureFileToFile(ctx, fileURL, 0, CountToEnd, nil, DownloadFromAzureFileOptions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(strings.Contains(err.Error(), "file can't be nil"), chk.Equals, true)
 }
@@ -693,7 +697,7 @@ func (ud *uploadDownloadSuite) TestDownloadFileParallelOverwriteLocalFile(c *chk
 	err := UploadBufferToAzureFile(ctx, srcBytes, fileURL, UploadToAzureFileOptions{FileHTTPHeaders: headers, Metadata: metadata})
 	c.Assert(err, chk.IsNil)
 
-	resp, err := DownloadAzureFileToFile(ctx, fileURL, localFile, DownloadFromAzureFileOptions{})
+	resp, err := DownloadAzureFileToFile(ctx, fileURL, 0, CountToEnd, localFile, DownloadFromAzureFileOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.ContentType(), chk.Equals, "application/octet-stream")
 	c.Assert(resp.ContentLength(), chk.Equals, int64(fileSize))
@@ -708,3 +712,222 @@ func (ud *uploadDownloadSuite) TestDownloadFileParallelOverwriteLocalFile(c *chk
 
 	c.Assert(destBytes, chk.DeepEquals, srcBytes)
 }
+
+func (ud *uploadDownloadSuite) TestDoBatchTransferCancelsOnFirstError(c *chk.C) {
+	failure := errors.New("range failed")
+	var started int32
+	err := doBatchTransfer(context.Background(), batchTransferOptions{
+		transferSize: 100,
+		chunkSize:    1,
+		parallelism:  2,
+		operation: func(offset int64, chunkSize int64, ctx context.Context) error {
+			atomic.AddInt32(&started, 1)
+			if offset == 0 {
+				return failure
+			}
+			<-ctx.Done() // Outstanding operations observe the cancellation.
+			return ctx.Err()
+		},
+		operationName: "TestDoBatchTransferCancelsOnFirstError",
+	})
+	c.Assert(err, chk.Equals, failure)
+	c.Assert(atomic.LoadInt32(&started) < 100, chk.Equals, true)
+}
+
+func (ud *uploadDownloadSuite) TestDoBatchTransferWaitsForOutstandingOperations(c *chk.C) {
+	failure := errors.New("range failed")
+	blocked := make(chan struct{}, 3)
+	var running int32
+	err := doBatchTransfer(context.Background(), batchTransferOptions{
+		transferSize: 4,
+		chunkSize:    1,
+		parallelism:  4,
+		operation: func(offset int64, chunkSize int64, ctx context.Context) error {
+			if offset == 0 {
+				for i := 0; i < 3; i++ {
+					<-blocked // Fail only once the other ranges are in flight
+				}
+				return failure
+			}
+			atomic.AddInt32(&running, 1)
+			blocked <- struct{}{}
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond) // Still writing after the cancellation
+			atomic.AddInt32(&running, -1)
+			return ctx.Err()
+		},
+		operationName: "TestDoBatchTransferWaitsForOutstandingOperations",
+	})
+	c.Assert(err, chk.Equals, failure)
+	c.Assert(atomic.LoadInt32(&running), chk.Equals, int32(0))
+}
+
+func (ud *uploadDownloadSuite) TestDoBatchTransferHonorsCallerCancellation(c *chk.C) {
+	cctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var started int32
+	err := doBatchTransfer(cctx, batchTransferOptions{
+		transferSize: 10,
+		chunkSize:    1,
+		parallelism:  2,
+		operation: func(offset int64, chunkSize int64, ctx context.Context) error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		},
+		operationName: "TestDoBatchTransferHonorsCallerCancellation",
+	})
+	c.Assert(err, chk.Equals, context.Canceled)
+	c.Assert(atomic.LoadInt32(&started), chk.Equals, int32(0))
+}
+
+// errorAfterReader returns err once limit bytes have been read from r.
+type errorAfterReader struct {
+	r     io.Reader
+	limit int
+	err   error
+}
+
+func (e *errorAfterReader) Read(p []byte) (int, error) {
+	if e.limit <= 0 {
+		return 0, e.err
+	}
+	if len(p) > e.limit {
+		p = p[:e.limit]
+	}
+	n, err := e.r.Read(p)
+	e.limit -= n
+	return n, err
+}
+
+func (ud *uploadDownloadSuite) TestUploadStreamToAzureFile(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
+
+	// The size isn't a multiple of the buffer size, so the last range is short.
+	fileSize := 5*1024*1024 + 100
+	_, srcBytes := getRandomDataAndReader(fileSize)
+	// Wrap the reader so its length can't be discovered.
+	reader := ioutil.NopCloser(bytes.NewReader(srcBytes))
+
+	err := UploadStreamToAzureFile(ctx, reader, fileURL, UploadStreamOptions{BufferSize: 1024 * 1024, MaxBuffers: 3, Metadata: Metadata{"foo": "bar"}})
+	c.Assert(err, chk.IsNil)
+
+	props, err := fileURL.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.ContentLength(), chk.Equals, int64(fileSize))
+	c.Assert(props.NewMetadata(), chk.DeepEquals, Metadata{"foo": "bar"})
+
+	destBytes := make([]byte, fileSize)
+	_, err = DownloadAzureFileToBuffer(ctx, fileURL, 0, CountToEnd, destBytes, DownloadFromAzureFileOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(destBytes, chk.DeepEquals, srcBytes)
+}
+
+func (ud *uploadDownloadSuite) TestUploadStreamToAzureFileEmpty(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
+
+	err := UploadStreamToAzureFile(ctx, bytes.NewReader(nil), fileURL, UploadStreamOptions{})
+	c.Assert(err, chk.IsNil)
+
+	props, err := fileURL.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.ContentLength(), chk.Equals, int64(0))
+}
+
+func (ud *uploadDownloadSuite) TestUploadStreamToAzureFileReaderError(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
+
+	readErr := errors.New("stream broke")
+	reader := &errorAfterReader{r: getReaderToRandomBytes(4096), limit: 3000, err: readErr}
+
+	err := UploadStreamToAzureFile(ctx, reader, fileURL, UploadStreamOptions{BufferSize: 1024})
+	c.Assert(err, chk.Equals, readErr)
+
+	// The partially written file is removed.
+	_, err = fileURL.GetProperties(ctx)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.(StorageError).Response().StatusCode, chk.Equals, http.StatusNotFound)
+}
+
+func (ud *uploadDownloadSuite) TestUploadStreamToAzureFileNegativeInvalidBufferSize(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := getShareURL(c, fsu)
+	fileURL, _ := getFileURLFromShare(c, shareURL)
+
+	err := UploadStreamToAzureFile(ctx, bytes.NewReader(nil), fileURL, UploadStreamOptions{BufferSize: -1})
+	c.Assert(err, chk.NotNil)
+	c.Assert(strings.Contains(err.Error(), "o.BufferSize must be >= 0"), chk.Equals, true)
+}
+
+func (ud *uploadDownloadSuite) TestDownloadAzureFileToBufferWithOffsetAndCount(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
+
+	fileSize := 3 * 1024 * 1024
+	_, srcBytes := getRandomDataAndReader(fileSize)
+	err := UploadBufferToAzureFile(ctx, srcBytes, fileURL, UploadToAzureFileOptions{})
+	c.Assert(err, chk.IsNil)
+
+	// Small ranges so that several of them complete out of order.
+	offset, count := int64(1000), int64(2*1024*1024)
+	destBytes := make([]byte, count)
+	progress := int64(0)
+	_, err = DownloadAzureFileToBuffer(ctx, fileURL, offset, count, destBytes,
+		DownloadFromAzureFileOptions{RangeSize: 64 * 1024, Parallelism: 8, Progress: func(b int64) { progress = b }})
+	c.Assert(err, chk.IsNil)
+	c.Assert(destBytes, chk.DeepEquals, srcBytes[offset:offset+count])
+	c.Assert(progress, chk.Equals, count)
+
+	// CountToEnd downloads from offset to the end of the file.
+	destBytes = make([]byte, int64(fileSize)-offset)
+	_, err = DownloadAzureFileToBuffer(ctx, fileURL, offset, CountToEnd, destBytes, DownloadFromAzureFileOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(destBytes, chk.DeepEquals, srcBytes[offset:])
+}
+
+func (ud *uploadDownloadSuite) TestDownloadAzureFileToFileWithOffsetAndCount(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, share)
+
+	fileSize := 1024 * 1024
+	_, srcBytes := getRandomDataAndReader(fileSize)
+	err := UploadBufferToAzureFile(ctx, srcBytes, fileURL, UploadToAzureFileOptions{})
+	c.Assert(err, chk.IsNil)
+
+	localFileName := generateFileName()
+	localFile, err := os.Create(localFileName)
+	c.Assert(err, chk.IsNil)
+	defer func() {
+		localFile.Close()
+		os.Remove(localFileName)
+	}()
+
+	_, err = DownloadAzureFileToFile(ctx, fileURL, 512, 4096, localFile, DownloadFromAzureFileOptions{RangeSize: 1024})
+	c.Assert(err, chk.IsNil)
+
+	destBytes, err := ioutil.ReadFile(localFileName)
+	c.Assert(err, chk.IsNil)
+	c.Assert(destBytes, chk.DeepEquals, srcBytes[512:512+4096])
+}
+
+func (ud *uploadDownloadSuite) TestDownloadAzureFileToBufferNegativeOffset(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := getShareURL(c, fsu)
+	fileURL, _ := getFileURLFromShare(c, shareURL)
+
+	_, err := DownloadAzureFileToBuffer(ctx, fileURL, -1, CountToEnd, nil, DownloadFromAzureFileOptions{})
+	c.Assert(err, chk.NotNil)
+	c.Assert(strings.Contains(err.Error(), "offset and count must be >= 0"), chk.Equals, true)
+}
@@ -52,7 +52,7 @@ func (s *DirectoryURLSuite) TestDirWithNewPipeline(c *chk.C) {
 	dirURL := fsu.NewShareURL(sharePrefix).NewDirectoryURL(directoryPrefix)
 
 	newDirURL := dirURL.WithPipeline(testPipeline{})
-	_, err := newDirURL.Create(ctx, azfile.Metadata{})
+	_, err := newDirURL.Create(ctx, azfile.Metadata{}, azfile.SMBProperties{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(err.Error(), chk.Equals, testPipelineMessage)
 }
@@ -66,7 +66,7 @@ func (s *DirectoryURLSuite) TestDirCreateDeleteDefault(c *chk.C) {
 
 	directory := share.NewDirectoryURL(directoryName)
 
-	cResp, err := directory.Create(context.Background(), azfile.Metadata{})
+	cResp, err := directory.Create(context.Background(), azfile.Metadata{}, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
@@ -96,7 +96,7 @@ func (s *DirectoryURLSuite) TestDirCreateDeleteNonDefault(c *chk.C) {
 		"bar": "bArvaLue",
 	}
 
-	cResp, err := directory.Create(context.Background(), md)
+	cResp, err := directory.Create(context.Background(), md, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
@@ -110,7 +110,7 @@ func (s *DirectoryURLSuite) TestDirCreateDeleteNonDefault(c *chk.C) {
 	c.Assert(gResp.StatusCode(), chk.Equals, 200)
 
 	// Creating again will result in 409 and ResourceAlreadyExists.
-	cResp, err = directory.Create(context.Background(), md)
+	cResp, err = directory.Create(context.Background(), md, azfile.SMBProperties{})
 	c.Assert(err, chk.Not(chk.IsNil))
 	serr := err.(azfile.StorageError)
 	c.Assert(serr.Response().StatusCode, chk.Equals, 409)
@@ -129,6 +129,62 @@ func (s *DirectoryURLSuite) TestDirCreateDeleteNonDefault(c *chk.C) {
 	c.Assert(serr.Response().StatusCode, chk.Equals, 404)
 }
 
+func (s *DirectoryURLSuite) TestDirCreateSetPropertiesSMBProperties(c *chk.C) {
+	sa := getFSU()
+	share, _ := createNewShare(c, sa)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+	directory := share.NewDirectoryURL(generateDirectoryName())
+
+	attributes := azfile.FileAttributeDirectory | azfile.FileAttributeHidden | azfile.FileAttributeArchive
+	creationTime := time.Date(2019, 11, 19, 21, 25, 15, 236522700, time.UTC)
+	lastWriteTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	cResp, err := directory.Create(ctx, nil, azfile.SMBProperties{FileAttributes: &attributes, FileCreationTime: &creationTime, FileLastWriteTime: &lastWriteTime})
+	c.Assert(err, chk.IsNil)
+	c.Assert(cResp.FilePermissionKey(), chk.Not(chk.Equals), "")
+
+	gResp, err := directory.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	p := gResp.NewSMBProperties()
+	c.Assert(*p.FileAttributes, chk.Equals, attributes)
+	c.Assert(p.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(p.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(*p.FilePermissionKey, chk.Equals, cResp.FilePermissionKey())
+
+	// Only the last write time is changed; the attributes, creation time and permission are preserved.
+	lastWriteTime = lastWriteTime.Add(time.Hour)
+	sResp, err := directory.SetProperties(ctx, azfile.SMBProperties{FileLastWriteTime: &lastWriteTime})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sResp.StatusCode(), chk.Equals, 200)
+
+	gResp, err = directory.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	updated := gResp.NewSMBProperties()
+	c.Assert(*updated.FileAttributes, chk.Equals, attributes)
+	c.Assert(updated.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(updated.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(*updated.FilePermissionKey, chk.Equals, *p.FilePermissionKey)
+}
+
+func (s *DirectoryURLSuite) TestDirDeleteRecursive(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	dir, _ := createNewDirectoryFromShare(c, share)
+	createNewFileFromDirectory(c, dir, 512)
+	subDir, _ := createNewDirectoryFromDirectory(c, dir)
+	createNewFileFromDirectory(c, subDir, 0)
+	createNewDirectoryFromDirectory(c, subDir)
+
+	_, err := dir.Delete(ctx)
+	validateStorageError(c, err, azfile.ServiceCodeDirectoryNotEmpty)
+
+	err = dir.DeleteRecursive(ctx, azfile.DeleteRecursiveOptions{ForceCloseHandles: true})
+	c.Assert(err, chk.IsNil)
+	_, err = dir.GetProperties(ctx)
+	validateStorageError(c, err, azfile.ServiceCodeResourceNotFound)
+}
+
 func (s *DirectoryURLSuite) TestDirCreateDeleteNegativeMultiLevelDir(c *chk.C) {
 	parentDirName := generateDirectoryName()
 	subDirName := generateDirectoryName()
@@ -142,17 +198,17 @@ func (s *DirectoryURLSuite) TestDirCreateDeleteNegativeMultiLevelDir(c *chk.C) {
 	subDirURL := parentDirURL.NewDirectoryURL(subDirName)
 
 	// Directory create with subDirURL
-	cResp, err := subDirURL.Create(context.Background(), nil)
+	cResp, err := subDirURL.Create(context.Background(), nil, azfile.SMBProperties{})
 	c.Assert(err, chk.NotNil)
 	serr := err.(azfile.StorageError)
 	c.Assert(serr.Response().StatusCode, chk.Equals, 404)
 	c.Assert(serr.ServiceCode(), chk.Equals, azfile.ServiceCodeParentNotFound)
 
-	cResp, err = parentDirURL.Create(context.Background(), nil)
+	cResp, err = parentDirURL.Create(context.Background(), nil, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 
-	cResp, err = subDirURL.Create(context.Background(), nil)
+	cResp, err = subDirURL.Create(context.Background(), nil, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 
@@ -187,7 +243,7 @@ func (s *DirectoryURLSuite) TestDirCreateEndWithSlash(c *chk.C) {
 
 	defer delDirectory(c, directory)
 
-	cResp, err := directory.Create(context.Background(), nil)
+	cResp, err := directory.Create(context.Background(), nil, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
@@ -309,6 +365,34 @@ func (s *DirectoryURLSuite) TestDirGetPropertiesWithBaseDirectory(c *chk.C) {
 	c.Assert(gResp.IsServerEncrypted(), chk.NotNil)
 }
 
+func (s *DirectoryURLSuite) TestDirGetPropertiesSMBProperties(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+	directory, _ := getDirectoryURLFromShare(c, share)
+
+	attributes := azfile.FileAttributeHidden | azfile.FileAttributeNotContentIndexed
+	creationTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastWriteTime := creationTime.Add(time.Hour)
+	_, err := directory.Create(ctx, azfile.Metadata{"owner": "me"},
+		azfile.SMBProperties{FileAttributes: &attributes, FileCreationTime: &creationTime, FileLastWriteTime: &lastWriteTime})
+	c.Assert(err, chk.IsNil)
+	defer delDirectory(c, directory)
+
+	gResp, err := directory.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	props := gResp.NewDirectoryProperties()
+	c.Assert(*props.FileAttributes, chk.Equals, attributes|azfile.FileAttributeDirectory)
+	c.Assert(props.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(props.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(props.FileChangeTime, chk.NotNil)
+	c.Assert(*props.FilePermissionKey, chk.Not(chk.Equals), "")
+	c.Assert(props.FileID, chk.Not(chk.Equals), "")
+	c.Assert(props.ServerEncrypted, chk.Equals, true)
+	c.Assert(props.ETag, chk.Equals, gResp.ETag())
+	c.Assert(props.Metadata, chk.DeepEquals, azfile.Metadata{"owner": "me"})
+}
+
 // Merge is not supported, as the key of metadata would be canonicalized
 func (s *DirectoryURLSuite) TestDirGetSetMetadataMergeAndReplace(c *chk.C) {
 	fsu := getFSU()
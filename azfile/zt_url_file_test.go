@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/binary"
 	"fmt"
+	"hash/crc64"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-storage-file-go/azfile"
@@ -24,7 +28,7 @@ const (
 )
 
 func delFile(c *chk.C, file azfile.FileURL) {
-	resp, err := file.Delete(context.Background())
+	resp, err := file.Delete(context.Background(), azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Response().StatusCode, chk.Equals, 202)
 }
@@ -48,7 +52,7 @@ func (s *FileURLSuite) TestFileWithNewPipeline(c *chk.C) {
 	fileURL := shareURL.NewRootDirectoryURL().NewFileURL(filePrefix)
 
 	newfileURL := fileURL.WithPipeline(testPipeline{})
-	_, err := newfileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err := newfileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(err.Error(), chk.Equals, testPipelineMessage)
 }
@@ -65,7 +69,7 @@ func (s *FileURLSuite) TestFileCreateDeleteDefault(c *chk.C) {
 	// Create and delete file in root directory.
 	file := shareURL.NewRootDirectoryURL().NewFileURL(generateFileName())
 
-	cResp, err := file.Create(context.Background(), 0, azfile.FileHTTPHeaders{}, nil)
+	cResp, err := file.Create(context.Background(), 0, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -75,7 +79,7 @@ func (s *FileURLSuite) TestFileCreateDeleteDefault(c *chk.C) {
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
 	c.Assert(cResp.IsServerEncrypted(), chk.NotNil)
 
-	delResp, err := file.Delete(context.Background())
+	delResp, err := file.Delete(context.Background(), azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(delResp.Response().StatusCode, chk.Equals, 202)
 	c.Assert(delResp.RequestID(), chk.Not(chk.Equals), "")
@@ -88,7 +92,7 @@ func (s *FileURLSuite) TestFileCreateDeleteDefault(c *chk.C) {
 	// Create and delete file in named directory.
 	file = dir.NewFileURL(generateFileName())
 
-	cResp, err = file.Create(context.Background(), 0, azfile.FileHTTPHeaders{}, nil)
+	cResp, err = file.Create(context.Background(), 0, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -98,7 +102,7 @@ func (s *FileURLSuite) TestFileCreateDeleteDefault(c *chk.C) {
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
 	c.Assert(cResp.IsServerEncrypted(), chk.NotNil)
 
-	delResp, err = file.Delete(context.Background())
+	delResp, err = file.Delete(context.Background(), azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(delResp.Response().StatusCode, chk.Equals, 202)
 	c.Assert(delResp.RequestID(), chk.Not(chk.Equals), "")
@@ -112,7 +116,7 @@ func (s *FileURLSuite) TestFileCreateNonDefaultMetadataNonEmpty(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata)
+	_, err := fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata, azfile.FileAccessConditions{})
 
 	resp, err := fileURL.GetProperties(ctx)
 	c.Assert(err, chk.IsNil)
@@ -125,7 +129,7 @@ func (s *FileURLSuite) TestFileCreateNonDefaultHTTPHeaders(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := fileURL.Create(ctx, 0, basicHeaders, nil)
+	_, err := fileURL.Create(ctx, 0, basicHeaders, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	resp, err := fileURL.GetProperties(ctx)
@@ -134,13 +138,49 @@ func (s *FileURLSuite) TestFileCreateNonDefaultHTTPHeaders(c *chk.C) {
 	c.Assert(h, chk.DeepEquals, basicHeaders)
 }
 
+func (s *FileURLSuite) TestFileCreateSetPropertiesSMBProperties(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, shareURL)
+
+	attributes := azfile.FileAttributeReadOnly | azfile.FileAttributeArchive
+	creationTime := time.Date(2019, 11, 19, 21, 25, 15, 236522700, time.UTC)
+	lastWriteTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := basicHeaders
+	h.SMBProperties = azfile.SMBProperties{FileAttributes: &attributes, FileCreationTime: &creationTime, FileLastWriteTime: &lastWriteTime}
+	_, err := fileURL.Create(ctx, 0, h, nil, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	resp, err := fileURL.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	p := resp.NewSMBProperties()
+	c.Assert(*p.FileAttributes, chk.Equals, attributes)
+	c.Assert(p.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(p.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(p.FilePermissionKey, chk.NotNil)
+
+	// Only the attributes are changed; the times and the permission are preserved.
+	attributes = azfile.FileAttributeHidden
+	_, err = fileURL.SetProperties(ctx, azfile.FileHTTPHeaders{SMBProperties: azfile.SMBProperties{FileAttributes: &attributes}}, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	resp, err = fileURL.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	updated := resp.NewSMBProperties()
+	c.Assert(*updated.FileAttributes, chk.Equals, azfile.FileAttributeHidden)
+	c.Assert(updated.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(updated.FileLastWriteTime.Equal(lastWriteTime), chk.Equals, true)
+	c.Assert(*updated.FilePermissionKey, chk.Equals, *p.FilePermissionKey)
+}
+
 func (s *FileURLSuite) TestFileCreateNegativeMetadataInvalid(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := createNewShare(c, fsu)
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"})
+	_, err := fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.NotNil)
 }
 
@@ -164,7 +204,7 @@ func (s *FileURLSuite) TestFileGetSetPropertiesNonDefault(c *chk.C) {
 		CacheControl:       "no-transform",
 		ContentDisposition: "attachment",
 	}
-	setResp, err := fileURL.SetHTTPHeaders(context.Background(), properties)
+	setResp, err := fileURL.SetHTTPHeaders(context.Background(), properties, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(setResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(setResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -214,7 +254,7 @@ func (s *FileURLSuite) TestFileGetSetPropertiesSnapshot(c *chk.C) {
 		CacheControl:       "no-transform",
 		ContentDisposition: "attachment",
 	}
-	setResp, err := fileURL.SetHTTPHeaders(context.Background(), properties)
+	setResp, err := fileURL.SetHTTPHeaders(context.Background(), properties, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(setResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(setResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -228,7 +268,7 @@ func (s *FileURLSuite) TestFileGetSetPropertiesSnapshot(c *chk.C) {
 		"foo": "foovalue",
 		"bar": "barvalue",
 	}
-	setResp2, err := fileURL.SetMetadata(context.Background(), metadata)
+	setResp2, err := fileURL.SetMetadata(context.Background(), metadata, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(setResp2.Response().StatusCode, chk.Equals, 200)
 
@@ -267,7 +307,7 @@ func (s *FileURLSuite) TestGetSetMetadataNonDefault(c *chk.C) {
 		"foo": "foovalue",
 		"bar": "barvalue",
 	}
-	setResp, err := fileURL.SetMetadata(context.Background(), metadata)
+	setResp, err := fileURL.SetMetadata(context.Background(), metadata, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(setResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(setResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -293,10 +333,10 @@ func (s *FileURLSuite) TestFileSetMetadataNil(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"not": "nil"})
+	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"not": "nil"}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = fileURL.SetMetadata(ctx, nil)
+	_, err = fileURL.SetMetadata(ctx, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	resp, err := fileURL.GetProperties(ctx)
@@ -310,10 +350,10 @@ func (s *FileURLSuite) TestFileSetMetadataDefaultEmpty(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"not": "nil"})
+	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"not": "nil"}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = fileURL.SetMetadata(ctx, azfile.Metadata{})
+	_, err = fileURL.SetMetadata(ctx, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	resp, err := fileURL.GetProperties(ctx)
@@ -327,7 +367,7 @@ func (s *FileURLSuite) TestFileSetMetadataInvalidField(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"})
+	_, err := fileURL.SetMetadata(ctx, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.NotNil)
 }
 
@@ -342,10 +382,10 @@ func (s *FileURLSuite) TestStartCopyDefault(c *chk.C) {
 	destFile, _ := getFileURLFromShare(c, shareURL)
 	defer delFile(c, destFile)
 
-	_, err := srcFile.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil)
+	_, err := srcFile.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	copyResp, err := destFile.StartCopy(context.Background(), srcFile.URL(), nil)
+	copyResp, err := destFile.StartCopy(context.Background(), srcFile.URL(), nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(copyResp.Response().StatusCode, chk.Equals, 202)
 	c.Assert(copyResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -375,7 +415,7 @@ func (s *FileURLSuite) TestStartCopyDefault(c *chk.C) {
 
 	if getResp != nil && getResp.CopyStatus() == azfile.CopyStatusSuccess {
 		// Abort will fail after copy finished
-		abortResp, err := destFile.AbortCopy(context.Background(), copyResp.CopyID())
+		abortResp, err := destFile.AbortCopy(context.Background(), copyResp.CopyID(), azfile.LeaseAccessConditions{})
 		c.Assert(err, chk.NotNil)
 		c.Assert(abortResp, chk.IsNil)
 		se, ok := err.(azfile.StorageError)
@@ -385,17 +425,11 @@ func (s *FileURLSuite) TestStartCopyDefault(c *chk.C) {
 }
 
 func waitForCopy(c *chk.C, copyFileURL azfile.FileURL, fileCopyResponse *azfile.FileStartCopyResponse) {
-	status := fileCopyResponse.CopyStatus()
 	// Wait for the copy to finish. If the copy takes longer than a minute, we will fail
-	start := time.Now()
-	for status != azfile.CopyStatusSuccess {
-		GetPropertiesResult, _ := copyFileURL.GetProperties(ctx)
-		status = GetPropertiesResult.CopyStatus()
-		currentTime := time.Now()
-		if currentTime.Sub(start) >= time.Minute {
-			c.Fail()
-		}
-	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	_, err := azfile.WaitForCopy(ctx, copyFileURL, fileCopyResponse.CopyID(), time.Second)
+	c.Assert(err, chk.IsNil)
 }
 
 func (s *FileURLSuite) TestFileStartCopyDestEmpty(c *chk.C) {
@@ -405,7 +439,7 @@ func (s *FileURLSuite) TestFileStartCopyDestEmpty(c *chk.C) {
 	fileURL, _ := createNewFileFromShareWithDefaultData(c, shareURL)
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
-	fileCopyResponse, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil)
+	fileCopyResponse, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 	waitForCopy(c, copyFileURL, fileCopyResponse)
 
@@ -426,7 +460,7 @@ func (s *FileURLSuite) TestFileStartCopyMetadata(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
-	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), basicMetadata)
+	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), basicMetadata, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 	waitForCopy(c, copyFileURL, resp)
 
@@ -443,10 +477,10 @@ func (s *FileURLSuite) TestFileStartCopyMetadataNil(c *chk.C) {
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
 	// Have the destination start with metadata so we ensure the nil metadata passed later takes effect
-	_, err := copyFileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata)
+	_, err := copyFileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil)
+	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 
 	waitForCopy(c, copyFileURL, resp)
@@ -464,10 +498,10 @@ func (s *FileURLSuite) TestFileStartCopyMetadataEmpty(c *chk.C) {
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
 	// Have the destination start with metadata so we ensure the empty metadata passed later takes effect
-	_, err := copyFileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata)
+	_, err := copyFileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, basicMetadata, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), azfile.Metadata{})
+	resp, err := copyFileURL.StartCopy(ctx, fileURL.URL(), azfile.Metadata{}, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 
 	waitForCopy(c, copyFileURL, resp)
@@ -484,7 +518,7 @@ func (s *FileURLSuite) TestFileStartCopyNegativeMetadataInvalidField(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := copyFileURL.StartCopy(ctx, fileURL.URL(), azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"})
+	_, err := copyFileURL.StartCopy(ctx, fileURL.URL(), azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"}, azfile.StartCopyOptions{})
 	c.Assert(err, chk.NotNil)
 }
 
@@ -495,7 +529,7 @@ func (s *FileURLSuite) TestFileStartCopySourceNonExistant(c *chk.C) {
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil)
+	_, err := copyFileURL.StartCopy(ctx, fileURL.URL(), nil, azfile.StartCopyOptions{})
 	validateStorageError(c, err, azfile.ServiceCodeResourceNotFound)
 }
 
@@ -522,7 +556,7 @@ func (s *FileURLSuite) TestFileStartCopyUsingSASSrc(c *chk.C) {
 	defer delShare(c, copyShareURL, azfile.DeleteSnapshotsOptionNone)
 	copyFileURL, _ := getFileURLFromShare(c, copyShareURL)
 
-	resp, err := copyFileURL.StartCopy(ctx, sasURL, nil)
+	resp, err := copyFileURL.StartCopy(ctx, sasURL, nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 
 	waitForCopy(c, copyFileURL, resp)
@@ -573,7 +607,7 @@ func (s *FileURLSuite) TestFileStartCopyUsingSASDest(c *chk.C) {
 	srcFileWithSasURL := fileURL.URL()
 	srcFileWithSasURL.RawQuery = queryParams.Encode()
 
-	resp, err := anonfileURL.StartCopy(ctx, srcFileWithSasURL, nil)
+	resp, err := anonfileURL.StartCopy(ctx, srcFileWithSasURL, nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 
 	// Allow copy to happen
@@ -601,14 +635,14 @@ func (s *FileURLSuite) TestFileAbortCopyInProgress(c *chk.C) {
 	for i := range fileData {
 		fileData[i] = byte('a' + i%26)
 	}
-	_, err := fileURL.Create(ctx, int64(fileSize), azfile.FileHTTPHeaders{}, nil)
+	_, err := fileURL.Create(ctx, int64(fileSize), azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader(fileData[0:4*1024*1024]), nil)
+	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader(fileData[0:4*1024*1024]), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
-	_, err = fileURL.UploadRange(ctx, 4*1024*1024, bytes.NewReader(fileData[4*1024*1024:8*1024*1024]), nil)
+	_, err = fileURL.UploadRange(ctx, 4*1024*1024, bytes.NewReader(fileData[4*1024*1024:8*1024*1024]), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
-	_, err = fileURL.UploadRange(ctx, 8*1024*1024, bytes.NewReader(fileData[8*1024*1024:]), nil)
+	_, err = fileURL.UploadRange(ctx, 8*1024*1024, bytes.NewReader(fileData[8*1024*1024:]), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	serviceSASValues := azfile.FileSASSignatureValues{ExpiryTime: time.Now().Add(time.Hour).UTC(),
 		Permissions: azfile.FileSASPermissions{Read: true, Write: true, Create: true}.String(), ShareName: shareName, FilePath: fileName}
@@ -625,11 +659,11 @@ func (s *FileURLSuite) TestFileAbortCopyInProgress(c *chk.C) {
 
 	defer delShare(c, copyShareURL, azfile.DeleteSnapshotsOptionNone)
 
-	resp, err := copyFileURL.StartCopy(ctx, srcFileWithSasURL, nil)
+	resp, err := copyFileURL.StartCopy(ctx, srcFileWithSasURL, nil, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.CopyStatus(), chk.Equals, azfile.CopyStatusPending)
 
-	_, err = copyFileURL.AbortCopy(ctx, resp.CopyID())
+	_, err = copyFileURL.AbortCopy(ctx, resp.CopyID(), azfile.LeaseAccessConditions{})
 	if err != nil {
 		// If the error is nil, the test continues as normal.
 		// If the error is not nil, we want to check if it's because the copy is finished and send a message indicating this.
@@ -648,7 +682,7 @@ func (s *FileURLSuite) TestFileAbortCopyNoCopyStarted(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 
 	copyFileURL, _ := getFileURLFromShare(c, shareURL)
-	_, err := copyFileURL.AbortCopy(ctx, "copynotstarted")
+	_, err := copyFileURL.AbortCopy(ctx, "copynotstarted", azfile.LeaseAccessConditions{})
 	validateStorageError(c, err, azfile.ServiceCodeInvalidQueryParameterValue)
 }
 
@@ -663,13 +697,31 @@ func (s *FileURLSuite) TestResizeFile(c *chk.C) {
 	c.Assert(err, chk.IsNil)
 	c.Assert(gResp.ContentLength(), chk.Equals, int64(1234))
 
-	rResp, err := fileURL.Resize(context.Background(), 4096)
+	_, err = fileURL.SetHTTPHeaders(ctx, azfile.FileHTTPHeaders{ContentType: "text/plain"}, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	_, err = fileURL.SetMetadata(ctx, basicMetadata, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	rResp, err := fileURL.Resize(context.Background(), 4096, azfile.ResizeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rResp.Response().StatusCode, chk.Equals, 200)
 
 	gResp, err = fileURL.GetProperties(ctx)
 	c.Assert(err, chk.IsNil)
 	c.Assert(gResp.ContentLength(), chk.Equals, int64(4096))
+	c.Assert(gResp.ETag(), chk.Equals, rResp.ETag())
+	c.Assert(gResp.ContentType(), chk.Equals, "text/plain")
+	c.Assert(gResp.NewMetadata(), chk.DeepEquals, basicMetadata)
+
+	// Shrinking the file truncates its data.
+	_, err = fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(4096), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+	_, err = fileURL.Resize(ctx, 512, azfile.ResizeOptions{})
+	c.Assert(err, chk.IsNil)
+	rangeList, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.HasLen, 1)
+	c.Assert(rangeList.Items[0], chk.DeepEquals, azfile.Range{Start: 0, End: 511})
 }
 
 func (s *FileURLSuite) TestFileResizeZero(c *chk.C) {
@@ -679,7 +731,7 @@ func (s *FileURLSuite) TestFileResizeZero(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 10)
 
 	// The default file is created with size > 0, so this should actually update
-	_, err := fileURL.Resize(ctx, 0)
+	_, err := fileURL.Resize(ctx, 0, azfile.ResizeOptions{})
 	c.Assert(err, chk.IsNil)
 
 	resp, err := fileURL.GetProperties(ctx)
@@ -693,7 +745,7 @@ func (s *FileURLSuite) TestFileResizeInvalidSizeNegative(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.Resize(ctx, -4)
+	_, err := fileURL.Resize(ctx, -4, azfile.ResizeOptions{})
 	c.Assert(err, chk.NotNil)
 	sErr := (err.(azfile.StorageError))
 	c.Assert(sErr.Response().StatusCode, chk.Equals, http.StatusBadRequest)
@@ -730,22 +782,50 @@ func (f *FileURLSuite) TestServiceSASShareSAS(c *chk.C) {
 	dirURL := azfile.NewDirectoryURL(*du, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
 
 	s := "Hello"
-	_, err = fileURL.Create(ctx, int64(len(s)), azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, int64(len(s)), azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
-	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte(s)), nil)
+	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte(s)), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	_, err = fileURL.Download(ctx, 0, azfile.CountToEnd, false)
 	c.Assert(err, chk.IsNil)
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = dirURL.Create(ctx, azfile.Metadata{})
+	_, err = dirURL.Create(ctx, azfile.Metadata{}, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 
 	_, err = dirURL.ListFilesAndDirectoriesSegment(ctx, azfile.Marker{}, azfile.ListFilesAndDirectoriesOptions{})
 	c.Assert(err, chk.IsNil)
 }
 
+func (f *FileURLSuite) TestServiceSASContentDisposition(c *chk.C) {
+	fsu := getFSU()
+	shareURL, shareName := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, fileName := createNewFileFromShareWithDefaultData(c, shareURL)
+
+	credential, _ := getCredential()
+	sasQueryParams, err := azfile.FileSASSignatureValues{
+		Protocol:           azfile.SASProtocolHTTPS,
+		ExpiryTime:         time.Now().UTC().Add(time.Hour),
+		ShareName:          shareName,
+		FilePath:           fileName,
+		Permissions:        azfile.FileSASPermissions{Read: true}.String(),
+		ContentDisposition: "attachment",
+		CacheControl:       "no-cache",
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+
+	parts := azfile.NewFileURLParts(fileURL.URL())
+	parts.SAS = sasQueryParams
+	sasFileURL := azfile.NewFileURL(parts.URL(), azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
+	resp, err := sasFileURL.Download(ctx, 0, azfile.CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	defer resp.Response().Body.Close()
+	c.Assert(resp.ContentDisposition(), chk.Equals, "attachment")
+	c.Assert(resp.CacheControl(), chk.Equals, "no-cache")
+}
+
 func (f *FileURLSuite) TestServiceSASFileSAS(c *chk.C) {
 	fsu := getFSU()
 	shareURL, shareName := createNewShare(c, fsu)
@@ -782,9 +862,9 @@ func (f *FileURLSuite) TestServiceSASFileSAS(c *chk.C) {
 	fileURL := azfile.NewFileURL(*u, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
 
 	s := "Hello"
-	_, err = fileURL.Create(ctx, int64(len(s)), azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, int64(len(s)), azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
-	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte(s)), nil)
+	_, err = fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte(s)), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	dResp, err := fileURL.Download(ctx, 0, azfile.CountToEnd, false)
 	c.Assert(err, chk.IsNil)
@@ -793,10 +873,41 @@ func (f *FileURLSuite) TestServiceSASFileSAS(c *chk.C) {
 	c.Assert(dResp.ContentEncoding(), chk.Equals, contentEncodingVal)
 	c.Assert(dResp.ContentLanguage(), chk.Equals, contentLanguageVal)
 	c.Assert(dResp.ContentType(), chk.Equals, contentTypeVal)
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 }
 
+func (f *FileURLSuite) TestFileDownloadWithReadOnlySASAndAnonymousCredential(c *chk.C) {
+	fsu := getFSU()
+	shareURL, shareName := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	_, fileName := createNewFileFromShareWithDefaultData(c, shareURL)
+
+	credential, accountName := getCredential()
+	sasQueryParams, err := azfile.FileSASSignatureValues{
+		Protocol:    azfile.SASProtocolHTTPS,
+		ExpiryTime:  time.Now().UTC().Add(time.Hour),
+		ShareName:   shareName,
+		FilePath:    fileName,
+		Permissions: azfile.FileSASPermissions{Read: true}.String(),
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+
+	u, _ := url.Parse(fmt.Sprintf("https://%s.file.core.windows.net/%s/%s?%s",
+		accountName, shareName, fileName, sasQueryParams.Encode()))
+	fileURL := azfile.NewFileURL(*u, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
+
+	dResp, err := fileURL.Download(ctx, 0, azfile.CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	data, err := ioutil.ReadAll(dResp.Body(azfile.RetryReaderOptions{}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(string(data), chk.Equals, fileDefaultData)
+
+	// The SAS grants read only, so the anonymous pipeline can't modify the file.
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
+	c.Assert(err, chk.NotNil)
+}
+
 func (s *FileURLSuite) TestDownloadEmptyZeroSizeFile(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := createNewShare(c, fsu)
@@ -836,6 +947,34 @@ func (s *FileURLSuite) TestDownloadEmptyZeroSizeFile(c *chk.C) {
 	c.Assert(resp.IsServerEncrypted(), chk.NotNil)
 }
 
+func (s *FileURLSuite) TestFileCreateSparse(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	// A file created with a size has no ranges, and downloads as that many zero bytes.
+	fileURL, _ := createNewFileFromShare(c, shareURL, 1024)
+	defer delFile(c, fileURL)
+	rangeList, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.HasLen, 0)
+	resp, err := fileURL.Download(ctx, 0, azfile.CountToEnd, false)
+	c.Assert(err, chk.IsNil)
+	download, err := ioutil.ReadAll(resp.Body(azfile.RetryReaderOptions{}))
+	c.Assert(err, chk.IsNil)
+	c.Assert(download, chk.DeepEquals, make([]byte, 1024))
+
+	// A range can't be written to a file created with size 0 until it's resized.
+	emptyURL, _ := createNewFileFromShare(c, shareURL, 0)
+	defer delFile(c, emptyURL)
+	_, err = emptyURL.UploadRange(ctx, 0, bytes.NewReader([]byte{1}), nil, azfile.UploadRangeOptions{})
+	validateStorageError(c, err, azfile.ServiceCodeInvalidRange)
+	_, err = emptyURL.Resize(ctx, 1, azfile.ResizeOptions{})
+	c.Assert(err, chk.IsNil)
+	_, err = emptyURL.UploadRange(ctx, 0, bytes.NewReader([]byte{1}), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+}
+
 func (s *FileURLSuite) TestUploadDownloadDefaultNonDefaultMD5(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := createNewShare(c, fsu)
@@ -846,7 +985,7 @@ func (s *FileURLSuite) TestUploadDownloadDefaultNonDefaultMD5(c *chk.C) {
 
 	contentR, contentD := getRandomDataAndReader(2048)
 
-	pResp, err := fileURL.UploadRange(context.Background(), 0, contentR, nil)
+	pResp, err := fileURL.UploadRange(context.Background(), 0, contentR, nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(pResp.ContentMD5(), chk.NotNil)
 	c.Assert(pResp.StatusCode(), chk.Equals, http.StatusCreated)
@@ -867,12 +1006,12 @@ func (s *FileURLSuite) TestUploadDownloadDefaultNonDefaultMD5(c *chk.C) {
 	c.Assert(resp.ContentType(), chk.Equals, "application/octet-stream")
 	c.Assert(resp.Status(), chk.Not(chk.Equals), "")
 
-	download, err := ioutil.ReadAll(resp.Response().Body)
+	download, err := ioutil.ReadAll(resp.Body(azfile.RetryReaderOptions{ValidateContentMD5: true}))
 	c.Assert(err, chk.IsNil)
 	c.Assert(download, chk.DeepEquals, contentD[:1024])
 
 	// Set ContentMD5 for the entire file.
-	_, err = fileURL.SetHTTPHeaders(context.Background(), azfile.FileHTTPHeaders{ContentMD5: pResp.ContentMD5(), ContentLanguage: "test"})
+	_, err = fileURL.SetHTTPHeaders(context.Background(), azfile.FileHTTPHeaders{ContentMD5: pResp.ContentMD5(), ContentLanguage: "test"}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	// Test get with another type of range index, and validate if FileContentMD5 can be get correclty.
@@ -1048,7 +1187,7 @@ func (s *FileURLSuite) TestFileUploadRangeNilBody(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.UploadRange(ctx, 0, nil, nil)
+	_, err := fileURL.UploadRange(ctx, 0, nil, nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(strings.Contains(err.Error(), "body must not be nil"), chk.Equals, true)
 }
@@ -1059,7 +1198,7 @@ func (s *FileURLSuite) TestFileUploadRangeEmptyBody(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	_, err := fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte{}), nil)
+	_, err := fileURL.UploadRange(ctx, 0, bytes.NewReader([]byte{}), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(strings.Contains(err.Error(), "body must contain readable data whose size is > 0"), chk.Equals, true)
 }
@@ -1070,7 +1209,7 @@ func (s *FileURLSuite) TestFileUploadRangeNonExistantFile(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(12), nil)
+	_, err := fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(12), nil, azfile.UploadRangeOptions{})
 	validateStorageError(c, err, azfile.ServiceCodeResourceNotFound)
 }
 
@@ -1086,7 +1225,7 @@ func (s *FileURLSuite) TestFileUploadRangeTransactionalMD5(c *chk.C) {
 	md5 := md5.Sum(contentD)
 
 	// Upload range with correct transactional MD5
-	pResp, err := fileURL.UploadRange(context.Background(), 0, contentR, md5[:])
+	pResp, err := fileURL.UploadRange(context.Background(), 0, contentR, md5[:], azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(pResp.ContentMD5(), chk.NotNil)
 	c.Assert(pResp.StatusCode(), chk.Equals, http.StatusCreated)
@@ -1098,7 +1237,7 @@ func (s *FileURLSuite) TestFileUploadRangeTransactionalMD5(c *chk.C) {
 	c.Assert(pResp.ContentMD5(), chk.DeepEquals, md5[:])
 
 	// Upload range with empty MD5, nil MD5 is covered by other cases.
-	pResp, err = fileURL.UploadRange(context.Background(), 1024, bytes.NewReader(contentD[1024:]), []byte{})
+	pResp, err = fileURL.UploadRange(context.Background(), 1024, bytes.NewReader(contentD[1024:]), []byte{}, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(pResp.ContentMD5(), chk.NotNil)
 	c.Assert(pResp.StatusCode(), chk.Equals, http.StatusCreated)
@@ -1125,7 +1264,7 @@ func (s *FileURLSuite) TestFileUploadRangeIncorrectTransactionalMD5(c *chk.C) {
 	_, incorrectMD5 := getRandomDataAndReader(16)
 
 	// Upload range with incorrect transactional MD5
-	_, err := fileURL.UploadRange(context.Background(), 0, contentR, incorrectMD5[:])
+	_, err := fileURL.UploadRange(context.Background(), 0, contentR, incorrectMD5[:], azfile.UploadRangeOptions{})
 	validateStorageError(c, err, azfile.ServiceCodeMd5Mismatch)
 }
 
@@ -1139,11 +1278,11 @@ func (s *FileURLSuite) TestGetRangeListNonDefaultExact(c *chk.C) {
 
 	fileSize := int64(512 * 10)
 
-	fileURL.Create(context.Background(), fileSize, azfile.FileHTTPHeaders{}, nil)
+	fileURL.Create(context.Background(), fileSize, azfile.FileHTTPHeaders{}, nil, azfile.FileAccessConditions{})
 
 	defer delFile(c, fileURL)
 
-	putResp, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(1024), nil)
+	putResp, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(1024), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(putResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(putResp.LastModified().IsZero(), chk.Equals, false)
@@ -1153,7 +1292,7 @@ func (s *FileURLSuite) TestGetRangeListNonDefaultExact(c *chk.C) {
 	c.Assert(putResp.Version(), chk.Not(chk.Equals), "")
 	c.Assert(putResp.Date().IsZero(), chk.Equals, false)
 
-	rangeList, err := fileURL.GetRangeList(context.Background(), 0, 1023)
+	rangeList, err := fileURL.GetRangeList(context.Background(), 0, 1023, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rangeList.Response().StatusCode, chk.Equals, 200)
 	c.Assert(rangeList.LastModified().IsZero(), chk.Equals, false)
@@ -1175,14 +1314,14 @@ func (s *FileURLSuite) TestClearRangeDefault(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 2048)
 	defer delFile(c, fileURL)
 
-	_, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil)
+	_, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	clearResp, err := fileURL.ClearRange(context.Background(), 0, 2048)
+	clearResp, err := fileURL.ClearRange(context.Background(), 0, 2048, azfile.ClearRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(clearResp.Response().StatusCode, chk.Equals, 201)
 
-	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd)
+	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rangeList.Items, chk.HasLen, 0)
 }
@@ -1195,14 +1334,14 @@ func (s *FileURLSuite) TestClearRangeNonDefault(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 4096)
 	defer delFile(c, fileURL)
 
-	_, err := fileURL.UploadRange(context.Background(), 2048, getReaderToRandomBytes(2048), nil)
+	_, err := fileURL.UploadRange(context.Background(), 2048, getReaderToRandomBytes(2048), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	clearResp, err := fileURL.ClearRange(context.Background(), 2048, 2048)
+	clearResp, err := fileURL.ClearRange(context.Background(), 2048, 2048, azfile.ClearRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(clearResp.Response().StatusCode, chk.Equals, 201)
 
-	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd)
+	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rangeList.Items, chk.HasLen, 0)
 }
@@ -1215,14 +1354,14 @@ func (s *FileURLSuite) TestClearRangeMultipleRanges(c *chk.C) {
 	fileURL, _ := createNewFileFromShare(c, shareURL, 2048)
 	defer delFile(c, fileURL)
 
-	_, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil)
+	_, err := fileURL.UploadRange(context.Background(), 0, getReaderToRandomBytes(2048), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	clearResp, err := fileURL.ClearRange(context.Background(), 1024, 1024)
+	clearResp, err := fileURL.ClearRange(context.Background(), 1024, 1024, azfile.ClearRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(clearResp.Response().StatusCode, chk.Equals, 201)
 
-	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd)
+	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rangeList.Items, chk.HasLen, 1)
 	c.Assert(rangeList.Items[0], chk.DeepEquals, azfile.Range{Start: 0, End: 1023})
@@ -1238,14 +1377,14 @@ func (s *FileURLSuite) TestClearRangeNonDefault1Count(c *chk.C) {
 	defer delFile(c, fileURL)
 
 	d := []byte{1}
-	_, err := fileURL.UploadRange(context.Background(), 0, bytes.NewReader(d), nil)
+	_, err := fileURL.UploadRange(context.Background(), 0, bytes.NewReader(d), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	clearResp, err := fileURL.ClearRange(context.Background(), 0, 1)
+	clearResp, err := fileURL.ClearRange(context.Background(), 0, 1, azfile.ClearRangeOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(clearResp.Response().StatusCode, chk.Equals, 201)
 
-	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd)
+	rangeList, err := fileURL.GetRangeList(context.Background(), 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(rangeList.Items, chk.HasLen, 1)
 	c.Assert(rangeList.Items[0], chk.DeepEquals, azfile.Range{Start: 0, End: 0})
@@ -1268,12 +1407,41 @@ func (s *FileURLSuite) TestClearRangeNonDefault1Count(c *chk.C) {
 // 	c.Assert(strings.Contains(err.Error(), "offset must be >= 0"), chk.Equals, true)
 // }
 
+func (s *FileURLSuite) TestClearRangeMiddle(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	fileURL, _ := createNewFileFromShare(c, shareURL, 3072)
+	uploadResp, err := fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(3072), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	clearResp, err := fileURL.ClearRange(ctx, 1024, 1024, azfile.ClearRangeOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(clearResp.ETag(), chk.Not(chk.Equals), uploadResp.ETag())
+	c.Assert(clearResp.LastModified().Before(uploadResp.LastModified()), chk.Equals, false)
+
+	rangeList, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.DeepEquals, []azfile.Range{{Start: 0, End: 1023}, {Start: 2048, End: 3071}})
+}
+
+func (s *FileURLSuite) TestFileClearRangeNegativeBeyondFileSize(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	fileURL, _ := createNewFileFromShare(c, shareURL, 1024)
+	_, err := fileURL.ClearRange(ctx, 1024, 1024, azfile.ClearRangeOptions{})
+	validateStorageError(c, err, azfile.ServiceCodeInvalidRange)
+}
+
 func (s *FileURLSuite) TestFileClearRangeNegativeInvalidCount(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := getShareURL(c, fsu)
 	fileURL, _ := getFileURLFromShare(c, shareURL)
 
-	_, err := fileURL.ClearRange(ctx, 0, 0)
+	_, err := fileURL.ClearRange(ctx, 0, 0, azfile.ClearRangeOptions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(strings.Contains(err.Error(), "count cannot be CountToEnd, and must be > 0"), chk.Equals, true)
 }
@@ -1283,7 +1451,7 @@ func setupGetRangeListTest(c *chk.C) (shareURL azfile.ShareURL, fileURL azfile.F
 	shareURL, _ = createNewShare(c, fsu)
 	fileURL, _ = createNewFileFromShare(c, shareURL, int64(testFileRangeSize))
 
-	_, err := fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(testFileRangeSize), nil)
+	_, err := fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(testFileRangeSize), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
 
 	return
@@ -1301,7 +1469,7 @@ func (s *FileURLSuite) TestFileGetRangeListDefaultEmptyFile(c *chk.C) {
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
 
-	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd)
+	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Items, chk.HasLen, 0)
 }
@@ -1310,7 +1478,7 @@ func (s *FileURLSuite) TestFileGetRangeListDefault1Range(c *chk.C) {
 	shareURL, fileURL := setupGetRangeListTest(c)
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 
-	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd)
+	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	validateBasicGetRangeList(c, resp, err)
 }
 
@@ -1318,12 +1486,12 @@ func (s *FileURLSuite) TestFileGetRangeListNonContiguousRanges(c *chk.C) {
 	shareURL, fileURL := setupGetRangeListTest(c)
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 
-	_, err := fileURL.Resize(ctx, int64(testFileRangeSize*3))
+	_, err := fileURL.Resize(ctx, int64(testFileRangeSize*3), azfile.ResizeOptions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = fileURL.UploadRange(ctx, testFileRangeSize*2, getReaderToRandomBytes(testFileRangeSize), nil)
+	_, err = fileURL.UploadRange(ctx, testFileRangeSize*2, getReaderToRandomBytes(testFileRangeSize), nil, azfile.UploadRangeOptions{})
 	c.Assert(err, chk.IsNil)
-	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd)
+	resp, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Items, chk.HasLen, 2)
 	c.Assert(resp.Items[0], chk.Equals, azfile.Range{Start: 0, End: testFileRangeSize - 1})
@@ -1334,7 +1502,7 @@ func (s *FileURLSuite) TestFileGetRangeListNonContiguousRangesCountLess(c *chk.C
 	shareURL, fileURL := setupGetRangeListTest(c)
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 
-	resp, err := fileURL.GetRangeList(ctx, 0, testFileRangeSize-1)
+	resp, err := fileURL.GetRangeList(ctx, 0, testFileRangeSize-1, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Items, chk.HasLen, 1)
 	c.Assert(resp.Items[0], chk.Equals, azfile.Range{Start: 0, End: testFileRangeSize - 2})
@@ -1344,7 +1512,7 @@ func (s *FileURLSuite) TestFileGetRangeListNonContiguousRangesCountExceed(c *chk
 	shareURL, fileURL := setupGetRangeListTest(c)
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 
-	resp, err := fileURL.GetRangeList(ctx, 0, testFileRangeSize+1)
+	resp, err := fileURL.GetRangeList(ctx, 0, testFileRangeSize+1, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	validateBasicGetRangeList(c, resp, err)
 }
@@ -1355,7 +1523,7 @@ func (s *FileURLSuite) TestFileGetRangeListSnapshot(c *chk.C) {
 
 	resp, _ := shareURL.CreateSnapshot(ctx, azfile.Metadata{})
 	snapshotURL := fileURL.WithSnapshot(resp.Snapshot())
-	resp2, err := snapshotURL.GetRangeList(ctx, 0, azfile.CountToEnd)
+	resp2, err := snapshotURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
 	c.Assert(err, chk.IsNil)
 	validateBasicGetRangeList(c, resp2, err)
 }
@@ -1381,3 +1549,341 @@ func (s *FileURLSuite) TestFileGetRangeListSnapshot(c *chk.C) {
 // 	c.Assert(err, chk.NotNil)
 // 	c.Assert(strings.Contains(err.Error(), "count must be >= 0"), chk.Equals, true)
 // }
+
+func (s *FileURLSuite) TestFileAcquireReleaseLease(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
+
+	proposedID := "c820a799-76d7-4ee2-6e15-546f19325c2c"
+	acResp, err := fileURL.AcquireLease(ctx, proposedID, azfile.FileInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+	c.Assert(acResp.StatusCode(), chk.Equals, 201)
+	c.Assert(acResp.LeaseID(), chk.Equals, proposedID)
+	c.Assert(acResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
+	c.Assert(acResp.LastModified().IsZero(), chk.Equals, false)
+	c.Assert(acResp.RequestID(), chk.Not(chk.Equals), "")
+	c.Assert(acResp.Version(), chk.Not(chk.Equals), "")
+
+	relResp, err := fileURL.ReleaseLease(ctx, proposedID)
+	c.Assert(err, chk.IsNil)
+	c.Assert(relResp.StatusCode(), chk.Equals, 200)
+	c.Assert(relResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
+}
+
+func (s *FileURLSuite) TestFileAcquireLeaseServiceGeneratedID(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
+
+	acResp, err := fileURL.AcquireLease(ctx, "", azfile.FileInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+	c.Assert(acResp.LeaseID(), chk.Not(chk.Equals), "")
+
+	_, err = fileURL.AcquireLease(ctx, "", azfile.FileInfiniteLeaseDuration)
+	validateStorageError(c, err, azfile.ServiceCodeLeaseAlreadyPresent)
+
+	_, err = fileURL.ReleaseLease(ctx, acResp.LeaseID())
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *FileURLSuite) TestFileChangeLease(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
+
+	proposedID := "c820a799-76d7-4ee2-6e15-546f19325c2c"
+	changedID := "a7a510c7-8fd0-4a5e-9f8b-7a3f4530cd01"
+	_, err := fileURL.AcquireLease(ctx, proposedID, azfile.FileInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+
+	chResp, err := fileURL.ChangeLease(ctx, proposedID, changedID)
+	c.Assert(err, chk.IsNil)
+	c.Assert(chResp.StatusCode(), chk.Equals, 200)
+	c.Assert(chResp.LeaseID(), chk.Equals, changedID)
+
+	_, err = fileURL.ReleaseLease(ctx, proposedID)
+	validateStorageError(c, err, azfile.ServiceCodeLeaseIDMismatchWithLeaseOperation)
+
+	_, err = fileURL.ReleaseLease(ctx, changedID)
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *FileURLSuite) TestFileBreakLease(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 0)
+
+	_, err := fileURL.AcquireLease(ctx, "", azfile.FileInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+
+	brResp, err := fileURL.BreakLease(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(brResp.StatusCode(), chk.Equals, 202)
+
+	// Once broken, the file can be written without a lease ID.
+	_, err = fileURL.SetMetadata(ctx, basicMetadata, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *FileURLSuite) TestFileAccessConditions(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := getFileURLFromShare(c, shareURL)
+
+	createOnce := azfile.FileAccessConditions{ModifiedAccessConditions: azfile.ModifiedAccessConditions{IfNoneMatch: azfile.ETagAny}}
+	cResp, err := fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, nil, createOnce)
+	c.Assert(err, chk.IsNil)
+	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, nil, createOnce)
+	validateStorageError(c, err, azfile.ServiceCodeConditionNotMet)
+
+	ac := azfile.FileAccessConditions{ModifiedAccessConditions: azfile.ModifiedAccessConditions{IfMatch: cResp.ETag()}}
+	_, err = fileURL.SetMetadata(ctx, basicMetadata, ac)
+	c.Assert(err, chk.IsNil)
+
+	// The ETag has changed since the file was created.
+	_, err = fileURL.SetMetadata(ctx, basicMetadata, ac)
+	validateStorageError(c, err, azfile.ServiceCodeConditionNotMet)
+	_, err = fileURL.Delete(ctx, ac)
+	validateStorageError(c, err, azfile.ServiceCodeConditionNotMet)
+}
+
+func (s *FileURLSuite) TestFileUploadRangeWithLease(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, testFileRangeSize)
+
+	acResp, err := fileURL.AcquireLease(ctx, "", azfile.FileInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+	ac := azfile.FileAccessConditions{LeaseAccessConditions: azfile.LeaseAccessConditions{LeaseID: acResp.LeaseID()}}
+
+	_, err = fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(testFileRangeSize), nil, azfile.UploadRangeOptions{})
+	validateStorageError(c, err, azfile.ServiceCodeLeaseIDMissing)
+
+	_, err = fileURL.UploadRange(ctx, 0, getReaderToRandomBytes(testFileRangeSize), nil, azfile.UploadRangeOptions{FileAccessConditions: ac})
+	c.Assert(err, chk.IsNil)
+
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
+	validateStorageError(c, err, azfile.ServiceCodeLeaseIDMissing)
+
+	_, err = fileURL.Delete(ctx, ac)
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *FileURLSuite) TestFileNewReaderAt(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 2048)
+	r, data := getRandomDataAndReader(2048)
+	_, err := fileURL.UploadRange(ctx, 0, r, nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	ra, err := fileURL.NewReaderAt(ctx, azfile.ReaderAtOptions{MaxRetryRequestsPerRead: 3})
+	c.Assert(err, chk.IsNil)
+
+	// A read within the file fills the whole buffer.
+	p := make([]byte, 512)
+	n, err := ra.ReadAt(p, 1024)
+	c.Assert(err, chk.IsNil)
+	c.Assert(n, chk.Equals, 512)
+	c.Assert(p, chk.DeepEquals, data[1024:1536])
+
+	// A read that crosses the end of the file returns a short count and io.EOF.
+	n, err = ra.ReadAt(p, 1800)
+	c.Assert(err, chk.Equals, io.EOF)
+	c.Assert(n, chk.Equals, 248)
+	c.Assert(p[:n], chk.DeepEquals, data[1800:])
+
+	// A read past the end of the file returns io.EOF.
+	n, err = ra.ReadAt(p, 2048)
+	c.Assert(err, chk.Equals, io.EOF)
+	c.Assert(n, chk.Equals, 0)
+}
+
+func (s *FileURLSuite) TestFileNewReaderAtConcurrent(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+	fileURL, _ := createNewFileFromShare(c, shareURL, 4096)
+	r, data := getRandomDataAndReader(4096)
+	_, err := fileURL.UploadRange(ctx, 0, r, nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	ra, err := fileURL.NewReaderAt(ctx, azfile.ReaderAtOptions{})
+	c.Assert(err, chk.IsNil)
+
+	const chunk = 512
+	results := make([][]byte, 4096/chunk)
+	errs := make([]error, len(results))
+	wg := sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = make([]byte, chunk)
+			_, errs[i] = ra.ReadAt(results[i], int64(i*chunk))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		c.Assert(errs[i], chk.IsNil)
+		c.Assert(results[i], chk.DeepEquals, data[i*chunk:(i+1)*chunk])
+	}
+}
+
+func (s *FileURLSuite) TestFileListAndForceCloseHandlesWithoutHandles(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+	file, _ := createNewFileFromShare(c, share, 0)
+
+	handles, err := file.ListHandles(ctx, azfile.Marker{}, azfile.ListHandlesOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(handles.HandleList, chk.HasLen, 0)
+	c.Assert(handles.NextMarker.NotDone(), chk.Equals, false)
+
+	closed, err := file.ForceCloseAllHandles(ctx, azfile.Marker{}, azfile.ForceCloseHandlesOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(closed.NumberOfHandlesClosed(), chk.Equals, int32(0))
+	c.Assert(closed.NextMarker().NotDone(), chk.Equals, false)
+}
+
+func (s *FileURLSuite) TestGetRangeListPrevShareSnapshot(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionInclude)
+
+	fileURL, _ := createNewFileFromShare(c, shareURL, 4096)
+	_, err := fileURL.UploadRange(ctx, 0, bytes.NewReader(make([]byte, 512)), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	snapshot, err := shareURL.CreateSnapshot(ctx, nil)
+	c.Assert(err, chk.IsNil)
+
+	_, err = fileURL.UploadRange(ctx, 2048, bytes.NewReader(make([]byte, 1024)), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	// The whole file has two ranges of data, but only the second was written since the snapshot.
+	rangeList, err := fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.DeepEquals, []azfile.Range{{Start: 0, End: 511}, {Start: 2048, End: 3071}})
+
+	rangeList, err = fileURL.GetRangeList(ctx, 0, azfile.CountToEnd, azfile.GetRangeListOptions{PrevShareSnapshot: snapshot.Snapshot()})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.DeepEquals, []azfile.Range{{Start: 2048, End: 3071}})
+
+	// Scoping the request to the first half of the file leaves out the second range.
+	rangeList, err = fileURL.GetRangeList(ctx, 0, 2048, azfile.GetRangeListOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(rangeList.Items, chk.DeepEquals, []azfile.Range{{Start: 0, End: 511}})
+}
+
+func (s *FileURLSuite) TestGetRangeListDiff(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionInclude)
+
+	fileURL, _ := createNewFileFromShare(c, shareURL, 4096)
+	_, err := fileURL.UploadRange(ctx, 0, bytes.NewReader(make([]byte, 2048)), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	snapshot, err := shareURL.CreateSnapshot(ctx, nil)
+	c.Assert(err, chk.IsNil)
+
+	_, err = fileURL.ClearRange(ctx, 512, 512, azfile.ClearRangeOptions{})
+	c.Assert(err, chk.IsNil)
+	_, err = fileURL.UploadRange(ctx, 3072, bytes.NewReader(make([]byte, 1024)), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	// The cleared range is reported apart from the written one.
+	diff, err := fileURL.GetRangeListDiff(ctx, snapshot.Snapshot(), 0, azfile.CountToEnd)
+	c.Assert(err, chk.IsNil)
+	c.Assert(diff.Ranges, chk.DeepEquals, []azfile.Range{{Start: 3072, End: 4095}})
+	c.Assert(diff.ClearRanges, chk.DeepEquals, []azfile.Range{{Start: 512, End: 1023}})
+}
+
+func (s *FileURLSuite) TestUploadRangeFromURL(c *chk.C) {
+	fsu := getFSU()
+	shareURL, shareName := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	srcFile, srcName := createNewFileFromShare(c, shareURL, 2048)
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i % 256)
+	}
+	_, err := srcFile.UploadRange(ctx, 0, bytes.NewReader(data), nil, azfile.UploadRangeOptions{})
+	c.Assert(err, chk.IsNil)
+
+	credential, _ := getCredential()
+	queryParams, err := azfile.FileSASSignatureValues{ExpiryTime: time.Now().Add(time.Hour).UTC(),
+		Permissions: azfile.FileSASPermissions{Read: true}.String(), ShareName: shareName, FilePath: srcName}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+	sasURL := srcFile.URL()
+	sasURL.RawQuery = queryParams.Encode()
+
+	destFile, _ := createNewFileFromShare(c, shareURL, 2048)
+	resp, err := destFile.UploadRangeFromURL(ctx, sasURL, 512, 1024, 512, azfile.UploadRangeFromURLOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.StatusCode(), chk.Equals, http.StatusCreated)
+	c.Assert(resp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
+	c.Assert(resp.LastModified().IsZero(), chk.Equals, false)
+
+	download, err := destFile.Download(ctx, 1024, 512, false)
+	c.Assert(err, chk.IsNil)
+	copied, err := ioutil.ReadAll(download.Response().Body)
+	c.Assert(err, chk.IsNil)
+	download.Response().Body.Close()
+	c.Assert(copied, chk.DeepEquals, data[512:])
+
+	// The service validates the source range against its CRC64.
+	crc := make([]byte, 8)
+	binary.LittleEndian.PutUint64(crc, crc64.Checksum(data[:512], crc64.MakeTable(0x9A6C9329AC4BC9B5)))
+	resp, err = destFile.UploadRangeFromURL(ctx, sasURL, 0, 0, 512, azfile.UploadRangeFromURLOptions{SourceContentCRC64: crc})
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.XMsContentCrc64(), chk.DeepEquals, crc)
+
+	crc[0]++
+	_, err = destFile.UploadRangeFromURL(ctx, sasURL, 0, 0, 512, azfile.UploadRangeFromURLOptions{SourceContentCRC64: crc})
+	validateStorageError(c, err, azfile.ServiceCodeCrc64Mismatch)
+}
+
+func (s *FileURLSuite) TestFileStartCopyFromSourceSMBProperties(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := createNewShare(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	srcFile, _ := getFileURLFromShare(c, shareURL)
+	attributes := azfile.FileAttributeHidden | azfile.FileAttributeArchive
+	creationTime := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := srcFile.Create(ctx, 0, azfile.FileHTTPHeaders{SMBProperties: azfile.SMBProperties{
+		FileAttributes: &attributes, FileCreationTime: &creationTime, FileLastWriteTime: &creationTime}}, nil, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	destFile, _ := getFileURLFromShare(c, shareURL)
+	resp, err := destFile.StartCopy(ctx, srcFile.URL(), nil, azfile.StartCopyOptions{
+		PermissionCopyMode: azfile.PermissionCopyModeSource,
+		CopyFileAttributes: true,
+		CopyCreationTime:   true,
+		CopyLastWriteTime:  true,
+	})
+	c.Assert(err, chk.IsNil)
+	waitForCopy(c, destFile, resp)
+
+	props, err := destFile.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.CopyID(), chk.Equals, resp.CopyID())
+	c.Assert(props.CopySource(), chk.Equals, srcFile.String())
+	smb := props.NewSMBProperties()
+	c.Assert(*smb.FileAttributes, chk.Equals, attributes)
+	c.Assert(smb.FileCreationTime.Equal(creationTime), chk.Equals, true)
+	c.Assert(smb.FileLastWriteTime.Equal(creationTime), chk.Equals, true)
+}
@@ -46,7 +46,7 @@ func (s *StorageAccountSuite) TestAccountWithPipeline(c *chk.C) {
 	fsu = fsu.WithPipeline(testPipeline{}) // testPipeline returns an identifying message as an error
 	shareURL := fsu.NewShareURL("name")
 
-	_, err := shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err := shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 
 	c.Assert(err.Error(), chk.Equals, testPipelineMessage)
 }
@@ -199,7 +199,7 @@ func (s *StorageAccountSuite) TestAccountListSharesNonDefault(c *chk.C) {
 		"bar": "barvalue",
 	}
 
-	_, err = share.SetMetadata(ctx, shareMetadata)
+	_, err = share.SetMetadata(ctx, shareMetadata, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	_, err = share.CreateSnapshot(ctx, nil)
@@ -332,17 +332,17 @@ func (s *StorageAccountSuite) TestAccountSAS(c *chk.C) {
 	testShareURL := sParts.URL()
 	shareURLWithSAS := azfile.NewShareURL(testShareURL, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
 	// Create
-	_, err = shareURLWithSAS.Create(ctx, azfile.Metadata{}, 0)
+	_, err = shareURLWithSAS.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	c.Assert(err, chk.IsNil)
 	// Write
 	metadata := azfile.Metadata{"foo": "bar"}
-	_, err = shareURLWithSAS.SetMetadata(ctx, metadata)
+	_, err = shareURLWithSAS.SetMetadata(ctx, metadata, azfile.ShareAccessConditions{})
 	// Read
 	gResp, err := shareURLWithSAS.GetProperties(ctx)
 	c.Assert(err, chk.IsNil)
 	c.Assert(gResp.NewMetadata(), chk.DeepEquals, metadata)
 	// Delete
-	defer shareURLWithSAS.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	defer shareURLWithSAS.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 
 	// Test dir URL
 	dParts := azfile.NewFileURLParts(dirURL.URL())
@@ -350,7 +350,7 @@ func (s *StorageAccountSuite) TestAccountSAS(c *chk.C) {
 	testDirURL := dParts.URL()
 	dirURLWithSAS := azfile.NewDirectoryURL(testDirURL, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
 	// Create
-	_, err = dirURLWithSAS.Create(ctx, azfile.Metadata{})
+	_, err = dirURLWithSAS.Create(ctx, azfile.Metadata{}, azfile.SMBProperties{})
 	c.Assert(err, chk.IsNil)
 	// Write
 	_, err = dirURLWithSAS.SetMetadata(ctx, metadata)
@@ -370,14 +370,47 @@ func (s *StorageAccountSuite) TestAccountSAS(c *chk.C) {
 	testFileURL := fParts.URL()
 	fileURLWithSAS := azfile.NewFileURL(testFileURL, azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
 	// Create
-	_, err = fileURLWithSAS.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err = fileURLWithSAS.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	// Write
-	_, err = fileURLWithSAS.SetMetadata(ctx, metadata)
+	_, err = fileURLWithSAS.SetMetadata(ctx, metadata, azfile.FileAccessConditions{})
 	// Read
 	gfResp, err := fileURLWithSAS.GetProperties(ctx)
 	c.Assert(err, chk.IsNil)
 	c.Assert(gfResp.NewMetadata(), chk.DeepEquals, metadata)
 	// Delete
-	defer fileURLWithSAS.Delete(ctx)
+	defer fileURLWithSAS.Delete(ctx, azfile.FileAccessConditions{})
+}
+
+func (s *StorageAccountSuite) TestAccountSASFileServiceProvisioning(c *chk.C) {
+	fsu := getFSU()
+	shareURL, _ := getShareURL(c, fsu)
+	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
+
+	credential, _ := getCredential()
+	sasQueryParams, err := azfile.AccountSASSignatureValues{
+		Protocol:      azfile.SASProtocolHTTPS,
+		ExpiryTime:    time.Now().UTC().Add(time.Hour),
+		Permissions:   azfile.AccountSASPermissions{List: true, Create: true, Write: true}.String(),
+		Services:      azfile.AccountSASServices{File: true}.String(),
+		ResourceTypes: azfile.AccountSASResourceTypes{Container: true, Object: true}.String(),
+	}.NewSASQueryParameters(credential)
+	c.Assert(err, chk.IsNil)
+	c.Assert(sasQueryParams.Services(), chk.Equals, "f")
+
+	sParts := azfile.NewFileURLParts(shareURL.URL())
+	sParts.SAS = sasQueryParams
+	shareURLWithSAS := azfile.NewShareURL(sParts.URL(), azfile.NewPipeline(azfile.NewAnonymousCredential(), azfile.PipelineOptions{}))
+	_, err = shareURLWithSAS.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+
+	fileURLWithSAS := shareURLWithSAS.NewRootDirectoryURL().NewFileURL(generateFileName())
+	_, err = fileURLWithSAS.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	// The SAS grants neither read nor delete.
+	_, err = fileURLWithSAS.GetProperties(ctx)
+	c.Assert(err, chk.NotNil)
+	_, err = shareURLWithSAS.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
+	c.Assert(err, chk.NotNil)
 }
@@ -18,7 +18,7 @@ type ShareURLSuite struct{}
 var _ = chk.Suite(&ShareURLSuite{})
 
 func delShare(c *chk.C, share azfile.ShareURL, option azfile.DeleteSnapshotsOptionType) {
-	resp, err := share.Delete(context.Background(), option)
+	resp, err := share.Delete(context.Background(), option, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(resp.Response().StatusCode, chk.Equals, 202)
 }
@@ -48,13 +48,47 @@ func (s *ShareURLSuite) TestShareWithNewPipeline(c *chk.C) {
 	shareURL, _ := getShareURL(c, fsu)
 	shareURL = shareURL.WithPipeline(pipeline)
 
-	_, err := shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err := shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 
 	c.Assert(err, chk.NotNil)
 	c.Assert(err.Error(), chk.Equals, testPipelineMessage)
 }
 
 // Note: test share create with default parameter is covered with preparing phase for FileURL and etc.
+func (s *ShareURLSuite) TestShareCreateIfNotExistsDeleteIfExists(c *chk.C) {
+	fsu := getFSU()
+	share, _ := getShareURL(c, fsu)
+
+	created, err := share.CreateIfNotExists(ctx, nil, azfile.ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, true)
+	created, err = share.CreateIfNotExists(ctx, nil, azfile.ShareCreateOptions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, false)
+
+	dir := share.NewDirectoryURL(generateDirectoryName())
+	created, err = dir.CreateIfNotExists(ctx, nil, azfile.SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, true)
+	created, err = dir.CreateIfNotExists(ctx, nil, azfile.SMBProperties{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(created, chk.Equals, false)
+
+	deleted, err := dir.DeleteIfExists(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, true)
+	deleted, err = dir.DeleteIfExists(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, false)
+
+	deleted, err = share.DeleteIfExists(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, true)
+	deleted, err = share.DeleteIfExists(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	c.Assert(deleted, chk.Equals, false)
+}
+
 func (s *ShareURLSuite) TestShareCreateDeleteNonDefault(c *chk.C) {
 	shareName := generateShareName()
 	sa := getFSU()
@@ -67,7 +101,7 @@ func (s *ShareURLSuite) TestShareCreateDeleteNonDefault(c *chk.C) {
 
 	quota := int32(1000)
 
-	cResp, err := share.Create(context.Background(), md, quota)
+	cResp, err := share.Create(context.Background(), md, azfile.ShareCreateOptions{QuotaInGB: quota})
 	c.Assert(err, chk.IsNil)
 	c.Assert(cResp.Response().StatusCode, chk.Equals, 201)
 	c.Assert(cResp.Date().IsZero(), chk.Equals, false)
@@ -83,7 +117,7 @@ func (s *ShareURLSuite) TestShareCreateDeleteNonDefault(c *chk.C) {
 	c.Assert(shares.ShareItems[0].Metadata, chk.DeepEquals, md)
 	c.Assert(shares.ShareItems[0].Properties.Quota, chk.Equals, quota)
 
-	dResp, err := share.Delete(context.Background(), azfile.DeleteSnapshotsOptionNone)
+	dResp, err := share.Delete(context.Background(), azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(dResp.Response().StatusCode, chk.Equals, 202)
 	c.Assert(dResp.Date().IsZero(), chk.Equals, false)
@@ -99,7 +133,7 @@ func (s *ShareURLSuite) TestShareCreateNilMetadata(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := getShareURL(c, fsu)
 
-	_, err := shareURL.Create(ctx, nil, 0)
+	_, err := shareURL.Create(ctx, nil, azfile.ShareCreateOptions{})
 	defer delShare(c, shareURL, azfile.DeleteSnapshotsOptionNone)
 	c.Assert(err, chk.IsNil)
 
@@ -112,7 +146,7 @@ func (s *ShareURLSuite) TestShareCreateNegativeInvalidName(c *chk.C) {
 	fsu := getFSU()
 	shareURL := fsu.NewShareURL("foo bar")
 
-	_, err := shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err := shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 
 	validateStorageError(c, err, azfile.ServiceCodeInvalidResourceName)
 }
@@ -121,7 +155,7 @@ func (s *ShareURLSuite) TestShareCreateNegativeInvalidMetadata(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := getShareURL(c, fsu)
 
-	_, err := shareURL.Create(ctx, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"}, 0)
+	_, err := shareURL.Create(ctx, azfile.Metadata{"!@#$%^&*()": "!@#$%^&*()"}, azfile.ShareCreateOptions{})
 
 	c.Assert(err, chk.NotNil)
 }
@@ -130,7 +164,7 @@ func (s *ShareURLSuite) TestShareDeleteNegativeNonExistant(c *chk.C) {
 	fsu := getFSU()
 	shareURL, _ := getShareURL(c, fsu)
 
-	_, err := shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err := shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	validateStorageError(c, err, azfile.ServiceCodeShareNotFound)
 }
 
@@ -141,7 +175,7 @@ func (s *ShareURLSuite) TestShareGetSetPropertiesNonDefault(c *chk.C) {
 
 	newQuota := int32(1234)
 
-	sResp, err := share.SetQuota(ctx, newQuota)
+	sResp, err := share.SetQuota(ctx, newQuota, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(sResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -166,7 +200,7 @@ func (s *ShareURLSuite) TestShareGetSetPropertiesDefault(c *chk.C) {
 	share, _ := createNewShare(c, fsu)
 	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
 
-	sResp, err := share.SetQuota(ctx, 0)
+	sResp, err := share.SetQuota(ctx, 0, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(sResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
@@ -191,7 +225,7 @@ func (s *ShareURLSuite) TestShareSetQuotaNegative(c *chk.C) {
 	share, _ := createNewShare(c, fsu)
 	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
 
-	_, err := share.SetQuota(ctx, -1)
+	_, err := share.SetQuota(ctx, -1, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.NotNil)
 	c.Assert(strings.Contains(err.Error(), validationErrorSubstring), chk.Equals, true)
 }
@@ -485,7 +519,7 @@ func (s *ShareURLSuite) TestShareGetSetMetadataDefault(c *chk.C) {
 	share, _ := createNewShare(c, fsu)
 	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
 
-	sResp, err := share.SetMetadata(context.Background(), azfile.Metadata{})
+	sResp, err := share.SetMetadata(context.Background(), azfile.Metadata{}, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(sResp.Date().IsZero(), chk.Equals, false)
@@ -514,7 +548,7 @@ func (s *ShareURLSuite) TestShareGetSetMetadataNonDefault(c *chk.C) {
 		"foo": "FooValuE",
 		"bar": "bArvaLue", // Note: As testing result, currently only support case-insensitive keys(key will be saved in lower-case).
 	}
-	sResp, err := share.SetMetadata(context.Background(), md)
+	sResp, err := share.SetMetadata(context.Background(), md, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
 	c.Assert(sResp.Date().IsZero(), chk.Equals, false)
@@ -543,7 +577,7 @@ func (s *ShareURLSuite) TestShareSetMetadataNegative(c *chk.C) {
 	md := azfile.Metadata{
 		"!@#$%^&*()": "!@#$%^&*()",
 	}
-	_, err := share.SetMetadata(context.Background(), md)
+	_, err := share.SetMetadata(context.Background(), md, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.NotNil)
 }
 
@@ -555,7 +589,7 @@ func (s *ShareURLSuite) TestShareGetStats(c *chk.C) {
 	newQuota := int32(300)
 
 	// In order to test and get LastModified property.
-	sResp, err := share.SetQuota(context.Background(), newQuota)
+	sResp, err := share.SetQuota(context.Background(), newQuota, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
 
@@ -568,6 +602,55 @@ func (s *ShareURLSuite) TestShareGetStats(c *chk.C) {
 	c.Assert(gResp.RequestID(), chk.Not(chk.Equals), "")
 	c.Assert(gResp.Version(), chk.Not(chk.Equals), "")
 	c.Assert(gResp.ShareUsage, chk.Equals, int32(0))
+	c.Assert(gResp.ShareUsageBytes, chk.Equals, int64(0))
+}
+
+func (s *ShareURLSuite) TestShareAccessTier(c *chk.C) {
+	fsu := getFSU()
+	share, _ := getShareURL(c, fsu)
+	_, err := share.Create(ctx, nil, azfile.ShareCreateOptions{AccessTier: azfile.AccessTierHot})
+	c.Assert(err, chk.IsNil)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	props, err := share.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(azfile.AccessTierType(props.AccessTier()), chk.Equals, azfile.AccessTierHot)
+
+	_, err = share.SetProperties(ctx, azfile.ShareSetPropertiesOptions{AccessTier: azfile.AccessTierCool})
+	c.Assert(err, chk.IsNil)
+
+	props, err = share.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(azfile.AccessTierType(props.AccessTier()), chk.Equals, azfile.AccessTierCool)
+	c.Assert(props.AccessTierChangeTime().IsZero(), chk.Equals, false)
+
+	// A standard account has no Premium tier; the service's error is returned as is.
+	_, err = share.SetProperties(ctx, azfile.ShareSetPropertiesOptions{AccessTier: azfile.AccessTierPremium})
+	c.Assert(err, chk.NotNil)
+	_, ok := err.(azfile.StorageError)
+	c.Assert(ok, chk.Equals, true)
+}
+
+func (s *ShareURLSuite) TestShareSetProperties(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	sResp, err := share.SetProperties(ctx, azfile.ShareSetPropertiesOptions{QuotaInGB: 200})
+	c.Assert(err, chk.IsNil)
+	c.Assert(sResp.Response().StatusCode, chk.Equals, 200)
+	c.Assert(sResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
+	c.Assert(sResp.LastModified().IsZero(), chk.Equals, false)
+
+	// The premium-only properties are absent on a standard share.
+	props, err := share.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.Quota(), chk.Equals, int32(200))
+	c.Assert(props.ProvisionedIops(), chk.Equals, int32(-1))
+	c.Assert(props.ProvisionedBandwidthMibps(), chk.Equals, int32(-1))
+
+	_, err = share.SetProperties(ctx, azfile.ShareSetPropertiesOptions{ProvisionedIops: 3100})
+	c.Assert(err, chk.NotNil)
 }
 
 func (s *ShareURLSuite) TestShareGetStatsNegative(c *chk.C) {
@@ -640,14 +723,14 @@ func (s *ShareURLSuite) TestShareCreateSnapshotDefault(c *chk.C) {
 	shareName := generateShareName()
 	shareURL := serviceURL.NewShareURL(shareName)
 
-	_, err := shareURL.Create(ctx, azfile.Metadata{}, 0)
+	_, err := shareURL.Create(ctx, azfile.Metadata{}, azfile.ShareCreateOptions{})
 	c.Assert(err, chk.IsNil)
 
-	defer shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionInclude)
+	defer shareURL.Delete(ctx, azfile.DeleteSnapshotsOptionInclude, azfile.ShareAccessConditions{})
 
 	// Let's create a file in the base share.
 	fileURL := shareURL.NewRootDirectoryURL().NewFileURL("myfile")
-	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{})
+	_, err = fileURL.Create(ctx, 0, azfile.FileHTTPHeaders{}, azfile.Metadata{}, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	// Create share snapshot, the snapshot contains the create file.
@@ -655,7 +738,7 @@ func (s *ShareURLSuite) TestShareCreateSnapshotDefault(c *chk.C) {
 	c.Assert(err, chk.IsNil)
 
 	// Delete file in base share.
-	_, err = fileURL.Delete(ctx)
+	_, err = fileURL.Delete(ctx, azfile.FileAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	// Restore file from share snapshot.
@@ -678,10 +761,10 @@ func (s *ShareURLSuite) TestShareCreateSnapshotDefault(c *chk.C) {
 	sourceURL := fileParts.URL()
 
 	// Do restore.
-	_, err = fileURL.StartCopy(ctx, sourceURL, azfile.Metadata{})
+	_, err = fileURL.StartCopy(ctx, sourceURL, azfile.Metadata{}, azfile.StartCopyOptions{})
 	c.Assert(err, chk.IsNil)
 
-	_, err = shareURL.WithSnapshot(snapshotShare.Snapshot()).Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = shareURL.WithSnapshot(snapshotShare.Snapshot()).Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 }
 
@@ -732,7 +815,7 @@ func (s *ShareURLSuite) TestShareDeleteSnapshot(c *chk.C) {
 	c.Assert(err, chk.IsNil)
 	snapshotURL := share.WithSnapshot(resp.Snapshot())
 
-	_, err = snapshotURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = snapshotURL.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	validateShareDeleted(c, snapshotURL)
@@ -745,13 +828,23 @@ func (s *ShareURLSuite) TestShareDeleteSnapshotsInclude(c *chk.C) {
 
 	_, err := share.CreateSnapshot(ctx, nil)
 	c.Assert(err, chk.IsNil)
-	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionInclude)
+	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionInclude, azfile.ShareAccessConditions{})
 	c.Assert(err, chk.IsNil)
 
 	lResp, _ := fsu.ListSharesSegment(ctx, azfile.Marker{}, azfile.ListSharesOptions{Detail: azfile.ListSharesDetail{Snapshots: true}, Prefix: shareName})
 	c.Assert(lResp.ShareItems, chk.HasLen, 0)
 }
 
+func (s *ShareURLSuite) TestShareDeleteSnapshotsIncludeWithoutSnapshots(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+
+	_, err := share.Delete(ctx, azfile.DeleteSnapshotsOptionInclude, azfile.ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	validateShareDeleted(c, share)
+}
+
 func (s *ShareURLSuite) TestShareDeleteSnapshotsNoneWithSnapshots(c *chk.C) {
 	fsu := getFSU()
 	share, _ := createNewShare(c, fsu)
@@ -759,6 +852,157 @@ func (s *ShareURLSuite) TestShareDeleteSnapshotsNoneWithSnapshots(c *chk.C) {
 
 	_, err := share.CreateSnapshot(ctx, nil)
 	c.Assert(err, chk.IsNil)
-	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionNone)
+	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
 	validateStorageError(c, err, azfile.ServiceCodeShareHasSnapshots)
 }
+
+func (s *ShareURLSuite) TestShareAcquireRenewReleaseLease(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	proposedID := "c820a799-76d7-4ee2-6e15-546f19325c2c"
+	acResp, err := share.AcquireLease(ctx, proposedID, 15)
+	c.Assert(err, chk.IsNil)
+	c.Assert(acResp.StatusCode(), chk.Equals, 201)
+	c.Assert(acResp.LeaseID(), chk.Equals, proposedID)
+	c.Assert(acResp.ETag(), chk.Not(chk.Equals), azfile.ETagNone)
+	c.Assert(acResp.LastModified().IsZero(), chk.Equals, false)
+
+	rnResp, err := share.RenewLease(ctx, proposedID)
+	c.Assert(err, chk.IsNil)
+	c.Assert(rnResp.StatusCode(), chk.Equals, 200)
+	c.Assert(rnResp.LeaseID(), chk.Equals, proposedID)
+
+	relResp, err := share.ReleaseLease(ctx, proposedID)
+	c.Assert(err, chk.IsNil)
+	c.Assert(relResp.StatusCode(), chk.Equals, 200)
+}
+
+func (s *ShareURLSuite) TestShareChangeLease(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	proposedID := "c820a799-76d7-4ee2-6e15-546f19325c2c"
+	changedID := "a7a510c7-8fd0-4a5e-9f8b-7a3f4530cd01"
+	_, err := share.AcquireLease(ctx, proposedID, azfile.ShareInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+
+	chResp, err := share.ChangeLease(ctx, proposedID, changedID)
+	c.Assert(err, chk.IsNil)
+	c.Assert(chResp.LeaseID(), chk.Equals, changedID)
+
+	_, err = share.ReleaseLease(ctx, changedID)
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *ShareURLSuite) TestShareBreakLeaseWithBreakPeriod(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	_, err := share.AcquireLease(ctx, "", 60)
+	c.Assert(err, chk.IsNil)
+
+	brResp, err := share.BreakLease(ctx, 5)
+	c.Assert(err, chk.IsNil)
+	c.Assert(brResp.StatusCode(), chk.Equals, 202)
+	c.Assert(brResp.LeaseTime() > 0 && brResp.LeaseTime() <= 5, chk.Equals, true)
+
+	// A zero break period breaks the lease immediately.
+	brResp, err = share.BreakLease(ctx, 0)
+	c.Assert(err, chk.IsNil)
+	c.Assert(brResp.LeaseTime(), chk.Equals, int32(0))
+}
+
+func (s *ShareURLSuite) TestShareDeleteAndSetQuotaWithLease(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+
+	acResp, err := share.AcquireLease(ctx, "", azfile.ShareInfiniteLeaseDuration)
+	c.Assert(err, chk.IsNil)
+	ac := azfile.ShareAccessConditions{LeaseAccessConditions: azfile.LeaseAccessConditions{LeaseID: acResp.LeaseID()}}
+
+	_, err = share.SetQuota(ctx, 1, azfile.ShareAccessConditions{})
+	validateStorageError(c, err, azfile.ServiceCodeLeaseIDMissing)
+	_, err = share.SetQuota(ctx, 1, ac)
+	c.Assert(err, chk.IsNil)
+
+	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
+	validateStorageError(c, err, azfile.ServiceCodeLeaseIDMissing)
+	_, err = share.Delete(ctx, azfile.DeleteSnapshotsOptionNone, ac)
+	c.Assert(err, chk.IsNil)
+}
+
+func (s *ShareURLSuite) TestShareCreateGetPermission(c *chk.C) {
+	fsu := getFSU()
+	share, _ := createNewShare(c, fsu)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	// An SDDL too long for the x-ms-file-permission header still goes through CreatePermission.
+	sddl := "O:S-1-5-21-397955417-626881126-188441444-3053964G:S-1-5-21-397955417-626881126-188441444-3053964D:(A;;FA;;;SY)"
+	for rid := 1000; len(sddl) <= azfile.FilePermissionMaxInlineSize; rid++ {
+		sddl += fmt.Sprintf("(A;;FA;;;S-1-5-21-397955417-626881126-188441444-%d)", rid)
+	}
+
+	created, err := share.CreatePermission(ctx, sddl)
+	c.Assert(err, chk.IsNil)
+	c.Assert(created.StatusCode(), chk.Equals, 201)
+	key := created.FilePermissionKey()
+	c.Assert(key, chk.Not(chk.Equals), "")
+
+	got, err := share.GetPermission(ctx, key)
+	c.Assert(err, chk.IsNil)
+	c.Assert(got.Permission, chk.Not(chk.Equals), "")
+
+	// The key can be used directly when creating files.
+	file, _ := getFileURLFromShare(c, share)
+	_, err = file.Create(ctx, 0, azfile.FileHTTPHeaders{SMBProperties: azfile.SMBProperties{FilePermissionKey: &key}}, nil, azfile.FileAccessConditions{})
+	c.Assert(err, chk.IsNil)
+	props, err := file.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+	c.Assert(props.FilePermissionKey(), chk.Not(chk.Equals), "")
+}
+
+func (s *ShareURLSuite) TestShareCreateGetPermissionNegative(c *chk.C) {
+	share := getFSU().NewShareURL(generateShareName())
+
+	_, err := share.CreatePermission(ctx, "")
+	c.Assert(err, chk.NotNil)
+	_, err = share.GetPermission(ctx, "")
+	c.Assert(err, chk.NotNil)
+}
+
+// This case requires share soft delete to be enabled on the account.
+func (s *ShareURLSuite) TestShareRestore(c *chk.C) {
+	fsu := getFSU()
+	share, shareName := createNewShare(c, fsu)
+	_, err := share.Delete(ctx, azfile.DeleteSnapshotsOptionNone, azfile.ShareAccessConditions{})
+	c.Assert(err, chk.IsNil)
+
+	var deleted *azfile.ShareItem
+	it := fsu.ListSharesAll(ctx, azfile.ListSharesOptions{Prefix: shareName, Detail: azfile.ListSharesDetail{Deleted: true}})
+	for it.Next() {
+		if item := it.Share(); item.Name == shareName && item.Deleted != nil && *item.Deleted {
+			deleted = item
+		}
+	}
+	c.Assert(it.Err(), chk.IsNil)
+	c.Assert(deleted, chk.NotNil)
+	c.Assert(deleted.Properties.DeletedTime, chk.NotNil)
+	c.Assert(deleted.Properties.RemainingRetentionDays, chk.NotNil)
+
+	resp, err := share.Restore(ctx, shareName, *deleted.Version)
+	c.Assert(err, chk.IsNil)
+	c.Assert(resp.StatusCode(), chk.Equals, 201)
+	defer delShare(c, share, azfile.DeleteSnapshotsOptionNone)
+
+	_, err = share.GetProperties(ctx)
+	c.Assert(err, chk.IsNil)
+
+	// The share exists again, so it can't be restored a second time.
+	_, err = share.Restore(ctx, shareName, *deleted.Version)
+	c.Assert(err, chk.NotNil)
+	c.Assert(err.(azfile.StorageError).Response().StatusCode, chk.Equals, 409)
+}
@@ -10,7 +10,19 @@ import (
 
 const (
 	// ServiceVersion specifies the version of the operations used in this package.
-	ServiceVersion = "2018-03-28"
+	ServiceVersion = "2020-04-08"
+
+	// renameServiceVersion is the version of the Rename operations, which earlier versions don't support.
+	renameServiceVersion = "2021-04-10"
+
+	// changeTimeServiceVersion is the version of requests that set x-ms-file-change-time, which earlier versions don't support.
+	changeTimeServiceVersion = "2021-06-08"
+
+	// tokenServiceVersion is the version of requests authorized with OAuth, which earlier versions don't accept.
+	tokenServiceVersion = "2022-11-02"
+
+	// copySourceAuthorizationServiceVersion is the version of requests that set x-ms-copy-source-authorization, which earlier versions don't support.
+	copySourceAuthorizationServiceVersion = "2022-11-02"
 )
 
 // managementClient is the base client for Azfile.
@@ -26,17 +26,27 @@ func newDirectoryClient(url url.URL, p pipeline.Pipeline) directoryClient {
 
 // Create creates a new directory under the specified share or parent directory.
 //
-// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// fileAttributes is if specified, the provided file attributes shall be set. Default value: 'Archive' for file and
+// 'Directory' for directory. 'None' can also be specified as default. fileCreationTime is creation time for the
+// file/directory. Default value: Now. fileLastWriteTime is last write time for the file/directory. Default value: Now.
+// fileChangeTime is change time for the file/directory. Default value: preserve; a request with a fileChangeTime is
+// sent with service version 2021-06-08. timeout is the timeout parameter is expressed in seconds. For more information,
+// see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
 // Timeouts for File Service Operations.</a> metadata is a name-value pair to associate with a file storage object.
-func (client directoryClient) Create(ctx context.Context, timeout *int32, metadata map[string]string) (*DirectoryCreateResponse, error) {
+// filePermission is if specified the permission (security descriptor) shall be set for the directory/file. This header
+// can be used if Permission size is <= 8KB, else x-ms-file-permission-key header shall be used. Default value: Inherit.
+// If SDDL is specified as input, it must have owner, group and dacl. Note: Only one of the x-ms-file-permission or
+// x-ms-file-permission-key should be specified. filePermissionKey is key of the permission to be set for the
+// directory/file. Note: Only one of the x-ms-file-permission or x-ms-file-permission-key should be specified.
+func (client directoryClient) Create(ctx context.Context, fileAttributes string, fileCreationTime string, fileLastWriteTime string, fileChangeTime *string, timeout *int32, metadata map[string]string, filePermission *string, filePermissionKey *string) (*DirectoryCreateResponse, error) {
 	if err := validate([]validation{
 		{targetValue: timeout,
 			constraints: []constraint{{target: "timeout", name: null, rule: false,
 				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.createPreparer(timeout, metadata)
+	req, err := client.createPreparer(fileAttributes, fileCreationTime, fileLastWriteTime, fileChangeTime, timeout, metadata, filePermission, filePermissionKey)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +58,7 @@ func (client directoryClient) Create(ctx context.Context, timeout *int32, metada
 }
 
 // createPreparer prepares the Create request.
-func (client directoryClient) createPreparer(timeout *int32, metadata map[string]string) (pipeline.Request, error) {
+func (client directoryClient) createPreparer(fileAttributes string, fileCreationTime string, fileLastWriteTime string, fileChangeTime *string, timeout *int32, metadata map[string]string, filePermission *string, filePermissionKey *string) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("PUT", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -65,6 +75,19 @@ func (client directoryClient) createPreparer(timeout *int32, metadata map[string
 		}
 	}
 	req.Header.Set("x-ms-version", ServiceVersion)
+	if filePermission != nil {
+		req.Header.Set("x-ms-file-permission", *filePermission)
+	}
+	if filePermissionKey != nil {
+		req.Header.Set("x-ms-file-permission-key", *filePermissionKey)
+	}
+	req.Header.Set("x-ms-file-attributes", fileAttributes)
+	req.Header.Set("x-ms-file-creation-time", fileCreationTime)
+	req.Header.Set("x-ms-file-last-write-time", fileLastWriteTime)
+	if fileChangeTime != nil {
+		req.Header.Set("x-ms-file-change-time", *fileChangeTime)
+		req.Header.Set("x-ms-version", changeTimeServiceVersion)
+	}
 	return req, nil
 }
 
@@ -129,6 +152,66 @@ func (client directoryClient) deleteResponder(resp pipeline.Response) (pipeline.
 	return &DirectoryDeleteResponse{rawResponse: resp.Response()}, err
 }
 
+// ForceCloseHandles closes all handles open for given directory.
+//
+// handleID is specifies handle ID opened on the file or directory to be closed. Asterix (‘*’) is a wildcard that
+// specifies all handles. timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> marker is a string value that identifies the portion of the list to be
+// returned with the next list operation. recursive is specifies operation should apply to the directory specified in
+// the URI, its files, its subdirectories and their files.
+func (client directoryClient) ForceCloseHandles(ctx context.Context, handleID string, timeout *int32, marker *string, recursive *bool) (*DirectoryForceCloseHandlesResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.forceCloseHandlesPreparer(handleID, timeout, marker, recursive)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.forceCloseHandlesResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*DirectoryForceCloseHandlesResponse), err
+}
+
+// forceCloseHandlesPreparer prepares the ForceCloseHandles request.
+func (client directoryClient) forceCloseHandlesPreparer(handleID string, timeout *int32, marker *string, recursive *bool) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	if marker != nil && len(*marker) > 0 {
+		params.Set("marker", *marker)
+	}
+	params.Set("comp", "forceclosehandles")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-handle-id", handleID)
+	if recursive != nil {
+		req.Header.Set("x-ms-recursive", strconv.FormatBool(*recursive))
+	}
+	return req, nil
+}
+
+// forceCloseHandlesResponder handles the response to the ForceCloseHandles request.
+func (client directoryClient) forceCloseHandlesResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &DirectoryForceCloseHandlesResponse{rawResponse: resp.Response()}, err
+}
+
 // GetProperties returns all system properties for the specified directory, and can also be used to check the existence
 // of a directory. The data returned does not include the files in the directory or any subdirectories.
 //
@@ -196,8 +279,10 @@ func (client directoryClient) getPropertiesResponder(resp pipeline.Response) (pi
 // specifies a value greater than 5,000, the server will return up to 5,000 items. timeout is the timeout parameter is
 // expressed in seconds. For more information, see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
-// Timeouts for File Service Operations.</a>
-func (client directoryClient) ListFilesAndDirectoriesSegment(ctx context.Context, prefix *string, sharesnapshot *string, marker *string, maxresults *int32, timeout *int32) (*ListFilesAndDirectoriesSegmentResponse, error) {
+// Timeouts for File Service Operations.</a> include is include this parameter to specify one or more datasets to
+// include in the response. includeExtendedInfo is include extended information, such as each entry's file ID; it is
+// implied when include is not empty.
+func (client directoryClient) ListFilesAndDirectoriesSegment(ctx context.Context, prefix *string, sharesnapshot *string, marker *string, maxresults *int32, timeout *int32, include []ListFilesIncludeType, includeExtendedInfo *bool) (*ListFilesAndDirectoriesSegmentResponse, error) {
 	if err := validate([]validation{
 		{targetValue: maxresults,
 			constraints: []constraint{{target: "maxresults", name: null, rule: false,
@@ -207,7 +292,7 @@ func (client directoryClient) ListFilesAndDirectoriesSegment(ctx context.Context
 				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.listFilesAndDirectoriesSegmentPreparer(prefix, sharesnapshot, marker, maxresults, timeout)
+	req, err := client.listFilesAndDirectoriesSegmentPreparer(prefix, sharesnapshot, marker, maxresults, timeout, include, includeExtendedInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +338,7 @@ func (client directoryClient) ListFilesAndDirectoriesSegment(ctx context.Context
 // }
 
 // listFilesAndDirectoriesSegmentPreparer prepares the ListFilesAndDirectoriesSegment request.
-func (client directoryClient) listFilesAndDirectoriesSegmentPreparer(prefix *string, sharesnapshot *string, marker *string, maxresults *int32, timeout *int32) (pipeline.Request, error) {
+func (client directoryClient) listFilesAndDirectoriesSegmentPreparer(prefix *string, sharesnapshot *string, marker *string, maxresults *int32, timeout *int32, include []ListFilesIncludeType, includeExtendedInfo *bool) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("GET", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -274,10 +359,16 @@ func (client directoryClient) listFilesAndDirectoriesSegmentPreparer(prefix *str
 	if timeout != nil {
 		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
 	}
+	if include != nil && len(include) > 0 {
+		params.Set("include", joinConst(include, ","))
+	}
 	params.Set("restype", "directory")
 	params.Set("comp", "list")
 	req.URL.RawQuery = params.Encode()
 	req.Header.Set("x-ms-version", ServiceVersion)
+	if includeExtendedInfo != nil {
+		req.Header.Set("x-ms-file-extended-info", strconv.FormatBool(*includeExtendedInfo))
+	}
 	return req, nil
 }
 
@@ -330,6 +421,184 @@ func (client directoryClient) listFilesAndDirectoriesSegmentResponder(resp pipel
 // 	return result, nil
 // }
 
+// ListHandles lists handles for directory.
+//
+// marker is a string value that identifies the portion of the list to be returned with the next list operation. The
+// operation returns a marker value within the response body if the list returned was not complete. The marker value may
+// then be used in a subsequent call to request the next set of list items. The marker value is opaque to the client.
+// maxresults is specifies the maximum number of entries to return. If the request does not specify maxresults, or
+// specifies a value greater than 5,000, the server will return up to 5,000 items. timeout is the timeout parameter is
+// expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> recursive is specifies operation should apply to the directory specified in
+// the URI, its files, its subdirectories and their files.
+func (client directoryClient) ListHandles(ctx context.Context, marker *string, maxresults *int32, timeout *int32, recursive *bool) (*ListHandlesResponse, error) {
+	if err := validate([]validation{
+		{targetValue: maxresults,
+			constraints: []constraint{{target: "maxresults", name: null, rule: false,
+				chain: []constraint{{target: "maxresults", name: inclusiveMinimum, rule: 1, chain: nil}}}}},
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.listHandlesPreparer(marker, maxresults, timeout, recursive)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.listHandlesResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ListHandlesResponse), err
+}
+
+// listHandlesPreparer prepares the ListHandles request.
+func (client directoryClient) listHandlesPreparer(marker *string, maxresults *int32, timeout *int32, recursive *bool) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("GET", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	if marker != nil && len(*marker) > 0 {
+		params.Set("marker", *marker)
+	}
+	if maxresults != nil {
+		params.Set("maxresults", strconv.FormatInt(int64(*maxresults), 10))
+	}
+	params.Set("comp", "listhandles")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	if recursive != nil {
+		req.Header.Set("x-ms-recursive", strconv.FormatBool(*recursive))
+	}
+	return req, nil
+}
+
+// listHandlesResponder handles the response to the ListHandles request.
+func (client directoryClient) listHandlesResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	result := &ListHandlesResponse{rawResponse: resp.Response()}
+	if err != nil {
+		return result, err
+	}
+	defer resp.Response().Body.Close()
+	b, err := ioutil.ReadAll(resp.Response().Body)
+	if err != nil {
+		return result, err
+	}
+	if len(b) > 0 {
+		b = removeBOM(b)
+		err = xml.Unmarshal(b, result)
+		if err != nil {
+			return result, NewResponseError(err, resp.Response(), "failed to unmarshal response body")
+		}
+	}
+	return result, nil
+}
+
+// Rename renames a directory, within its share, to the client's URL.
+//
+// renameSource is the URL of the directory to rename, in the same share as the destination. timeout is the timeout
+// parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> replaceIfExists is whether an existing file at the destination is replaced.
+// ignoreReadOnly is whether an existing file with the ReadOnly attribute is replaced. sourceLeaseID is the lease ID of
+// the source file, if it's leased. destinationLeaseID is the lease ID of the file at the destination, if it's leased.
+// fileAttributes, fileCreationTime and fileLastWriteTime are the SMB properties to set on the destination; by default
+// the source's are kept. fileChangeTime is the change time to set on the destination; by default the source's is kept.
+// A request with a fileChangeTime is sent with service version 2021-06-08. filePermission is the security descriptor to
+// set on the destination. filePermissionKey is the key of a security descriptor stored on the share to set on the
+// destination. metadata is the metadata to set on the destination; by default the source's is kept.
+func (client directoryClient) Rename(ctx context.Context, renameSource string, timeout *int32, replaceIfExists *bool, ignoreReadOnly *bool, sourceLeaseID *string, destinationLeaseID *string, fileAttributes *string, fileCreationTime *string, fileLastWriteTime *string, fileChangeTime *string, filePermission *string, filePermissionKey *string, metadata map[string]string) (*DirectoryRenameResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.renamePreparer(renameSource, timeout, replaceIfExists, ignoreReadOnly, sourceLeaseID, destinationLeaseID, fileAttributes, fileCreationTime, fileLastWriteTime, fileChangeTime, filePermission, filePermissionKey, metadata)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.renameResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*DirectoryRenameResponse), err
+}
+
+// renamePreparer prepares the Rename request.
+func (client directoryClient) renamePreparer(renameSource string, timeout *int32, replaceIfExists *bool, ignoreReadOnly *bool, sourceLeaseID *string, destinationLeaseID *string, fileAttributes *string, fileCreationTime *string, fileLastWriteTime *string, fileChangeTime *string, filePermission *string, filePermissionKey *string, metadata map[string]string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "directory")
+	params.Set("comp", "rename")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", renameServiceVersion)
+	req.Header.Set("x-ms-file-rename-source", renameSource)
+	if replaceIfExists != nil {
+		req.Header.Set("x-ms-file-rename-replace-if-exists", strconv.FormatBool(*replaceIfExists))
+	}
+	if ignoreReadOnly != nil {
+		req.Header.Set("x-ms-file-rename-ignore-readonly", strconv.FormatBool(*ignoreReadOnly))
+	}
+	if sourceLeaseID != nil {
+		req.Header.Set("x-ms-source-lease-id", *sourceLeaseID)
+	}
+	if destinationLeaseID != nil {
+		req.Header.Set("x-ms-destination-lease-id", *destinationLeaseID)
+	}
+	if fileAttributes != nil {
+		req.Header.Set("x-ms-file-attributes", *fileAttributes)
+	}
+	if fileCreationTime != nil {
+		req.Header.Set("x-ms-file-creation-time", *fileCreationTime)
+	}
+	if fileLastWriteTime != nil {
+		req.Header.Set("x-ms-file-last-write-time", *fileLastWriteTime)
+	}
+	if fileChangeTime != nil {
+		req.Header.Set("x-ms-file-change-time", *fileChangeTime)
+		req.Header.Set("x-ms-version", changeTimeServiceVersion)
+	}
+	if filePermission != nil {
+		req.Header.Set("x-ms-file-permission", *filePermission)
+	}
+	if filePermissionKey != nil {
+		req.Header.Set("x-ms-file-permission-key", *filePermissionKey)
+	}
+	if metadata != nil {
+		for k, v := range metadata {
+			req.Header.Set("x-ms-meta-"+k, v)
+		}
+	}
+	return req, nil
+}
+
+// renameResponder handles the response to the Rename request.
+func (client directoryClient) renameResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &DirectoryRenameResponse{rawResponse: resp.Response()}, err
+}
+
 // SetMetadata updates user defined metadata for the specified directory.
 //
 // timeout is the timeout parameter is expressed in seconds. For more information, see <a
@@ -385,3 +654,129 @@ func (client directoryClient) setMetadataResponder(resp pipeline.Response) (pipe
 	resp.Response().Body.Close()
 	return &DirectorySetMetadataResponse{rawResponse: resp.Response()}, err
 }
+
+// SetProperties sets properties on the directory.
+//
+// fileAttributes is if specified, the provided file attributes shall be set. Default value: 'Archive' for file and
+// 'Directory' for directory. 'None' can also be specified as default. fileCreationTime is creation time for the
+// file/directory. Default value: Now. fileLastWriteTime is last write time for the file/directory. Default value: Now.
+// fileChangeTime is change time for the file/directory. Default value: preserve; a request with a fileChangeTime is
+// sent with service version 2021-06-08. timeout is the timeout parameter is expressed in seconds. For more information,
+// see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> filePermission is if specified the permission (security descriptor) shall
+// be set for the directory/file. This header can be used if Permission size is <= 8KB, else x-ms-file-permission-key
+// header shall be used. Default value: Inherit. If SDDL is specified as input, it must have owner, group and dacl.
+// Note: Only one of the x-ms-file-permission or x-ms-file-permission-key should be specified. filePermissionKey is key
+// of the permission to be set for the directory/file. Note: Only one of the x-ms-file-permission or
+// x-ms-file-permission-key should be specified.
+func (client directoryClient) SetProperties(ctx context.Context, fileAttributes string, fileCreationTime string, fileLastWriteTime string, fileChangeTime *string, timeout *int32, filePermission *string, filePermissionKey *string) (*DirectorySetPropertiesResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.setPropertiesPreparer(fileAttributes, fileCreationTime, fileLastWriteTime, fileChangeTime, timeout, filePermission, filePermissionKey)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.setPropertiesResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*DirectorySetPropertiesResponse), err
+}
+
+// setPropertiesPreparer prepares the SetProperties request.
+func (client directoryClient) setPropertiesPreparer(fileAttributes string, fileCreationTime string, fileLastWriteTime string, fileChangeTime *string, timeout *int32, filePermission *string, filePermissionKey *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "directory")
+	params.Set("comp", "properties")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	if filePermission != nil {
+		req.Header.Set("x-ms-file-permission", *filePermission)
+	}
+	if filePermissionKey != nil {
+		req.Header.Set("x-ms-file-permission-key", *filePermissionKey)
+	}
+	req.Header.Set("x-ms-file-attributes", fileAttributes)
+	req.Header.Set("x-ms-file-creation-time", fileCreationTime)
+	req.Header.Set("x-ms-file-last-write-time", fileLastWriteTime)
+	if fileChangeTime != nil {
+		req.Header.Set("x-ms-file-change-time", *fileChangeTime)
+		req.Header.Set("x-ms-version", changeTimeServiceVersion)
+	}
+	return req, nil
+}
+
+// setPropertiesResponder handles the response to the SetProperties request.
+func (client directoryClient) setPropertiesResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &DirectorySetPropertiesResponse{rawResponse: resp.Response()}, err
+}
+
+// Undelete restores the soft-deleted directory.
+//
+// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a>
+func (client directoryClient) Undelete(ctx context.Context, timeout *int32) (*DirectoryUndeleteResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.undeletePreparer(timeout)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.undeleteResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*DirectoryUndeleteResponse), err
+}
+
+// undeletePreparer prepares the Undelete request.
+func (client directoryClient) undeletePreparer(timeout *int32) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "directory")
+	params.Set("comp", "undelete")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-undelete", "true")
+	return req, nil
+}
+
+// undeleteResponder handles the response to the Undelete request.
+func (client directoryClient) undeleteResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &DirectoryUndeleteResponse{rawResponse: resp.Response()}, err
+}
@@ -53,11 +53,32 @@ func (md *Metadata) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
-// Marker represents an opaque value used in paged responses.
+// Marker represents an opaque value used in paged responses. The zero value starts a listing, and each segment's
+// NextMarker continues it, until NotDone returns false:
+//
+//	for marker := (azfile.Marker{}); marker.NotDone(); {
+//		resp, err := directoryURL.ListFilesAndDirectoriesSegment(ctx, marker, azfile.ListFilesAndDirectoriesOptions{})
+//		if err != nil {
+//			...
+//		}
+//		...
+//		marker = resp.NextMarker
+//	}
 type Marker struct {
 	val *string
 }
 
+// NewMarker returns a Marker that continues a listing from val, a NextMarker value saved with GetVal, e.g. to
+// resume the listing in another process. NewMarker("") returns the zero Marker, which starts the listing over:
+// don't save the NextMarker of a listing's final segment, for which NotDone is false.
+func NewMarker(val string) Marker {
+	if val == "" {
+		return Marker{}
+	}
+	return Marker{val: &val}
+}
+
+// GetVal returns the marker's value, or nil for a Marker that starts a listing.
 func (m Marker) GetVal() *string {
 	return m.val
 }
@@ -97,6 +118,27 @@ func validateError(err error) {
 	}
 }
 
+// AccessTierType enumerates the values for access tier type.
+type AccessTierType string
+
+const (
+	// AccessTierCool ...
+	AccessTierCool AccessTierType = "Cool"
+	// AccessTierHot ...
+	AccessTierHot AccessTierType = "Hot"
+	// AccessTierNone represents an empty AccessTierType.
+	AccessTierNone AccessTierType = ""
+	// AccessTierPremium ...
+	AccessTierPremium AccessTierType = "Premium"
+	// AccessTierTransactionOptimized ...
+	AccessTierTransactionOptimized AccessTierType = "TransactionOptimized"
+)
+
+// PossibleAccessTierTypeValues returns an array of possible values for the AccessTierType const type.
+func PossibleAccessTierTypeValues() []AccessTierType {
+	return []AccessTierType{AccessTierCool, AccessTierHot, AccessTierNone, AccessTierPremium, AccessTierTransactionOptimized}
+}
+
 // CopyStatusType enumerates the values for copy status type.
 type CopyStatusType string
 
@@ -150,10 +192,92 @@ func PossibleFileRangeWriteTypeValues() []FileRangeWriteType {
 	return []FileRangeWriteType{FileRangeWriteClear, FileRangeWriteNone, FileRangeWriteUpdate}
 }
 
+// LeaseDurationType enumerates the values for lease duration type.
+type LeaseDurationType string
+
+const (
+	// LeaseDurationFixed ...
+	LeaseDurationFixed LeaseDurationType = "fixed"
+	// LeaseDurationInfinite ...
+	LeaseDurationInfinite LeaseDurationType = "infinite"
+	// LeaseDurationNone represents an empty LeaseDurationType.
+	LeaseDurationNone LeaseDurationType = ""
+)
+
+// PossibleLeaseDurationTypeValues returns an array of possible values for the LeaseDurationType const type.
+func PossibleLeaseDurationTypeValues() []LeaseDurationType {
+	return []LeaseDurationType{LeaseDurationFixed, LeaseDurationInfinite, LeaseDurationNone}
+}
+
+// LeaseStateType enumerates the values for lease state type.
+type LeaseStateType string
+
+const (
+	// LeaseStateAvailable ...
+	LeaseStateAvailable LeaseStateType = "available"
+	// LeaseStateBreaking ...
+	LeaseStateBreaking LeaseStateType = "breaking"
+	// LeaseStateBroken ...
+	LeaseStateBroken LeaseStateType = "broken"
+	// LeaseStateExpired ...
+	LeaseStateExpired LeaseStateType = "expired"
+	// LeaseStateLeased ...
+	LeaseStateLeased LeaseStateType = "leased"
+	// LeaseStateNone represents an empty LeaseStateType.
+	LeaseStateNone LeaseStateType = ""
+)
+
+// PossibleLeaseStateTypeValues returns an array of possible values for the LeaseStateType const type.
+func PossibleLeaseStateTypeValues() []LeaseStateType {
+	return []LeaseStateType{LeaseStateAvailable, LeaseStateBreaking, LeaseStateBroken, LeaseStateExpired, LeaseStateLeased, LeaseStateNone}
+}
+
+// LeaseStatusType enumerates the values for lease status type.
+type LeaseStatusType string
+
+const (
+	// LeaseStatusLocked ...
+	LeaseStatusLocked LeaseStatusType = "locked"
+	// LeaseStatusNone represents an empty LeaseStatusType.
+	LeaseStatusNone LeaseStatusType = ""
+	// LeaseStatusUnlocked ...
+	LeaseStatusUnlocked LeaseStatusType = "unlocked"
+)
+
+// PossibleLeaseStatusTypeValues returns an array of possible values for the LeaseStatusType const type.
+func PossibleLeaseStatusTypeValues() []LeaseStatusType {
+	return []LeaseStatusType{LeaseStatusLocked, LeaseStatusNone, LeaseStatusUnlocked}
+}
+
+// ListFilesIncludeType enumerates the values for list files include type.
+type ListFilesIncludeType string
+
+const (
+	// ListFilesIncludeAttributes ...
+	ListFilesIncludeAttributes ListFilesIncludeType = "Attributes"
+	// ListFilesIncludeDeleted ...
+	ListFilesIncludeDeleted ListFilesIncludeType = "Deleted"
+	// ListFilesIncludeEtag ...
+	ListFilesIncludeEtag ListFilesIncludeType = "Etag"
+	// ListFilesIncludeNone represents an empty ListFilesIncludeType.
+	ListFilesIncludeNone ListFilesIncludeType = ""
+	// ListFilesIncludePermissionKey ...
+	ListFilesIncludePermissionKey ListFilesIncludeType = "PermissionKey"
+	// ListFilesIncludeTimestamps ...
+	ListFilesIncludeTimestamps ListFilesIncludeType = "Timestamps"
+)
+
+// PossibleListFilesIncludeTypeValues returns an array of possible values for the ListFilesIncludeType const type.
+func PossibleListFilesIncludeTypeValues() []ListFilesIncludeType {
+	return []ListFilesIncludeType{ListFilesIncludeAttributes, ListFilesIncludeDeleted, ListFilesIncludeEtag, ListFilesIncludeNone, ListFilesIncludePermissionKey, ListFilesIncludeTimestamps}
+}
+
 // ListSharesIncludeType enumerates the values for list shares include type.
 type ListSharesIncludeType string
 
 const (
+	// ListSharesIncludeDeleted ...
+	ListSharesIncludeDeleted ListSharesIncludeType = "deleted"
 	// ListSharesIncludeMetadata ...
 	ListSharesIncludeMetadata ListSharesIncludeType = "metadata"
 	// ListSharesIncludeNone represents an empty ListSharesIncludeType.
@@ -164,7 +288,60 @@ const (
 
 // PossibleListSharesIncludeTypeValues returns an array of possible values for the ListSharesIncludeType const type.
 func PossibleListSharesIncludeTypeValues() []ListSharesIncludeType {
-	return []ListSharesIncludeType{ListSharesIncludeMetadata, ListSharesIncludeNone, ListSharesIncludeSnapshots}
+	return []ListSharesIncludeType{ListSharesIncludeDeleted, ListSharesIncludeMetadata, ListSharesIncludeNone, ListSharesIncludeSnapshots}
+}
+
+// PermissionCopyModeType enumerates the values for permission copy mode type.
+type PermissionCopyModeType string
+
+const (
+	// PermissionCopyModeNone represents an empty PermissionCopyModeType.
+	PermissionCopyModeNone PermissionCopyModeType = ""
+	// PermissionCopyModeOverride ...
+	PermissionCopyModeOverride PermissionCopyModeType = "override"
+	// PermissionCopyModeSource ...
+	PermissionCopyModeSource PermissionCopyModeType = "source"
+)
+
+// PossiblePermissionCopyModeTypeValues returns an array of possible values for the PermissionCopyModeType const type.
+func PossiblePermissionCopyModeTypeValues() []PermissionCopyModeType {
+	return []PermissionCopyModeType{PermissionCopyModeNone, PermissionCopyModeOverride, PermissionCopyModeSource}
+}
+
+// ShareEnabledProtocolsType enumerates the values for share enabled protocols type.
+type ShareEnabledProtocolsType string
+
+const (
+	// ShareEnabledProtocolsNFS ...
+	ShareEnabledProtocolsNFS ShareEnabledProtocolsType = "NFS"
+	// ShareEnabledProtocolsNone represents an empty ShareEnabledProtocolsType.
+	ShareEnabledProtocolsNone ShareEnabledProtocolsType = ""
+	// ShareEnabledProtocolsSMB ...
+	ShareEnabledProtocolsSMB ShareEnabledProtocolsType = "SMB"
+)
+
+// PossibleShareEnabledProtocolsTypeValues returns an array of possible values for the ShareEnabledProtocolsType const type.
+func PossibleShareEnabledProtocolsTypeValues() []ShareEnabledProtocolsType {
+	return []ShareEnabledProtocolsType{ShareEnabledProtocolsNFS, ShareEnabledProtocolsNone, ShareEnabledProtocolsSMB}
+}
+
+// ShareRootSquashType enumerates the values for share root squash type.
+type ShareRootSquashType string
+
+const (
+	// ShareRootSquashAllSquash ...
+	ShareRootSquashAllSquash ShareRootSquashType = "AllSquash"
+	// ShareRootSquashNone represents an empty ShareRootSquashType.
+	ShareRootSquashNone ShareRootSquashType = ""
+	// ShareRootSquashNoRootSquash ...
+	ShareRootSquashNoRootSquash ShareRootSquashType = "NoRootSquash"
+	// ShareRootSquashRootSquash ...
+	ShareRootSquashRootSquash ShareRootSquashType = "RootSquash"
+)
+
+// PossibleShareRootSquashTypeValues returns an array of possible values for the ShareRootSquashType const type.
+func PossibleShareRootSquashTypeValues() []ShareRootSquashType {
+	return []ShareRootSquashType{ShareRootSquashAllSquash, ShareRootSquashNone, ShareRootSquashNoRootSquash, ShareRootSquashRootSquash}
 }
 
 // AccessPolicy - An Access policy.
@@ -226,6 +403,11 @@ func (dcr DirectoryCreateResponse) Status() string {
 	return dcr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dcr DirectoryCreateResponse) ClientRequestID() string {
+	return dcr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (dcr DirectoryCreateResponse) Date() time.Time {
 	s := dcr.rawResponse.Header.Get("Date")
@@ -249,6 +431,41 @@ func (dcr DirectoryCreateResponse) ETag() ETag {
 	return ETag(dcr.rawResponse.Header.Get("ETag"))
 }
 
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (dcr DirectoryCreateResponse) FileAttributes() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (dcr DirectoryCreateResponse) FileChangeTime() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (dcr DirectoryCreateResponse) FileCreationTime() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (dcr DirectoryCreateResponse) FileID() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (dcr DirectoryCreateResponse) FileLastWriteTime() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (dcr DirectoryCreateResponse) FileParentID() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (dcr DirectoryCreateResponse) FilePermissionKey() string {
+	return dcr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
 // IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
 func (dcr DirectoryCreateResponse) IsServerEncrypted() string {
 	return dcr.rawResponse.Header.Get("x-ms-request-server-encrypted")
@@ -297,6 +514,11 @@ func (ddr DirectoryDeleteResponse) Status() string {
 	return ddr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ddr DirectoryDeleteResponse) ClientRequestID() string {
+	return ddr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (ddr DirectoryDeleteResponse) Date() time.Time {
 	s := ddr.rawResponse.Header.Get("Date")
@@ -325,6 +547,90 @@ func (ddr DirectoryDeleteResponse) Version() string {
 	return ddr.rawResponse.Header.Get("x-ms-version")
 }
 
+// DirectoryForceCloseHandlesResponse ...
+type DirectoryForceCloseHandlesResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (dfchr DirectoryForceCloseHandlesResponse) Response() *http.Response {
+	return dfchr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (dfchr DirectoryForceCloseHandlesResponse) StatusCode() int {
+	return dfchr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (dfchr DirectoryForceCloseHandlesResponse) Status() string {
+	return dfchr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dfchr DirectoryForceCloseHandlesResponse) ClientRequestID() string {
+	return dfchr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (dfchr DirectoryForceCloseHandlesResponse) Date() time.Time {
+	s := dfchr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (dfchr DirectoryForceCloseHandlesResponse) ErrorCode() string {
+	return dfchr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// Marker returns the value for header x-ms-marker.
+func (dfchr DirectoryForceCloseHandlesResponse) Marker() string {
+	return dfchr.rawResponse.Header.Get("x-ms-marker")
+}
+
+// NumberOfHandlesClosed returns the value for header x-ms-number-of-handles-closed.
+func (dfchr DirectoryForceCloseHandlesResponse) NumberOfHandlesClosed() int32 {
+	s := dfchr.rawResponse.Header.Get("x-ms-number-of-handles-closed")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// NumberOfHandlesFailedToClose returns the value for header x-ms-number-of-handles-failed.
+func (dfchr DirectoryForceCloseHandlesResponse) NumberOfHandlesFailedToClose() int32 {
+	s := dfchr.rawResponse.Header.Get("x-ms-number-of-handles-failed")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (dfchr DirectoryForceCloseHandlesResponse) RequestID() string {
+	return dfchr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (dfchr DirectoryForceCloseHandlesResponse) Version() string {
+	return dfchr.rawResponse.Header.Get("x-ms-version")
+}
+
 // DirectoryGetPropertiesResponse ...
 type DirectoryGetPropertiesResponse struct {
 	rawResponse *http.Response
@@ -358,6 +664,11 @@ func (dgpr DirectoryGetPropertiesResponse) Status() string {
 	return dgpr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dgpr DirectoryGetPropertiesResponse) ClientRequestID() string {
+	return dgpr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (dgpr DirectoryGetPropertiesResponse) Date() time.Time {
 	s := dgpr.rawResponse.Header.Get("Date")
@@ -381,6 +692,41 @@ func (dgpr DirectoryGetPropertiesResponse) ETag() ETag {
 	return ETag(dgpr.rawResponse.Header.Get("ETag"))
 }
 
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (dgpr DirectoryGetPropertiesResponse) FileAttributes() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (dgpr DirectoryGetPropertiesResponse) FileChangeTime() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (dgpr DirectoryGetPropertiesResponse) FileCreationTime() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (dgpr DirectoryGetPropertiesResponse) FileID() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (dgpr DirectoryGetPropertiesResponse) FileLastWriteTime() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (dgpr DirectoryGetPropertiesResponse) FileParentID() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (dgpr DirectoryGetPropertiesResponse) FilePermissionKey() string {
+	return dgpr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
 // IsServerEncrypted returns the value for header x-ms-server-encrypted.
 func (dgpr DirectoryGetPropertiesResponse) IsServerEncrypted() string {
 	return dgpr.rawResponse.Header.Get("x-ms-server-encrypted")
@@ -409,6 +755,117 @@ func (dgpr DirectoryGetPropertiesResponse) Version() string {
 	return dgpr.rawResponse.Header.Get("x-ms-version")
 }
 
+// DirectoryRenameResponse ...
+type DirectoryRenameResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (drr DirectoryRenameResponse) Response() *http.Response {
+	return drr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (drr DirectoryRenameResponse) StatusCode() int {
+	return drr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (drr DirectoryRenameResponse) Status() string {
+	return drr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (drr DirectoryRenameResponse) ClientRequestID() string {
+	return drr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (drr DirectoryRenameResponse) Date() time.Time {
+	s := drr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (drr DirectoryRenameResponse) ETag() ETag {
+	return ETag(drr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (drr DirectoryRenameResponse) ErrorCode() string {
+	return drr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (drr DirectoryRenameResponse) FileAttributes() string {
+	return drr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (drr DirectoryRenameResponse) FileChangeTime() string {
+	return drr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (drr DirectoryRenameResponse) FileCreationTime() string {
+	return drr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (drr DirectoryRenameResponse) FileID() string {
+	return drr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (drr DirectoryRenameResponse) FileLastWriteTime() string {
+	return drr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (drr DirectoryRenameResponse) FileParentID() string {
+	return drr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (drr DirectoryRenameResponse) FilePermissionKey() string {
+	return drr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (drr DirectoryRenameResponse) IsServerEncrypted() string {
+	return drr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (drr DirectoryRenameResponse) LastModified() time.Time {
+	s := drr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (drr DirectoryRenameResponse) RequestID() string {
+	return drr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (drr DirectoryRenameResponse) Version() string {
+	return drr.rawResponse.Header.Get("x-ms-version")
+}
+
 // DirectorySetMetadataResponse ...
 type DirectorySetMetadataResponse struct {
 	rawResponse *http.Response
@@ -429,6 +886,11 @@ func (dsmr DirectorySetMetadataResponse) Status() string {
 	return dsmr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dsmr DirectorySetMetadataResponse) ClientRequestID() string {
+	return dsmr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (dsmr DirectorySetMetadataResponse) Date() time.Time {
 	s := dsmr.rawResponse.Header.Get("Date")
@@ -467,65 +929,234 @@ func (dsmr DirectorySetMetadataResponse) Version() string {
 	return dsmr.rawResponse.Header.Get("x-ms-version")
 }
 
-// downloadResponse - Wraps the response from the fileClient.Download method.
-type downloadResponse struct {
+// DirectorySetPropertiesResponse ...
+type DirectorySetPropertiesResponse struct {
 	rawResponse *http.Response
 }
 
-// NewMetadata returns user-defined key/value pairs.
-func (dr downloadResponse) NewMetadata() Metadata {
-	md := Metadata{}
-	for k, v := range dr.rawResponse.Header {
-		if len(k) > mdPrefixLen {
-			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
-				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
-			}
-		}
-	}
-	return md
-}
-
 // Response returns the raw HTTP response object.
-func (dr downloadResponse) Response() *http.Response {
-	return dr.rawResponse
+func (dspr DirectorySetPropertiesResponse) Response() *http.Response {
+	return dspr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (dr downloadResponse) StatusCode() int {
-	return dr.rawResponse.StatusCode
+func (dspr DirectorySetPropertiesResponse) StatusCode() int {
+	return dspr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (dr downloadResponse) Status() string {
-	return dr.rawResponse.Status
+func (dspr DirectorySetPropertiesResponse) Status() string {
+	return dspr.rawResponse.Status
 }
 
-// Body returns the raw HTTP response object's Body.
-func (dr downloadResponse) Body() io.ReadCloser {
-	return dr.rawResponse.Body
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dspr DirectorySetPropertiesResponse) ClientRequestID() string {
+	return dspr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
-// AcceptRanges returns the value for header Accept-Ranges.
-func (dr downloadResponse) AcceptRanges() string {
-	return dr.rawResponse.Header.Get("Accept-Ranges")
+// Date returns the value for header Date.
+func (dspr DirectorySetPropertiesResponse) Date() time.Time {
+	s := dspr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
 }
 
-// CacheControl returns the value for header Cache-Control.
-func (dr downloadResponse) CacheControl() string {
-	return dr.rawResponse.Header.Get("Cache-Control")
+// ETag returns the value for header ETag.
+func (dspr DirectorySetPropertiesResponse) ETag() ETag {
+	return ETag(dspr.rawResponse.Header.Get("ETag"))
 }
 
-// ContentDisposition returns the value for header Content-Disposition.
-func (dr downloadResponse) ContentDisposition() string {
-	return dr.rawResponse.Header.Get("Content-Disposition")
+// ErrorCode returns the value for header x-ms-error-code.
+func (dspr DirectorySetPropertiesResponse) ErrorCode() string {
+	return dspr.rawResponse.Header.Get("x-ms-error-code")
 }
 
-// ContentEncoding returns the value for header Content-Encoding.
-func (dr downloadResponse) ContentEncoding() string {
-	return dr.rawResponse.Header.Get("Content-Encoding")
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (dspr DirectorySetPropertiesResponse) FileAttributes() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-attributes")
 }
 
-// ContentLanguage returns the value for header Content-Language.
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (dspr DirectorySetPropertiesResponse) FileChangeTime() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (dspr DirectorySetPropertiesResponse) FileCreationTime() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (dspr DirectorySetPropertiesResponse) FileID() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (dspr DirectorySetPropertiesResponse) FileLastWriteTime() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (dspr DirectorySetPropertiesResponse) FileParentID() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (dspr DirectorySetPropertiesResponse) FilePermissionKey() string {
+	return dspr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (dspr DirectorySetPropertiesResponse) IsServerEncrypted() string {
+	return dspr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (dspr DirectorySetPropertiesResponse) LastModified() time.Time {
+	s := dspr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (dspr DirectorySetPropertiesResponse) RequestID() string {
+	return dspr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (dspr DirectorySetPropertiesResponse) Version() string {
+	return dspr.rawResponse.Header.Get("x-ms-version")
+}
+
+// DirectoryUndeleteResponse ...
+type DirectoryUndeleteResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (dur DirectoryUndeleteResponse) Response() *http.Response {
+	return dur.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (dur DirectoryUndeleteResponse) StatusCode() int {
+	return dur.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (dur DirectoryUndeleteResponse) Status() string {
+	return dur.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dur DirectoryUndeleteResponse) ClientRequestID() string {
+	return dur.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (dur DirectoryUndeleteResponse) Date() time.Time {
+	s := dur.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (dur DirectoryUndeleteResponse) ErrorCode() string {
+	return dur.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (dur DirectoryUndeleteResponse) RequestID() string {
+	return dur.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (dur DirectoryUndeleteResponse) Version() string {
+	return dur.rawResponse.Header.Get("x-ms-version")
+}
+
+// downloadResponse - Wraps the response from the fileClient.Download method.
+type downloadResponse struct {
+	rawResponse *http.Response
+}
+
+// NewMetadata returns user-defined key/value pairs.
+func (dr downloadResponse) NewMetadata() Metadata {
+	md := Metadata{}
+	for k, v := range dr.rawResponse.Header {
+		if len(k) > mdPrefixLen {
+			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
+				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
+			}
+		}
+	}
+	return md
+}
+
+// Response returns the raw HTTP response object.
+func (dr downloadResponse) Response() *http.Response {
+	return dr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (dr downloadResponse) StatusCode() int {
+	return dr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (dr downloadResponse) Status() string {
+	return dr.rawResponse.Status
+}
+
+// Body returns the raw HTTP response object's Body.
+func (dr downloadResponse) Body() io.ReadCloser {
+	return dr.rawResponse.Body
+}
+
+// AcceptRanges returns the value for header Accept-Ranges.
+func (dr downloadResponse) AcceptRanges() string {
+	return dr.rawResponse.Header.Get("Accept-Ranges")
+}
+
+// CacheControl returns the value for header Cache-Control.
+func (dr downloadResponse) CacheControl() string {
+	return dr.rawResponse.Header.Get("Cache-Control")
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dr downloadResponse) ClientRequestID() string {
+	return dr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ContentDisposition returns the value for header Content-Disposition.
+func (dr downloadResponse) ContentDisposition() string {
+	return dr.rawResponse.Header.Get("Content-Disposition")
+}
+
+// ContentEncoding returns the value for header Content-Encoding.
+func (dr downloadResponse) ContentEncoding() string {
+	return dr.rawResponse.Header.Get("Content-Encoding")
+}
+
+// ContentLanguage returns the value for header Content-Language.
 func (dr downloadResponse) ContentLanguage() string {
 	return dr.rawResponse.Header.Get("Content-Language")
 }
@@ -617,6 +1248,11 @@ func (dr downloadResponse) Date() time.Time {
 	return t
 }
 
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (dr downloadResponse) EncryptionScope() string {
+	return dr.rawResponse.Header.Get("x-ms-encryption-scope")
+}
+
 // ErrorCode returns the value for header x-ms-error-code.
 func (dr downloadResponse) ErrorCode() string {
 	return dr.rawResponse.Header.Get("x-ms-error-code")
@@ -627,6 +1263,16 @@ func (dr downloadResponse) ETag() ETag {
 	return ETag(dr.rawResponse.Header.Get("ETag"))
 }
 
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (dr downloadResponse) FileAttributes() string {
+	return dr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (dr downloadResponse) FileChangeTime() string {
+	return dr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
 // FileContentMD5 returns the value for header x-ms-content-md5.
 func (dr downloadResponse) FileContentMD5() []byte {
 	s := dr.rawResponse.Header.Get("x-ms-content-md5")
@@ -640,6 +1286,31 @@ func (dr downloadResponse) FileContentMD5() []byte {
 	return b
 }
 
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (dr downloadResponse) FileCreationTime() string {
+	return dr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (dr downloadResponse) FileID() string {
+	return dr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (dr downloadResponse) FileLastWriteTime() string {
+	return dr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (dr downloadResponse) FileParentID() string {
+	return dr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (dr downloadResponse) FilePermissionKey() string {
+	return dr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
 // IsServerEncrypted returns the value for header x-ms-server-encrypted.
 func (dr downloadResponse) IsServerEncrypted() string {
 	return dr.rawResponse.Header.Get("x-ms-server-encrypted")
@@ -658,6 +1329,21 @@ func (dr downloadResponse) LastModified() time.Time {
 	return t
 }
 
+// LeaseDuration returns the value for header x-ms-lease-duration.
+func (dr downloadResponse) LeaseDuration() LeaseDurationType {
+	return LeaseDurationType(dr.rawResponse.Header.Get("x-ms-lease-duration"))
+}
+
+// LeaseState returns the value for header x-ms-lease-state.
+func (dr downloadResponse) LeaseState() LeaseStateType {
+	return LeaseStateType(dr.rawResponse.Header.Get("x-ms-lease-state"))
+}
+
+// LeaseStatus returns the value for header x-ms-lease-status.
+func (dr downloadResponse) LeaseStatus() LeaseStatusType {
+	return LeaseStatusType(dr.rawResponse.Header.Get("x-ms-lease-status"))
+}
+
 // RequestID returns the value for header x-ms-request-id.
 func (dr downloadResponse) RequestID() string {
 	return dr.rawResponse.Header.Get("x-ms-request-id")
@@ -688,6 +1374,11 @@ func (facr FileAbortCopyResponse) Status() string {
 	return facr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (facr FileAbortCopyResponse) ClientRequestID() string {
+	return facr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (facr FileAbortCopyResponse) Date() time.Time {
 	s := facr.rawResponse.Header.Get("Date")
@@ -716,29 +1407,34 @@ func (facr FileAbortCopyResponse) Version() string {
 	return facr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileCreateResponse ...
-type FileCreateResponse struct {
+// FileAcquireLeaseResponse ...
+type FileAcquireLeaseResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (fcr FileCreateResponse) Response() *http.Response {
-	return fcr.rawResponse
+func (falr FileAcquireLeaseResponse) Response() *http.Response {
+	return falr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fcr FileCreateResponse) StatusCode() int {
-	return fcr.rawResponse.StatusCode
+func (falr FileAcquireLeaseResponse) StatusCode() int {
+	return falr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fcr FileCreateResponse) Status() string {
-	return fcr.rawResponse.Status
+func (falr FileAcquireLeaseResponse) Status() string {
+	return falr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (falr FileAcquireLeaseResponse) ClientRequestID() string {
+	return falr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (fcr FileCreateResponse) Date() time.Time {
-	s := fcr.rawResponse.Header.Get("Date")
+func (falr FileAcquireLeaseResponse) Date() time.Time {
+	s := falr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -749,24 +1445,19 @@ func (fcr FileCreateResponse) Date() time.Time {
 	return t
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (fcr FileCreateResponse) ErrorCode() string {
-	return fcr.rawResponse.Header.Get("x-ms-error-code")
-}
-
 // ETag returns the value for header ETag.
-func (fcr FileCreateResponse) ETag() ETag {
-	return ETag(fcr.rawResponse.Header.Get("ETag"))
+func (falr FileAcquireLeaseResponse) ETag() ETag {
+	return ETag(falr.rawResponse.Header.Get("ETag"))
 }
 
-// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
-func (fcr FileCreateResponse) IsServerEncrypted() string {
-	return fcr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+// ErrorCode returns the value for header x-ms-error-code.
+func (falr FileAcquireLeaseResponse) ErrorCode() string {
+	return falr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // LastModified returns the value for header Last-Modified.
-func (fcr FileCreateResponse) LastModified() time.Time {
-	s := fcr.rawResponse.Header.Get("Last-Modified")
+func (falr FileAcquireLeaseResponse) LastModified() time.Time {
+	s := falr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -777,39 +1468,49 @@ func (fcr FileCreateResponse) LastModified() time.Time {
 	return t
 }
 
+// LeaseID returns the value for header x-ms-lease-id.
+func (falr FileAcquireLeaseResponse) LeaseID() string {
+	return falr.rawResponse.Header.Get("x-ms-lease-id")
+}
+
 // RequestID returns the value for header x-ms-request-id.
-func (fcr FileCreateResponse) RequestID() string {
-	return fcr.rawResponse.Header.Get("x-ms-request-id")
+func (falr FileAcquireLeaseResponse) RequestID() string {
+	return falr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (fcr FileCreateResponse) Version() string {
-	return fcr.rawResponse.Header.Get("x-ms-version")
+func (falr FileAcquireLeaseResponse) Version() string {
+	return falr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileDeleteResponse ...
-type FileDeleteResponse struct {
+// FileBreakLeaseResponse ...
+type FileBreakLeaseResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (fdr FileDeleteResponse) Response() *http.Response {
-	return fdr.rawResponse
+func (fblr FileBreakLeaseResponse) Response() *http.Response {
+	return fblr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fdr FileDeleteResponse) StatusCode() int {
-	return fdr.rawResponse.StatusCode
+func (fblr FileBreakLeaseResponse) StatusCode() int {
+	return fblr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fdr FileDeleteResponse) Status() string {
-	return fdr.rawResponse.Status
+func (fblr FileBreakLeaseResponse) Status() string {
+	return fblr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fblr FileBreakLeaseResponse) ClientRequestID() string {
+	return fblr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (fdr FileDeleteResponse) Date() time.Time {
-	s := fdr.rawResponse.Header.Get("Date")
+func (fblr FileBreakLeaseResponse) Date() time.Time {
+	s := fblr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -820,108 +1521,95 @@ func (fdr FileDeleteResponse) Date() time.Time {
 	return t
 }
 
+// ETag returns the value for header ETag.
+func (fblr FileBreakLeaseResponse) ETag() ETag {
+	return ETag(fblr.rawResponse.Header.Get("ETag"))
+}
+
 // ErrorCode returns the value for header x-ms-error-code.
-func (fdr FileDeleteResponse) ErrorCode() string {
-	return fdr.rawResponse.Header.Get("x-ms-error-code")
+func (fblr FileBreakLeaseResponse) ErrorCode() string {
+	return fblr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (fblr FileBreakLeaseResponse) LastModified() time.Time {
+	s := fblr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseID returns the value for header x-ms-lease-id.
+func (fblr FileBreakLeaseResponse) LeaseID() string {
+	return fblr.rawResponse.Header.Get("x-ms-lease-id")
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (fdr FileDeleteResponse) RequestID() string {
-	return fdr.rawResponse.Header.Get("x-ms-request-id")
+func (fblr FileBreakLeaseResponse) RequestID() string {
+	return fblr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (fdr FileDeleteResponse) Version() string {
-	return fdr.rawResponse.Header.Get("x-ms-version")
+func (fblr FileBreakLeaseResponse) Version() string {
+	return fblr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileGetPropertiesResponse ...
-type FileGetPropertiesResponse struct {
+// FileChangeLeaseResponse ...
+type FileChangeLeaseResponse struct {
 	rawResponse *http.Response
 }
 
-// NewMetadata returns user-defined key/value pairs.
-func (fgpr FileGetPropertiesResponse) NewMetadata() Metadata {
-	md := Metadata{}
-	for k, v := range fgpr.rawResponse.Header {
-		if len(k) > mdPrefixLen {
-			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
-				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
-			}
-		}
-	}
-	return md
-}
-
 // Response returns the raw HTTP response object.
-func (fgpr FileGetPropertiesResponse) Response() *http.Response {
-	return fgpr.rawResponse
+func (fclr FileChangeLeaseResponse) Response() *http.Response {
+	return fclr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fgpr FileGetPropertiesResponse) StatusCode() int {
-	return fgpr.rawResponse.StatusCode
+func (fclr FileChangeLeaseResponse) StatusCode() int {
+	return fclr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fgpr FileGetPropertiesResponse) Status() string {
-	return fgpr.rawResponse.Status
+func (fclr FileChangeLeaseResponse) Status() string {
+	return fclr.rawResponse.Status
 }
 
-// CacheControl returns the value for header Cache-Control.
-func (fgpr FileGetPropertiesResponse) CacheControl() string {
-	return fgpr.rawResponse.Header.Get("Cache-Control")
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fclr FileChangeLeaseResponse) ClientRequestID() string {
+	return fclr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
-// ContentDisposition returns the value for header Content-Disposition.
-func (fgpr FileGetPropertiesResponse) ContentDisposition() string {
-	return fgpr.rawResponse.Header.Get("Content-Disposition")
+// Date returns the value for header Date.
+func (fclr FileChangeLeaseResponse) Date() time.Time {
+	s := fclr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
 }
 
-// ContentEncoding returns the value for header Content-Encoding.
-func (fgpr FileGetPropertiesResponse) ContentEncoding() string {
-	return fgpr.rawResponse.Header.Get("Content-Encoding")
+// ETag returns the value for header ETag.
+func (fclr FileChangeLeaseResponse) ETag() ETag {
+	return ETag(fclr.rawResponse.Header.Get("ETag"))
 }
 
-// ContentLanguage returns the value for header Content-Language.
-func (fgpr FileGetPropertiesResponse) ContentLanguage() string {
-	return fgpr.rawResponse.Header.Get("Content-Language")
-}
-
-// ContentLength returns the value for header Content-Length.
-func (fgpr FileGetPropertiesResponse) ContentLength() int64 {
-	s := fgpr.rawResponse.Header.Get("Content-Length")
-	if s == "" {
-		return -1
-	}
-	i, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		i = 0
-	}
-	return i
-}
-
-// ContentMD5 returns the value for header Content-MD5.
-func (fgpr FileGetPropertiesResponse) ContentMD5() []byte {
-	s := fgpr.rawResponse.Header.Get("Content-MD5")
-	if s == "" {
-		return nil
-	}
-	b, err := base64.StdEncoding.DecodeString(s)
-	if err != nil {
-		b = nil
-	}
-	return b
-}
-
-// ContentType returns the value for header Content-Type.
-func (fgpr FileGetPropertiesResponse) ContentType() string {
-	return fgpr.rawResponse.Header.Get("Content-Type")
+// ErrorCode returns the value for header x-ms-error-code.
+func (fclr FileChangeLeaseResponse) ErrorCode() string {
+	return fclr.rawResponse.Header.Get("x-ms-error-code")
 }
 
-// CopyCompletionTime returns the value for header x-ms-copy-completion-time.
-func (fgpr FileGetPropertiesResponse) CopyCompletionTime() time.Time {
-	s := fgpr.rawResponse.Header.Get("x-ms-copy-completion-time")
+// LastModified returns the value for header Last-Modified.
+func (fclr FileChangeLeaseResponse) LastModified() time.Time {
+	s := fclr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -932,67 +1620,49 @@ func (fgpr FileGetPropertiesResponse) CopyCompletionTime() time.Time {
 	return t
 }
 
-// CopyID returns the value for header x-ms-copy-id.
-func (fgpr FileGetPropertiesResponse) CopyID() string {
-	return fgpr.rawResponse.Header.Get("x-ms-copy-id")
-}
-
-// CopyProgress returns the value for header x-ms-copy-progress.
-func (fgpr FileGetPropertiesResponse) CopyProgress() string {
-	return fgpr.rawResponse.Header.Get("x-ms-copy-progress")
-}
-
-// CopySource returns the value for header x-ms-copy-source.
-func (fgpr FileGetPropertiesResponse) CopySource() string {
-	return fgpr.rawResponse.Header.Get("x-ms-copy-source")
+// LeaseID returns the value for header x-ms-lease-id.
+func (fclr FileChangeLeaseResponse) LeaseID() string {
+	return fclr.rawResponse.Header.Get("x-ms-lease-id")
 }
 
-// CopyStatus returns the value for header x-ms-copy-status.
-func (fgpr FileGetPropertiesResponse) CopyStatus() CopyStatusType {
-	return CopyStatusType(fgpr.rawResponse.Header.Get("x-ms-copy-status"))
+// RequestID returns the value for header x-ms-request-id.
+func (fclr FileChangeLeaseResponse) RequestID() string {
+	return fclr.rawResponse.Header.Get("x-ms-request-id")
 }
 
-// CopyStatusDescription returns the value for header x-ms-copy-status-description.
-func (fgpr FileGetPropertiesResponse) CopyStatusDescription() string {
-	return fgpr.rawResponse.Header.Get("x-ms-copy-status-description")
+// Version returns the value for header x-ms-version.
+func (fclr FileChangeLeaseResponse) Version() string {
+	return fclr.rawResponse.Header.Get("x-ms-version")
 }
 
-// Date returns the value for header Date.
-func (fgpr FileGetPropertiesResponse) Date() time.Time {
-	s := fgpr.rawResponse.Header.Get("Date")
-	if s == "" {
-		return time.Time{}
-	}
-	t, err := time.Parse(time.RFC1123, s)
-	if err != nil {
-		t = time.Time{}
-	}
-	return t
+// FileCreateResponse ...
+type FileCreateResponse struct {
+	rawResponse *http.Response
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (fgpr FileGetPropertiesResponse) ErrorCode() string {
-	return fgpr.rawResponse.Header.Get("x-ms-error-code")
+// Response returns the raw HTTP response object.
+func (fcr FileCreateResponse) Response() *http.Response {
+	return fcr.rawResponse
 }
 
-// ETag returns the value for header ETag.
-func (fgpr FileGetPropertiesResponse) ETag() ETag {
-	return ETag(fgpr.rawResponse.Header.Get("ETag"))
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (fcr FileCreateResponse) StatusCode() int {
+	return fcr.rawResponse.StatusCode
 }
 
-// FileType returns the value for header x-ms-type.
-func (fgpr FileGetPropertiesResponse) FileType() string {
-	return string(fgpr.rawResponse.Header.Get("x-ms-type"))
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (fcr FileCreateResponse) Status() string {
+	return fcr.rawResponse.Status
 }
 
-// IsServerEncrypted returns the value for header x-ms-server-encrypted.
-func (fgpr FileGetPropertiesResponse) IsServerEncrypted() string {
-	return fgpr.rawResponse.Header.Get("x-ms-server-encrypted")
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fcr FileCreateResponse) ClientRequestID() string {
+	return fcr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
-// LastModified returns the value for header Last-Modified.
-func (fgpr FileGetPropertiesResponse) LastModified() time.Time {
-	s := fgpr.rawResponse.Header.Get("Last-Modified")
+// Date returns the value for header Date.
+func (fcr FileCreateResponse) Date() time.Time {
+	s := fcr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1003,73 +1673,64 @@ func (fgpr FileGetPropertiesResponse) LastModified() time.Time {
 	return t
 }
 
-// RequestID returns the value for header x-ms-request-id.
-func (fgpr FileGetPropertiesResponse) RequestID() string {
-	return fgpr.rawResponse.Header.Get("x-ms-request-id")
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (fcr FileCreateResponse) EncryptionScope() string {
+	return fcr.rawResponse.Header.Get("x-ms-encryption-scope")
 }
 
-// Version returns the value for header x-ms-version.
-func (fgpr FileGetPropertiesResponse) Version() string {
-	return fgpr.rawResponse.Header.Get("x-ms-version")
+// ErrorCode returns the value for header x-ms-error-code.
+func (fcr FileCreateResponse) ErrorCode() string {
+	return fcr.rawResponse.Header.Get("x-ms-error-code")
 }
 
-// FileProperty - File properties.
-type FileProperty struct {
-	// ContentLength - Content length of the file. This value may not be up-to-date since an SMB client may have modified the file locally. The value of Content-Length may not reflect that fact until the handle is closed or the op-lock is broken. To retrieve current property values, call Get File Properties.
-	ContentLength int64 `xml:"Content-Length"`
+// ETag returns the value for header ETag.
+func (fcr FileCreateResponse) ETag() ETag {
+	return ETag(fcr.rawResponse.Header.Get("ETag"))
 }
 
-// FileSetHTTPHeadersResponse ...
-type FileSetHTTPHeadersResponse struct {
-	rawResponse *http.Response
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (fcr FileCreateResponse) FileAttributes() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-attributes")
 }
 
-// Response returns the raw HTTP response object.
-func (fshhr FileSetHTTPHeadersResponse) Response() *http.Response {
-	return fshhr.rawResponse
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (fcr FileCreateResponse) FileChangeTime() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-change-time")
 }
 
-// StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fshhr FileSetHTTPHeadersResponse) StatusCode() int {
-	return fshhr.rawResponse.StatusCode
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (fcr FileCreateResponse) FileCreationTime() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-creation-time")
 }
 
-// Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fshhr FileSetHTTPHeadersResponse) Status() string {
-	return fshhr.rawResponse.Status
+// FileID returns the value for header x-ms-file-id.
+func (fcr FileCreateResponse) FileID() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-id")
 }
 
-// Date returns the value for header Date.
-func (fshhr FileSetHTTPHeadersResponse) Date() time.Time {
-	s := fshhr.rawResponse.Header.Get("Date")
-	if s == "" {
-		return time.Time{}
-	}
-	t, err := time.Parse(time.RFC1123, s)
-	if err != nil {
-		t = time.Time{}
-	}
-	return t
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (fcr FileCreateResponse) FileLastWriteTime() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-last-write-time")
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (fshhr FileSetHTTPHeadersResponse) ErrorCode() string {
-	return fshhr.rawResponse.Header.Get("x-ms-error-code")
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (fcr FileCreateResponse) FileParentID() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-parent-id")
 }
 
-// ETag returns the value for header ETag.
-func (fshhr FileSetHTTPHeadersResponse) ETag() ETag {
-	return ETag(fshhr.rawResponse.Header.Get("ETag"))
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (fcr FileCreateResponse) FilePermissionKey() string {
+	return fcr.rawResponse.Header.Get("x-ms-file-permission-key")
 }
 
 // IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
-func (fshhr FileSetHTTPHeadersResponse) IsServerEncrypted() string {
-	return fshhr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+func (fcr FileCreateResponse) IsServerEncrypted() string {
+	return fcr.rawResponse.Header.Get("x-ms-request-server-encrypted")
 }
 
 // LastModified returns the value for header Last-Modified.
-func (fshhr FileSetHTTPHeadersResponse) LastModified() time.Time {
-	s := fshhr.rawResponse.Header.Get("Last-Modified")
+func (fcr FileCreateResponse) LastModified() time.Time {
+	s := fcr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1081,38 +1742,43 @@ func (fshhr FileSetHTTPHeadersResponse) LastModified() time.Time {
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (fshhr FileSetHTTPHeadersResponse) RequestID() string {
-	return fshhr.rawResponse.Header.Get("x-ms-request-id")
+func (fcr FileCreateResponse) RequestID() string {
+	return fcr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (fshhr FileSetHTTPHeadersResponse) Version() string {
-	return fshhr.rawResponse.Header.Get("x-ms-version")
+func (fcr FileCreateResponse) Version() string {
+	return fcr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileSetMetadataResponse ...
-type FileSetMetadataResponse struct {
+// FileDeleteResponse ...
+type FileDeleteResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (fsmr FileSetMetadataResponse) Response() *http.Response {
-	return fsmr.rawResponse
+func (fdr FileDeleteResponse) Response() *http.Response {
+	return fdr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fsmr FileSetMetadataResponse) StatusCode() int {
-	return fsmr.rawResponse.StatusCode
+func (fdr FileDeleteResponse) StatusCode() int {
+	return fdr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fsmr FileSetMetadataResponse) Status() string {
-	return fsmr.rawResponse.Status
+func (fdr FileDeleteResponse) Status() string {
+	return fdr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fdr FileDeleteResponse) ClientRequestID() string {
+	return fdr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (fsmr FileSetMetadataResponse) Date() time.Time {
-	s := fsmr.rawResponse.Header.Get("Date")
+func (fdr FileDeleteResponse) Date() time.Time {
+	s := fdr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1124,63 +1790,48 @@ func (fsmr FileSetMetadataResponse) Date() time.Time {
 }
 
 // ErrorCode returns the value for header x-ms-error-code.
-func (fsmr FileSetMetadataResponse) ErrorCode() string {
-	return fsmr.rawResponse.Header.Get("x-ms-error-code")
-}
-
-// ETag returns the value for header ETag.
-func (fsmr FileSetMetadataResponse) ETag() ETag {
-	return ETag(fsmr.rawResponse.Header.Get("ETag"))
-}
-
-// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
-func (fsmr FileSetMetadataResponse) IsServerEncrypted() string {
-	return fsmr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+func (fdr FileDeleteResponse) ErrorCode() string {
+	return fdr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (fsmr FileSetMetadataResponse) RequestID() string {
-	return fsmr.rawResponse.Header.Get("x-ms-request-id")
+func (fdr FileDeleteResponse) RequestID() string {
+	return fdr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (fsmr FileSetMetadataResponse) Version() string {
-	return fsmr.rawResponse.Header.Get("x-ms-version")
+func (fdr FileDeleteResponse) Version() string {
+	return fdr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileStartCopyResponse ...
-type FileStartCopyResponse struct {
+// FileForceCloseHandlesResponse ...
+type FileForceCloseHandlesResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (fscr FileStartCopyResponse) Response() *http.Response {
-	return fscr.rawResponse
+func (ffchr FileForceCloseHandlesResponse) Response() *http.Response {
+	return ffchr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (fscr FileStartCopyResponse) StatusCode() int {
-	return fscr.rawResponse.StatusCode
+func (ffchr FileForceCloseHandlesResponse) StatusCode() int {
+	return ffchr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (fscr FileStartCopyResponse) Status() string {
-	return fscr.rawResponse.Status
-}
-
-// CopyID returns the value for header x-ms-copy-id.
-func (fscr FileStartCopyResponse) CopyID() string {
-	return fscr.rawResponse.Header.Get("x-ms-copy-id")
+func (ffchr FileForceCloseHandlesResponse) Status() string {
+	return ffchr.rawResponse.Status
 }
 
-// CopyStatus returns the value for header x-ms-copy-status.
-func (fscr FileStartCopyResponse) CopyStatus() CopyStatusType {
-	return CopyStatusType(fscr.rawResponse.Header.Get("x-ms-copy-status"))
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ffchr FileForceCloseHandlesResponse) ClientRequestID() string {
+	return ffchr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (fscr FileStartCopyResponse) Date() time.Time {
-	s := fscr.rawResponse.Header.Get("Date")
+func (ffchr FileForceCloseHandlesResponse) Date() time.Time {
+	s := ffchr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1192,61 +1843,125 @@ func (fscr FileStartCopyResponse) Date() time.Time {
 }
 
 // ErrorCode returns the value for header x-ms-error-code.
-func (fscr FileStartCopyResponse) ErrorCode() string {
-	return fscr.rawResponse.Header.Get("x-ms-error-code")
+func (ffchr FileForceCloseHandlesResponse) ErrorCode() string {
+	return ffchr.rawResponse.Header.Get("x-ms-error-code")
 }
 
-// ETag returns the value for header ETag.
-func (fscr FileStartCopyResponse) ETag() ETag {
-	return ETag(fscr.rawResponse.Header.Get("ETag"))
+// Marker returns the value for header x-ms-marker.
+func (ffchr FileForceCloseHandlesResponse) Marker() string {
+	return ffchr.rawResponse.Header.Get("x-ms-marker")
 }
 
-// LastModified returns the value for header Last-Modified.
-func (fscr FileStartCopyResponse) LastModified() time.Time {
-	s := fscr.rawResponse.Header.Get("Last-Modified")
+// NumberOfHandlesClosed returns the value for header x-ms-number-of-handles-closed.
+func (ffchr FileForceCloseHandlesResponse) NumberOfHandlesClosed() int32 {
+	s := ffchr.rawResponse.Header.Get("x-ms-number-of-handles-closed")
 	if s == "" {
-		return time.Time{}
+		return -1
 	}
-	t, err := time.Parse(time.RFC1123, s)
+	i, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
-		t = time.Time{}
+		i = 0
 	}
-	return t
+	return int32(i)
+}
+
+// NumberOfHandlesFailedToClose returns the value for header x-ms-number-of-handles-failed.
+func (ffchr FileForceCloseHandlesResponse) NumberOfHandlesFailedToClose() int32 {
+	s := ffchr.rawResponse.Header.Get("x-ms-number-of-handles-failed")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (fscr FileStartCopyResponse) RequestID() string {
-	return fscr.rawResponse.Header.Get("x-ms-request-id")
+func (ffchr FileForceCloseHandlesResponse) RequestID() string {
+	return ffchr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (fscr FileStartCopyResponse) Version() string {
-	return fscr.rawResponse.Header.Get("x-ms-version")
+func (ffchr FileForceCloseHandlesResponse) Version() string {
+	return ffchr.rawResponse.Header.Get("x-ms-version")
 }
 
-// FileUploadRangeResponse ...
-type FileUploadRangeResponse struct {
-	rawResponse *http.Response
+// FileGetPropertiesResponse ...
+type FileGetPropertiesResponse struct {
+	rawResponse *http.Response
+}
+
+// NewMetadata returns user-defined key/value pairs.
+func (fgpr FileGetPropertiesResponse) NewMetadata() Metadata {
+	md := Metadata{}
+	for k, v := range fgpr.rawResponse.Header {
+		if len(k) > mdPrefixLen {
+			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
+				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
+			}
+		}
+	}
+	return md
 }
 
 // Response returns the raw HTTP response object.
-func (furr FileUploadRangeResponse) Response() *http.Response {
-	return furr.rawResponse
+func (fgpr FileGetPropertiesResponse) Response() *http.Response {
+	return fgpr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (furr FileUploadRangeResponse) StatusCode() int {
-	return furr.rawResponse.StatusCode
+func (fgpr FileGetPropertiesResponse) StatusCode() int {
+	return fgpr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (furr FileUploadRangeResponse) Status() string {
-	return furr.rawResponse.Status
+func (fgpr FileGetPropertiesResponse) Status() string {
+	return fgpr.rawResponse.Status
+}
+
+// CacheControl returns the value for header Cache-Control.
+func (fgpr FileGetPropertiesResponse) CacheControl() string {
+	return fgpr.rawResponse.Header.Get("Cache-Control")
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fgpr FileGetPropertiesResponse) ClientRequestID() string {
+	return fgpr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ContentDisposition returns the value for header Content-Disposition.
+func (fgpr FileGetPropertiesResponse) ContentDisposition() string {
+	return fgpr.rawResponse.Header.Get("Content-Disposition")
+}
+
+// ContentEncoding returns the value for header Content-Encoding.
+func (fgpr FileGetPropertiesResponse) ContentEncoding() string {
+	return fgpr.rawResponse.Header.Get("Content-Encoding")
+}
+
+// ContentLanguage returns the value for header Content-Language.
+func (fgpr FileGetPropertiesResponse) ContentLanguage() string {
+	return fgpr.rawResponse.Header.Get("Content-Language")
+}
+
+// ContentLength returns the value for header Content-Length.
+func (fgpr FileGetPropertiesResponse) ContentLength() int64 {
+	s := fgpr.rawResponse.Header.Get("Content-Length")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		i = 0
+	}
+	return i
 }
 
 // ContentMD5 returns the value for header Content-MD5.
-func (furr FileUploadRangeResponse) ContentMD5() []byte {
-	s := furr.rawResponse.Header.Get("Content-MD5")
+func (fgpr FileGetPropertiesResponse) ContentMD5() []byte {
+	s := fgpr.rawResponse.Header.Get("Content-MD5")
 	if s == "" {
 		return nil
 	}
@@ -1257,9 +1972,14 @@ func (furr FileUploadRangeResponse) ContentMD5() []byte {
 	return b
 }
 
-// Date returns the value for header Date.
-func (furr FileUploadRangeResponse) Date() time.Time {
-	s := furr.rawResponse.Header.Get("Date")
+// ContentType returns the value for header Content-Type.
+func (fgpr FileGetPropertiesResponse) ContentType() string {
+	return fgpr.rawResponse.Header.Get("Content-Type")
+}
+
+// CopyCompletionTime returns the value for header x-ms-copy-completion-time.
+func (fgpr FileGetPropertiesResponse) CopyCompletionTime() time.Time {
+	s := fgpr.rawResponse.Header.Get("x-ms-copy-completion-time")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1270,24 +1990,34 @@ func (furr FileUploadRangeResponse) Date() time.Time {
 	return t
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (furr FileUploadRangeResponse) ErrorCode() string {
-	return furr.rawResponse.Header.Get("x-ms-error-code")
+// CopyID returns the value for header x-ms-copy-id.
+func (fgpr FileGetPropertiesResponse) CopyID() string {
+	return fgpr.rawResponse.Header.Get("x-ms-copy-id")
 }
 
-// ETag returns the value for header ETag.
-func (furr FileUploadRangeResponse) ETag() ETag {
-	return ETag(furr.rawResponse.Header.Get("ETag"))
+// CopyProgress returns the value for header x-ms-copy-progress.
+func (fgpr FileGetPropertiesResponse) CopyProgress() string {
+	return fgpr.rawResponse.Header.Get("x-ms-copy-progress")
 }
 
-// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
-func (furr FileUploadRangeResponse) IsServerEncrypted() string {
-	return furr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+// CopySource returns the value for header x-ms-copy-source.
+func (fgpr FileGetPropertiesResponse) CopySource() string {
+	return fgpr.rawResponse.Header.Get("x-ms-copy-source")
 }
 
-// LastModified returns the value for header Last-Modified.
-func (furr FileUploadRangeResponse) LastModified() time.Time {
-	s := furr.rawResponse.Header.Get("Last-Modified")
+// CopyStatus returns the value for header x-ms-copy-status.
+func (fgpr FileGetPropertiesResponse) CopyStatus() CopyStatusType {
+	return CopyStatusType(fgpr.rawResponse.Header.Get("x-ms-copy-status"))
+}
+
+// CopyStatusDescription returns the value for header x-ms-copy-status-description.
+func (fgpr FileGetPropertiesResponse) CopyStatusDescription() string {
+	return fgpr.rawResponse.Header.Get("x-ms-copy-status-description")
+}
+
+// Date returns the value for header Date.
+func (fgpr FileGetPropertiesResponse) Date() time.Time {
+	s := fgpr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1298,102 +2028,160 @@ func (furr FileUploadRangeResponse) LastModified() time.Time {
 	return t
 }
 
-// RequestID returns the value for header x-ms-request-id.
-func (furr FileUploadRangeResponse) RequestID() string {
-	return furr.rawResponse.Header.Get("x-ms-request-id")
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (fgpr FileGetPropertiesResponse) EncryptionScope() string {
+	return fgpr.rawResponse.Header.Get("x-ms-encryption-scope")
 }
 
-// Version returns the value for header x-ms-version.
-func (furr FileUploadRangeResponse) Version() string {
-	return furr.rawResponse.Header.Get("x-ms-version")
+// ErrorCode returns the value for header x-ms-error-code.
+func (fgpr FileGetPropertiesResponse) ErrorCode() string {
+	return fgpr.rawResponse.Header.Get("x-ms-error-code")
 }
 
-// ListSharesResponse - An enumeration of shares.
-type ListSharesResponse struct {
-	rawResponse *http.Response
-	// XMLName is used for marshalling and is subject to removal in a future release.
-	XMLName         xml.Name    `xml:"EnumerationResults"`
-	ServiceEndpoint string      `xml:"ServiceEndpoint,attr"`
-	Prefix          *string     `xml:"Prefix"`
-	Marker          *string     `xml:"Marker"`
-	MaxResults      *int32      `xml:"MaxResults"`
-	ShareItems      []ShareItem `xml:"Shares>Share"`
-	NextMarker      Marker      `xml:"NextMarker"`
+// ETag returns the value for header ETag.
+func (fgpr FileGetPropertiesResponse) ETag() ETag {
+	return ETag(fgpr.rawResponse.Header.Get("ETag"))
 }
 
-// Response returns the raw HTTP response object.
-func (lsr ListSharesResponse) Response() *http.Response {
-	return lsr.rawResponse
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (fgpr FileGetPropertiesResponse) FileAttributes() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-attributes")
 }
 
-// StatusCode returns the HTTP status code of the response, e.g. 200.
-func (lsr ListSharesResponse) StatusCode() int {
-	return lsr.rawResponse.StatusCode
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (fgpr FileGetPropertiesResponse) FileChangeTime() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-change-time")
 }
 
-// Status returns the HTTP status message of the response, e.g. "200 OK".
-func (lsr ListSharesResponse) Status() string {
-	return lsr.rawResponse.Status
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (fgpr FileGetPropertiesResponse) FileCreationTime() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-creation-time")
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (lsr ListSharesResponse) ErrorCode() string {
-	return lsr.rawResponse.Header.Get("x-ms-error-code")
+// FileID returns the value for header x-ms-file-id.
+func (fgpr FileGetPropertiesResponse) FileID() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (fgpr FileGetPropertiesResponse) FileLastWriteTime() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (fgpr FileGetPropertiesResponse) FileParentID() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (fgpr FileGetPropertiesResponse) FilePermissionKey() string {
+	return fgpr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// FileType returns the value for header x-ms-type.
+func (fgpr FileGetPropertiesResponse) FileType() string {
+	return string(fgpr.rawResponse.Header.Get("x-ms-type"))
+}
+
+// IsServerEncrypted returns the value for header x-ms-server-encrypted.
+func (fgpr FileGetPropertiesResponse) IsServerEncrypted() string {
+	return fgpr.rawResponse.Header.Get("x-ms-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (fgpr FileGetPropertiesResponse) LastModified() time.Time {
+	s := fgpr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseDuration returns the value for header x-ms-lease-duration.
+func (fgpr FileGetPropertiesResponse) LeaseDuration() LeaseDurationType {
+	return LeaseDurationType(fgpr.rawResponse.Header.Get("x-ms-lease-duration"))
+}
+
+// LeaseState returns the value for header x-ms-lease-state.
+func (fgpr FileGetPropertiesResponse) LeaseState() LeaseStateType {
+	return LeaseStateType(fgpr.rawResponse.Header.Get("x-ms-lease-state"))
+}
+
+// LeaseStatus returns the value for header x-ms-lease-status.
+func (fgpr FileGetPropertiesResponse) LeaseStatus() LeaseStatusType {
+	return LeaseStatusType(fgpr.rawResponse.Header.Get("x-ms-lease-status"))
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (lsr ListSharesResponse) RequestID() string {
-	return lsr.rawResponse.Header.Get("x-ms-request-id")
+func (fgpr FileGetPropertiesResponse) RequestID() string {
+	return fgpr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (lsr ListSharesResponse) Version() string {
-	return lsr.rawResponse.Header.Get("x-ms-version")
+func (fgpr FileGetPropertiesResponse) Version() string {
+	return fgpr.rawResponse.Header.Get("x-ms-version")
 }
 
-// Metrics - Storage Analytics metrics for file service.
-type Metrics struct {
-	// Version - The version of Storage Analytics to configure.
-	Version string `xml:"Version"`
-	// Enabled - Indicates whether metrics are enabled for the File service.
-	Enabled bool `xml:"Enabled"`
-	// IncludeAPIs - Indicates whether metrics should generate summary statistics for called API operations.
-	IncludeAPIs     *bool            `xml:"IncludeAPIs"`
-	RetentionPolicy *RetentionPolicy `xml:"RetentionPolicy"`
+// FileProperty - File properties.
+type FileProperty struct {
+	// ContentLength - Content length of the file. This value may not be up-to-date since an SMB client may have modified the file locally. The value of Content-Length may not reflect that fact until the handle is closed or the op-lock is broken. To retrieve current property values, call Get File Properties.
+	ContentLength  int64      `xml:"Content-Length"`
+	CreationTime   *time.Time `xml:"CreationTime"`
+	LastAccessTime *time.Time `xml:"LastAccessTime"`
+	LastWriteTime  *time.Time `xml:"LastWriteTime"`
+	ChangeTime     *time.Time `xml:"ChangeTime"`
+	LastModified   *time.Time `xml:"Last-Modified"`
+	Etag           *ETag      `xml:"Etag"`
+	// DeletedTime - When the soft-deleted entry was deleted. Soft-deleted entries are only listed with ListFilesAndDirectoriesDetail.Deleted.
+	DeletedTime *time.Time `xml:"DeletedTime"`
+	// RemainingRetentionDays - The number of days left before the soft-deleted entry is permanently deleted.
+	RemainingRetentionDays *int32 `xml:"RemainingRetentionDays"`
 }
 
-// Range - An Azure Storage file range.
-type Range struct {
-	// Start - Start of the range.
-	Start int64 `xml:"Start"`
-	// End - End of the range.
-	End int64 `xml:"End"`
+// MarshalXML implements the xml.Marshaler interface for FileProperty.
+func (fp FileProperty) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	fp2 := (*fileProperty)(unsafe.Pointer(&fp))
+	return e.EncodeElement(*fp2, start)
 }
 
-// Ranges - Wraps the response from the fileClient.GetRangeList method.
-type Ranges struct {
+// UnmarshalXML implements the xml.Unmarshaler interface for FileProperty.
+func (fp *FileProperty) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	fp2 := (*fileProperty)(unsafe.Pointer(fp))
+	return d.DecodeElement(fp2, &start)
+}
+
+// FileReleaseLeaseResponse ...
+type FileReleaseLeaseResponse struct {
 	rawResponse *http.Response
-	Items       []Range `xml:"Range"`
 }
 
 // Response returns the raw HTTP response object.
-func (r Ranges) Response() *http.Response {
-	return r.rawResponse
+func (frlr FileReleaseLeaseResponse) Response() *http.Response {
+	return frlr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (r Ranges) StatusCode() int {
-	return r.rawResponse.StatusCode
+func (frlr FileReleaseLeaseResponse) StatusCode() int {
+	return frlr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (r Ranges) Status() string {
-	return r.rawResponse.Status
+func (frlr FileReleaseLeaseResponse) Status() string {
+	return frlr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (frlr FileReleaseLeaseResponse) ClientRequestID() string {
+	return frlr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (r Ranges) Date() time.Time {
-	s := r.rawResponse.Header.Get("Date")
+func (frlr FileReleaseLeaseResponse) Date() time.Time {
+	s := frlr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1404,32 +2192,19 @@ func (r Ranges) Date() time.Time {
 	return t
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (r Ranges) ErrorCode() string {
-	return r.rawResponse.Header.Get("x-ms-error-code")
-}
-
 // ETag returns the value for header ETag.
-func (r Ranges) ETag() ETag {
-	return ETag(r.rawResponse.Header.Get("ETag"))
+func (frlr FileReleaseLeaseResponse) ETag() ETag {
+	return ETag(frlr.rawResponse.Header.Get("ETag"))
 }
 
-// FileContentLength returns the value for header x-ms-content-length.
-func (r Ranges) FileContentLength() int64 {
-	s := r.rawResponse.Header.Get("x-ms-content-length")
-	if s == "" {
-		return -1
-	}
-	i, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		i = 0
-	}
-	return i
+// ErrorCode returns the value for header x-ms-error-code.
+func (frlr FileReleaseLeaseResponse) ErrorCode() string {
+	return frlr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // LastModified returns the value for header Last-Modified.
-func (r Ranges) LastModified() time.Time {
-	s := r.rawResponse.Header.Get("Last-Modified")
+func (frlr FileReleaseLeaseResponse) LastModified() time.Time {
+	s := frlr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1441,81 +2216,1569 @@ func (r Ranges) LastModified() time.Time {
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (r Ranges) RequestID() string {
-	return r.rawResponse.Header.Get("x-ms-request-id")
+func (frlr FileReleaseLeaseResponse) RequestID() string {
+	return frlr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (r Ranges) Version() string {
-	return r.rawResponse.Header.Get("x-ms-version")
+func (frlr FileReleaseLeaseResponse) Version() string {
+	return frlr.rawResponse.Header.Get("x-ms-version")
 }
 
-// RetentionPolicy - The retention policy.
-type RetentionPolicy struct {
-	// Enabled - Indicates whether a retention policy is enabled for the File service. If false, metrics data is retained, and the user is responsible for deleting it.
-	Enabled bool `xml:"Enabled"`
-	// Days - Indicates the number of days that metrics data should be retained. All data older than this value will be deleted. Metrics data is deleted on a best-effort basis after the retention period expires.
-	Days *int32 `xml:"Days"`
+// FileRenameResponse ...
+type FileRenameResponse struct {
+	rawResponse *http.Response
 }
 
-// ServiceSetPropertiesResponse ...
+// Response returns the raw HTTP response object.
+func (frr FileRenameResponse) Response() *http.Response {
+	return frr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (frr FileRenameResponse) StatusCode() int {
+	return frr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (frr FileRenameResponse) Status() string {
+	return frr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (frr FileRenameResponse) ClientRequestID() string {
+	return frr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (frr FileRenameResponse) Date() time.Time {
+	s := frr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (frr FileRenameResponse) ETag() ETag {
+	return ETag(frr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (frr FileRenameResponse) ErrorCode() string {
+	return frr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (frr FileRenameResponse) FileAttributes() string {
+	return frr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (frr FileRenameResponse) FileChangeTime() string {
+	return frr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (frr FileRenameResponse) FileCreationTime() string {
+	return frr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (frr FileRenameResponse) FileID() string {
+	return frr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (frr FileRenameResponse) FileLastWriteTime() string {
+	return frr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (frr FileRenameResponse) FileParentID() string {
+	return frr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (frr FileRenameResponse) FilePermissionKey() string {
+	return frr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (frr FileRenameResponse) IsServerEncrypted() string {
+	return frr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (frr FileRenameResponse) LastModified() time.Time {
+	s := frr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (frr FileRenameResponse) RequestID() string {
+	return frr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (frr FileRenameResponse) Version() string {
+	return frr.rawResponse.Header.Get("x-ms-version")
+}
+
+// FileSetHTTPHeadersResponse ...
+type FileSetHTTPHeadersResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (fshhr FileSetHTTPHeadersResponse) Response() *http.Response {
+	return fshhr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (fshhr FileSetHTTPHeadersResponse) StatusCode() int {
+	return fshhr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (fshhr FileSetHTTPHeadersResponse) Status() string {
+	return fshhr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fshhr FileSetHTTPHeadersResponse) ClientRequestID() string {
+	return fshhr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (fshhr FileSetHTTPHeadersResponse) Date() time.Time {
+	s := fshhr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (fshhr FileSetHTTPHeadersResponse) EncryptionScope() string {
+	return fshhr.rawResponse.Header.Get("x-ms-encryption-scope")
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (fshhr FileSetHTTPHeadersResponse) ErrorCode() string {
+	return fshhr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (fshhr FileSetHTTPHeadersResponse) ETag() ETag {
+	return ETag(fshhr.rawResponse.Header.Get("ETag"))
+}
+
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (fshhr FileSetHTTPHeadersResponse) FileAttributes() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-attributes")
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (fshhr FileSetHTTPHeadersResponse) FileChangeTime() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-change-time")
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (fshhr FileSetHTTPHeadersResponse) FileCreationTime() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-creation-time")
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (fshhr FileSetHTTPHeadersResponse) FileID() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-id")
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (fshhr FileSetHTTPHeadersResponse) FileLastWriteTime() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-last-write-time")
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (fshhr FileSetHTTPHeadersResponse) FileParentID() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-parent-id")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (fshhr FileSetHTTPHeadersResponse) FilePermissionKey() string {
+	return fshhr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (fshhr FileSetHTTPHeadersResponse) IsServerEncrypted() string {
+	return fshhr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (fshhr FileSetHTTPHeadersResponse) LastModified() time.Time {
+	s := fshhr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (fshhr FileSetHTTPHeadersResponse) RequestID() string {
+	return fshhr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (fshhr FileSetHTTPHeadersResponse) Version() string {
+	return fshhr.rawResponse.Header.Get("x-ms-version")
+}
+
+// FileSetMetadataResponse ...
+type FileSetMetadataResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (fsmr FileSetMetadataResponse) Response() *http.Response {
+	return fsmr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (fsmr FileSetMetadataResponse) StatusCode() int {
+	return fsmr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (fsmr FileSetMetadataResponse) Status() string {
+	return fsmr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fsmr FileSetMetadataResponse) ClientRequestID() string {
+	return fsmr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (fsmr FileSetMetadataResponse) Date() time.Time {
+	s := fsmr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (fsmr FileSetMetadataResponse) ErrorCode() string {
+	return fsmr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (fsmr FileSetMetadataResponse) ETag() ETag {
+	return ETag(fsmr.rawResponse.Header.Get("ETag"))
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (fsmr FileSetMetadataResponse) IsServerEncrypted() string {
+	return fsmr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (fsmr FileSetMetadataResponse) RequestID() string {
+	return fsmr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (fsmr FileSetMetadataResponse) Version() string {
+	return fsmr.rawResponse.Header.Get("x-ms-version")
+}
+
+// FileStartCopyResponse ...
+type FileStartCopyResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (fscr FileStartCopyResponse) Response() *http.Response {
+	return fscr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (fscr FileStartCopyResponse) StatusCode() int {
+	return fscr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (fscr FileStartCopyResponse) Status() string {
+	return fscr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fscr FileStartCopyResponse) ClientRequestID() string {
+	return fscr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// CopyID returns the value for header x-ms-copy-id.
+func (fscr FileStartCopyResponse) CopyID() string {
+	return fscr.rawResponse.Header.Get("x-ms-copy-id")
+}
+
+// CopyStatus returns the value for header x-ms-copy-status.
+func (fscr FileStartCopyResponse) CopyStatus() CopyStatusType {
+	return CopyStatusType(fscr.rawResponse.Header.Get("x-ms-copy-status"))
+}
+
+// Date returns the value for header Date.
+func (fscr FileStartCopyResponse) Date() time.Time {
+	s := fscr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (fscr FileStartCopyResponse) ErrorCode() string {
+	return fscr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (fscr FileStartCopyResponse) ETag() ETag {
+	return ETag(fscr.rawResponse.Header.Get("ETag"))
+}
+
+// LastModified returns the value for header Last-Modified.
+func (fscr FileStartCopyResponse) LastModified() time.Time {
+	s := fscr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (fscr FileStartCopyResponse) RequestID() string {
+	return fscr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (fscr FileStartCopyResponse) Version() string {
+	return fscr.rawResponse.Header.Get("x-ms-version")
+}
+
+// FileUndeleteResponse ...
+type FileUndeleteResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (fur FileUndeleteResponse) Response() *http.Response {
+	return fur.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (fur FileUndeleteResponse) StatusCode() int {
+	return fur.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (fur FileUndeleteResponse) Status() string {
+	return fur.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fur FileUndeleteResponse) ClientRequestID() string {
+	return fur.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (fur FileUndeleteResponse) Date() time.Time {
+	s := fur.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (fur FileUndeleteResponse) ErrorCode() string {
+	return fur.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (fur FileUndeleteResponse) RequestID() string {
+	return fur.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (fur FileUndeleteResponse) Version() string {
+	return fur.rawResponse.Header.Get("x-ms-version")
+}
+
+// FileUploadRangeFromURLResponse ...
+type FileUploadRangeFromURLResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (furfur FileUploadRangeFromURLResponse) Response() *http.Response {
+	return furfur.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (furfur FileUploadRangeFromURLResponse) StatusCode() int {
+	return furfur.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (furfur FileUploadRangeFromURLResponse) Status() string {
+	return furfur.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (furfur FileUploadRangeFromURLResponse) ClientRequestID() string {
+	return furfur.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (furfur FileUploadRangeFromURLResponse) Date() time.Time {
+	s := furfur.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (furfur FileUploadRangeFromURLResponse) ETag() ETag {
+	return ETag(furfur.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (furfur FileUploadRangeFromURLResponse) ErrorCode() string {
+	return furfur.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (furfur FileUploadRangeFromURLResponse) IsServerEncrypted() string {
+	return furfur.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (furfur FileUploadRangeFromURLResponse) LastModified() time.Time {
+	s := furfur.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (furfur FileUploadRangeFromURLResponse) RequestID() string {
+	return furfur.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (furfur FileUploadRangeFromURLResponse) Version() string {
+	return furfur.rawResponse.Header.Get("x-ms-version")
+}
+
+// XMsContentCrc64 returns the value for header x-ms-content-crc64.
+func (furfur FileUploadRangeFromURLResponse) XMsContentCrc64() []byte {
+	s := furfur.rawResponse.Header.Get("x-ms-content-crc64")
+	if s == "" {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		b = nil
+	}
+	return b
+}
+
+// FileUploadRangeResponse ...
+type FileUploadRangeResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (furr FileUploadRangeResponse) Response() *http.Response {
+	return furr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (furr FileUploadRangeResponse) StatusCode() int {
+	return furr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (furr FileUploadRangeResponse) Status() string {
+	return furr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (furr FileUploadRangeResponse) ClientRequestID() string {
+	return furr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ContentMD5 returns the value for header Content-MD5.
+func (furr FileUploadRangeResponse) ContentMD5() []byte {
+	s := furr.rawResponse.Header.Get("Content-MD5")
+	if s == "" {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		b = nil
+	}
+	return b
+}
+
+// Date returns the value for header Date.
+func (furr FileUploadRangeResponse) Date() time.Time {
+	s := furr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (furr FileUploadRangeResponse) EncryptionScope() string {
+	return furr.rawResponse.Header.Get("x-ms-encryption-scope")
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (furr FileUploadRangeResponse) ErrorCode() string {
+	return furr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (furr FileUploadRangeResponse) ETag() ETag {
+	return ETag(furr.rawResponse.Header.Get("ETag"))
+}
+
+// IsServerEncrypted returns the value for header x-ms-request-server-encrypted.
+func (furr FileUploadRangeResponse) IsServerEncrypted() string {
+	return furr.rawResponse.Header.Get("x-ms-request-server-encrypted")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (furr FileUploadRangeResponse) LastModified() time.Time {
+	s := furr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (furr FileUploadRangeResponse) RequestID() string {
+	return furr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (furr FileUploadRangeResponse) Version() string {
+	return furr.rawResponse.Header.Get("x-ms-version")
+}
+
+// HandleItem - A listed Azure Storage handle item.
+type HandleItem struct {
+	// XMLName is used for marshalling and is subject to removal in a future release.
+	XMLName xml.Name `xml:"Handle"`
+	// HandleID - XSMB service handle ID
+	HandleID string `xml:"HandleId"`
+	// Path - File or directory name including full path starting from share root
+	Path string `xml:"Path"`
+	// FileID - FileId uniquely identifies the file or directory.
+	FileID string `xml:"FileId"`
+	// ParentID - ParentId uniquely identifies the parent directory of the object.
+	ParentID *string `xml:"ParentId"`
+	// SessionID - SMB session ID in context of which the file handle was opened
+	SessionID string `xml:"SessionId"`
+	// ClientIP - Client IP that opened the handle
+	ClientIP string `xml:"ClientIp"`
+	// OpenTime - Time when the session that previously opened the handle has last been reconnected. (UTC)
+	OpenTime time.Time `xml:"OpenTime"`
+	// LastReconnectTime - Time handle was last connected to (UTC)
+	LastReconnectTime *time.Time `xml:"LastReconnectTime"`
+}
+
+// MarshalXML implements the xml.Marshaler interface for HandleItem.
+func (hi HandleItem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	hi2 := (*handleItem)(unsafe.Pointer(&hi))
+	return e.EncodeElement(*hi2, start)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface for HandleItem.
+func (hi *HandleItem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	hi2 := (*handleItem)(unsafe.Pointer(hi))
+	return d.DecodeElement(hi2, &start)
+}
+
+// ListHandlesResponse - An enumeration of handles.
+type ListHandlesResponse struct {
+	rawResponse *http.Response
+	// XMLName is used for marshalling and is subject to removal in a future release.
+	XMLName    xml.Name     `xml:"EnumerationResults"`
+	HandleList []HandleItem `xml:"Entries>Handle"`
+	NextMarker Marker       `xml:"NextMarker"`
+}
+
+// Response returns the raw HTTP response object.
+func (lhr ListHandlesResponse) Response() *http.Response {
+	return lhr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (lhr ListHandlesResponse) StatusCode() int {
+	return lhr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (lhr ListHandlesResponse) Status() string {
+	return lhr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (lhr ListHandlesResponse) ClientRequestID() string {
+	return lhr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ContentType returns the value for header Content-Type.
+func (lhr ListHandlesResponse) ContentType() string {
+	return lhr.rawResponse.Header.Get("Content-Type")
+}
+
+// Date returns the value for header Date.
+func (lhr ListHandlesResponse) Date() time.Time {
+	s := lhr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (lhr ListHandlesResponse) ErrorCode() string {
+	return lhr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (lhr ListHandlesResponse) RequestID() string {
+	return lhr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (lhr ListHandlesResponse) Version() string {
+	return lhr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ListSharesResponse - An enumeration of shares.
+type ListSharesResponse struct {
+	rawResponse *http.Response
+	// XMLName is used for marshalling and is subject to removal in a future release.
+	XMLName         xml.Name    `xml:"EnumerationResults"`
+	ServiceEndpoint string      `xml:"ServiceEndpoint,attr"`
+	Prefix          *string     `xml:"Prefix"`
+	Marker          *string     `xml:"Marker"`
+	MaxResults      *int32      `xml:"MaxResults"`
+	ShareItems      []ShareItem `xml:"Shares>Share"`
+	NextMarker      Marker      `xml:"NextMarker"`
+}
+
+// Response returns the raw HTTP response object.
+func (lsr ListSharesResponse) Response() *http.Response {
+	return lsr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (lsr ListSharesResponse) StatusCode() int {
+	return lsr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (lsr ListSharesResponse) Status() string {
+	return lsr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (lsr ListSharesResponse) ClientRequestID() string {
+	return lsr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (lsr ListSharesResponse) ErrorCode() string {
+	return lsr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (lsr ListSharesResponse) RequestID() string {
+	return lsr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (lsr ListSharesResponse) Version() string {
+	return lsr.rawResponse.Header.Get("x-ms-version")
+}
+
+// Metrics - Storage Analytics metrics for file service.
+type Metrics struct {
+	// Version - The version of Storage Analytics to configure.
+	Version string `xml:"Version"`
+	// Enabled - Indicates whether metrics are enabled for the File service.
+	Enabled bool `xml:"Enabled"`
+	// IncludeAPIs - Indicates whether metrics should generate summary statistics for called API operations.
+	IncludeAPIs     *bool            `xml:"IncludeAPIs"`
+	RetentionPolicy *RetentionPolicy `xml:"RetentionPolicy"`
+}
+
+// Range - An Azure Storage file range.
+type Range struct {
+	// Start - Start of the range.
+	Start int64 `xml:"Start"`
+	// End - End of the range.
+	End int64 `xml:"End"`
+}
+
+// RangeDiff - Wraps the response from the fileClient.GetRangeListDiff method.
+type RangeDiff struct {
+	rawResponse *http.Response
+	// Ranges - The ranges written since the previous snapshot.
+	Ranges []Range `xml:"Range"`
+	// ClearRanges - The ranges cleared since the previous snapshot.
+	ClearRanges []Range `xml:"ClearRange"`
+}
+
+// Response returns the raw HTTP response object.
+func (rd RangeDiff) Response() *http.Response {
+	return rd.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (rd RangeDiff) StatusCode() int {
+	return rd.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (rd RangeDiff) Status() string {
+	return rd.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (rd RangeDiff) ClientRequestID() string {
+	return rd.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (rd RangeDiff) Date() time.Time {
+	s := rd.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (rd RangeDiff) ErrorCode() string {
+	return rd.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (rd RangeDiff) ETag() ETag {
+	return ETag(rd.rawResponse.Header.Get("ETag"))
+}
+
+// FileContentLength returns the value for header x-ms-content-length.
+func (rd RangeDiff) FileContentLength() int64 {
+	s := rd.rawResponse.Header.Get("x-ms-content-length")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		i = 0
+	}
+	return i
+}
+
+// LastModified returns the value for header Last-Modified.
+func (rd RangeDiff) LastModified() time.Time {
+	s := rd.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (rd RangeDiff) RequestID() string {
+	return rd.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (rd RangeDiff) Version() string {
+	return rd.rawResponse.Header.Get("x-ms-version")
+}
+
+// Ranges - Wraps the response from the fileClient.GetRangeList method.
+type Ranges struct {
+	rawResponse *http.Response
+	Items       []Range `xml:"Range"`
+}
+
+// Response returns the raw HTTP response object.
+func (r Ranges) Response() *http.Response {
+	return r.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (r Ranges) StatusCode() int {
+	return r.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (r Ranges) Status() string {
+	return r.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (r Ranges) ClientRequestID() string {
+	return r.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (r Ranges) Date() time.Time {
+	s := r.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (r Ranges) ErrorCode() string {
+	return r.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (r Ranges) ETag() ETag {
+	return ETag(r.rawResponse.Header.Get("ETag"))
+}
+
+// FileContentLength returns the value for header x-ms-content-length.
+func (r Ranges) FileContentLength() int64 {
+	s := r.rawResponse.Header.Get("x-ms-content-length")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		i = 0
+	}
+	return i
+}
+
+// LastModified returns the value for header Last-Modified.
+func (r Ranges) LastModified() time.Time {
+	s := r.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (r Ranges) RequestID() string {
+	return r.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (r Ranges) Version() string {
+	return r.rawResponse.Header.Get("x-ms-version")
+}
+
+// RetentionPolicy - The retention policy.
+type RetentionPolicy struct {
+	// Enabled - Indicates whether a retention policy is enabled for the File service. If false, metrics data is retained, and the user is responsible for deleting it.
+	Enabled bool `xml:"Enabled"`
+	// Days - Indicates the number of days that metrics data should be retained. All data older than this value will be deleted. Metrics data is deleted on a best-effort basis after the retention period expires.
+	Days *int32 `xml:"Days"`
+}
+
+// ServiceSetPropertiesResponse ...
 type ServiceSetPropertiesResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (sspr ServiceSetPropertiesResponse) Response() *http.Response {
-	return sspr.rawResponse
+func (sspr ServiceSetPropertiesResponse) Response() *http.Response {
+	return sspr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (sspr ServiceSetPropertiesResponse) StatusCode() int {
+	return sspr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (sspr ServiceSetPropertiesResponse) Status() string {
+	return sspr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sspr ServiceSetPropertiesResponse) ClientRequestID() string {
+	return sspr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (sspr ServiceSetPropertiesResponse) ErrorCode() string {
+	return sspr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (sspr ServiceSetPropertiesResponse) RequestID() string {
+	return sspr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (sspr ServiceSetPropertiesResponse) Version() string {
+	return sspr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareAcquireLeaseResponse ...
+type ShareAcquireLeaseResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (salr ShareAcquireLeaseResponse) Response() *http.Response {
+	return salr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (salr ShareAcquireLeaseResponse) StatusCode() int {
+	return salr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (salr ShareAcquireLeaseResponse) Status() string {
+	return salr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (salr ShareAcquireLeaseResponse) ClientRequestID() string {
+	return salr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (salr ShareAcquireLeaseResponse) Date() time.Time {
+	s := salr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (salr ShareAcquireLeaseResponse) ETag() ETag {
+	return ETag(salr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (salr ShareAcquireLeaseResponse) ErrorCode() string {
+	return salr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (salr ShareAcquireLeaseResponse) LastModified() time.Time {
+	s := salr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseID returns the value for header x-ms-lease-id.
+func (salr ShareAcquireLeaseResponse) LeaseID() string {
+	return salr.rawResponse.Header.Get("x-ms-lease-id")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (salr ShareAcquireLeaseResponse) RequestID() string {
+	return salr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (salr ShareAcquireLeaseResponse) Version() string {
+	return salr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareBreakLeaseResponse ...
+type ShareBreakLeaseResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (sblr ShareBreakLeaseResponse) Response() *http.Response {
+	return sblr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (sblr ShareBreakLeaseResponse) StatusCode() int {
+	return sblr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (sblr ShareBreakLeaseResponse) Status() string {
+	return sblr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sblr ShareBreakLeaseResponse) ClientRequestID() string {
+	return sblr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (sblr ShareBreakLeaseResponse) Date() time.Time {
+	s := sblr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (sblr ShareBreakLeaseResponse) ETag() ETag {
+	return ETag(sblr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (sblr ShareBreakLeaseResponse) ErrorCode() string {
+	return sblr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (sblr ShareBreakLeaseResponse) LastModified() time.Time {
+	s := sblr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseID returns the value for header x-ms-lease-id.
+func (sblr ShareBreakLeaseResponse) LeaseID() string {
+	return sblr.rawResponse.Header.Get("x-ms-lease-id")
+}
+
+// LeaseTime returns the value for header x-ms-lease-time.
+func (sblr ShareBreakLeaseResponse) LeaseTime() int32 {
+	s := sblr.rawResponse.Header.Get("x-ms-lease-time")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (sblr ShareBreakLeaseResponse) RequestID() string {
+	return sblr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (sblr ShareBreakLeaseResponse) Version() string {
+	return sblr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareChangeLeaseResponse ...
+type ShareChangeLeaseResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (sclr ShareChangeLeaseResponse) Response() *http.Response {
+	return sclr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (sclr ShareChangeLeaseResponse) StatusCode() int {
+	return sclr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (sclr ShareChangeLeaseResponse) Status() string {
+	return sclr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sclr ShareChangeLeaseResponse) ClientRequestID() string {
+	return sclr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (sclr ShareChangeLeaseResponse) Date() time.Time {
+	s := sclr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (sclr ShareChangeLeaseResponse) ETag() ETag {
+	return ETag(sclr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (sclr ShareChangeLeaseResponse) ErrorCode() string {
+	return sclr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (sclr ShareChangeLeaseResponse) LastModified() time.Time {
+	s := sclr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseID returns the value for header x-ms-lease-id.
+func (sclr ShareChangeLeaseResponse) LeaseID() string {
+	return sclr.rawResponse.Header.Get("x-ms-lease-id")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (sclr ShareChangeLeaseResponse) RequestID() string {
+	return sclr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (sclr ShareChangeLeaseResponse) Version() string {
+	return sclr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareCreatePermissionResponse ...
+type ShareCreatePermissionResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (scpr ShareCreatePermissionResponse) Response() *http.Response {
+	return scpr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (scpr ShareCreatePermissionResponse) StatusCode() int {
+	return scpr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (scpr ShareCreatePermissionResponse) Status() string {
+	return scpr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (scpr ShareCreatePermissionResponse) ClientRequestID() string {
+	return scpr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (scpr ShareCreatePermissionResponse) Date() time.Time {
+	s := scpr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (scpr ShareCreatePermissionResponse) ErrorCode() string {
+	return scpr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (scpr ShareCreatePermissionResponse) FilePermissionKey() string {
+	return scpr.rawResponse.Header.Get("x-ms-file-permission-key")
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (scpr ShareCreatePermissionResponse) RequestID() string {
+	return scpr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (scpr ShareCreatePermissionResponse) Version() string {
+	return scpr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareCreateResponse ...
+type ShareCreateResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (scr ShareCreateResponse) Response() *http.Response {
+	return scr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (scr ShareCreateResponse) StatusCode() int {
+	return scr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (scr ShareCreateResponse) Status() string {
+	return scr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (scr ShareCreateResponse) ClientRequestID() string {
+	return scr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (scr ShareCreateResponse) Date() time.Time {
+	s := scr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (scr ShareCreateResponse) ErrorCode() string {
+	return scr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (scr ShareCreateResponse) ETag() ETag {
+	return ETag(scr.rawResponse.Header.Get("ETag"))
+}
+
+// LastModified returns the value for header Last-Modified.
+func (scr ShareCreateResponse) LastModified() time.Time {
+	s := scr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (scr ShareCreateResponse) RequestID() string {
+	return scr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (scr ShareCreateResponse) Version() string {
+	return scr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareCreateSnapshotResponse ...
+type ShareCreateSnapshotResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (scsr ShareCreateSnapshotResponse) Response() *http.Response {
+	return scsr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (scsr ShareCreateSnapshotResponse) StatusCode() int {
+	return scsr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (scsr ShareCreateSnapshotResponse) Status() string {
+	return scsr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (scsr ShareCreateSnapshotResponse) ClientRequestID() string {
+	return scsr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (scsr ShareCreateSnapshotResponse) Date() time.Time {
+	s := scsr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (scsr ShareCreateSnapshotResponse) ErrorCode() string {
+	return scsr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (scsr ShareCreateSnapshotResponse) ETag() ETag {
+	return ETag(scsr.rawResponse.Header.Get("ETag"))
+}
+
+// LastModified returns the value for header Last-Modified.
+func (scsr ShareCreateSnapshotResponse) LastModified() time.Time {
+	s := scsr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (scsr ShareCreateSnapshotResponse) RequestID() string {
+	return scsr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Snapshot returns the value for header x-ms-snapshot.
+func (scsr ShareCreateSnapshotResponse) Snapshot() string {
+	return scsr.rawResponse.Header.Get("x-ms-snapshot")
+}
+
+// Version returns the value for header x-ms-version.
+func (scsr ShareCreateSnapshotResponse) Version() string {
+	return scsr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareDeleteResponse ...
+type ShareDeleteResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (sdr ShareDeleteResponse) Response() *http.Response {
+	return sdr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (sspr ServiceSetPropertiesResponse) StatusCode() int {
-	return sspr.rawResponse.StatusCode
+func (sdr ShareDeleteResponse) StatusCode() int {
+	return sdr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (sspr ServiceSetPropertiesResponse) Status() string {
-	return sspr.rawResponse.Status
+func (sdr ShareDeleteResponse) Status() string {
+	return sdr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sdr ShareDeleteResponse) ClientRequestID() string {
+	return sdr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (sdr ShareDeleteResponse) Date() time.Time {
+	s := sdr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
 }
 
 // ErrorCode returns the value for header x-ms-error-code.
-func (sspr ServiceSetPropertiesResponse) ErrorCode() string {
-	return sspr.rawResponse.Header.Get("x-ms-error-code")
+func (sdr ShareDeleteResponse) ErrorCode() string {
+	return sdr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (sspr ServiceSetPropertiesResponse) RequestID() string {
-	return sspr.rawResponse.Header.Get("x-ms-request-id")
+func (sdr ShareDeleteResponse) RequestID() string {
+	return sdr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (sspr ServiceSetPropertiesResponse) Version() string {
-	return sspr.rawResponse.Header.Get("x-ms-version")
+func (sdr ShareDeleteResponse) Version() string {
+	return sdr.rawResponse.Header.Get("x-ms-version")
 }
 
-// ShareCreateResponse ...
-type ShareCreateResponse struct {
+// ShareGetPropertiesResponse ...
+type ShareGetPropertiesResponse struct {
 	rawResponse *http.Response
 }
 
+// NewMetadata returns user-defined key/value pairs.
+func (sgpr ShareGetPropertiesResponse) NewMetadata() Metadata {
+	md := Metadata{}
+	for k, v := range sgpr.rawResponse.Header {
+		if len(k) > mdPrefixLen {
+			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
+				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
+			}
+		}
+	}
+	return md
+}
+
 // Response returns the raw HTTP response object.
-func (scr ShareCreateResponse) Response() *http.Response {
-	return scr.rawResponse
+func (sgpr ShareGetPropertiesResponse) Response() *http.Response {
+	return sgpr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (scr ShareCreateResponse) StatusCode() int {
-	return scr.rawResponse.StatusCode
+func (sgpr ShareGetPropertiesResponse) StatusCode() int {
+	return sgpr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (scr ShareCreateResponse) Status() string {
-	return scr.rawResponse.Status
+func (sgpr ShareGetPropertiesResponse) Status() string {
+	return sgpr.rawResponse.Status
+}
+
+// AccessTier returns the value for header x-ms-access-tier.
+func (sgpr ShareGetPropertiesResponse) AccessTier() string {
+	return sgpr.rawResponse.Header.Get("x-ms-access-tier")
+}
+
+// AccessTierChangeTime returns the value for header x-ms-access-tier-change-time.
+func (sgpr ShareGetPropertiesResponse) AccessTierChangeTime() time.Time {
+	s := sgpr.rawResponse.Header.Get("x-ms-access-tier-change-time")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// AccessTierTransitionState returns the value for header x-ms-access-tier-transition-state.
+func (sgpr ShareGetPropertiesResponse) AccessTierTransitionState() string {
+	return sgpr.rawResponse.Header.Get("x-ms-access-tier-transition-state")
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sgpr ShareGetPropertiesResponse) ClientRequestID() string {
+	return sgpr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (scr ShareCreateResponse) Date() time.Time {
-	s := scr.rawResponse.Header.Get("Date")
+func (sgpr ShareGetPropertiesResponse) Date() time.Time {
+	s := sgpr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1526,19 +3789,226 @@ func (scr ShareCreateResponse) Date() time.Time {
 	return t
 }
 
+// EnabledProtocols returns the value for header x-ms-enabled-protocols.
+func (sgpr ShareGetPropertiesResponse) EnabledProtocols() string {
+	return sgpr.rawResponse.Header.Get("x-ms-enabled-protocols")
+}
+
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (sgpr ShareGetPropertiesResponse) EncryptionScope() string {
+	return sgpr.rawResponse.Header.Get("x-ms-encryption-scope")
+}
+
 // ErrorCode returns the value for header x-ms-error-code.
-func (scr ShareCreateResponse) ErrorCode() string {
-	return scr.rawResponse.Header.Get("x-ms-error-code")
+func (sgpr ShareGetPropertiesResponse) ErrorCode() string {
+	return sgpr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// ETag returns the value for header ETag.
+func (sgpr ShareGetPropertiesResponse) ETag() ETag {
+	return ETag(sgpr.rawResponse.Header.Get("ETag"))
+}
+
+// IncludedBurstIops returns the value for header x-ms-share-included-burst-iops.
+func (sgpr ShareGetPropertiesResponse) IncludedBurstIops() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-included-burst-iops")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// LastModified returns the value for header Last-Modified.
+func (sgpr ShareGetPropertiesResponse) LastModified() time.Time {
+	s := sgpr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// MaxBurstCreditsForIops returns the value for header x-ms-share-max-burst-credits-for-iops.
+func (sgpr ShareGetPropertiesResponse) MaxBurstCreditsForIops() int64 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-max-burst-credits-for-iops")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		i = 0
+	}
+	return i
+}
+
+// NextAllowedProvisionedBandwidthDowngradeTime returns the value for header x-ms-share-next-allowed-provisioned-bandwidth-downgrade-time.
+func (sgpr ShareGetPropertiesResponse) NextAllowedProvisionedBandwidthDowngradeTime() time.Time {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-next-allowed-provisioned-bandwidth-downgrade-time")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// NextAllowedProvisionedIopsDowngradeTime returns the value for header x-ms-share-next-allowed-provisioned-iops-downgrade-time.
+func (sgpr ShareGetPropertiesResponse) NextAllowedProvisionedIopsDowngradeTime() time.Time {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-next-allowed-provisioned-iops-downgrade-time")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// NextAllowedQuotaDowngradeTime returns the value for header x-ms-share-next-allowed-quota-downgrade-time.
+func (sgpr ShareGetPropertiesResponse) NextAllowedQuotaDowngradeTime() time.Time {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-next-allowed-quota-downgrade-time")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ProvisionedBandwidthMibps returns the value for header x-ms-share-provisioned-bandwidth-mibps.
+func (sgpr ShareGetPropertiesResponse) ProvisionedBandwidthMibps() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-provisioned-bandwidth-mibps")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// ProvisionedEgressMBps returns the value for header x-ms-share-provisioned-egress-mbps.
+func (sgpr ShareGetPropertiesResponse) ProvisionedEgressMBps() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-provisioned-egress-mbps")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// ProvisionedIngressMBps returns the value for header x-ms-share-provisioned-ingress-mbps.
+func (sgpr ShareGetPropertiesResponse) ProvisionedIngressMBps() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-provisioned-ingress-mbps")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// ProvisionedIops returns the value for header x-ms-share-provisioned-iops.
+func (sgpr ShareGetPropertiesResponse) ProvisionedIops() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-provisioned-iops")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// Quota returns the value for header x-ms-share-quota.
+func (sgpr ShareGetPropertiesResponse) Quota() int32 {
+	s := sgpr.rawResponse.Header.Get("x-ms-share-quota")
+	if s == "" {
+		return -1
+	}
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		i = 0
+	}
+	return int32(i)
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (sgpr ShareGetPropertiesResponse) RequestID() string {
+	return sgpr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// RootSquash returns the value for header x-ms-root-squash.
+func (sgpr ShareGetPropertiesResponse) RootSquash() string {
+	return sgpr.rawResponse.Header.Get("x-ms-root-squash")
+}
+
+// Version returns the value for header x-ms-version.
+func (sgpr ShareGetPropertiesResponse) Version() string {
+	return sgpr.rawResponse.Header.Get("x-ms-version")
+}
+
+// ShareItem - A listed Azure Storage share item.
+type ShareItem struct {
+	// XMLName is used for marshalling and is subject to removal in a future release.
+	XMLName    xml.Name        `xml:"Share"`
+	Name       string          `xml:"Name"`
+	Snapshot   *string         `xml:"Snapshot"`
+	Deleted    *bool           `xml:"Deleted"`
+	Version    *string         `xml:"Version"`
+	Properties ShareProperties `xml:"Properties"`
+	Metadata   Metadata        `xml:"Metadata"`
+}
+
+// SharePermission - A permission (a security descriptor) at the share level.
+type SharePermission struct {
+	rawResponse *http.Response
+	// Permission - The permission in the Security Descriptor Definition Language (SDDL).
+	Permission string `json:"permission"`
+}
+
+// Response returns the raw HTTP response object.
+func (sp SharePermission) Response() *http.Response {
+	return sp.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (sp SharePermission) StatusCode() int {
+	return sp.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (sp SharePermission) Status() string {
+	return sp.rawResponse.Status
 }
 
-// ETag returns the value for header ETag.
-func (scr ShareCreateResponse) ETag() ETag {
-	return ETag(scr.rawResponse.Header.Get("ETag"))
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sp SharePermission) ClientRequestID() string {
+	return sp.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
-// LastModified returns the value for header Last-Modified.
-func (scr ShareCreateResponse) LastModified() time.Time {
-	s := scr.rawResponse.Header.Get("Last-Modified")
+// Date returns the value for header Date.
+func (sp SharePermission) Date() time.Time {
+	s := sp.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1549,39 +4019,78 @@ func (scr ShareCreateResponse) LastModified() time.Time {
 	return t
 }
 
+// ErrorCode returns the value for header x-ms-error-code.
+func (sp SharePermission) ErrorCode() string {
+	return sp.rawResponse.Header.Get("x-ms-error-code")
+}
+
 // RequestID returns the value for header x-ms-request-id.
-func (scr ShareCreateResponse) RequestID() string {
-	return scr.rawResponse.Header.Get("x-ms-request-id")
+func (sp SharePermission) RequestID() string {
+	return sp.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (scr ShareCreateResponse) Version() string {
-	return scr.rawResponse.Header.Get("x-ms-version")
+func (sp SharePermission) Version() string {
+	return sp.rawResponse.Header.Get("x-ms-version")
 }
 
-// ShareCreateSnapshotResponse ...
-type ShareCreateSnapshotResponse struct {
+// ShareProperties - Properties of a share.
+type ShareProperties struct {
+	LastModified           time.Time                 `xml:"Last-Modified"`
+	Etag                   ETag                      `xml:"Etag"`
+	Quota                  int32                     `xml:"Quota"`
+	DeletedTime            *time.Time                `xml:"DeletedTime"`
+	RemainingRetentionDays *int32                    `xml:"RemainingRetentionDays"`
+	EnabledProtocols       ShareEnabledProtocolsType `xml:"EnabledProtocols"`
+	RootSquash             ShareRootSquashType       `xml:"RootSquash"`
+}
+
+// MarshalXML implements the xml.Marshaler interface for ShareProperties.
+func (sp ShareProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	sp2 := (*shareProperties)(unsafe.Pointer(&sp))
+	return e.EncodeElement(*sp2, start)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface for ShareProperties.
+func (sp *ShareProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	sp2 := (*shareProperties)(unsafe.Pointer(sp))
+	return d.DecodeElement(sp2, &start)
+}
+
+// ShareProtocolSettings - Protocol settings
+type ShareProtocolSettings struct {
+	// Smb - Settings for SMB protocol.
+	Smb *ShareSmbSettings `xml:"SMB"`
+}
+
+// ShareReleaseLeaseResponse ...
+type ShareReleaseLeaseResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (scsr ShareCreateSnapshotResponse) Response() *http.Response {
-	return scsr.rawResponse
+func (srlr ShareReleaseLeaseResponse) Response() *http.Response {
+	return srlr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (scsr ShareCreateSnapshotResponse) StatusCode() int {
-	return scsr.rawResponse.StatusCode
+func (srlr ShareReleaseLeaseResponse) StatusCode() int {
+	return srlr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (scsr ShareCreateSnapshotResponse) Status() string {
-	return scsr.rawResponse.Status
+func (srlr ShareReleaseLeaseResponse) Status() string {
+	return srlr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (srlr ShareReleaseLeaseResponse) ClientRequestID() string {
+	return srlr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (scsr ShareCreateSnapshotResponse) Date() time.Time {
-	s := scsr.rawResponse.Header.Get("Date")
+func (srlr ShareReleaseLeaseResponse) Date() time.Time {
+	s := srlr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1592,19 +4101,19 @@ func (scsr ShareCreateSnapshotResponse) Date() time.Time {
 	return t
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (scsr ShareCreateSnapshotResponse) ErrorCode() string {
-	return scsr.rawResponse.Header.Get("x-ms-error-code")
+// ETag returns the value for header ETag.
+func (srlr ShareReleaseLeaseResponse) ETag() ETag {
+	return ETag(srlr.rawResponse.Header.Get("ETag"))
 }
 
-// ETag returns the value for header ETag.
-func (scsr ShareCreateSnapshotResponse) ETag() ETag {
-	return ETag(scsr.rawResponse.Header.Get("ETag"))
+// ErrorCode returns the value for header x-ms-error-code.
+func (srlr ShareReleaseLeaseResponse) ErrorCode() string {
+	return srlr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // LastModified returns the value for header Last-Modified.
-func (scsr ShareCreateSnapshotResponse) LastModified() time.Time {
-	s := scsr.rawResponse.Header.Get("Last-Modified")
+func (srlr ShareReleaseLeaseResponse) LastModified() time.Time {
+	s := srlr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1616,43 +4125,43 @@ func (scsr ShareCreateSnapshotResponse) LastModified() time.Time {
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (scsr ShareCreateSnapshotResponse) RequestID() string {
-	return scsr.rawResponse.Header.Get("x-ms-request-id")
-}
-
-// Snapshot returns the value for header x-ms-snapshot.
-func (scsr ShareCreateSnapshotResponse) Snapshot() string {
-	return scsr.rawResponse.Header.Get("x-ms-snapshot")
+func (srlr ShareReleaseLeaseResponse) RequestID() string {
+	return srlr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (scsr ShareCreateSnapshotResponse) Version() string {
-	return scsr.rawResponse.Header.Get("x-ms-version")
+func (srlr ShareReleaseLeaseResponse) Version() string {
+	return srlr.rawResponse.Header.Get("x-ms-version")
 }
 
-// ShareDeleteResponse ...
-type ShareDeleteResponse struct {
+// ShareRenewLeaseResponse ...
+type ShareRenewLeaseResponse struct {
 	rawResponse *http.Response
 }
 
 // Response returns the raw HTTP response object.
-func (sdr ShareDeleteResponse) Response() *http.Response {
-	return sdr.rawResponse
+func (srlr ShareRenewLeaseResponse) Response() *http.Response {
+	return srlr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (sdr ShareDeleteResponse) StatusCode() int {
-	return sdr.rawResponse.StatusCode
+func (srlr ShareRenewLeaseResponse) StatusCode() int {
+	return srlr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (sdr ShareDeleteResponse) Status() string {
-	return sdr.rawResponse.Status
+func (srlr ShareRenewLeaseResponse) Status() string {
+	return srlr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (srlr ShareRenewLeaseResponse) ClientRequestID() string {
+	return srlr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (sdr ShareDeleteResponse) Date() time.Time {
-	s := sdr.rawResponse.Header.Get("Date")
+func (srlr ShareRenewLeaseResponse) Date() time.Time {
+	s := srlr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1663,57 +4172,72 @@ func (sdr ShareDeleteResponse) Date() time.Time {
 	return t
 }
 
+// ETag returns the value for header ETag.
+func (srlr ShareRenewLeaseResponse) ETag() ETag {
+	return ETag(srlr.rawResponse.Header.Get("ETag"))
+}
+
 // ErrorCode returns the value for header x-ms-error-code.
-func (sdr ShareDeleteResponse) ErrorCode() string {
-	return sdr.rawResponse.Header.Get("x-ms-error-code")
+func (srlr ShareRenewLeaseResponse) ErrorCode() string {
+	return srlr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (srlr ShareRenewLeaseResponse) LastModified() time.Time {
+	s := srlr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// LeaseID returns the value for header x-ms-lease-id.
+func (srlr ShareRenewLeaseResponse) LeaseID() string {
+	return srlr.rawResponse.Header.Get("x-ms-lease-id")
 }
 
 // RequestID returns the value for header x-ms-request-id.
-func (sdr ShareDeleteResponse) RequestID() string {
-	return sdr.rawResponse.Header.Get("x-ms-request-id")
+func (srlr ShareRenewLeaseResponse) RequestID() string {
+	return srlr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (sdr ShareDeleteResponse) Version() string {
-	return sdr.rawResponse.Header.Get("x-ms-version")
+func (srlr ShareRenewLeaseResponse) Version() string {
+	return srlr.rawResponse.Header.Get("x-ms-version")
 }
 
-// ShareGetPropertiesResponse ...
-type ShareGetPropertiesResponse struct {
+// ShareRestoreResponse ...
+type ShareRestoreResponse struct {
 	rawResponse *http.Response
 }
 
-// NewMetadata returns user-defined key/value pairs.
-func (sgpr ShareGetPropertiesResponse) NewMetadata() Metadata {
-	md := Metadata{}
-	for k, v := range sgpr.rawResponse.Header {
-		if len(k) > mdPrefixLen {
-			if prefix := k[0:mdPrefixLen]; strings.EqualFold(prefix, mdPrefix) {
-				md[strings.ToLower(k[mdPrefixLen:])] = v[0]
-			}
-		}
-	}
-	return md
-}
-
 // Response returns the raw HTTP response object.
-func (sgpr ShareGetPropertiesResponse) Response() *http.Response {
-	return sgpr.rawResponse
+func (srr ShareRestoreResponse) Response() *http.Response {
+	return srr.rawResponse
 }
 
 // StatusCode returns the HTTP status code of the response, e.g. 200.
-func (sgpr ShareGetPropertiesResponse) StatusCode() int {
-	return sgpr.rawResponse.StatusCode
+func (srr ShareRestoreResponse) StatusCode() int {
+	return srr.rawResponse.StatusCode
 }
 
 // Status returns the HTTP status message of the response, e.g. "200 OK".
-func (sgpr ShareGetPropertiesResponse) Status() string {
-	return sgpr.rawResponse.Status
+func (srr ShareRestoreResponse) Status() string {
+	return srr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (srr ShareRestoreResponse) ClientRequestID() string {
+	return srr.rawResponse.Header.Get("x-ms-client-request-id")
 }
 
 // Date returns the value for header Date.
-func (sgpr ShareGetPropertiesResponse) Date() time.Time {
-	s := sgpr.rawResponse.Header.Get("Date")
+func (srr ShareRestoreResponse) Date() time.Time {
+	s := srr.rawResponse.Header.Get("Date")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1724,19 +4248,19 @@ func (sgpr ShareGetPropertiesResponse) Date() time.Time {
 	return t
 }
 
-// ErrorCode returns the value for header x-ms-error-code.
-func (sgpr ShareGetPropertiesResponse) ErrorCode() string {
-	return sgpr.rawResponse.Header.Get("x-ms-error-code")
+// ETag returns the value for header ETag.
+func (srr ShareRestoreResponse) ETag() ETag {
+	return ETag(srr.rawResponse.Header.Get("ETag"))
 }
 
-// ETag returns the value for header ETag.
-func (sgpr ShareGetPropertiesResponse) ETag() ETag {
-	return ETag(sgpr.rawResponse.Header.Get("ETag"))
+// ErrorCode returns the value for header x-ms-error-code.
+func (srr ShareRestoreResponse) ErrorCode() string {
+	return srr.rawResponse.Header.Get("x-ms-error-code")
 }
 
 // LastModified returns the value for header Last-Modified.
-func (sgpr ShareGetPropertiesResponse) LastModified() time.Time {
-	s := sgpr.rawResponse.Header.Get("Last-Modified")
+func (srr ShareRestoreResponse) LastModified() time.Time {
+	s := srr.rawResponse.Header.Get("Last-Modified")
 	if s == "" {
 		return time.Time{}
 	}
@@ -1747,56 +4271,14 @@ func (sgpr ShareGetPropertiesResponse) LastModified() time.Time {
 	return t
 }
 
-// Quota returns the value for header x-ms-share-quota.
-func (sgpr ShareGetPropertiesResponse) Quota() int32 {
-	s := sgpr.rawResponse.Header.Get("x-ms-share-quota")
-	if s == "" {
-		return -1
-	}
-	i, err := strconv.ParseInt(s, 10, 32)
-	if err != nil {
-		i = 0
-	}
-	return int32(i)
-}
-
 // RequestID returns the value for header x-ms-request-id.
-func (sgpr ShareGetPropertiesResponse) RequestID() string {
-	return sgpr.rawResponse.Header.Get("x-ms-request-id")
+func (srr ShareRestoreResponse) RequestID() string {
+	return srr.rawResponse.Header.Get("x-ms-request-id")
 }
 
 // Version returns the value for header x-ms-version.
-func (sgpr ShareGetPropertiesResponse) Version() string {
-	return sgpr.rawResponse.Header.Get("x-ms-version")
-}
-
-// ShareItem - A listed Azure Storage share item.
-type ShareItem struct {
-	// XMLName is used for marshalling and is subject to removal in a future release.
-	XMLName    xml.Name        `xml:"Share"`
-	Name       string          `xml:"Name"`
-	Snapshot   *string         `xml:"Snapshot"`
-	Properties ShareProperties `xml:"Properties"`
-	Metadata   Metadata        `xml:"Metadata"`
-}
-
-// ShareProperties - Properties of a share.
-type ShareProperties struct {
-	LastModified time.Time `xml:"Last-Modified"`
-	Etag         ETag      `xml:"Etag"`
-	Quota        int32     `xml:"Quota"`
-}
-
-// MarshalXML implements the xml.Marshaler interface for ShareProperties.
-func (sp ShareProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	sp2 := (*shareProperties)(unsafe.Pointer(&sp))
-	return e.EncodeElement(*sp2, start)
-}
-
-// UnmarshalXML implements the xml.Unmarshaler interface for ShareProperties.
-func (sp *ShareProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	sp2 := (*shareProperties)(unsafe.Pointer(sp))
-	return d.DecodeElement(sp2, &start)
+func (srr ShareRestoreResponse) Version() string {
+	return srr.rawResponse.Header.Get("x-ms-version")
 }
 
 // ShareSetAccessPolicyResponse ...
@@ -1819,6 +4301,11 @@ func (ssapr ShareSetAccessPolicyResponse) Status() string {
 	return ssapr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ssapr ShareSetAccessPolicyResponse) ClientRequestID() string {
+	return ssapr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (ssapr ShareSetAccessPolicyResponse) Date() time.Time {
 	s := ssapr.rawResponse.Header.Get("Date")
@@ -1885,6 +4372,11 @@ func (ssmr ShareSetMetadataResponse) Status() string {
 	return ssmr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ssmr ShareSetMetadataResponse) ClientRequestID() string {
+	return ssmr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (ssmr ShareSetMetadataResponse) Date() time.Time {
 	s := ssmr.rawResponse.Header.Get("Date")
@@ -1931,6 +4423,77 @@ func (ssmr ShareSetMetadataResponse) Version() string {
 	return ssmr.rawResponse.Header.Get("x-ms-version")
 }
 
+// ShareSetPropertiesResponse ...
+type ShareSetPropertiesResponse struct {
+	rawResponse *http.Response
+}
+
+// Response returns the raw HTTP response object.
+func (sspr ShareSetPropertiesResponse) Response() *http.Response {
+	return sspr.rawResponse
+}
+
+// StatusCode returns the HTTP status code of the response, e.g. 200.
+func (sspr ShareSetPropertiesResponse) StatusCode() int {
+	return sspr.rawResponse.StatusCode
+}
+
+// Status returns the HTTP status message of the response, e.g. "200 OK".
+func (sspr ShareSetPropertiesResponse) Status() string {
+	return sspr.rawResponse.Status
+}
+
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (sspr ShareSetPropertiesResponse) ClientRequestID() string {
+	return sspr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
+// Date returns the value for header Date.
+func (sspr ShareSetPropertiesResponse) Date() time.Time {
+	s := sspr.rawResponse.Header.Get("Date")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// ETag returns the value for header ETag.
+func (sspr ShareSetPropertiesResponse) ETag() ETag {
+	return ETag(sspr.rawResponse.Header.Get("ETag"))
+}
+
+// ErrorCode returns the value for header x-ms-error-code.
+func (sspr ShareSetPropertiesResponse) ErrorCode() string {
+	return sspr.rawResponse.Header.Get("x-ms-error-code")
+}
+
+// LastModified returns the value for header Last-Modified.
+func (sspr ShareSetPropertiesResponse) LastModified() time.Time {
+	s := sspr.rawResponse.Header.Get("Last-Modified")
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC1123, s)
+	if err != nil {
+		t = time.Time{}
+	}
+	return t
+}
+
+// RequestID returns the value for header x-ms-request-id.
+func (sspr ShareSetPropertiesResponse) RequestID() string {
+	return sspr.rawResponse.Header.Get("x-ms-request-id")
+}
+
+// Version returns the value for header x-ms-version.
+func (sspr ShareSetPropertiesResponse) Version() string {
+	return sspr.rawResponse.Header.Get("x-ms-version")
+}
+
 // ShareSetQuotaResponse ...
 type ShareSetQuotaResponse struct {
 	rawResponse *http.Response
@@ -1951,6 +4514,11 @@ func (ssqr ShareSetQuotaResponse) Status() string {
 	return ssqr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ssqr ShareSetQuotaResponse) ClientRequestID() string {
+	return ssqr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (ssqr ShareSetQuotaResponse) Date() time.Time {
 	s := ssqr.rawResponse.Header.Get("Date")
@@ -1997,11 +4565,19 @@ func (ssqr ShareSetQuotaResponse) Version() string {
 	return ssqr.rawResponse.Header.Get("x-ms-version")
 }
 
+// ShareSmbSettings - Settings for SMB protocol.
+type ShareSmbSettings struct {
+	// Multichannel - Settings for SMB Multichannel.
+	Multichannel *SmbMultichannel `xml:"Multichannel"`
+}
+
 // ShareStats - Stats for the share.
 type ShareStats struct {
 	rawResponse *http.Response
 	// ShareUsage - The approximate size of the data stored on the share, rounded up to the nearest gigabyte. Note that this value may not include all recently created or recently resized files.
 	ShareUsage int32 `xml:"ShareUsage"`
+	// ShareUsageBytes - The approximate size of the data stored in bytes. Note that this value may not include all recently created or recently resized files.
+	ShareUsageBytes int64 `xml:"ShareUsageBytes"`
 }
 
 // Response returns the raw HTTP response object.
@@ -2019,6 +4595,11 @@ func (ss ShareStats) Status() string {
 	return ss.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ss ShareStats) ClientRequestID() string {
+	return ss.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (ss ShareStats) Date() time.Time {
 	s := ss.rawResponse.Header.Get("Date")
@@ -2094,6 +4675,11 @@ func (si SignedIdentifiers) Status() string {
 	return si.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (si SignedIdentifiers) ClientRequestID() string {
+	return si.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // Date returns the value for header Date.
 func (si SignedIdentifiers) Date() time.Time {
 	s := si.rawResponse.Header.Get("Date")
@@ -2145,6 +4731,12 @@ func (si SignedIdentifiers) Version() string {
 // 	Message *string `xml:"Message"`
 // }
 
+// SmbMultichannel - Settings for SMB multichannel
+type SmbMultichannel struct {
+	// Enabled - If SMB multichannel is enabled.
+	Enabled *bool `xml:"Enabled"`
+}
+
 // StorageServiceProperties - Storage service properties.
 type StorageServiceProperties struct {
 	rawResponse *http.Response
@@ -2154,6 +4746,8 @@ type StorageServiceProperties struct {
 	MinuteMetrics *Metrics `xml:"MinuteMetrics"`
 	// Cors - The set of CORS rules.
 	Cors []CorsRule `xml:"Cors>CorsRule"`
+	// Protocol - Protocol settings
+	Protocol *ShareProtocolSettings `xml:"ProtocolSettings"`
 }
 
 // Response returns the raw HTTP response object.
@@ -2171,6 +4765,11 @@ func (ssp StorageServiceProperties) Status() string {
 	return ssp.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ssp StorageServiceProperties) ClientRequestID() string {
+	return ssp.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // ErrorCode returns the value for header x-ms-error-code.
 func (ssp StorageServiceProperties) ErrorCode() string {
 	return ssp.rawResponse.Header.Get("x-ms-error-code")
@@ -2190,6 +4789,12 @@ func init() {
 	if reflect.TypeOf((*AccessPolicy)(nil)).Elem().Size() != reflect.TypeOf((*accessPolicy)(nil)).Elem().Size() {
 		validateError(errors.New("size mismatch between AccessPolicy and accessPolicy"))
 	}
+	if reflect.TypeOf((*FileProperty)(nil)).Elem().Size() != reflect.TypeOf((*fileProperty)(nil)).Elem().Size() {
+		validateError(errors.New("size mismatch between FileProperty and fileProperty"))
+	}
+	if reflect.TypeOf((*HandleItem)(nil)).Elem().Size() != reflect.TypeOf((*handleItem)(nil)).Elem().Size() {
+		validateError(errors.New("size mismatch between HandleItem and handleItem"))
+	}
 	if reflect.TypeOf((*ShareProperties)(nil)).Elem().Size() != reflect.TypeOf((*shareProperties)(nil)).Elem().Size() {
 		validateError(errors.New("size mismatch between ShareProperties and shareProperties"))
 	}
@@ -2241,9 +4846,40 @@ type accessPolicy struct {
 	Permission *string      `xml:"Permission"`
 }
 
+// internal type used for marshalling
+type fileProperty struct {
+	ContentLength          int64        `xml:"Content-Length"`
+	CreationTime           *timeRFC3339 `xml:"CreationTime"`
+	LastAccessTime         *timeRFC3339 `xml:"LastAccessTime"`
+	LastWriteTime          *timeRFC3339 `xml:"LastWriteTime"`
+	ChangeTime             *timeRFC3339 `xml:"ChangeTime"`
+	LastModified           *timeRFC1123 `xml:"Last-Modified"`
+	Etag                   *ETag        `xml:"Etag"`
+	DeletedTime            *timeRFC1123 `xml:"DeletedTime"`
+	RemainingRetentionDays *int32       `xml:"RemainingRetentionDays"`
+}
+
+// internal type used for marshalling
+type handleItem struct {
+	// XMLName is used for marshalling and is subject to removal in a future release.
+	XMLName           xml.Name     `xml:"Handle"`
+	HandleID          string       `xml:"HandleId"`
+	Path              string       `xml:"Path"`
+	FileID            string       `xml:"FileId"`
+	ParentID          *string      `xml:"ParentId"`
+	SessionID         string       `xml:"SessionId"`
+	ClientIP          string       `xml:"ClientIp"`
+	OpenTime          timeRFC1123  `xml:"OpenTime"`
+	LastReconnectTime *timeRFC1123 `xml:"LastReconnectTime"`
+}
+
 // internal type used for marshalling
 type shareProperties struct {
-	LastModified timeRFC1123 `xml:"Last-Modified"`
-	Etag         ETag        `xml:"Etag"`
-	Quota        int32       `xml:"Quota"`
+	LastModified           timeRFC1123               `xml:"Last-Modified"`
+	Etag                   ETag                      `xml:"Etag"`
+	Quota                  int32                     `xml:"Quota"`
+	DeletedTime            *timeRFC1123              `xml:"DeletedTime"`
+	RemainingRetentionDays *int32                    `xml:"RemainingRetentionDays"`
+	EnabledProtocols       ShareEnabledProtocolsType `xml:"EnabledProtocols"`
+	RootSquash             ShareRootSquashType       `xml:"RootSquash"`
 }
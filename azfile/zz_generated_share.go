@@ -6,6 +6,7 @@ package azfile
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"github.com/Azure/azure-pipeline-go/pipeline"
 	"io"
@@ -25,14 +26,217 @@ func newShareClient(url url.URL, p pipeline.Pipeline) shareClient {
 	return shareClient{newManagementClient(url, p)}
 }
 
+// AcquireLease the Lease Share operation establishes and manages a lock on a share, or the specified snapshot for set and
+// delete share operations.
+//
+// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> duration is specifies the duration of the lease, in seconds, or negative
+// one (-1) for a lease that never expires. A non-infinite lease can be between 15 and 60 seconds. A lease duration
+// cannot be changed using renew or change. proposedLeaseID is proposed lease ID, in a GUID string format. The File
+// service returns 400 (Invalid request) if the proposed lease ID is not in the correct format. See Guid Constructor
+// (String) for a list of valid GUID string formats. requestID is provides a client-generated, opaque value with a 1 KB
+// character limit that is recorded in the analytics logs when storage analytics logging is enabled.
+func (client shareClient) AcquireLease(ctx context.Context, timeout *int32, duration *int32, proposedLeaseID *string, requestID *string) (*ShareAcquireLeaseResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.acquireLeasePreparer(timeout, duration, proposedLeaseID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.acquireLeaseResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareAcquireLeaseResponse), err
+}
+
+// acquireLeasePreparer prepares the AcquireLease request.
+func (client shareClient) acquireLeasePreparer(timeout *int32, duration *int32, proposedLeaseID *string, requestID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("comp", "lease")
+	params.Set("restype", "share")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-lease-action", "acquire")
+	if duration != nil {
+		req.Header.Set("x-ms-lease-duration", strconv.FormatInt(int64(*duration), 10))
+	}
+	if proposedLeaseID != nil {
+		req.Header.Set("x-ms-proposed-lease-id", *proposedLeaseID)
+	}
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	return req, nil
+}
+
+// acquireLeaseResponder handles the response to the AcquireLease request.
+func (client shareClient) acquireLeaseResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK, http.StatusCreated)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareAcquireLeaseResponse{rawResponse: resp.Response()}, err
+}
+
+// BreakLease the Lease Share operation establishes and manages a lock on a share, or the specified snapshot for set and
+// delete share operations.
+//
+// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> breakPeriod is for a break operation, proposed duration the lease should
+// continue before it is broken, in seconds, between 0 and 60. This break period is only used if it is shorter than the
+// time remaining on the lease. If longer, the time remaining on the lease is used. A new lease will not be available
+// before the break period has expired, but the lease may be held for longer than the break period. If this header does
+// not appear with a break operation, a fixed-duration lease breaks after the remaining lease period elapses, and an
+// infinite lease breaks immediately. leaseID is if specified, the operation only succeeds if the resource's lease is
+// active and matches this ID. requestID is provides a client-generated, opaque value with a 1 KB character limit that
+// is recorded in the analytics logs when storage analytics logging is enabled.
+func (client shareClient) BreakLease(ctx context.Context, timeout *int32, breakPeriod *int32, leaseID *string, requestID *string) (*ShareBreakLeaseResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.breakLeasePreparer(timeout, breakPeriod, leaseID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.breakLeaseResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareBreakLeaseResponse), err
+}
+
+// breakLeasePreparer prepares the BreakLease request.
+func (client shareClient) breakLeasePreparer(timeout *int32, breakPeriod *int32, leaseID *string, requestID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("comp", "lease")
+	params.Set("restype", "share")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-lease-action", "break")
+	if breakPeriod != nil {
+		req.Header.Set("x-ms-lease-break-period", strconv.FormatInt(int64(*breakPeriod), 10))
+	}
+	if leaseID != nil {
+		req.Header.Set("x-ms-lease-id", *leaseID)
+	}
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	return req, nil
+}
+
+// breakLeaseResponder handles the response to the BreakLease request.
+func (client shareClient) breakLeaseResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK, http.StatusAccepted)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareBreakLeaseResponse{rawResponse: resp.Response()}, err
+}
+
+// ChangeLease the Lease Share operation establishes and manages a lock on a share, or the specified snapshot for set and
+// delete share operations.
+//
+// leaseID is specifies the current lease ID on the resource. timeout is the timeout parameter is expressed in seconds.
+// For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> proposedLeaseID is proposed lease ID, in a GUID string format. The File
+// service returns 400 (Invalid request) if the proposed lease ID is not in the correct format. See Guid Constructor
+// (String) for a list of valid GUID string formats. requestID is provides a client-generated, opaque value with a 1 KB
+// character limit that is recorded in the analytics logs when storage analytics logging is enabled.
+func (client shareClient) ChangeLease(ctx context.Context, leaseID string, timeout *int32, proposedLeaseID *string, requestID *string) (*ShareChangeLeaseResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.changeLeasePreparer(leaseID, timeout, proposedLeaseID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.changeLeaseResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareChangeLeaseResponse), err
+}
+
+// changeLeasePreparer prepares the ChangeLease request.
+func (client shareClient) changeLeasePreparer(leaseID string, timeout *int32, proposedLeaseID *string, requestID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("comp", "lease")
+	params.Set("restype", "share")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-lease-action", "change")
+	req.Header.Set("x-ms-lease-id", leaseID)
+	if proposedLeaseID != nil {
+		req.Header.Set("x-ms-proposed-lease-id", *proposedLeaseID)
+	}
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	return req, nil
+}
+
+// changeLeaseResponder handles the response to the ChangeLease request.
+func (client shareClient) changeLeaseResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareChangeLeaseResponse{rawResponse: resp.Response()}, err
+}
+
 // Create creates a new share under the specified account. If the share with the same name already exists, the
 // operation fails.
 //
 // timeout is the timeout parameter is expressed in seconds. For more information, see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
 // Timeouts for File Service Operations.</a> metadata is a name-value pair to associate with a file storage object.
-// quota is specifies the maximum size of the share, in gigabytes.
-func (client shareClient) Create(ctx context.Context, timeout *int32, metadata map[string]string, quota *int32) (*ShareCreateResponse, error) {
+// quota is specifies the maximum size of the share, in gigabytes. accessTier is specifies the access tier of the share.
+// enabledProtocols is protocols to enable on the share. rootSquash is root squash to set on the share.  Only valid for
+// NFS shares. encryptionScope is if specified, the name of the account's encryption scope with which the service
+// encrypts the data.
+func (client shareClient) Create(ctx context.Context, timeout *int32, metadata map[string]string, quota *int32, accessTier AccessTierType, enabledProtocols ShareEnabledProtocolsType, rootSquash ShareRootSquashType, encryptionScope *string) (*ShareCreateResponse, error) {
 	if err := validate([]validation{
 		{targetValue: timeout,
 			constraints: []constraint{{target: "timeout", name: null, rule: false,
@@ -42,7 +246,7 @@ func (client shareClient) Create(ctx context.Context, timeout *int32, metadata m
 				chain: []constraint{{target: "quota", name: inclusiveMinimum, rule: 1, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.createPreparer(timeout, metadata, quota)
+	req, err := client.createPreparer(timeout, metadata, quota, accessTier, enabledProtocols, rootSquash, encryptionScope)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +258,7 @@ func (client shareClient) Create(ctx context.Context, timeout *int32, metadata m
 }
 
 // createPreparer prepares the Create request.
-func (client shareClient) createPreparer(timeout *int32, metadata map[string]string, quota *int32) (pipeline.Request, error) {
+func (client shareClient) createPreparer(timeout *int32, metadata map[string]string, quota *int32, accessTier AccessTierType, enabledProtocols ShareEnabledProtocolsType, rootSquash ShareRootSquashType, encryptionScope *string) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("PUT", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -73,6 +277,18 @@ func (client shareClient) createPreparer(timeout *int32, metadata map[string]str
 	if quota != nil {
 		req.Header.Set("x-ms-share-quota", strconv.FormatInt(int64(*quota), 10))
 	}
+	if accessTier != AccessTierNone {
+		req.Header.Set("x-ms-access-tier", string(accessTier))
+	}
+	if enabledProtocols != ShareEnabledProtocolsNone {
+		req.Header.Set("x-ms-enabled-protocols", string(enabledProtocols))
+	}
+	if rootSquash != ShareRootSquashNone {
+		req.Header.Set("x-ms-root-squash", string(rootSquash))
+	}
+	if encryptionScope != nil {
+		req.Header.Set("x-ms-encryption-scope", *encryptionScope)
+	}
 	req.Header.Set("x-ms-version", ServiceVersion)
 	return req, nil
 }
@@ -88,6 +304,66 @@ func (client shareClient) createResponder(resp pipeline.Response) (pipeline.Resp
 	return &ShareCreateResponse{rawResponse: resp.Response()}, err
 }
 
+// CreatePermission create a permission (a security descriptor).
+//
+// sharePermission is a permission (a security descriptor) at the share level. timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a>
+func (client shareClient) CreatePermission(ctx context.Context, sharePermission SharePermission, timeout *int32) (*ShareCreatePermissionResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.createPermissionPreparer(sharePermission, timeout)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.createPermissionResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareCreatePermissionResponse), err
+}
+
+// createPermissionPreparer prepares the CreatePermission request.
+func (client shareClient) createPermissionPreparer(sharePermission SharePermission, timeout *int32) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "share")
+	params.Set("comp", "filepermission")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	b, err := json.Marshal(sharePermission)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to marshal request body")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	err = req.SetBody(bytes.NewReader(b))
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to set request body")
+	}
+	return req, nil
+}
+
+// createPermissionResponder handles the response to the CreatePermission request.
+func (client shareClient) createPermissionResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK, http.StatusCreated)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareCreatePermissionResponse{rawResponse: resp.Response()}, err
+}
+
 // CreateSnapshot creates a read-only snapshot of a share.
 //
 // timeout is the timeout parameter is expressed in seconds. For more information, see <a
@@ -151,15 +427,16 @@ func (client shareClient) createSnapshotResponder(resp pipeline.Response) (pipel
 // to query. timeout is the timeout parameter is expressed in seconds. For more information, see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
 // Timeouts for File Service Operations.</a> deleteSnapshots is specifies the option include to delete the base share
-// and all of its snapshots.
-func (client shareClient) Delete(ctx context.Context, sharesnapshot *string, timeout *int32, deleteSnapshots DeleteSnapshotsOptionType) (*ShareDeleteResponse, error) {
+// and all of its snapshots. leaseID is if specified, the operation only succeeds if the resource's lease is active and
+// matches this ID.
+func (client shareClient) Delete(ctx context.Context, sharesnapshot *string, timeout *int32, deleteSnapshots DeleteSnapshotsOptionType, leaseID *string) (*ShareDeleteResponse, error) {
 	if err := validate([]validation{
 		{targetValue: timeout,
 			constraints: []constraint{{target: "timeout", name: null, rule: false,
 				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.deletePreparer(sharesnapshot, timeout, deleteSnapshots)
+	req, err := client.deletePreparer(sharesnapshot, timeout, deleteSnapshots, leaseID)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +448,7 @@ func (client shareClient) Delete(ctx context.Context, sharesnapshot *string, tim
 }
 
 // deletePreparer prepares the Delete request.
-func (client shareClient) deletePreparer(sharesnapshot *string, timeout *int32, deleteSnapshots DeleteSnapshotsOptionType) (pipeline.Request, error) {
+func (client shareClient) deletePreparer(sharesnapshot *string, timeout *int32, deleteSnapshots DeleteSnapshotsOptionType, leaseID *string) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("DELETE", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -189,6 +466,9 @@ func (client shareClient) deletePreparer(sharesnapshot *string, timeout *int32,
 	if deleteSnapshots != DeleteSnapshotsOptionNone {
 		req.Header.Set("x-ms-delete-snapshots", string(deleteSnapshots))
 	}
+	if leaseID != nil {
+		req.Header.Set("x-ms-lease-id", *leaseID)
+	}
 	return req, nil
 }
 
@@ -268,6 +548,72 @@ func (client shareClient) getAccessPolicyResponder(resp pipeline.Response) (pipe
 	return result, nil
 }
 
+// GetPermission returns the permission (security descriptor) for a given key
+//
+// filePermissionKey is key of the permission to be set for the directory/file. timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a>
+func (client shareClient) GetPermission(ctx context.Context, filePermissionKey string, timeout *int32) (*SharePermission, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.getPermissionPreparer(filePermissionKey, timeout)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.getPermissionResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*SharePermission), err
+}
+
+// getPermissionPreparer prepares the GetPermission request.
+func (client shareClient) getPermissionPreparer(filePermissionKey string, timeout *int32) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("GET", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "share")
+	params.Set("comp", "filepermission")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-file-permission-key", filePermissionKey)
+	return req, nil
+}
+
+// getPermissionResponder handles the response to the GetPermission request.
+func (client shareClient) getPermissionResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	result := &SharePermission{rawResponse: resp.Response()}
+	if err != nil {
+		return result, err
+	}
+	defer resp.Response().Body.Close()
+	b, err := ioutil.ReadAll(resp.Response().Body)
+	if err != nil {
+		return result, err
+	}
+	if len(b) > 0 {
+		b = removeBOM(b)
+		err = json.Unmarshal(b, result)
+		if err != nil {
+			return result, NewResponseError(err, resp.Response(), "failed to unmarshal response body")
+		}
+	}
+	return result, nil
+}
+
 // GetProperties returns all user-defined metadata and system properties for the specified share or share snapshot. The
 // data returned does not include the share's list of files.
 //
@@ -388,6 +734,187 @@ func (client shareClient) getStatisticsResponder(resp pipeline.Response) (pipeli
 	return result, nil
 }
 
+// ReleaseLease the Lease Share operation establishes and manages a lock on a share, or the specified snapshot for set and
+// delete share operations.
+//
+// leaseID is specifies the current lease ID on the resource. timeout is the timeout parameter is expressed in seconds.
+// For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> requestID is provides a client-generated, opaque value with a 1 KB
+// character limit that is recorded in the analytics logs when storage analytics logging is enabled.
+func (client shareClient) ReleaseLease(ctx context.Context, leaseID string, timeout *int32, requestID *string) (*ShareReleaseLeaseResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.releaseLeasePreparer(leaseID, timeout, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.releaseLeaseResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareReleaseLeaseResponse), err
+}
+
+// releaseLeasePreparer prepares the ReleaseLease request.
+func (client shareClient) releaseLeasePreparer(leaseID string, timeout *int32, requestID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("comp", "lease")
+	params.Set("restype", "share")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-lease-action", "release")
+	req.Header.Set("x-ms-lease-id", leaseID)
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	return req, nil
+}
+
+// releaseLeaseResponder handles the response to the ReleaseLease request.
+func (client shareClient) releaseLeaseResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareReleaseLeaseResponse{rawResponse: resp.Response()}, err
+}
+
+// RenewLease the Lease Share operation establishes and manages a lock on a share, or the specified snapshot for set and
+// delete share operations.
+//
+// leaseID is specifies the current lease ID on the resource. timeout is the timeout parameter is expressed in seconds.
+// For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> requestID is provides a client-generated, opaque value with a 1 KB
+// character limit that is recorded in the analytics logs when storage analytics logging is enabled.
+func (client shareClient) RenewLease(ctx context.Context, leaseID string, timeout *int32, requestID *string) (*ShareRenewLeaseResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.renewLeasePreparer(leaseID, timeout, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.renewLeaseResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareRenewLeaseResponse), err
+}
+
+// renewLeasePreparer prepares the RenewLease request.
+func (client shareClient) renewLeasePreparer(leaseID string, timeout *int32, requestID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("comp", "lease")
+	params.Set("restype", "share")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	req.Header.Set("x-ms-lease-action", "renew")
+	req.Header.Set("x-ms-lease-id", leaseID)
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	return req, nil
+}
+
+// renewLeaseResponder handles the response to the RenewLease request.
+func (client shareClient) renewLeaseResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareRenewLeaseResponse{rawResponse: resp.Response()}, err
+}
+
+// Restore restores a previously deleted Share.
+//
+// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> requestID is provides a client-generated, opaque value with a 1 KB
+// character limit that is recorded in the analytics logs when storage analytics logging is enabled. deletedShareName is
+// specifies the name of the preivously-deleted share. deletedShareVersion is specifies the version of the
+// preivously-deleted share.
+func (client shareClient) Restore(ctx context.Context, timeout *int32, requestID *string, deletedShareName *string, deletedShareVersion *string) (*ShareRestoreResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.restorePreparer(timeout, requestID, deletedShareName, deletedShareVersion)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.restoreResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareRestoreResponse), err
+}
+
+// restorePreparer prepares the Restore request.
+func (client shareClient) restorePreparer(timeout *int32, requestID *string, deletedShareName *string, deletedShareVersion *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "share")
+	params.Set("comp", "undelete")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	if requestID != nil {
+		req.Header.Set("x-ms-client-request-id", *requestID)
+	}
+	if deletedShareName != nil {
+		req.Header.Set("x-ms-deleted-share-name", *deletedShareName)
+	}
+	if deletedShareVersion != nil {
+		req.Header.Set("x-ms-deleted-share-version", *deletedShareVersion)
+	}
+	return req, nil
+}
+
+// restoreResponder handles the response to the Restore request.
+func (client shareClient) restoreResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK, http.StatusCreated)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareRestoreResponse{rawResponse: resp.Response()}, err
+}
+
 // SetAccessPolicy sets a stored access policy for use with shared access signatures.
 //
 // shareACL is the ACL for the share. timeout is the timeout parameter is expressed in seconds. For more information,
@@ -454,14 +981,15 @@ func (client shareClient) setAccessPolicyResponder(resp pipeline.Response) (pipe
 // timeout is the timeout parameter is expressed in seconds. For more information, see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
 // Timeouts for File Service Operations.</a> metadata is a name-value pair to associate with a file storage object.
-func (client shareClient) SetMetadata(ctx context.Context, timeout *int32, metadata map[string]string) (*ShareSetMetadataResponse, error) {
+// leaseID is if specified, the operation only succeeds if the resource's lease is active and matches this ID.
+func (client shareClient) SetMetadata(ctx context.Context, timeout *int32, metadata map[string]string, leaseID *string) (*ShareSetMetadataResponse, error) {
 	if err := validate([]validation{
 		{targetValue: timeout,
 			constraints: []constraint{{target: "timeout", name: null, rule: false,
 				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.setMetadataPreparer(timeout, metadata)
+	req, err := client.setMetadataPreparer(timeout, metadata, leaseID)
 	if err != nil {
 		return nil, err
 	}
@@ -473,7 +1001,7 @@ func (client shareClient) SetMetadata(ctx context.Context, timeout *int32, metad
 }
 
 // setMetadataPreparer prepares the SetMetadata request.
-func (client shareClient) setMetadataPreparer(timeout *int32, metadata map[string]string) (pipeline.Request, error) {
+func (client shareClient) setMetadataPreparer(timeout *int32, metadata map[string]string, leaseID *string) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("PUT", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -490,6 +1018,9 @@ func (client shareClient) setMetadataPreparer(timeout *int32, metadata map[strin
 			req.Header.Set("x-ms-meta-"+k, v)
 		}
 	}
+	if leaseID != nil {
+		req.Header.Set("x-ms-lease-id", *leaseID)
+	}
 	req.Header.Set("x-ms-version", ServiceVersion)
 	return req, nil
 }
@@ -505,12 +1036,89 @@ func (client shareClient) setMetadataResponder(resp pipeline.Response) (pipeline
 	return &ShareSetMetadataResponse{rawResponse: resp.Response()}, err
 }
 
+// SetProperties sets properties for the specified share.
+//
+// timeout is the timeout parameter is expressed in seconds. For more information, see <a
+// href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
+// Timeouts for File Service Operations.</a> quota is specifies the maximum size of the share, in gigabytes. accessTier
+// is specifies the access tier of the share. provisionedIops is specifies the provisioned IOPS of a premium share.
+// provisionedBandwidthMibps is specifies the provisioned bandwidth of a premium share, in MiB/s. rootSquash is root
+// squash to set on the share.  Only valid for NFS shares. leaseID is if specified, the operation only succeeds if the
+// resource's lease is active and matches this ID.
+func (client shareClient) SetProperties(ctx context.Context, timeout *int32, quota *int32, accessTier AccessTierType, provisionedIops *int32, provisionedBandwidthMibps *int32, rootSquash ShareRootSquashType, leaseID *string) (*ShareSetPropertiesResponse, error) {
+	if err := validate([]validation{
+		{targetValue: timeout,
+			constraints: []constraint{{target: "timeout", name: null, rule: false,
+				chain: []constraint{{target: "timeout", name: inclusiveMinimum, rule: 0, chain: nil}}}}},
+		{targetValue: quota,
+			constraints: []constraint{{target: "quota", name: null, rule: false,
+				chain: []constraint{{target: "quota", name: inclusiveMinimum, rule: 1, chain: nil}}}}}}); err != nil {
+		return nil, err
+	}
+	req, err := client.setPropertiesPreparer(timeout, quota, accessTier, provisionedIops, provisionedBandwidthMibps, rootSquash, leaseID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pipeline().Do(ctx, responderPolicyFactory{responder: client.setPropertiesResponder}, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ShareSetPropertiesResponse), err
+}
+
+// setPropertiesPreparer prepares the SetProperties request.
+func (client shareClient) setPropertiesPreparer(timeout *int32, quota *int32, accessTier AccessTierType, provisionedIops *int32, provisionedBandwidthMibps *int32, rootSquash ShareRootSquashType, leaseID *string) (pipeline.Request, error) {
+	req, err := pipeline.NewRequest("PUT", client.url, nil)
+	if err != nil {
+		return req, pipeline.NewError(err, "failed to create request")
+	}
+	params := req.URL.Query()
+	if timeout != nil {
+		params.Set("timeout", strconv.FormatInt(int64(*timeout), 10))
+	}
+	params.Set("restype", "share")
+	params.Set("comp", "properties")
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("x-ms-version", ServiceVersion)
+	if quota != nil {
+		req.Header.Set("x-ms-share-quota", strconv.FormatInt(int64(*quota), 10))
+	}
+	if accessTier != AccessTierNone {
+		req.Header.Set("x-ms-access-tier", string(accessTier))
+	}
+	if provisionedIops != nil {
+		req.Header.Set("x-ms-share-provisioned-iops", strconv.FormatInt(int64(*provisionedIops), 10))
+	}
+	if provisionedBandwidthMibps != nil {
+		req.Header.Set("x-ms-share-provisioned-bandwidth-mibps", strconv.FormatInt(int64(*provisionedBandwidthMibps), 10))
+	}
+	if rootSquash != ShareRootSquashNone {
+		req.Header.Set("x-ms-root-squash", string(rootSquash))
+	}
+	if leaseID != nil {
+		req.Header.Set("x-ms-lease-id", *leaseID)
+	}
+	return req, nil
+}
+
+// setPropertiesResponder handles the response to the SetProperties request.
+func (client shareClient) setPropertiesResponder(resp pipeline.Response) (pipeline.Response, error) {
+	err := validateResponse(resp, http.StatusOK)
+	if resp == nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Response().Body)
+	resp.Response().Body.Close()
+	return &ShareSetPropertiesResponse{rawResponse: resp.Response()}, err
+}
+
 // SetQuota sets quota for the specified share.
 //
 // timeout is the timeout parameter is expressed in seconds. For more information, see <a
 // href="https://docs.microsoft.com/en-us/rest/api/storageservices/Setting-Timeouts-for-File-Service-Operations?redirectedfrom=MSDN">Setting
-// Timeouts for File Service Operations.</a> quota is specifies the maximum size of the share, in gigabytes.
-func (client shareClient) SetQuota(ctx context.Context, timeout *int32, quota *int32) (*ShareSetQuotaResponse, error) {
+// Timeouts for File Service Operations.</a> quota is specifies the maximum size of the share, in gigabytes. leaseID
+// is if specified, the operation only succeeds if the resource's lease is active and matches this ID.
+func (client shareClient) SetQuota(ctx context.Context, timeout *int32, quota *int32, leaseID *string) (*ShareSetQuotaResponse, error) {
 	if err := validate([]validation{
 		{targetValue: timeout,
 			constraints: []constraint{{target: "timeout", name: null, rule: false,
@@ -520,7 +1128,7 @@ func (client shareClient) SetQuota(ctx context.Context, timeout *int32, quota *i
 				chain: []constraint{{target: "quota", name: inclusiveMinimum, rule: 1, chain: nil}}}}}}); err != nil {
 		return nil, err
 	}
-	req, err := client.setQuotaPreparer(timeout, quota)
+	req, err := client.setQuotaPreparer(timeout, quota, leaseID)
 	if err != nil {
 		return nil, err
 	}
@@ -532,7 +1140,7 @@ func (client shareClient) SetQuota(ctx context.Context, timeout *int32, quota *i
 }
 
 // setQuotaPreparer prepares the SetQuota request.
-func (client shareClient) setQuotaPreparer(timeout *int32, quota *int32) (pipeline.Request, error) {
+func (client shareClient) setQuotaPreparer(timeout *int32, quota *int32, leaseID *string) (pipeline.Request, error) {
 	req, err := pipeline.NewRequest("PUT", client.url, nil)
 	if err != nil {
 		return req, pipeline.NewError(err, "failed to create request")
@@ -548,6 +1156,9 @@ func (client shareClient) setQuotaPreparer(timeout *int32, quota *int32) (pipeli
 	if quota != nil {
 		req.Header.Set("x-ms-share-quota", strconv.FormatInt(int64(*quota), 10))
 	}
+	if leaseID != nil {
+		req.Header.Set("x-ms-lease-id", *leaseID)
+	}
 	return req, nil
 }
 
@@ -5,7 +5,7 @@ package azfile
 
 // UserAgent returns the UserAgent string to use when sending http.Requests.
 func UserAgent() string {
-	return "Azure-SDK-For-Go/10.0.0 azfile/2018-03-28"
+	return "Azure-SDK-For-Go/10.0.0 azfile/2020-04-08"
 }
 
 // Version returns the semantic version (see http://semver.org) of the client.
@@ -15,6 +15,14 @@ type FileHTTPHeaders struct {
 	ContentLanguage    string
 	ContentDisposition string
 	CacheControl       string
+
+	// EncryptionScope, if not "", is the name of the account's encryption scope with which FileURL's Create and
+	// SetProperties methods have the service encrypt the file. A scope the account doesn't have fails the request.
+	EncryptionScope string
+
+	// SMBProperties are the file's SMB properties. FileURL's Create and SetProperties methods use them;
+	// SetHTTPHeaders keeps the file's current SMB properties.
+	SMBProperties
 }
 
 // NewHTTPHeaders returns the user-modifiable properties for this file.
@@ -26,9 +34,15 @@ func (dr DownloadResponse) NewHTTPHeaders() FileHTTPHeaders {
 		ContentDisposition: dr.ContentDisposition(),
 		CacheControl:       dr.CacheControl(),
 		ContentMD5:         dr.ContentMD5(),
+		EncryptionScope:    dr.EncryptionScope(),
 	}
 }
 
+// NewSMBProperties returns the SMB properties for this file.
+func (dr DownloadResponse) NewSMBProperties() SMBProperties {
+	return newSMBProperties(dr)
+}
+
 // NewHTTPHeaders returns the user-modifiable properties for this file.
 func (fgpr FileGetPropertiesResponse) NewHTTPHeaders() FileHTTPHeaders {
 	return FileHTTPHeaders{
@@ -38,6 +52,48 @@ func (fgpr FileGetPropertiesResponse) NewHTTPHeaders() FileHTTPHeaders {
 		ContentDisposition: fgpr.ContentDisposition(),
 		CacheControl:       fgpr.CacheControl(),
 		ContentMD5:         fgpr.ContentMD5(),
+		EncryptionScope:    fgpr.EncryptionScope(),
+	}
+}
+
+// NewSMBProperties returns the SMB properties for this file.
+func (fgpr FileGetPropertiesResponse) NewSMBProperties() SMBProperties {
+	return newSMBProperties(fgpr)
+}
+
+// NewSMBProperties returns the SMB properties for this directory.
+func (dgpr DirectoryGetPropertiesResponse) NewSMBProperties() SMBProperties {
+	return newSMBProperties(dgpr)
+}
+
+// DirectoryProperties are a directory's properties, parsed from its GetProperties response. The service doesn't
+// return a directory's number of files or subdirectories; list it for those.
+type DirectoryProperties struct {
+	// SMBProperties are the directory's attributes, creation, last write and change times, and the key of its
+	// permission.
+	SMBProperties
+
+	// FileID and FileParentID are the IDs of the directory and of its parent directory.
+	FileID, FileParentID string
+
+	// ServerEncrypted is true if the directory's metadata is encrypted with the share's encryption key.
+	ServerEncrypted bool
+
+	ETag         ETag
+	LastModified time.Time
+	Metadata     Metadata
+}
+
+// NewDirectoryProperties returns the properties of this directory.
+func (dgpr DirectoryGetPropertiesResponse) NewDirectoryProperties() DirectoryProperties {
+	return DirectoryProperties{
+		SMBProperties:   dgpr.NewSMBProperties(),
+		FileID:          dgpr.FileID(),
+		FileParentID:    dgpr.FileParentID(),
+		ServerEncrypted: dgpr.IsServerEncrypted() == "true",
+		ETag:            dgpr.ETag(),
+		LastModified:    dgpr.LastModified(),
+		Metadata:        dgpr.NewMetadata(),
 	}
 }
 
@@ -76,6 +132,11 @@ func (dr DownloadResponse) CacheControl() string {
 	return dr.dr.CacheControl()
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (dr DownloadResponse) ClientRequestID() string {
+	return dr.dr.ClientRequestID()
+}
+
 // ContentDisposition returns the value for header Content-Disposition.
 func (dr DownloadResponse) ContentDisposition() string {
 	return dr.dr.ContentDisposition()
@@ -141,6 +202,11 @@ func (dr DownloadResponse) Date() time.Time {
 	return dr.dr.Date()
 }
 
+// EncryptionScope returns the value for header x-ms-encryption-scope.
+func (dr DownloadResponse) EncryptionScope() string {
+	return dr.dr.EncryptionScope()
+}
+
 // ETag returns the value for header ETag.
 func (dr DownloadResponse) ETag() ETag {
 	return dr.dr.ETag()
@@ -156,6 +222,21 @@ func (dr DownloadResponse) LastModified() time.Time {
 	return dr.dr.LastModified()
 }
 
+// LeaseDuration returns the value for header x-ms-lease-duration.
+func (dr DownloadResponse) LeaseDuration() LeaseDurationType {
+	return dr.dr.LeaseDuration()
+}
+
+// LeaseState returns the value for header x-ms-lease-state.
+func (dr DownloadResponse) LeaseState() LeaseStateType {
+	return dr.dr.LeaseState()
+}
+
+// LeaseStatus returns the value for header x-ms-lease-status.
+func (dr DownloadResponse) LeaseStatus() LeaseStatusType {
+	return dr.dr.LeaseStatus()
+}
+
 // RequestID returns the value for header x-ms-request-id.
 func (dr DownloadResponse) RequestID() string {
 	return dr.dr.RequestID()
@@ -181,13 +262,54 @@ func (dr DownloadResponse) ContentMD5() []byte {
 	return dr.dr.ContentMD5()
 }
 
+// FileAttributes returns the value for header x-ms-file-attributes.
+func (dr DownloadResponse) FileAttributes() string {
+	return dr.dr.FileAttributes()
+}
+
+// FileChangeTime returns the value for header x-ms-file-change-time.
+func (dr DownloadResponse) FileChangeTime() string {
+	return dr.dr.FileChangeTime()
+}
+
+// FileCreationTime returns the value for header x-ms-file-creation-time.
+func (dr DownloadResponse) FileCreationTime() string {
+	return dr.dr.FileCreationTime()
+}
+
+// FileID returns the value for header x-ms-file-id.
+func (dr DownloadResponse) FileID() string {
+	return dr.dr.FileID()
+}
+
+// FileLastWriteTime returns the value for header x-ms-file-last-write-time.
+func (dr DownloadResponse) FileLastWriteTime() string {
+	return dr.dr.FileLastWriteTime()
+}
+
+// FileParentID returns the value for header x-ms-file-parent-id.
+func (dr DownloadResponse) FileParentID() string {
+	return dr.dr.FileParentID()
+}
+
+// FilePermissionKey returns the value for header x-ms-file-permission-key.
+func (dr DownloadResponse) FilePermissionKey() string {
+	return dr.dr.FilePermissionKey()
+}
+
 // FileItem - Listed file item.
 type FileItem struct {
 	// XMLName is used for marshalling and is subject to removal in a future release.
 	XMLName xml.Name `xml:"File"`
 	// Name - Name of the entry.
 	Name       string        `xml:"Name"`
+	FileID     *string       `xml:"FileId"`
 	Properties *FileProperty `xml:"Properties"`
+	// Attributes - The file's SMB attributes, e.g. "ReadOnly | Archive"; see ParseFileAttributeFlags.
+	Attributes    *string `xml:"Attributes"`
+	PermissionKey *string `xml:"PermissionKey"`
+	// Deleted - Whether the entry is soft-deleted, and can be restored with Undelete; see ListFilesAndDirectoriesDetail.Deleted.
+	Deleted *bool `xml:"Deleted"`
 }
 
 // DirectoryItem - Listed directory item.
@@ -195,7 +317,14 @@ type DirectoryItem struct {
 	// XMLName is used for marshalling and is subject to removal in a future release.
 	XMLName xml.Name `xml:"Directory"`
 	// Name - Name of the entry.
-	Name string `xml:"Name"`
+	Name       string        `xml:"Name"`
+	FileID     *string       `xml:"FileId"`
+	Properties *FileProperty `xml:"Properties"`
+	// Attributes - The directory's SMB attributes, e.g. "Directory | Hidden"; see ParseFileAttributeFlags.
+	Attributes    *string `xml:"Attributes"`
+	PermissionKey *string `xml:"PermissionKey"`
+	// Deleted - Whether the entry is soft-deleted, and can be restored with Undelete; see ListFilesAndDirectoriesDetail.Deleted.
+	Deleted *bool `xml:"Deleted"`
 }
 
 // ListFilesAndDirectoriesSegmentResponse - An enumeration of directories and files.
@@ -230,6 +359,11 @@ func (ldafr ListFilesAndDirectoriesSegmentResponse) Status() string {
 	return ldafr.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (ldafr ListFilesAndDirectoriesSegmentResponse) ClientRequestID() string {
+	return ldafr.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // ContentType returns the value for header Content-Type.
 func (ldafr ListFilesAndDirectoriesSegmentResponse) ContentType() string {
 	return ldafr.rawResponse.Header.Get("Content-Type")
@@ -284,8 +418,12 @@ type FileServiceProperties struct {
 	HourMetrics MetricProperties
 	// MinuteMetrics - A summary of request statistics grouped by API in minute aggregates for files.
 	MinuteMetrics MetricProperties
-	// Cors - The set of CORS rules.
+	// Cors - The set of CORS rules. When setting properties, nil leaves the service's rules unchanged, and an empty,
+	// non-nil slice removes them all.
 	Cors []CorsRule
+	// Protocol - The settings of the protocols used to access the service's shares. When setting properties, nil leaves
+	// them unchanged.
+	Protocol *ShareProtocolSettings
 }
 
 // Response returns the raw HTTP response object.
@@ -303,6 +441,11 @@ func (fsp FileServiceProperties) Status() string {
 	return fsp.rawResponse.Status
 }
 
+// ClientRequestID returns the value for header x-ms-client-request-id.
+func (fsp FileServiceProperties) ClientRequestID() string {
+	return fsp.rawResponse.Header.Get("x-ms-client-request-id")
+}
+
 // RequestID returns the value for header x-ms-request-id.
 func (fsp FileServiceProperties) RequestID() string {
 	return fsp.rawResponse.Header.Get("x-ms-request-id")
@@ -312,3 +455,17 @@ func (fsp FileServiceProperties) RequestID() string {
 func (fsp FileServiceProperties) Version() string {
 	return fsp.rawResponse.Header.Get("x-ms-version")
 }
+
+// NextMarker returns the Marker to pass to FileURL's ForceCloseHandles or ForceCloseAllHandles to close the handles
+// that remain after a partial close. Its NotDone method returns false once all of the handles are closed.
+func (r FileForceCloseHandlesResponse) NextMarker() Marker {
+	m := r.Marker()
+	return Marker{val: &m}
+}
+
+// NextMarker returns the Marker to pass to DirectoryURL's ForceCloseHandles or ForceCloseAllHandles to close the
+// handles that remain after a partial close. Its NotDone method returns false once all of the handles are closed.
+func (r DirectoryForceCloseHandlesResponse) NextMarker() Marker {
+	m := r.Marker()
+	return Marker{val: &m}
+}
@@ -4,6 +4,7 @@ require (
 	github.com/Azure/azure-pipeline-go v0.2.1
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/kr/pty v1.1.5 // indirect
+	github.com/mattn/go-ieproxy v0.0.0-20190610004146-91bb50d98149
 	golang.org/x/sys v0.0.0-20190626221950-04f50cda93cb
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127
 )